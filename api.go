@@ -0,0 +1,65 @@
+package dynamodb
+
+import (
+	"context"
+)
+
+// TableAPI is the item level surface of a Table. Code depending on it
+// rather than on *Table can be unit tested against a mock or a fake.
+type TableAPI interface {
+	GetItem(key *Key, isRetry bool) (map[string]*Attribute, error)
+	GetItemConsistent(key *Key, consistentRead bool, isRetry bool) (map[string]*Attribute, error)
+	GetFirstExisting(keys []Key, isRetry bool) (map[string]*Attribute, int, error)
+	GetFirstExistingContext(ctx context.Context, keys []Key, isRetry bool) (map[string]*Attribute, int, error)
+
+	PutItem(hashKey string, rangeKey string, attributes []Attribute, isRetry bool) (bool, error)
+	ConditionalPutItem(hashKey, rangeKey string, attributes, expected []Attribute, isRetry bool) (bool, error)
+	PutItemWithOptions(ctx context.Context, item []Attribute, opts *WriteOptions) (*WriteResult, error)
+	DeleteItemWithOptions(ctx context.Context, key *Key, opts *WriteOptions) (*WriteResult, error)
+	UpdateItemWithOptions(ctx context.Context, key *Key, attributes []Attribute, action string, opts *WriteOptions) (*WriteResult, error)
+
+	DeleteItem(key *Key, isRetry bool) (bool, error)
+	ConditionalDeleteItem(key *Key, expected []Attribute, isRetry bool) (bool, error)
+	AddAttributes(key *Key, attributes []Attribute, isRetry bool) (bool, error)
+	UpdateAttributes(key *Key, attributes []Attribute, isRetry bool) (bool, error)
+	DeleteAttributes(key *Key, attributes []Attribute, isRetry bool) (bool, error)
+	ConditionalAddAttributes(key *Key, attributes, expected []Attribute, isRetry bool) (bool, error)
+	ConditionalUpdateAttributes(key *Key, attributes, expected []Attribute, isRetry bool) (bool, error)
+	ConditionalDeleteAttributes(key *Key, attributes, expected []Attribute, isRetry bool) (bool, error)
+	Increment(key *Key, attribute string, delta int64, isRetry bool) (int64, error)
+
+	Query(attributeComparisons []AttributeComparison, isRetry bool) ([]map[string]*Attribute, error)
+	QueryOnIndex(attributeComparisons []AttributeComparison, indexName string, isRetry bool) ([]map[string]*Attribute, error)
+	LimitedQuery(attributeComparisons []AttributeComparison, limit int64, isRetry bool) ([]map[string]*Attribute, error)
+	QueryWithOptions(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions) ([]map[string]*Attribute, *Key, error)
+	CountQuery(attributeComparisons []AttributeComparison, isRetry bool) (int64, error)
+	QueryTable(q *Query, isRetry bool) ([]map[string]*Attribute, *Key, error)
+
+	Scan(attributeComparisons []AttributeComparison, isRetry bool) ([]map[string]*Attribute, error)
+	ScanCount(attributeComparisons []AttributeComparison, isRetry bool) (int64, error)
+	ScanPartial(attributeComparisons []AttributeComparison, exclusiveStartKey *Key, isRetry bool) ([]map[string]*Attribute, *Key, error)
+	ParallelScan(attributeComparisons []AttributeComparison, segment int, totalSegments int, isRetry bool) ([]map[string]*Attribute, error)
+	FetchResults(query *Query, isRetry bool) ([]map[string]*Attribute, error)
+	FetchPartialResults(query *Query, isRetry bool) ([]map[string]*Attribute, *Key, error)
+
+	BatchGetItems(keys []Key) *BatchGetItem
+	BatchWriteItems(itemActions map[string][][]Attribute) *BatchWriteItem
+
+	KeyFromItem(item map[string]*Attribute) (*Key, error)
+	DescribeTable(isRetry bool) (*TableDescriptionT, error)
+}
+
+// ServerAPI is the table management surface of a Server.
+type ServerAPI interface {
+	ListTables(isRetry bool) ([]string, error)
+	CreateTable(tableDescription TableDescriptionT, isRetry bool) (string, error)
+	DeleteTable(tableDescription TableDescriptionT, isRetry bool) (string, error)
+	DescribeTable(name string, isRetry bool) (*TableDescriptionT, error)
+	UpdateTimeToLive(tableName string, attributeName string, enabled bool, isRetry bool) error
+	DescribeTimeToLive(tableName string, isRetry bool) (*TimeToLiveDescriptionT, error)
+}
+
+var (
+	_ TableAPI  = (*Table)(nil)
+	_ ServerAPI = (*Server)(nil)
+)
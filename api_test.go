@@ -0,0 +1,66 @@
+package dynamodb_test
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type APISuite struct{}
+
+var _ = check.Suite(&APISuite{})
+
+// mockTable stores items in a map. The embedded TableAPI, left nil, makes
+// the methods the code under test must not call panic.
+type mockTable struct {
+	dynamodb.TableAPI
+	items map[string]map[string]*dynamodb.Attribute
+}
+
+func (m *mockTable) GetItem(key *dynamodb.Key, isRetry bool) (map[string]*dynamodb.Attribute, error) {
+	item, ok := m.items[key.HashKey]
+	if !ok {
+		return nil, dynamodb.ErrNotFound
+	}
+	return item, nil
+}
+
+func (m *mockTable) PutItemWithOptions(ctx context.Context, attributes []dynamodb.Attribute, opts *dynamodb.WriteOptions) (*dynamodb.WriteResult, error) {
+	item := make(map[string]*dynamodb.Attribute)
+	for i := range attributes {
+		item[attributes[i].Name] = &attributes[i]
+	}
+	m.items[item["id"].Value] = item
+	return &dynamodb.WriteResult{}, nil
+}
+
+// visit counts a visit of id, as code written against TableAPI would.
+func visit(t dynamodb.TableAPI, id string) (int, error) {
+	visits := 0
+	item, err := t.GetItem(&dynamodb.Key{HashKey: id}, false)
+	if err == nil {
+		visits, _ = strconv.Atoi(item["visits"].Value)
+	} else if err != dynamodb.ErrNotFound {
+		return 0, err
+	}
+	visits++
+	_, err = t.PutItemWithOptions(context.Background(), []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", id),
+		*dynamodb.NewNumericAttribute("visits", strconv.Itoa(visits)),
+	}, nil)
+	return visits, err
+}
+
+func (s *APISuite) TestMockTable(c *check.C) {
+	mock := &mockTable{items: make(map[string]map[string]*dynamodb.Attribute)}
+
+	visits, err := visit(mock, "u1")
+	c.Assert(err, check.IsNil)
+	c.Check(visits, check.Equals, 1)
+	visits, err = visit(mock, "u1")
+	c.Assert(err, check.IsNil)
+	c.Check(visits, check.Equals, 2)
+	c.Check(mock.items["u1"]["visits"].Value, check.Equals, "2")
+}
@@ -0,0 +1,155 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+)
+
+// DefaultAssumeRoleDuration is how long the credentials of an
+// AssumeRoleProvider last when its Duration is zero.
+const DefaultAssumeRoleDuration = time.Hour
+
+// An AssumeRoleProvider retrieves temporary credentials of a role with STS
+// AssumeRole, typically to access the tables of another account. Wrap it
+// in a CredentialsCache, as NewWithAssumeRole does, to assume the role
+// again only when the credentials are about to expire.
+type AssumeRoleProvider struct {
+	RoleARN     string
+	SessionName string
+	// The credentials allowed to assume the role.
+	Base aws.Auth
+	// The region of the STS endpoint, STSEndpoint defaulting to the
+	// regional one.
+	Region aws.Region
+	// Lifetime of the credentials, DefaultAssumeRoleDuration when zero.
+	Duration time.Duration
+	// Required by some roles assumable from other accounts.
+	ExternalID string
+	// http.DefaultClient when nil.
+	HTTPClient *http.Client
+
+	mu sync.Mutex
+}
+
+// NewWithAssumeRole returns a Server for region signing its requests with
+// the credentials of roleARN, assumed with baseCreds and renewed before
+// they expire.
+func NewWithAssumeRole(roleARN, sessionName string, baseCreds aws.Auth, region aws.Region) *Server {
+	s := New(aws.Auth{}, region)
+	s.Credentials = NewCredentialsCache(&AssumeRoleProvider{
+		RoleARN:     roleARN,
+		SessionName: sessionName,
+		Base:        baseCreds,
+		Region:      region,
+	})
+	return s
+}
+
+type assumeRoleResponse struct {
+	Credentials struct {
+		AccessKeyId     string
+		SecretAccessKey string
+		SessionToken    string
+		Expiration      time.Time
+	} `xml:"AssumeRoleResult>Credentials"`
+}
+
+type stsErrorResponse struct {
+	Code      string `xml:"Error>Code"`
+	Message   string `xml:"Error>Message"`
+	RequestID string `xml:"RequestId"`
+}
+
+// baseAuth returns the credentials to sign AssumeRole with, and their
+// session token. Token may replace the credentials it is called on, so it
+// is called on a copy of Base, which concurrent renewals share.
+func (p *AssumeRoleProvider) baseAuth() (aws.Auth, string) {
+	p.mu.Lock()
+	base := p.Base
+	p.mu.Unlock()
+	token := base.Token()
+	return *aws.NewAuth(base.AccessKey, base.SecretKey, token, authNeverExpires), token
+}
+
+func (p *AssumeRoleProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	duration := p.Duration
+	if duration <= 0 {
+		duration = DefaultAssumeRoleDuration
+	}
+	form := url.Values{
+		"Action":          {"AssumeRole"},
+		"Version":         {"2011-06-15"},
+		"RoleArn":         {p.RoleARN},
+		"RoleSessionName": {p.SessionName},
+		"DurationSeconds": {strconv.Itoa(int(duration / time.Second))},
+	}
+	if p.ExternalID != "" {
+		form.Set("ExternalId", p.ExternalID)
+	}
+
+	endpoint := p.Region.STSEndpoint
+	if endpoint == "" {
+		if p.Region.Name == "" {
+			return Credentials{}, errors.New("No STS endpoint to assume the role with.")
+		}
+		endpoint = "https://sts." + p.Region.Name + "." + partitionSuffix(p.Region.Name)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimSuffix(endpoint, "/")+"/", strings.NewReader(form.Encode()))
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	req.Header.Set("X-Amz-Date", time.Now().UTC().Format(aws.ISO8601BasicFormat))
+	base, token := p.baseAuth()
+	if token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+	aws.NewV4Signer(base, "sts", p.Region).Sign(req)
+
+	client := p.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	if resp.StatusCode != 200 {
+		var e stsErrorResponse
+		xml.Unmarshal(body, &e)
+		return Credentials{}, &Error{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Code:       e.Code,
+			Message:    e.Message,
+			RequestID:  e.RequestID,
+		}
+	}
+	var r assumeRoleResponse
+	if err := xml.Unmarshal(body, &r); err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{
+		AccessKeyID:     r.Credentials.AccessKeyId,
+		SecretAccessKey: r.Credentials.SecretAccessKey,
+		SessionToken:    r.Credentials.SessionToken,
+		CanExpire:       true,
+		Expires:         r.Credentials.Expiration,
+	}, nil
+}
@@ -0,0 +1,146 @@
+package dynamodb_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type AssumeRoleSuite struct {
+	sts     *httptest.Server
+	region  aws.Region
+	expires time.Duration // lifetime of the credentials handed out
+
+	mu    sync.Mutex
+	forms []map[string]string
+	fail  bool
+}
+
+var _ = check.Suite(&AssumeRoleSuite{})
+
+func (s *AssumeRoleSuite) SetUpTest(c *check.C) {
+	s.forms, s.fail, s.expires = nil, false, time.Hour
+	s.sts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.ParseForm(), check.IsNil)
+		form := map[string]string{"Authorization": r.Header.Get("Authorization"), "X-Amz-Security-Token": r.Header.Get("X-Amz-Security-Token")}
+		for name := range r.PostForm {
+			form[name] = r.PostForm.Get(name)
+		}
+		s.mu.Lock()
+		s.forms = append(s.forms, form)
+		n := len(s.forms)
+		s.mu.Unlock()
+
+		if s.fail {
+			w.WriteHeader(403)
+			fmt.Fprint(w, `<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code><Message>Not authorized to perform sts:AssumeRole</Message></Error><RequestId>req-1</RequestId></ErrorResponse>`)
+			return
+		}
+		fmt.Fprintf(w, `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>ASIA%d</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>token-%d</SessionToken>
+      <Expiration>%s</Expiration>
+    </Credentials>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`, n, n, time.Now().Add(s.expires).UTC().Format(time.RFC3339))
+	}))
+	s.region = aws.Region{Name: "us-east-1", DynamoDBEndpoint: "http://127.0.0.1:1", STSEndpoint: s.sts.URL}
+}
+
+func (s *AssumeRoleSuite) TearDownTest(c *check.C) {
+	s.sts.Close()
+}
+
+func (s *AssumeRoleSuite) signed(c *check.C, server *dynamodb.Server) *dynamodb.SignedRequest {
+	var request *dynamodb.SignedRequest
+	ctx := dynamodb.WithDryRun(context.Background(), func(req *dynamodb.SignedRequest) { request = req })
+	_, err := server.DoContext(ctx, dynamodb.OPERATION_LIST_TABLES, []byte("{}"), false)
+	c.Check(err, check.Equals, dynamodb.ErrDryRun)
+	c.Assert(request, check.NotNil)
+	return request
+}
+
+func (s *AssumeRoleSuite) TestNewWithAssumeRole(c *check.C) {
+	base := aws.Auth{AccessKey: "BASE_KEY", SecretKey: "BASE_SECRET"}
+	server := dynamodb.NewWithAssumeRole("arn:aws:iam::123456789012:role/reader", "reports", base, s.region)
+
+	for i := 0; i < 2; i++ {
+		request := s.signed(c, server)
+		c.Check(strings.HasPrefix(request.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=ASIA1/"), check.Equals, true)
+		c.Check(request.Header.Get("X-Amz-Security-Token"), check.Equals, "token-1")
+	}
+	c.Assert(s.forms, check.HasLen, 1)
+	c.Check(s.forms[0]["Action"], check.Equals, "AssumeRole")
+	c.Check(s.forms[0]["RoleArn"], check.Equals, "arn:aws:iam::123456789012:role/reader")
+	c.Check(s.forms[0]["RoleSessionName"], check.Equals, "reports")
+	c.Check(s.forms[0]["DurationSeconds"], check.Equals, "3600")
+	c.Check(strings.Contains(s.forms[0]["Authorization"], "Credential=BASE_KEY/"), check.Equals, true)
+}
+
+func (s *AssumeRoleSuite) TestRenewal(c *check.C) {
+	// Credentials expiring within the expiry window are renewed on use.
+	s.expires = time.Minute
+	server := dynamodb.NewWithAssumeRole("arn:aws:iam::123456789012:role/reader", "reports", aws.Auth{}, s.region)
+	c.Check(s.signed(c, server).Header.Get("X-Amz-Security-Token"), check.Equals, "token-1")
+	s.expires = time.Hour
+	c.Check(s.signed(c, server).Header.Get("X-Amz-Security-Token"), check.Equals, "token-2")
+	c.Check(s.signed(c, server).Header.Get("X-Amz-Security-Token"), check.Equals, "token-2")
+}
+
+func (s *AssumeRoleSuite) TestProvider(c *check.C) {
+	p := &dynamodb.AssumeRoleProvider{
+		RoleARN:     "arn:aws:iam::123456789012:role/reader",
+		SessionName: "reports",
+		Region:      s.region,
+		Duration:    15 * time.Minute,
+		ExternalID:  "partner",
+	}
+	creds, err := p.Retrieve(context.Background())
+	c.Assert(err, check.IsNil)
+	c.Check(creds.AccessKeyID, check.Equals, "ASIA1")
+	c.Check(creds.CanExpire, check.Equals, true)
+	c.Check(creds.Expires.After(time.Now()), check.Equals, true)
+	c.Check(s.forms[0]["DurationSeconds"], check.Equals, "900")
+	c.Check(s.forms[0]["ExternalId"], check.Equals, "partner")
+
+	s.fail = true
+	_, err = p.Retrieve(context.Background())
+	c.Check(err, check.ErrorMatches, "AccessDenied: Not authorized to perform sts:AssumeRole .request id req-1.")
+	c.Check(dynamodb.ErrorCode(err), check.Equals, "AccessDenied")
+}
+
+func (s *AssumeRoleSuite) TestBaseSessionToken(c *check.C) {
+	p := &dynamodb.AssumeRoleProvider{
+		RoleARN:     "arn:aws:iam::123456789012:role/reader",
+		SessionName: "reports",
+		Base:        *aws.NewAuth("ASIABASE", "BASE_SECRET", "base-token", time.Now().Add(time.Hour)),
+		Region:      s.region,
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := p.Retrieve(context.Background())
+			c.Check(err, check.IsNil)
+		}()
+	}
+	wg.Wait()
+
+	c.Assert(s.forms, check.HasLen, 4)
+	for _, form := range s.forms {
+		c.Check(form["X-Amz-Security-Token"], check.Equals, "base-token")
+		c.Check(form["Authorization"], check.Matches, "AWS4-HMAC-SHA256 Credential=ASIABASE/.*")
+	}
+}
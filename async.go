@@ -0,0 +1,58 @@
+package dynamodb
+
+import (
+	"context"
+)
+
+// AsyncItem is the outcome of GetItemAsync.
+type AsyncItem struct {
+	Item map[string]*Attribute
+	Err  error
+}
+
+// AsyncPage is the outcome of QueryAsync and ScanAsync.
+type AsyncPage struct {
+	Page *PageResult
+	Err  error
+}
+
+// GetItemAsync runs GetItemConsistent in its own goroutine and returns a
+// channel receiving its outcome, so that many reads can be in flight at
+// once:
+//
+//	a := t.GetItemAsync(keyA, false, false)
+//	b := t.GetItemAsync(keyB, false, false)
+//	resultA, resultB := <-a, <-b
+//
+// The channel is buffered: the goroutine does not leak when the outcome is
+// never received.
+func (t *Table) GetItemAsync(key *Key, consistentRead bool, isRetry bool) <-chan AsyncItem {
+	ch := make(chan AsyncItem, 1)
+	go func() {
+		item, err := t.GetItemConsistent(key, consistentRead, isRetry)
+		ch <- AsyncItem{item, err}
+	}()
+	return ch
+}
+
+// QueryAsync is QueryPage run as GetItemAsync. Cancelling ctx aborts the
+// request.
+func (t *Table) QueryAsync(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions) <-chan AsyncPage {
+	ch := make(chan AsyncPage, 1)
+	go func() {
+		page, err := t.QueryPage(ctx, keyConditions, opts)
+		ch <- AsyncPage{page, err}
+	}()
+	return ch
+}
+
+// ScanAsync is ScanPage run as GetItemAsync. Cancelling ctx aborts the
+// request.
+func (t *Table) ScanAsync(ctx context.Context, opts *ScanOptions) <-chan AsyncPage {
+	ch := make(chan AsyncPage, 1)
+	go func() {
+		page, err := t.ScanPage(ctx, opts)
+		ch <- AsyncPage{page, err}
+	}()
+	return ch
+}
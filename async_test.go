@@ -0,0 +1,80 @@
+package dynamodb_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type AsyncSuite struct {
+	table *dynamodb.Table
+
+	mu       sync.Mutex
+	inFlight int
+	maxIn    int
+}
+
+var _ = check.Suite(&AsyncSuite{})
+
+func (s *AsyncSuite) SetUpTest(c *check.C) {
+	s.inFlight, s.maxIn = 0, 0
+	server, _ := dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation == "GetItem" {
+				s.mu.Lock()
+				s.inFlight++
+				if s.inFlight > s.maxIn {
+					s.maxIn = s.inFlight
+				}
+				s.mu.Unlock()
+				time.Sleep(20 * time.Millisecond)
+				defer func() {
+					s.mu.Lock()
+					s.inFlight--
+					s.mu.Unlock()
+				}()
+			}
+			return next(req)
+		}
+	})
+
+	s.table = createTable(c, server, tableSchema(c, "users", idKey{}))
+
+	for _, id := range []string{"u1", "u2", "u3"} {
+		_, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone(id, ""), *dynamodb.NewStringAttribute("name", id)), nil)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *AsyncSuite) TestGetItemAsync(c *check.C) {
+	var results []<-chan dynamodb.AsyncItem
+	for _, id := range []string{"u1", "u2", "u3", "missing"} {
+		results = append(results, s.table.GetItemAsync(&dynamodb.Key{HashKey: id}, false, false))
+	}
+	for i, id := range []string{"u1", "u2", "u3"} {
+		r := <-results[i]
+		c.Assert(r.Err, check.IsNil)
+		c.Check(r.Item["name"].Value, check.Equals, id)
+	}
+	c.Check((<-results[3]).Err, check.Equals, dynamodb.ErrNotFound)
+	c.Check(s.maxIn > 1, check.Equals, true)
+}
+
+func (s *AsyncSuite) TestQueryAndScanAsync(c *check.C) {
+	ctx := context.Background()
+	query := s.table.QueryAsync(ctx, []dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("id", "u2"),
+	}, nil)
+	scan := s.table.ScanAsync(ctx, nil)
+
+	q := <-query
+	c.Assert(q.Err, check.IsNil)
+	c.Check(q.Page.Items, check.HasLen, 1)
+	r := <-scan
+	c.Assert(r.Err, check.IsNil)
+	c.Check(r.Page.Count, check.Equals, int64(3))
+}
@@ -13,6 +13,10 @@ const (
 	TYPE_NUMBER_SET = "NS"
 	TYPE_BINARY_SET = "BS"
 
+	TYPE_LIST = "L"
+	TYPE_MAP  = "M"
+	TYPE_NULL = "NULL"
+
 	COMPARISON_EQUAL                    = "EQ"
 	COMPARISON_NOT_EQUAL                = "NE"
 	COMPARISON_LESS_THAN_OR_EQUAL       = "LE"
@@ -26,11 +30,25 @@ const (
 	COMPARISON_BEGINS_WITH              = "BEGINS_WITH"
 	COMPARISON_IN                       = "IN"
 	COMPARISON_BETWEEN                  = "BETWEEN"
+
+	CONDITIONAL_OPERATOR_AND = "AND"
+	CONDITIONAL_OPERATOR_OR  = "OR"
 )
 
+// Key values are in their wire format whatever the key types are: decimal
+// strings for numeric keys and base64 for binary keys. NewKey and
+// Table.NewKey build one from Go values.
+//
+// The keys returned to resume a Query or Scan of a secondary index also
+// carry the index key attributes of the last item, which Dynamodb needs
+// in ExclusiveStartKey, so pass them back unchanged.
 type Key struct {
 	HashKey  string
 	RangeKey string
+
+	// exclusiveStart is the JSON of the LastEvaluatedKey the key comes
+	// from, when it holds more than the table key.
+	exclusiveStart string
 }
 
 type PrimaryKey struct {
@@ -43,6 +61,10 @@ type Attribute struct {
 	Name      string
 	Value     string
 	SetValues []string
+	// Elements of a list, whose Names are unused.
+	ListValues []Attribute
+	// Members of a map, each named after its key.
+	MapValues map[string]*Attribute
 	Exists    string // exists on dynamodb? Values: "true", "false", or ""
 }
 
@@ -108,6 +130,8 @@ func NewNumericAttribute(name string, value string) *Attribute {
 	}
 }
 
+// NewBinaryAttribute expects value to be base64 encoded already; use
+// NewBytesAttribute to store raw bytes.
 func NewBinaryAttribute(name string, value string) *Attribute {
 	return &Attribute{
 		Type:  TYPE_BINARY,
@@ -140,6 +164,60 @@ func NewBinarySetAttribute(name string, values []string) *Attribute {
 	}
 }
 
+// NewNullAttribute returns a NULL value.
+func NewNullAttribute(name string) *Attribute {
+	return &Attribute{
+		Type:  TYPE_NULL,
+		Name:  name,
+		Value: "true",
+	}
+}
+
+// NewListAttribute returns a list of values, whose Names are unused.
+func NewListAttribute(name string, values []Attribute) *Attribute {
+	return &Attribute{
+		Type:       TYPE_LIST,
+		Name:       name,
+		ListValues: values,
+	}
+}
+
+// NewMapAttribute returns a map of values, keyed by their Names.
+func NewMapAttribute(name string, values []Attribute) *Attribute {
+	members := make(map[string]*Attribute, len(values))
+	for i := range values {
+		members[values[i].Name] = &values[i]
+	}
+	return &Attribute{
+		Type:      TYPE_MAP,
+		Name:      name,
+		MapValues: members,
+	}
+}
+
+// wireValue returns the value of a in the wire format, e.g. {"S": "abc"}.
+func (a *Attribute) wireValue() msi {
+	switch {
+	case a.SetType():
+		return msi{a.Type: a.SetValues}
+	case a.Type == TYPE_LIST:
+		values := make([]msi, len(a.ListValues))
+		for i := range a.ListValues {
+			values[i] = a.ListValues[i].wireValue()
+		}
+		return msi{a.Type: values}
+	case a.Type == TYPE_MAP:
+		members := make(map[string]msi, len(a.MapValues))
+		for key, member := range a.MapValues {
+			members[key] = member.wireValue()
+		}
+		return msi{a.Type: members}
+	case a.Type == TYPE_NULL:
+		return msi{a.Type: true}
+	}
+	return msi{a.Type: a.Value}
+}
+
 func (a *Attribute) SetType() bool {
 	switch a.Type {
 	case TYPE_BINARY_SET, TYPE_NUMBER_SET, TYPE_STRING_SET:
@@ -161,6 +239,20 @@ func (k *PrimaryKey) HasRange() bool {
 	return k.RangeAttribute != nil
 }
 
+// attributeCount returns the number of key attributes, 1 or 2.
+func (k *PrimaryKey) attributeCount() int {
+	if k.HasRange() {
+		return 2
+	}
+	return 1
+}
+
+// isKeyAttribute reports whether name is the hash or range attribute.
+func (k *PrimaryKey) isKeyAttribute(name string) bool {
+	return (k.KeyAttribute != nil && k.KeyAttribute.Name == name) ||
+		(k.RangeAttribute != nil && k.RangeAttribute.Name == name)
+}
+
 // Useful when you may have many goroutines using a primary key, so they don't fuxor up your values.
 func (k *PrimaryKey) Clone(h string, r string) []Attribute {
 	pk := &Attribute{
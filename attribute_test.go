@@ -0,0 +1,170 @@
+package dynamodb_test
+
+import (
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type AttributeSuite struct {
+}
+
+var _ = check.Suite(&AttributeSuite{})
+
+func (s *AttributeSuite) TestNewTTLAttribute(c *check.C) {
+	expiresAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	c.Check(dynamodb.NewTTLAttribute("ttl", expiresAt), check.DeepEquals, dynamodb.NewNumericAttribute("ttl", "1577934245"))
+}
+
+func (s *AttributeSuite) TestNumberConversions(c *check.C) {
+	c.Check(dynamodb.NewInt64Attribute("n", -42), check.DeepEquals, dynamodb.NewNumericAttribute("n", "-42"))
+	c.Check(dynamodb.NewUint64Attribute("n", math.MaxUint64), check.DeepEquals, dynamodb.NewNumericAttribute("n", "18446744073709551615"))
+
+	i, err := dynamodb.NewNumericAttribute("n", "1.5E3").Int64()
+	c.Check(err, check.IsNil)
+	c.Check(i, check.Equals, int64(1500))
+
+	_, err = dynamodb.NewNumericAttribute("n", "9223372036854775808").Int64()
+	c.Check(err, check.ErrorMatches, ".*overflows int64.")
+	_, err = dynamodb.NewNumericAttribute("n", "-1").Uint64()
+	c.Check(err, check.ErrorMatches, ".*overflows uint64.")
+	_, err = dynamodb.NewNumericAttribute("n", "1.5").Int64()
+	c.Check(err, check.ErrorMatches, ".*is not an integer.")
+	_, err = dynamodb.NewStringAttribute("n", "1").Int64()
+	c.Check(err, check.ErrorMatches, ".*not a number.")
+
+	f, err := dynamodb.NewNumericAttribute("n", "0.25").Float64()
+	c.Check(err, check.IsNil)
+	c.Check(f, check.Equals, 0.25)
+}
+
+func (s *AttributeSuite) TestNumberRange(c *check.C) {
+	_, err := dynamodb.NewFloat64Attribute("n", math.NaN())
+	c.Check(err, check.NotNil)
+	_, err = dynamodb.NewFloat64Attribute("n", 1e200)
+	c.Check(err, check.ErrorMatches, ".*out of the range of a number.")
+	a, err := dynamodb.NewFloat64Attribute("n", 1e-100)
+	c.Check(err, check.IsNil)
+	c.Check(a.Value, check.Equals, "1e-100")
+
+	big38, _ := new(big.Int).SetString("12345678901234567890123456789012345678", 10)
+	a, err = dynamodb.NewBigIntAttribute("n", big38)
+	c.Check(err, check.IsNil)
+	back, err := a.BigInt()
+	c.Check(err, check.IsNil)
+	c.Check(back.Cmp(big38), check.Equals, 0)
+
+	big39 := new(big.Int).Add(new(big.Int).Mul(big38, big.NewInt(10)), big.NewInt(9))
+	_, err = dynamodb.NewBigIntAttribute("n", big39)
+	c.Check(err, check.ErrorMatches, ".*more than 38 significant digits.")
+
+	// Trailing zeros are not significant.
+	_, err = dynamodb.NewBigIntAttribute("n", new(big.Int).Exp(big.NewInt(10), big.NewInt(100), nil))
+	c.Check(err, check.IsNil)
+
+	bf, err := dynamodb.NewNumericAttribute("n", "1234567890.12345678901234567890123").BigFloat()
+	c.Check(err, check.IsNil)
+	c.Check(bf.Text('f', 23), check.Equals, "1234567890.12345678901234567890123")
+}
+
+func (s *AttributeSuite) TestBytes(c *check.C) {
+	a := dynamodb.NewBytesAttribute("b", []byte("bytes"))
+	c.Check(a, check.DeepEquals, dynamodb.NewBinaryAttribute("b", "Ynl0ZXM="))
+	b, err := a.Bytes()
+	c.Check(err, check.IsNil)
+	c.Check(b, check.DeepEquals, []byte("bytes"))
+
+	set := dynamodb.NewBytesSetAttribute("bs", [][]byte{[]byte("a"), {0xff}})
+	c.Check(set.SetValues, check.DeepEquals, []string{"YQ==", "/w=="})
+	values, err := set.BytesSet()
+	c.Check(err, check.IsNil)
+	c.Check(values, check.DeepEquals, [][]byte{[]byte("a"), {0xff}})
+
+	_, err = dynamodb.NewBinaryAttribute("b", "not base64!").Bytes()
+	c.Check(err, check.NotNil)
+	_, err = dynamodb.NewStringAttribute("s", "YQ==").Bytes()
+	c.Check(err, check.ErrorMatches, ".*not binary.")
+}
+
+func (s *AttributeSuite) TestTimeFormats(c *check.C) {
+	t := time.Date(2020, 1, 2, 3, 4, 5, 6000000, time.FixedZone("JST", 9*3600))
+
+	a := dynamodb.NewTimeAttribute("at", t)
+	c.Check(a, check.DeepEquals, dynamodb.NewStringAttribute("at", "2020-01-01T18:04:05Z"))
+	parsed, err := a.Time()
+	c.Check(err, check.IsNil)
+	c.Check(parsed.Equal(t.Truncate(time.Second)), check.Equals, true)
+
+	a = dynamodb.NewTimeAttributeFormat("at", t, dynamodb.TIME_FORMAT_RFC3339_NANO)
+	c.Check(a.Value, check.Equals, "2020-01-01T18:04:05.006000000Z")
+	parsed, err = a.Time()
+	c.Check(err, check.IsNil)
+	c.Check(parsed.Equal(t), check.Equals, true)
+
+	a = dynamodb.NewTimeAttributeFormat("at", t, dynamodb.TIME_FORMAT_EPOCH_MILLIS)
+	c.Check(a, check.DeepEquals, dynamodb.NewNumericAttribute("at", "1577901845006"))
+	parsed, err = a.TimeFormat(dynamodb.TIME_FORMAT_EPOCH_MILLIS)
+	c.Check(err, check.IsNil)
+	c.Check(parsed.Equal(t), check.Equals, true)
+
+	defer func(format string) { dynamodb.DefaultTimeFormat = format }(dynamodb.DefaultTimeFormat)
+	dynamodb.DefaultTimeFormat = dynamodb.TIME_FORMAT_EPOCH_SECONDS
+	a = dynamodb.NewTimeAttribute("at", t)
+	c.Check(a, check.DeepEquals, dynamodb.NewNumericAttribute("at", "1577901845"))
+	parsed, err = a.Time()
+	c.Check(err, check.IsNil)
+	c.Check(parsed.Equal(t.Truncate(time.Second)), check.Equals, true)
+}
+
+func (s *AttributeSuite) TestTypedConditions(c *check.C) {
+	eq, err := dynamodb.Equals("user", "alice")
+	c.Assert(err, check.IsNil)
+	c.Check(eq, check.DeepEquals, dynamodb.NewEqualStringAttributeComparison("user", "alice"))
+
+	lt, err := dynamodb.LessThan("seq", 10)
+	c.Assert(err, check.IsNil)
+	c.Check(lt, check.DeepEquals, dynamodb.NewNumericAttributeComparison("seq", dynamodb.COMPARISON_LESS_THAN, 10))
+
+	between, err := dynamodb.Between("score", 1.5, big.NewInt(3))
+	c.Assert(err, check.IsNil)
+	c.Check(between.AttributeValueList, check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewNumericAttribute("score", "1.5"),
+		*dynamodb.NewNumericAttribute("score", "3"),
+	})
+
+	prefix, err := dynamodb.BeginsWith("blob", []byte{0xff})
+	c.Assert(err, check.IsNil)
+	c.Check(prefix.AttributeValueList, check.DeepEquals, []dynamodb.Attribute{*dynamodb.NewBinaryAttribute("blob", "/w==")})
+
+	_, err = dynamodb.Between("score", 1, "2")
+	c.Check(err, check.ErrorMatches, "BETWEEN condition on score: values of types N and S.")
+	_, err = dynamodb.BeginsWith("seq", 1)
+	c.Check(err, check.ErrorMatches, "BEGINS_WITH condition on seq: a string or binary prefix is required.")
+	_, err = dynamodb.Equals("tags", []string{"a"})
+	c.Check(err, check.ErrorMatches, "EQ condition on tags: Unsupported key type .*")
+}
+
+func (s *AttributeSuite) TestEstimateItemSize(c *check.C) {
+	c.Check(dynamodb.EstimateItemSize(nil), check.Equals, 0)
+	c.Check(dynamodb.EstimateItemSize([]dynamodb.Attribute{*dynamodb.NewStringAttribute("name", "héllo")}), check.Equals, 4+6)
+	c.Check(dynamodb.EstimateItemSize([]dynamodb.Attribute{*dynamodb.NewBytesAttribute("b", []byte{1, 2, 3, 4})}), check.Equals, 1+4)
+
+	for n, size := range map[string]int{"0": 1, "7": 2, "12": 2, "123": 3, "-0.00120": 2, "1.5E3": 2, "1000": 2} {
+		c.Check(dynamodb.EstimateItemSize([]dynamodb.Attribute{*dynamodb.NewNumericAttribute("", n)}), check.Equals, size, check.Commentf(n))
+	}
+
+	c.Check(dynamodb.EstimateItemSize([]dynamodb.Attribute{
+		*dynamodb.NewStringSetAttribute("ss", []string{"a", "bc"}),
+		*dynamodb.NewNumericSetAttribute("ns", []string{"1", "22"}),
+	}), check.Equals, 2+3+2+2+2)
+
+	c.Check(dynamodb.EstimateItemSize([]dynamodb.Attribute{
+		*dynamodb.NewListAttribute("l", []dynamodb.Attribute{*dynamodb.NewStringAttribute("", "ab"), *dynamodb.NewNumericAttribute("", "7")}),
+	}), check.Equals, 1+3+(1+2)+(1+2))
+	c.Check(dynamodb.EstimateItemSize([]dynamodb.Attribute{
+		*dynamodb.NewMapAttribute("m", []dynamodb.Attribute{*dynamodb.NewStringAttribute("k", "ab")}),
+	}), check.Equals, 1+3+(1+1+2))
+}
@@ -0,0 +1,90 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	PITR_STATUS_ENABLED  = "ENABLED"
+	PITR_STATUS_DISABLED = "DISABLED"
+)
+
+type PointInTimeRecoveryDescriptionT struct {
+	PointInTimeRecoveryStatus  string
+	EarliestRestorableDateTime float64
+	LatestRestorableDateTime   float64
+}
+
+type ContinuousBackupsDescriptionT struct {
+	ContinuousBackupsStatus        string
+	PointInTimeRecoveryDescription PointInTimeRecoveryDescriptionT
+}
+
+type continuousBackupsResponse struct {
+	ContinuousBackupsDescription ContinuousBackupsDescriptionT
+}
+
+type restoreTableResponse struct {
+	TableDescription TableDescriptionT
+}
+
+func (t *Table) UpdateContinuousBackups(pointInTimeRecovery bool, isRetry bool) (*ContinuousBackupsDescriptionT, error) {
+	return t.Server.UpdateContinuousBackups(t.Name, pointInTimeRecovery, isRetry)
+}
+
+// UpdateContinuousBackups enables or disables point in time recovery.
+func (s *Server) UpdateContinuousBackups(tableName string, pointInTimeRecovery bool, isRetry bool) (*ContinuousBackupsDescriptionT, error) {
+	q := NewEmptyQuery()
+	q.addTableByName(tableName)
+	q.AddPointInTimeRecoverySpecification(pointInTimeRecovery)
+
+	return s.continuousBackups(target("UpdateContinuousBackups"), q, isRetry)
+}
+
+func (t *Table) DescribeContinuousBackups(isRetry bool) (*ContinuousBackupsDescriptionT, error) {
+	return t.Server.DescribeContinuousBackups(t.Name, isRetry)
+}
+
+func (s *Server) DescribeContinuousBackups(tableName string, isRetry bool) (*ContinuousBackupsDescriptionT, error) {
+	q := NewEmptyQuery()
+	q.addTableByName(tableName)
+
+	return s.continuousBackups(target("DescribeContinuousBackups"), q, isRetry)
+}
+
+func (s *Server) continuousBackups(target string, q *Query, isRetry bool) (*ContinuousBackupsDescriptionT, error) {
+	jsonResponse, err := s.queryServer(target, q, isRetry)
+	if err != nil {
+		return nil, err
+	}
+
+	var r continuousBackupsResponse
+	err = json.Unmarshal(jsonResponse, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r.ContinuousBackupsDescription, nil
+}
+
+// RestoreTableToPointInTime creates targetTableName from the state of
+// sourceTableName at restoreDateTime, or at the latest restorable time when
+// restoreDateTime is zero. The new table is returned in CREATING status.
+func (s *Server) RestoreTableToPointInTime(sourceTableName, targetTableName string, restoreDateTime time.Time, isRetry bool) (*TableDescriptionT, error) {
+	q := NewEmptyQuery()
+	q.AddRestoreToPointInTime(sourceTableName, targetTableName, restoreDateTime)
+
+	jsonResponse, err := s.queryServer(target("RestoreTableToPointInTime"), q, isRetry)
+	if err != nil {
+		return nil, err
+	}
+
+	var r restoreTableResponse
+	err = json.Unmarshal(jsonResponse, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r.TableDescription, nil
+}
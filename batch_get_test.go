@@ -0,0 +1,127 @@
+package dynamodb_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type BatchGetSuite struct {
+	table *dynamodb.Table
+
+	mu       sync.Mutex
+	requests int
+	inFlight int
+	maxIn    int
+	fail     error
+	// Number of requests left whose keys are all left unprocessed.
+	unprocess int
+}
+
+var _ = check.Suite(&BatchGetSuite{})
+
+func (s *BatchGetSuite) SetUpTest(c *check.C) {
+	s.requests, s.inFlight, s.maxIn, s.fail, s.unprocess = 0, 0, 0, nil, 0
+	server, _ := dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation != "BatchGetItem" {
+				return next(req)
+			}
+			s.mu.Lock()
+			s.requests++
+			s.inFlight++
+			if s.inFlight > s.maxIn {
+				s.maxIn = s.inFlight
+			}
+			fail := s.fail
+			unprocess := s.unprocess > 0
+			if unprocess {
+				s.unprocess--
+			}
+			s.mu.Unlock()
+			defer func() {
+				s.mu.Lock()
+				s.inFlight--
+				s.mu.Unlock()
+			}()
+			time.Sleep(10 * time.Millisecond)
+			if fail != nil {
+				return nil, fail
+			}
+			if unprocess {
+				var body struct{ RequestItems json.RawMessage }
+				c.Assert(json.Unmarshal(req.Body, &body), check.IsNil)
+				return []byte(`{"Responses":{},"UnprocessedKeys":` + string(body.RequestItems) + `}`), nil
+			}
+			return next(req)
+		}
+	})
+
+	s.table = createTable(c, server, tableSchema(c, "users", idKey{}))
+
+	w := s.table.NewBatchWriter(dynamodb.BatchWriterOptions{})
+	for i := 0; i < 200; i++ {
+		c.Assert(w.Put([]dynamodb.Attribute{*dynamodb.NewStringAttribute("id", strconv.Itoa(i))}), check.IsNil)
+	}
+	c.Assert(w.Close(), check.IsNil)
+}
+
+func (s *BatchGetSuite) keys(n int) []dynamodb.Key {
+	keys := make([]dynamodb.Key, n)
+	for i := range keys {
+		keys[i] = dynamodb.Key{HashKey: strconv.Itoa(i)}
+	}
+	return keys
+}
+
+func (s *BatchGetSuite) TestSplitsKeys(c *check.C) {
+	results, err := s.table.BatchGetItems(s.keys(250)).Execute(false)
+	c.Assert(err, check.IsNil)
+	c.Check(results["users"], check.HasLen, 200)
+	c.Check(s.requests, check.Equals, 3)
+	c.Check(s.maxIn > 1, check.Equals, true)
+}
+
+func (s *BatchGetSuite) TestConcurrency(c *check.C) {
+	b := s.table.BatchGetItems(s.keys(450))
+	b.Concurrency = 1
+	results, err := b.Execute(false)
+	c.Assert(err, check.IsNil)
+	c.Check(results["users"], check.HasLen, 200)
+	c.Check(s.requests, check.Equals, 5)
+	c.Check(s.maxIn, check.Equals, 1)
+}
+
+func (s *BatchGetSuite) TestError(c *check.C) {
+	s.fail = errors.New("unavailable")
+	_, err := s.table.BatchGetItems(s.keys(250)).Execute(false)
+	c.Check(err, check.ErrorMatches, ".*unavailable")
+}
+
+func (s *BatchGetSuite) TestUnprocessedKeysAreRetried(c *check.C) {
+	s.table.Server.RetryPolicy = &dynamodb.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	s.unprocess = 1
+	results, err := s.table.BatchGetItems(s.keys(250)).Execute(false)
+	c.Assert(err, check.IsNil)
+	c.Check(results["users"], check.HasLen, 200)
+	c.Check(s.requests, check.Equals, 4)
+}
+
+func (s *BatchGetSuite) TestUnprocessedKeysAreReported(c *check.C) {
+	s.table.Server.RetryPolicy = &dynamodb.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	s.unprocess = 3
+	b := s.table.BatchGetItems(s.keys(150))
+	b.Concurrency = 1
+	results, err := b.Execute(false)
+	var unprocessed *dynamodb.UnprocessedKeysError
+	c.Assert(errors.As(err, &unprocessed), check.Equals, true)
+	c.Check(unprocessed.Keys, check.HasLen, 100)
+	c.Check(results["users"], check.HasLen, 50)
+	c.Check(s.requests, check.Equals, 4)
+}
@@ -0,0 +1,101 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Maximum number of requests Dynamodb accepts in one BatchWriteItem call.
+const maxBatchWriteItems = 25
+
+// UnprocessedItemsError is returned by batched writes when Dynamodb left
+// requests unprocessed after the retries allowed by the Server's
+// RetryPolicy, or when the context was done before they were re-sent.
+type UnprocessedItemsError struct {
+	// The items of the unprocessed PutRequests and the keys of the
+	// unprocessed DeleteRequests.
+	Puts    [][]Attribute
+	Deletes [][]Attribute
+	// The context error which stopped the retries, if any.
+	Err error
+}
+
+func (e *UnprocessedItemsError) Error() string {
+	message := fmt.Sprintf("%d write requests were left unprocessed.", len(e.Puts)+len(e.Deletes))
+	if e.Err != nil {
+		message += " " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *UnprocessedItemsError) Unwrap() error {
+	return e.Err
+}
+
+// batchWrite puts and deletes (at most 25 requests in total) in one
+// BatchWriteItem call, re-sending unprocessed requests with backoff as the
+// Server's RetryPolicy allows. Requests still unprocessed are returned in
+// an *UnprocessedItemsError.
+func (t *Table) batchWrite(ctx context.Context, puts, deletes [][]Attribute, isRetry bool) error {
+	policy := t.Server.retryPolicy()
+	started := time.Now()
+	for attempt := 0; len(puts)+len(deletes) > 0; attempt++ {
+		if attempt > 0 {
+			// Unprocessed requests are a sign of throttling.
+			delay := policy.Backoff(attempt - 1)
+			if !policy.allows(attempt-1, time.Since(started), delay) {
+				return &UnprocessedItemsError{Puts: puts, Deletes: deletes}
+			}
+			if !sleepContext(ctx, delay) {
+				return &UnprocessedItemsError{Puts: puts, Deletes: deletes, Err: ctx.Err()}
+			}
+		}
+
+		actions := map[string][][]Attribute{}
+		if len(puts) > 0 {
+			actions["Put"] = puts
+		}
+		if len(deletes) > 0 {
+			actions["Delete"] = deletes
+		}
+		q := NewEmptyQuery()
+		q.AddWriteRequestItems(map[*Table]map[string][][]Attribute{t: actions})
+
+		jsonResponse, err := t.Server.queryServerContext(ctx, target("BatchWriteItem"), q, isRetry)
+		if err != nil {
+			return err
+		}
+
+		var r batchWriteResponse
+		if err := decodeResponse(jsonResponse, &r); err != nil {
+			return err
+		}
+
+		puts, deletes = nil, nil
+		for _, request := range r.UnprocessedItems[t.Name] {
+			if request.PutRequest != nil {
+				puts = append(puts, attributeSlice(request.PutRequest.Item.attributes()))
+			} else if request.DeleteRequest != nil {
+				deletes = append(deletes, attributeSlice(request.DeleteRequest.Key.attributes()))
+			}
+		}
+	}
+	return nil
+}
+
+// attributeSlice returns the attributes of item sorted by name.
+func attributeSlice(item map[string]*Attribute) []Attribute {
+	names := make([]string, 0, len(item))
+	for name := range item {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attributes := make([]Attribute, len(names))
+	for i, name := range names {
+		attributes[i] = *item[name]
+	}
+	return attributes
+}
@@ -0,0 +1,239 @@
+package dynamodb_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type BatchWriteSuite struct {
+	server *dynamodb.Server
+	table  *dynamodb.Table
+}
+
+var _ = check.Suite(&BatchWriteSuite{})
+
+func (s *BatchWriteSuite) SetUpTest(c *check.C) {
+	s.server = dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"})
+	pk := dynamodb.PrimaryKey{
+		KeyAttribute:   dynamodb.NewStringAttribute("id", ""),
+		RangeAttribute: dynamodb.NewNumericAttribute("n", ""),
+	}
+	s.table = s.server.NewTable("items", pk)
+}
+
+func (s *BatchWriteSuite) TestDeleteAllItems(c *check.C) {
+	var keys []string
+	for i := 0; i < 30; i++ {
+		keys = append(keys, fmt.Sprintf(`{"id":{"S":"k%d"},"n":{"N":"%d"}}`, i, i))
+	}
+
+	var batches []int
+	unprocessedOnce := true
+	s.server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			switch req.Operation {
+			case "Scan":
+				c.Check(strings.Contains(string(req.Body), `"ProjectionExpression":"#h, #r"`), check.Equals, true)
+				return []byte(`{"Count":30,"ScannedCount":30,"Items":[` + strings.Join(keys, ",") + `]}`), nil
+			case "BatchWriteItem":
+				var body struct {
+					RequestItems map[string][]json.RawMessage
+				}
+				c.Assert(json.Unmarshal(req.Body, &body), check.IsNil)
+				requests := body.RequestItems["items"]
+				batches = append(batches, len(requests))
+				if unprocessedOnce {
+					unprocessedOnce = false
+					return []byte(`{"UnprocessedItems":{"items":[` + string(requests[0]) + `]}}`), nil
+				}
+				return []byte(`{"UnprocessedItems":{}}`), nil
+			}
+			c.Fatalf("unexpected operation %s", req.Operation)
+			return nil, nil
+		}
+	})
+
+	deleted, err := s.table.DeleteAllItems(1)
+	c.Assert(err, check.IsNil)
+	c.Check(deleted, check.Equals, int64(30))
+	c.Check(batches, check.DeepEquals, []int{25, 1, 5})
+}
+
+func (s *BatchWriteSuite) TestCopyTable(c *check.C) {
+	dst := s.server.NewTable("items_copy", s.table.Key)
+
+	var written []string
+	s.server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			switch req.Operation {
+			case "Scan":
+				if strings.Contains(string(req.Body), "ExclusiveStartKey") {
+					return []byte(`{"Count":1,"ScannedCount":1,"Items":[{"id":{"S":"b"},"n":{"N":"2"}}]}`), nil
+				}
+				return []byte(`{"Count":1,"ScannedCount":1,"Items":[{"id":{"S":"a"},"n":{"N":"1"},"v":{"S":"x"}}],` +
+					`"LastEvaluatedKey":{"id":{"S":"a"},"n":{"N":"1"}}}`), nil
+			case "BatchWriteItem":
+				written = append(written, string(req.Body))
+				return []byte(`{"UnprocessedItems":{}}`), nil
+			}
+			c.Fatalf("unexpected operation %s", req.Operation)
+			return nil, nil
+		}
+	})
+
+	var reports []dynamodb.CopyProgress
+	progress, err := dynamodb.CopyTable(context.Background(), s.table, dst, &dynamodb.CopyTableOptions{
+		TotalSegments: 1,
+		Progress:      func(p dynamodb.CopyProgress) { reports = append(reports, p) },
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(progress, check.Equals, dynamodb.CopyProgress{Scanned: 2, Written: 2})
+	c.Check(reports, check.HasLen, 2)
+	c.Check(written, check.DeepEquals, []string{
+		`{"RequestItems":{"items_copy":[{"PutRequest":{"Item":{"id":{"S":"a"},"n":{"N":"1"},"v":{"S":"x"}}}}]}}`,
+		`{"RequestItems":{"items_copy":[{"PutRequest":{"Item":{"id":{"S":"b"},"n":{"N":"2"}}}}]}}`,
+	})
+
+	other := s.server.NewTable("other", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+	_, err = dynamodb.CopyTable(context.Background(), s.table, other, nil)
+	c.Check(err, check.ErrorMatches, "Source and destination tables must have the same primary key.")
+}
+
+func (s *BatchWriteSuite) TestExportImportJSON(c *check.C) {
+	var written []string
+	s.server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			switch req.Operation {
+			case "Scan":
+				return []byte(`{"Count":1,"ScannedCount":1,"Items":[{"id":{"S":"a"},"n":{"N":"1"},"tags":{"SS":["x","y"]}}]}`), nil
+			case "BatchWriteItem":
+				written = append(written, string(req.Body))
+				return []byte(`{"UnprocessedItems":{}}`), nil
+			}
+			c.Fatalf("unexpected operation %s", req.Operation)
+			return nil, nil
+		}
+	})
+
+	var buf bytes.Buffer
+	n, err := s.table.ExportToJSON(&buf, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, int64(1))
+	c.Check(buf.String(), check.Equals, `{"id":{"S":"a"},"n":{"N":"1"},"tags":{"SS":["x","y"]}}`+"\n")
+
+	plain := &dynamodb.JSONOptions{Format: dynamodb.JSON_FORMAT_PLAIN}
+	buf.Reset()
+	_, err = s.table.ExportToJSON(&buf, plain)
+	c.Assert(err, check.IsNil)
+	c.Check(buf.String(), check.Equals, `{"id":"a","n":1,"tags":["x","y"]}`+"\n")
+
+	n, err = s.table.ImportFromJSON(strings.NewReader(`{"id":"b","n":2,"ns":[1,2]}`+"\n"+`["c"]`), plain)
+	c.Check(err, check.ErrorMatches, "Item 2: json: cannot unmarshal array.*")
+	c.Check(n, check.Equals, int64(0))
+
+	n, err = s.table.ImportFromJSON(strings.NewReader(`{"id":"b","n":2,"ns":[1,2]}`), plain)
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, int64(1))
+	c.Check(written, check.DeepEquals, []string{
+		`{"RequestItems":{"items":[{"PutRequest":{"Item":{"id":{"S":"b"},"n":{"N":"2"},"ns":{"L":[{"N":"1"},{"N":"2"}]}}}}]}}`,
+	})
+}
+
+func (s *BatchWriteSuite) TestPlainJSONRoundTrip(c *check.C) {
+	server, _ := dynamodbtest.NewServer()
+	var tables []*dynamodb.Table
+	for _, name := range []string{"source", "target"} {
+		tables = append(tables, createTable(c, server, tableSchema(c, name, idKey{})))
+	}
+
+	attributes := []dynamodb.Attribute{
+		*dynamodb.NewNumericAttribute("n", "1.5"),
+		*dynamodb.NewListAttribute("mixed", []dynamodb.Attribute{
+			*dynamodb.NewStringAttribute("", "x"),
+			*dynamodb.NewNumericAttribute("", "2"),
+			*dynamodb.NewNullAttribute(""),
+		}),
+		*dynamodb.NewListAttribute("empty", nil),
+		*dynamodb.NewMapAttribute("profile", []dynamodb.Attribute{
+			*dynamodb.NewStringAttribute("name", "Ann"),
+			*dynamodb.NewMapAttribute("address", []dynamodb.Attribute{*dynamodb.NewStringAttribute("city", "Oslo")}),
+			*dynamodb.NewListAttribute("langs", []dynamodb.Attribute{*dynamodb.NewStringAttribute("", "go")}),
+		}),
+	}
+	_, err := tables[0].PutItemWithOptions(context.Background(), append(tables[0].Key.Clone("a", ""), attributes...), nil)
+	c.Assert(err, check.IsNil)
+
+	plain := &dynamodb.JSONOptions{Format: dynamodb.JSON_FORMAT_PLAIN}
+	var buf bytes.Buffer
+	_, err = tables[0].ExportToJSON(&buf, plain)
+	c.Assert(err, check.IsNil)
+	n, err := tables[1].ImportFromJSON(&buf, plain)
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, int64(1))
+
+	want, err := tables[0].GetItem(&dynamodb.Key{HashKey: "a"}, false)
+	c.Assert(err, check.IsNil)
+	got, err := tables[1].GetItem(&dynamodb.Key{HashKey: "a"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(dynamodb.ItemsEqual(got, want), check.Equals, true, check.Commentf("got %v", got))
+	c.Check(got["profile"].MapValues["address"].MapValues["city"].Value, check.Equals, "Oslo")
+}
+
+// unprocessedAlways makes every BatchWriteItem leave all its requests
+// unprocessed, counting the calls.
+func (s *BatchWriteSuite) unprocessedAlways(c *check.C, calls *int) {
+	s.server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			c.Assert(req.Operation, check.Equals, "BatchWriteItem")
+			*calls++
+			var body struct {
+				RequestItems map[string]json.RawMessage
+			}
+			c.Assert(json.Unmarshal(req.Body, &body), check.IsNil)
+			return []byte(`{"UnprocessedItems":{"items":` + string(body.RequestItems["items"]) + `}}`), nil
+		}
+	})
+}
+
+func (s *BatchWriteSuite) TestUnprocessedItemsAreBounded(c *check.C) {
+	s.server.RetryPolicy = &dynamodb.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	calls := 0
+	s.unprocessedAlways(c, &calls)
+
+	w := s.table.NewBatchWriter(dynamodb.BatchWriterOptions{})
+	c.Assert(w.Put([]dynamodb.Attribute{*dynamodb.NewStringAttribute("id", "a"), *dynamodb.NewNumericAttribute("n", "1")}), check.IsNil)
+	err := w.Flush()
+	var unprocessed *dynamodb.UnprocessedItemsError
+	c.Assert(errors.As(err, &unprocessed), check.Equals, true)
+	c.Check(unprocessed.Puts, check.DeepEquals, [][]dynamodb.Attribute{{*dynamodb.NewStringAttribute("id", "a"), *dynamodb.NewNumericAttribute("n", "1")}})
+	c.Check(unprocessed.Deletes, check.HasLen, 0)
+	c.Check(err, check.ErrorMatches, "1 write requests were left unprocessed.")
+	c.Check(calls, check.Equals, 3)
+}
+
+func (s *BatchWriteSuite) TestUnprocessedItemsAreCancelled(c *check.C) {
+	s.server.RetryPolicy = &dynamodb.RetryPolicy{BaseDelay: time.Hour, MaxDelay: time.Hour}
+	calls := 0
+	s.unprocessedAlways(c, &calls)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	ch := make(chan []dynamodb.Attribute, 1)
+	ch <- []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", "a"), *dynamodb.NewNumericAttribute("n", "1")}
+	close(ch)
+	started := time.Now()
+	_, err := s.table.BulkLoadWithOptions(ctx, ch, &dynamodb.BulkLoadOptions{Workers: 1})
+	c.Check(errors.Is(err, context.DeadlineExceeded), check.Equals, true)
+	c.Check(time.Since(started) < time.Second, check.Equals, true)
+	c.Check(calls, check.Equals, 1)
+}
@@ -0,0 +1,161 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBatchWriterClosed is returned by the calls made on a closed
+// BatchWriter.
+var ErrBatchWriterClosed = errors.New("Batch writer closed")
+
+// BatchWriterOptions configures NewBatchWriter.
+type BatchWriterOptions struct {
+	// Number of buffered requests triggering a flush, at most and by
+	// default 25, the BatchWriteItem limit.
+	BatchSize int
+	// When positive, requests are flushed at the latest this long after
+	// being buffered, even if the batch is not full.
+	FlushInterval time.Duration
+	IsRetry       bool
+}
+
+// A BatchWriter buffers puts and deletes, sending them with BatchWriteItem
+// when BatchSize of them are buffered, FlushInterval elapsed, or on Flush
+// and Close. Unprocessed requests are re-sent as the Server's RetryPolicy
+// allows, then returned in an *UnprocessedItemsError. A request on a key
+// already buffered replaces the buffered one, as it would have overwritten
+// it anyway.
+//
+// Once a flush fails, the requests it held are lost and every later call
+// returns its error, as with bufio.Writer. A BatchWriter is safe for
+// concurrent use; Close it when done.
+type BatchWriter struct {
+	table *Table
+	opts  BatchWriterOptions
+
+	mu       sync.Mutex
+	requests []batchRequest
+	buffered map[Key]int // index in requests of the request of a key
+	timer    *time.Timer
+	err      error
+	closed   bool
+}
+
+type batchRequest struct {
+	attributes []Attribute // the item of puts, the key of deletes
+	put        bool
+}
+
+func (t *Table) NewBatchWriter(opts BatchWriterOptions) *BatchWriter {
+	if opts.BatchSize <= 0 || opts.BatchSize > maxBatchWriteItems {
+		opts.BatchSize = maxBatchWriteItems
+	}
+	return &BatchWriter{table: t, opts: opts, buffered: make(map[Key]int)}
+}
+
+// Put buffers the write of item.
+func (w *BatchWriter) Put(item []Attribute) error {
+	if err := w.table.validateItem(item); err != nil {
+		return err
+	}
+	var key *Key
+	if w.table.Key.KeyAttribute != nil {
+		key, _ = w.table.KeyFromItem(attributeMapOf(item))
+	}
+	return w.add(key, batchRequest{item, true})
+}
+
+// Delete buffers the deletion of the item of key.
+func (w *BatchWriter) Delete(key *Key) error {
+	if err := w.table.validateKey(key); err != nil {
+		return err
+	}
+	return w.add(key, batchRequest{w.table.Key.Clone(key.HashKey, key.RangeKey), false})
+}
+
+func (w *BatchWriter) add(key *Key, request batchRequest) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrBatchWriterClosed
+	}
+	if w.err != nil {
+		return w.err
+	}
+
+	if key != nil {
+		if i, ok := w.buffered[*key]; ok {
+			w.requests[i] = request
+			return nil
+		}
+		w.buffered[*key] = len(w.requests)
+	}
+	w.requests = append(w.requests, request)
+
+	if len(w.requests) >= w.opts.BatchSize {
+		return w.flushLocked()
+	}
+	if w.timer == nil && w.opts.FlushInterval > 0 {
+		w.timer = time.AfterFunc(w.opts.FlushInterval, w.flushOnTimer)
+	}
+	return nil
+}
+
+// Flush sends the buffered requests.
+func (w *BatchWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	return w.flushLocked()
+}
+
+// Close flushes the buffered requests and releases the writer.
+func (w *BatchWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+	return w.flushLocked()
+}
+
+func (w *BatchWriter) flushOnTimer() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timer = nil
+	if w.err == nil {
+		w.flushLocked()
+	}
+}
+
+func (w *BatchWriter) flushLocked() error {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if len(w.requests) == 0 {
+		return nil
+	}
+	var puts, deletes [][]Attribute
+	for _, request := range w.requests {
+		if request.put {
+			puts = append(puts, request.attributes)
+		} else {
+			deletes = append(deletes, request.attributes)
+		}
+	}
+	w.requests = nil
+	w.buffered = make(map[Key]int)
+
+	w.err = w.table.batchWrite(context.Background(), puts, deletes, w.opts.IsRetry)
+	return w.err
+}
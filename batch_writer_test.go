@@ -0,0 +1,105 @@
+package dynamodb_test
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type BatchWriterSuite struct {
+	server *dynamodb.Server
+	table  *dynamodb.Table
+
+	mu      sync.Mutex
+	batches []int // number of requests of each BatchWriteItem call
+}
+
+var _ = check.Suite(&BatchWriterSuite{})
+
+func (s *BatchWriterSuite) SetUpTest(c *check.C) {
+	s.batches = nil
+	s.server, _ = dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation == "BatchWriteItem" {
+				s.mu.Lock()
+				s.batches = append(s.batches, strings.Count(string(req.Body), "Request\":"))
+				s.mu.Unlock()
+			}
+			return next(req)
+		}
+	})
+
+	s.table = createTable(c, s.server, tableSchema(c, "events", idKey{}))
+}
+
+func (s *BatchWriterSuite) item(id string) []dynamodb.Attribute {
+	return []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", id), *dynamodb.NewStringAttribute("kind", "click")}
+}
+
+func (s *BatchWriterSuite) TestBatches(c *check.C) {
+	w := s.table.NewBatchWriter(dynamodb.BatchWriterOptions{})
+	for i := 0; i < 60; i++ {
+		c.Assert(w.Put(s.item(strconv.Itoa(i))), check.IsNil)
+	}
+	c.Check(s.batches, check.DeepEquals, []int{25, 25})
+	c.Assert(w.Close(), check.IsNil)
+	c.Check(s.batches, check.DeepEquals, []int{25, 25, 10})
+
+	items, err := s.table.Scan(nil, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 60)
+
+	c.Check(w.Put(s.item("late")), check.Equals, dynamodb.ErrBatchWriterClosed)
+}
+
+func (s *BatchWriterSuite) TestSameKeyIsCoalesced(c *check.C) {
+	w := s.table.NewBatchWriter(dynamodb.BatchWriterOptions{BatchSize: 10})
+	c.Assert(w.Put(s.item("a")), check.IsNil)
+	c.Assert(w.Put(s.item("b")), check.IsNil)
+	c.Assert(w.Delete(&dynamodb.Key{HashKey: "a"}), check.IsNil)
+	c.Assert(w.Put(s.item("b")), check.IsNil)
+	c.Assert(w.Flush(), check.IsNil)
+	c.Check(s.batches, check.DeepEquals, []int{2})
+
+	items, err := s.table.Scan(nil, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(items, check.HasLen, 1)
+	c.Check(items[0]["id"].Value, check.Equals, "b")
+}
+
+func (s *BatchWriterSuite) TestFlushInterval(c *check.C) {
+	w := s.table.NewBatchWriter(dynamodb.BatchWriterOptions{FlushInterval: 10 * time.Millisecond})
+	defer w.Close()
+	c.Assert(w.Put(s.item("a")), check.IsNil)
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		n := len(s.batches)
+		s.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	s.mu.Lock()
+	c.Check(s.batches, check.DeepEquals, []int{1})
+	s.mu.Unlock()
+}
+
+func (s *BatchWriterSuite) TestErrorsAreSticky(c *check.C) {
+	missing := s.server.NewTable("missing", s.table.Key)
+	w := missing.NewBatchWriter(dynamodb.BatchWriterOptions{})
+	c.Assert(w.Put(s.item("a")), check.IsNil)
+	err := w.Flush()
+	c.Check(dynamodb.IsNotFound(err), check.Equals, true)
+	c.Check(w.Put(s.item("b")), check.Equals, err)
+	c.Check(w.Close(), check.Equals, err)
+
+	c.Check(s.table.NewBatchWriter(dynamodb.BatchWriterOptions{}).Put([]dynamodb.Attribute{*dynamodb.NewStringAttribute("kind", "click")}), check.NotNil)
+}
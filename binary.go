@@ -0,0 +1,49 @@
+package dynamodb
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// NewBytesAttribute returns a binary attribute holding value, base64 encoded
+// as the API requires.
+func NewBytesAttribute(name string, value []byte) *Attribute {
+	return NewBinaryAttribute(name, base64.StdEncoding.EncodeToString(value))
+}
+
+func NewBytesSetAttribute(name string, values [][]byte) *Attribute {
+	encoded := make([]string, len(values))
+	for i, value := range values {
+		encoded[i] = base64.StdEncoding.EncodeToString(value)
+	}
+	return NewBinarySetAttribute(name, encoded)
+}
+
+// Bytes decodes the value of a binary attribute, such as one returned by
+// GetItem.
+func (a *Attribute) Bytes() ([]byte, error) {
+	if a.Type != TYPE_BINARY {
+		return nil, fmt.Errorf("Attribute %s is of type %s, not binary.", a.Name, a.Type)
+	}
+	b, err := base64.StdEncoding.DecodeString(a.Value)
+	if err != nil {
+		return nil, fmt.Errorf("Attribute %s: %s", a.Name, err)
+	}
+	return b, nil
+}
+
+// BytesSet decodes the values of a binary set attribute.
+func (a *Attribute) BytesSet() ([][]byte, error) {
+	if a.Type != TYPE_BINARY_SET {
+		return nil, fmt.Errorf("Attribute %s is of type %s, not a binary set.", a.Name, a.Type)
+	}
+	values := make([][]byte, len(a.SetValues))
+	for i, s := range a.SetValues {
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("Attribute %s: %s", a.Name, err)
+		}
+		values[i] = b
+	}
+	return values, nil
+}
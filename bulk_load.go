@@ -0,0 +1,207 @@
+package dynamodb
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BulkLoadOptions configures BulkLoadWithOptions.
+type BulkLoadOptions struct {
+	// Number of goroutines writing batches, 1 when zero.
+	Workers int
+	// Write capacity units per second the load may consume, estimated from
+	// the item sizes; unlimited when zero. A RateLimiter installed on the
+	// Server applies as well.
+	WriteUnitsPerSecond float64
+	// OnProgress, when set, is called after every batch with the totals so
+	// far. Calls are serialized.
+	OnProgress func(BulkLoadProgress)
+	// OnError, when set, is called with the items of every failed batch,
+	// or with the single invalid item. The load goes on if it returns nil,
+	// and stops with its error otherwise. When nil, the first error stops
+	// the load.
+	OnError func(items [][]Attribute, err error) error
+	IsRetry bool
+}
+
+// BulkLoadProgress is the state of a bulk load.
+type BulkLoadProgress struct {
+	Written int64 // items written
+	Failed  int64 // items passed to OnError
+	Batches int64 // BatchWriteItem calls completed
+	Elapsed time.Duration
+}
+
+// BulkLoad writes every item received from ch with the given number of
+// workers, stopping at the first error. See BulkLoadWithOptions.
+func (t *Table) BulkLoad(ch <-chan []Attribute, workers int) error {
+	_, err := t.BulkLoadWithOptions(context.Background(), ch, &BulkLoadOptions{Workers: workers})
+	return err
+}
+
+// BulkLoadWithOptions writes every item received from ch until it is
+// closed, each worker batching the items it receives 25 at a time with
+// BatchWriteItem and re-sending unprocessed ones. Items are written in no
+// particular order: a key received twice may end up with either item.
+//
+// When the load stops early, on error or when ctx is done, the rest of ch
+// is drained in the background so that its producer does not block; the
+// producer must still close it.
+func (t *Table) BulkLoadWithOptions(ctx context.Context, ch <-chan []Attribute, opts *BulkLoadOptions) (BulkLoadProgress, error) {
+	if opts == nil {
+		opts = &BulkLoadOptions{}
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	l := &bulkLoad{table: t, opts: opts, started: time.Now(), cancel: cancel}
+	if opts.WriteUnitsPerSecond > 0 {
+		l.limiter = NewRateLimiter(0, opts.WriteUnitsPerSecond)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.work(ctx, ch)
+		}()
+	}
+	wg.Wait()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err == nil && ctx.Err() != nil {
+		l.err = ctx.Err()
+	}
+	if l.err != nil {
+		go func() {
+			for range ch {
+			}
+		}()
+	}
+	l.progress.Elapsed = time.Since(l.started)
+	return l.progress, l.err
+}
+
+type bulkLoad struct {
+	table   *Table
+	opts    *BulkLoadOptions
+	limiter *RateLimiter
+	started time.Time
+	cancel  context.CancelFunc
+
+	mu       sync.Mutex
+	progress BulkLoadProgress
+	err      error
+}
+
+func (l *bulkLoad) work(ctx context.Context, ch <-chan []Attribute) {
+	batch := make([][]Attribute, 0, maxBatchWriteItems)
+	keys := make(map[Key]bool, maxBatchWriteItems)
+	flush := func() bool {
+		if len(batch) == 0 {
+			return true
+		}
+		ok := l.write(ctx, batch)
+		batch = make([][]Attribute, 0, maxBatchWriteItems)
+		keys = make(map[Key]bool, maxBatchWriteItems)
+		return ok
+	}
+
+	for {
+		var item []Attribute
+		var open bool
+		select {
+		case <-ctx.Done():
+			return
+		case item, open = <-ch:
+		}
+		if !open {
+			flush()
+			return
+		}
+
+		if err := l.table.validateItem(item); err != nil {
+			if !l.failed([][]Attribute{item}, err) {
+				return
+			}
+			continue
+		}
+		// A batch cannot hold the same key twice.
+		if l.table.Key.KeyAttribute != nil {
+			if key, err := l.table.KeyFromItem(attributeMapOf(item)); err == nil {
+				if keys[*key] && !flush() {
+					return
+				}
+				keys[*key] = true
+			}
+		}
+		batch = append(batch, item)
+		if len(batch) == maxBatchWriteItems && !flush() {
+			return
+		}
+	}
+}
+
+// write sends one batch and reports whether the load goes on.
+func (l *bulkLoad) write(ctx context.Context, batch [][]Attribute) bool {
+	if l.limiter != nil {
+		if d := l.limiter.delay([]string{l.table.Name}, true); d > 0 && !sleepContext(ctx, d) {
+			return false
+		}
+	}
+
+	err := l.table.batchWrite(ctx, batch, nil, l.opts.IsRetry)
+	if l.limiter != nil {
+		units := 0
+		for _, item := range batch {
+			units += (EstimateItemSize(item) + 1023) / 1024
+		}
+		l.limiter.consume(map[string]float64{l.table.Name: float64(units)}, true)
+	}
+	if err != nil {
+		return l.failed(batch, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.progress.Written += int64(len(batch))
+	l.progress.Batches++
+	l.report()
+	return true
+}
+
+// failed handles the failure of items and reports whether the load goes
+// on.
+func (l *bulkLoad) failed(items [][]Attribute, err error) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return false
+	}
+	if l.opts.OnError != nil {
+		err = l.opts.OnError(items, err)
+	}
+	l.progress.Failed += int64(len(items))
+	if err != nil {
+		l.err = err
+		l.cancel()
+		return false
+	}
+	l.report()
+	return true
+}
+
+func (l *bulkLoad) report() {
+	if l.opts.OnProgress != nil {
+		p := l.progress
+		p.Elapsed = time.Since(l.started)
+		l.opts.OnProgress(p)
+	}
+}
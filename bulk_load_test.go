@@ -0,0 +1,132 @@
+package dynamodb_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type BulkLoadSuite struct {
+	server *dynamodb.Server
+	table  *dynamodb.Table
+
+	mu      sync.Mutex
+	batches int
+	fail    error // returned by BatchWriteItem when set
+}
+
+var _ = check.Suite(&BulkLoadSuite{})
+
+func (s *BulkLoadSuite) SetUpTest(c *check.C) {
+	s.batches, s.fail = 0, nil
+	s.server, _ = dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation == "BatchWriteItem" {
+				s.mu.Lock()
+				s.batches++
+				fail := s.fail
+				s.mu.Unlock()
+				if fail != nil {
+					return nil, fail
+				}
+			}
+			return next(req)
+		}
+	})
+
+	s.table = createTable(c, s.server, tableSchema(c, "events", idKey{}))
+}
+
+func (s *BulkLoadSuite) items(ids ...string) <-chan []dynamodb.Attribute {
+	ch := make(chan []dynamodb.Attribute)
+	go func() {
+		defer close(ch)
+		for _, id := range ids {
+			ch <- []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", id), *dynamodb.NewStringAttribute("kind", "click")}
+		}
+	}()
+	return ch
+}
+
+func bulkIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = strconv.Itoa(i)
+	}
+	return ids
+}
+
+func (s *BulkLoadSuite) TestBulkLoad(c *check.C) {
+	c.Assert(s.table.BulkLoad(s.items(bulkIDs(230)...), 4), check.IsNil)
+
+	items, err := s.table.Scan(nil, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 230)
+	// Each worker sends at most one partial batch.
+	c.Check(s.batches >= 10 && s.batches <= 13, check.Equals, true, check.Commentf("%d batches", s.batches))
+}
+
+func (s *BulkLoadSuite) TestProgress(c *check.C) {
+	var reports []dynamodb.BulkLoadProgress
+	progress, err := s.table.BulkLoadWithOptions(context.Background(), s.items(bulkIDs(60)...), &dynamodb.BulkLoadOptions{
+		Workers:    3,
+		OnProgress: func(p dynamodb.BulkLoadProgress) { reports = append(reports, p) },
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(progress.Written, check.Equals, int64(60))
+	c.Check(progress.Batches, check.Equals, int64(len(reports)))
+	c.Check(reports[len(reports)-1].Written, check.Equals, int64(60))
+}
+
+func (s *BulkLoadSuite) TestSameKeyInBatch(c *check.C) {
+	_, err := s.table.BulkLoadWithOptions(context.Background(), s.items("a", "b", "a"), nil)
+	c.Assert(err, check.IsNil)
+	c.Check(s.batches, check.Equals, 2)
+}
+
+func (s *BulkLoadSuite) TestOnErrorContinues(c *check.C) {
+	ch := make(chan []dynamodb.Attribute, 3)
+	ch <- []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", "a")}
+	ch <- []dynamodb.Attribute{*dynamodb.NewStringAttribute("kind", "click")}
+	ch <- []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", "b")}
+	close(ch)
+
+	var failed [][]dynamodb.Attribute
+	progress, err := s.table.BulkLoadWithOptions(context.Background(), ch, &dynamodb.BulkLoadOptions{
+		OnError: func(items [][]dynamodb.Attribute, err error) error {
+			failed = append(failed, items...)
+			return nil
+		},
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(progress.Written, check.Equals, int64(2))
+	c.Check(progress.Failed, check.Equals, int64(1))
+	c.Check(failed, check.HasLen, 1)
+}
+
+func (s *BulkLoadSuite) TestErrorStops(c *check.C) {
+	s.fail = errors.New("unavailable")
+	progress, err := s.table.BulkLoadWithOptions(context.Background(), s.items(bulkIDs(200)...), &dynamodb.BulkLoadOptions{Workers: 2})
+	c.Assert(err, check.ErrorMatches, ".*unavailable")
+	c.Check(progress.Written, check.Equals, int64(0))
+	c.Check(progress.Failed, check.Equals, int64(25))
+}
+
+func (s *BulkLoadSuite) TestWriteUnitsPerSecond(c *check.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// 100 one-unit items at 50 units per second: once the first second
+	// worth of units is spent, the fourth batch waits for half a second.
+	progress, err := s.table.BulkLoadWithOptions(ctx, s.items(bulkIDs(100)...), &dynamodb.BulkLoadOptions{
+		WriteUnitsPerSecond: 50,
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(progress.Written, check.Equals, int64(100))
+	c.Check(progress.Elapsed > 400*time.Millisecond, check.Equals, true, check.Commentf("%s", progress.Elapsed))
+}
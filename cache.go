@@ -0,0 +1,296 @@
+package dynamodb
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Defaults of CacheOptions.
+const (
+	DefaultCacheSize = 10000
+	DefaultCacheTTL  = time.Minute
+)
+
+// CacheOptions configures NewCachedTable.
+type CacheOptions struct {
+	// Maximum number of items kept, the least recently used ones being
+	// evicted first. DefaultCacheSize when zero.
+	Size int
+	// How long an item is served from the cache after being read.
+	// DefaultCacheTTL when zero.
+	TTL time.Duration
+	// How long a key found missing keeps being answered ErrNotFound
+	// without reading it again, shielding the table from repeated
+	// misses. Misses are not cached when zero. Keep it short: items
+	// created by other clients are not seen before it expires.
+	NegativeTTL time.Duration
+}
+
+// A CachedTable serves the items of Table from an in-memory LRU cache,
+// reading through to Dynamodb on misses. Writes made through it
+// invalidate the keys they touch; writes made by other clients, or
+// through Table directly, are only seen once the cached items expire. It
+// is safe for concurrent use.
+type CachedTable struct {
+	Table *Table
+
+	size        int
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	mu      sync.Mutex
+	lru     *list.List // of *cacheEntry, most recently used first
+	entries map[Key]*list.Element
+	// Incremented by every invalidation, so that reads which started
+	// before one do not cache what they got.
+	generation uint64
+}
+
+type cacheEntry struct {
+	key     Key
+	item    map[string]*Attribute // nil for keys found missing
+	expires time.Time
+}
+
+func NewCachedTable(t *Table, opts CacheOptions) *CachedTable {
+	if opts.Size <= 0 {
+		opts.Size = DefaultCacheSize
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultCacheTTL
+	}
+	return &CachedTable{
+		Table:       t,
+		size:        opts.Size,
+		ttl:         opts.TTL,
+		negativeTTL: opts.NegativeTTL,
+		lru:         list.New(),
+		entries:     make(map[Key]*list.Element),
+	}
+}
+
+// GetItem returns the cached item, or reads it with GetItem and caches it.
+// Callers may modify the returned item.
+func (c *CachedTable) GetItem(key *Key, isRetry bool) (map[string]*Attribute, error) {
+	if item, ok := c.lookup(*key); ok {
+		if item == nil {
+			return nil, ErrNotFound
+		}
+		return item, nil
+	}
+	generation := c.currentGeneration()
+	item, err := c.Table.GetItem(key, isRetry)
+	return c.stored(*key, item, err, generation)
+}
+
+// GetItemConsistent is GetItem, except that consistent reads bypass the
+// cache, refreshing it with what they read.
+func (c *CachedTable) GetItemConsistent(key *Key, consistentRead bool, isRetry bool) (map[string]*Attribute, error) {
+	if !consistentRead {
+		return c.GetItem(key, isRetry)
+	}
+	generation := c.currentGeneration()
+	item, err := c.Table.GetItemConsistent(key, true, isRetry)
+	return c.stored(*key, item, err, generation)
+}
+
+// stored caches the outcome of reading key, misses included.
+func (c *CachedTable) stored(key Key, item map[string]*Attribute, err error, generation uint64) (map[string]*Attribute, error) {
+	if err == ErrNotFound {
+		c.store(key, nil, generation)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, item, generation)
+	return cloneItem(item), nil
+}
+
+// BatchGetItems returns the items of keys which exist, reading the ones
+// missing from the cache with BatchGetItem, 100 at a time.
+func (c *CachedTable) BatchGetItems(keys []Key, isRetry bool) (map[Key]map[string]*Attribute, error) {
+	found := make(map[Key]map[string]*Attribute, len(keys))
+	var missing []Key
+	requested := make(map[Key]bool, len(keys))
+	for _, key := range keys {
+		if requested[key] {
+			continue
+		}
+		requested[key] = true
+		if item, ok := c.lookup(key); !ok {
+			missing = append(missing, key)
+		} else if item != nil {
+			found[key] = item
+		}
+	}
+
+	generation := c.currentGeneration()
+	for start := 0; start < len(missing); start += maxBatchGetKeys {
+		end := start + maxBatchGetKeys
+		if end > len(missing) {
+			end = len(missing)
+		}
+		items, err := c.Table.batchGetKeys(context.Background(), missing[start:end], isRetry)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range missing[start:end] {
+			item, ok := items[c.Table.canonicalKey(key)]
+			if !ok {
+				c.store(key, nil, generation)
+				continue
+			}
+			c.store(key, item, generation)
+			found[key] = cloneItem(item)
+		}
+	}
+	return found, nil
+}
+
+// PutItemWithOptions writes the item and invalidates its key.
+func (c *CachedTable) PutItemWithOptions(ctx context.Context, item []Attribute, opts *WriteOptions) (*WriteResult, error) {
+	result, err := c.Table.PutItemWithOptions(ctx, item, opts)
+	if key, keyErr := c.Table.KeyFromItem(attributeMapOf(item)); keyErr == nil {
+		c.Invalidate(*key)
+	}
+	return result, err
+}
+
+// UpdateItemWithOptions updates the item and invalidates its key.
+func (c *CachedTable) UpdateItemWithOptions(ctx context.Context, key *Key, attributes []Attribute, action string, opts *WriteOptions) (*WriteResult, error) {
+	result, err := c.Table.UpdateItemWithOptions(ctx, key, attributes, action, opts)
+	c.Invalidate(*key)
+	return result, err
+}
+
+// DeleteItemWithOptions deletes the item and invalidates its key.
+func (c *CachedTable) DeleteItemWithOptions(ctx context.Context, key *Key, opts *WriteOptions) (*WriteResult, error) {
+	result, err := c.Table.DeleteItemWithOptions(ctx, key, opts)
+	c.Invalidate(*key)
+	return result, err
+}
+
+// Invalidate drops the cached item of key, if any, e.g. after writing it
+// through Table.
+func (c *CachedTable) Invalidate(key Key) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	if e, ok := c.entries[key]; ok {
+		c.lru.Remove(e)
+		delete(c.entries, key)
+	}
+}
+
+// Purge drops every cached item.
+func (c *CachedTable) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.lru.Init()
+	c.entries = make(map[Key]*list.Element)
+}
+
+// Len returns the number of cached items and misses, expired ones
+// included.
+func (c *CachedTable) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *CachedTable) lookup(key Key) (map[string]*Attribute, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		c.lru.Remove(e)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	if entry.item == nil {
+		return nil, true
+	}
+	return cloneItem(entry.item), true
+}
+
+func (c *CachedTable) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// store caches item, nil meaning that key does not exist, unless the
+// cache was invalidated since generation.
+func (c *CachedTable) store(key Key, item map[string]*Attribute, generation uint64) {
+	ttl := c.ttl
+	if item == nil {
+		ttl = c.negativeTTL
+	} else {
+		item = cloneItem(item)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation != generation || ttl <= 0 {
+		return
+	}
+	entry := &cacheEntry{key: key, item: item, expires: time.Now().Add(ttl)}
+	if e, ok := c.entries[key]; ok {
+		e.Value = entry
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// cloneItem copies an item, so that the cache does not share attributes
+// with its callers.
+func cloneItem(item map[string]*Attribute) map[string]*Attribute {
+	clone := make(map[string]*Attribute, len(item))
+	for name, a := range item {
+		c := cloneAttribute(*a)
+		clone[name] = &c
+	}
+	return clone
+}
+
+func cloneAttribute(a Attribute) Attribute {
+	a.SetValues = append([]string(nil), a.SetValues...)
+	if a.ListValues != nil {
+		values := make([]Attribute, len(a.ListValues))
+		for i := range a.ListValues {
+			values[i] = cloneAttribute(a.ListValues[i])
+		}
+		a.ListValues = values
+	}
+	if a.MapValues != nil {
+		members := make(map[string]*Attribute, len(a.MapValues))
+		for key, member := range a.MapValues {
+			c := cloneAttribute(*member)
+			members[key] = &c
+		}
+		a.MapValues = members
+	}
+	return a
+}
+
+func attributeMapOf(attributes []Attribute) map[string]*Attribute {
+	m := make(map[string]*Attribute, len(attributes))
+	for i := range attributes {
+		m[attributes[i].Name] = &attributes[i]
+	}
+	return m
+}
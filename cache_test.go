@@ -0,0 +1,152 @@
+package dynamodb_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type CacheSuite struct {
+	table *dynamodb.Table
+	// Number of requests per operation.
+	calls map[string]int
+}
+
+var _ = check.Suite(&CacheSuite{})
+
+func (s *CacheSuite) SetUpTest(c *check.C) {
+	s.calls = map[string]int{}
+	server, _ := dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			s.calls[req.Operation]++
+			return next(req)
+		}
+	})
+
+	s.table = createTable(c, server, tableSchema(c, "users", idKey{}))
+
+	for _, id := range []string{"u1", "u2", "u3"} {
+		_, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone(id, ""), *dynamodb.NewStringAttribute("name", "name of "+id)), nil)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *CacheSuite) TestReadThrough(c *check.C) {
+	cache := dynamodb.NewCachedTable(s.table, dynamodb.CacheOptions{})
+	key := &dynamodb.Key{HashKey: "u1"}
+
+	for i := 0; i < 3; i++ {
+		item, err := cache.GetItem(key, false)
+		c.Assert(err, check.IsNil)
+		c.Check(item["name"].Value, check.Equals, "name of u1")
+		item["name"].Value = "modified by the caller"
+	}
+	c.Check(s.calls["GetItem"], check.Equals, 1)
+
+	_, err := cache.GetItemConsistent(key, true, false)
+	c.Assert(err, check.IsNil)
+	c.Check(s.calls["GetItem"], check.Equals, 2)
+
+	items, err := cache.BatchGetItems([]dynamodb.Key{{HashKey: "u1"}, {HashKey: "u2"}, {HashKey: "missing"}}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 2)
+	c.Check(items[dynamodb.Key{HashKey: "u2"}]["name"].Value, check.Equals, "name of u2")
+	c.Check(s.calls["BatchGetItem"], check.Equals, 1)
+
+	_, err = cache.BatchGetItems([]dynamodb.Key{{HashKey: "u1"}, {HashKey: "u2"}}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(s.calls["BatchGetItem"], check.Equals, 1)
+}
+
+func (s *CacheSuite) TestWritesInvalidate(c *check.C) {
+	cache := dynamodb.NewCachedTable(s.table, dynamodb.CacheOptions{})
+	key := &dynamodb.Key{HashKey: "u1"}
+	ctx := context.Background()
+
+	_, err := cache.GetItem(key, false)
+	c.Assert(err, check.IsNil)
+	_, err = cache.PutItemWithOptions(ctx, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", "u1"),
+		*dynamodb.NewStringAttribute("name", "Ann"),
+	}, nil)
+	c.Assert(err, check.IsNil)
+	item, err := cache.GetItem(key, false)
+	c.Assert(err, check.IsNil)
+	c.Check(item["name"].Value, check.Equals, "Ann")
+
+	_, err = cache.UpdateItemWithOptions(ctx, key, []dynamodb.Attribute{*dynamodb.NewStringAttribute("name", "Bob")}, "PUT", nil)
+	c.Assert(err, check.IsNil)
+	item, err = cache.GetItem(key, false)
+	c.Assert(err, check.IsNil)
+	c.Check(item["name"].Value, check.Equals, "Bob")
+
+	_, err = cache.DeleteItemWithOptions(ctx, key, nil)
+	c.Assert(err, check.IsNil)
+	_, err = cache.GetItem(key, false)
+	c.Check(err, check.Equals, dynamodb.ErrNotFound)
+	c.Check(s.calls["GetItem"], check.Equals, 4)
+}
+
+func (s *CacheSuite) TestEvictionAndExpiry(c *check.C) {
+	cache := dynamodb.NewCachedTable(s.table, dynamodb.CacheOptions{Size: 2, TTL: 20 * time.Millisecond})
+	for _, id := range []string{"u1", "u2", "u3"} {
+		_, err := cache.GetItem(&dynamodb.Key{HashKey: id}, false)
+		c.Assert(err, check.IsNil)
+	}
+	c.Check(cache.Len(), check.Equals, 2)
+
+	// u1 was the least recently used.
+	_, err := cache.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(s.calls["GetItem"], check.Equals, 4)
+
+	time.Sleep(30 * time.Millisecond)
+	_, err = cache.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(s.calls["GetItem"], check.Equals, 5)
+
+	cache.Purge()
+	c.Check(cache.Len(), check.Equals, 0)
+}
+
+func (s *CacheSuite) TestNegativeCache(c *check.C) {
+	key := &dynamodb.Key{HashKey: "missing"}
+
+	// Misses are not cached by default.
+	cache := dynamodb.NewCachedTable(s.table, dynamodb.CacheOptions{})
+	for i := 0; i < 2; i++ {
+		_, err := cache.GetItem(key, false)
+		c.Check(err, check.Equals, dynamodb.ErrNotFound)
+	}
+	c.Check(s.calls["GetItem"], check.Equals, 2)
+
+	cache = dynamodb.NewCachedTable(s.table, dynamodb.CacheOptions{NegativeTTL: 20 * time.Millisecond})
+	for i := 0; i < 3; i++ {
+		_, err := cache.GetItem(key, false)
+		c.Check(err, check.Equals, dynamodb.ErrNotFound)
+	}
+	c.Check(s.calls["GetItem"], check.Equals, 3)
+
+	items, err := cache.BatchGetItems([]dynamodb.Key{*key, {HashKey: "also-missing"}}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 0)
+	c.Check(s.calls["BatchGetItem"], check.Equals, 1)
+	_, err = cache.GetItem(&dynamodb.Key{HashKey: "also-missing"}, false)
+	c.Check(err, check.Equals, dynamodb.ErrNotFound)
+	c.Check(s.calls["GetItem"], check.Equals, 3)
+
+	// Writing the key through the cache forgets the miss.
+	_, err = cache.PutItemWithOptions(context.Background(), []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", "missing")}, nil)
+	c.Assert(err, check.IsNil)
+	_, err = cache.GetItem(key, false)
+	c.Check(err, check.IsNil)
+
+	// Misses expire after NegativeTTL.
+	time.Sleep(30 * time.Millisecond)
+	_, err = cache.GetItem(&dynamodb.Key{HashKey: "also-missing"}, false)
+	c.Check(err, check.Equals, dynamodb.ErrNotFound)
+	c.Check(s.calls["GetItem"], check.Equals, 5)
+}
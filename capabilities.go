@@ -0,0 +1,113 @@
+package dynamodb
+
+import (
+	"sort"
+)
+
+// Version of the Dynamodb API spoken by this client.
+const APIVersion = "20120810"
+
+// Feature names reported by SupportedFeatures.
+const (
+	FEATURE_LEGACY_CONDITIONS = "legacy-conditions" // KeyConditions, ScanFilter, Expected, AttributeUpdates
+	FEATURE_EXPRESSIONS       = "expressions"       // Filter, Projection, Update and Condition expressions
+	FEATURE_CONSUMED_CAPACITY = "consumed-capacity"
+	FEATURE_PARALLEL_SCAN     = "parallel-scan"
+	FEATURE_STREAMS           = "streams"
+	FEATURE_TTL               = "ttl"
+	FEATURE_PITR              = "point-in-time-recovery"
+	FEATURE_GLOBAL_TABLES     = "global-tables-2019.11.21"
+	FEATURE_TAGGING           = "tagging"
+	FEATURE_STRUCT_MARSHALING = "struct-marshaling"
+)
+
+var supportedOperations = []string{
+	"BatchGetItem",
+	"BatchWriteItem",
+	"CreateTable",
+	"DeleteItem",
+	"DeleteTable",
+	"DescribeContinuousBackups",
+	"DescribeEndpoints",
+	"DescribeTable",
+	"DescribeTimeToLive",
+	"GetItem",
+	"ListTables",
+	"ListTagsOfResource",
+	"PutItem",
+	"Query",
+	"RestoreTableToPointInTime",
+	"Scan",
+	"TagResource",
+	"UntagResource",
+	"UpdateContinuousBackups",
+	"UpdateItem",
+	"UpdateTable",
+	"UpdateTimeToLive",
+}
+
+var supportedStreamsOperations = []string{
+	"DescribeStream",
+	"GetRecords",
+	"GetShardIterator",
+}
+
+var supportedFeatures = []string{
+	FEATURE_LEGACY_CONDITIONS,
+	FEATURE_EXPRESSIONS,
+	FEATURE_CONSUMED_CAPACITY,
+	FEATURE_PARALLEL_SCAN,
+	FEATURE_STREAMS,
+	FEATURE_TTL,
+	FEATURE_PITR,
+	FEATURE_GLOBAL_TABLES,
+	FEATURE_TAGGING,
+	FEATURE_STRUCT_MARSHALING,
+}
+
+// Capabilities describes what this client can do, so that tooling built
+// on top of it can feature-detect rather than compare versions.
+type Capabilities struct {
+	APIVersion        string
+	Operations        []string // DynamoDB_20120810 operations
+	StreamsOperations []string // DynamoDBStreams_20120810 operations
+	Features          []string
+}
+
+// SupportedCapabilities returns the capabilities of this client. The
+// returned slices are copies, sorted by name.
+func SupportedCapabilities() Capabilities {
+	return Capabilities{
+		APIVersion:        APIVersion,
+		Operations:        sortedCopy(supportedOperations),
+		StreamsOperations: sortedCopy(supportedStreamsOperations),
+		Features:          sortedCopy(supportedFeatures),
+	}
+}
+
+// SupportsOperation reports whether the client wraps the named Dynamodb or
+// Dynamodb Streams operation, e.g. "Query".
+func (c Capabilities) SupportsOperation(name string) bool {
+	return contains(c.Operations, name) || contains(c.StreamsOperations, name)
+}
+
+// HasFeature reports whether the named FEATURE_* is supported.
+func (c Capabilities) HasFeature(name string) bool {
+	return contains(c.Features, name)
+}
+
+func sortedCopy(in []string) []string {
+	out := make([]string, len(in))
+	copy(out, in)
+	sort.Strings(out)
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
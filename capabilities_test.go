@@ -0,0 +1,53 @@
+package dynamodb_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type CapabilitiesSuite struct {
+}
+
+var _ = check.Suite(&CapabilitiesSuite{})
+
+func (s *CapabilitiesSuite) TestSupportedCapabilities(c *check.C) {
+	caps := dynamodb.SupportedCapabilities()
+	c.Check(caps.APIVersion, check.Equals, "20120810")
+	c.Check(caps.SupportsOperation("Query"), check.Equals, true)
+	c.Check(caps.SupportsOperation("GetRecords"), check.Equals, true)
+	c.Check(caps.SupportsOperation("ExecuteStatement"), check.Equals, false)
+	c.Check(caps.HasFeature(dynamodb.FEATURE_STREAMS), check.Equals, true)
+	c.Check(caps.HasFeature("transactions"), check.Equals, false)
+
+	// Callers must not be able to alter the package's lists.
+	caps.Operations[0] = "Mutated"
+	c.Check(dynamodb.SupportedCapabilities().SupportsOperation("Mutated"), check.Equals, false)
+}
+
+// TestOperationsAreListed checks that every operation the package sends,
+// as found in its sources, is reported as supported.
+func (s *CapabilitiesSuite) TestOperationsAreListed(c *check.C) {
+	sent := regexp.MustCompile(`\b(?:target|streamsTarget|write|fetchPage|fetchPageContext)\((?:ctx, )?"(\w+)"`)
+	files, err := filepath.Glob("*.go")
+	c.Assert(err, check.IsNil)
+
+	caps := dynamodb.SupportedCapabilities()
+	found := 0
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		source, err := os.ReadFile(file)
+		c.Assert(err, check.IsNil)
+		for _, m := range sent.FindAllStringSubmatch(string(source), -1) {
+			found++
+			c.Check(caps.SupportsOperation(m[1]), check.Equals, true, check.Commentf("%s sends %s", file, m[1]))
+		}
+	}
+	c.Check(found > 20, check.Equals, true)
+}
@@ -0,0 +1,63 @@
+package dynamodb
+
+const (
+	RETURN_CONSUMED_CAPACITY_INDEXES = "INDEXES"
+	RETURN_CONSUMED_CAPACITY_TOTAL   = "TOTAL"
+	RETURN_CONSUMED_CAPACITY_NONE    = "NONE"
+)
+
+type CapacityT struct {
+	CapacityUnits      float64
+	ReadCapacityUnits  float64
+	WriteCapacityUnits float64
+}
+
+type ConsumedCapacityT struct {
+	TableName              string
+	CapacityUnits          float64
+	ReadCapacityUnits      float64
+	WriteCapacityUnits     float64
+	Table                  *CapacityT
+	GlobalSecondaryIndexes map[string]CapacityT
+	LocalSecondaryIndexes  map[string]CapacityT
+}
+
+// QueryWithBudget runs q page by page until either every page has been
+// read or at least maxCapacityUnits have been consumed. In the latter case
+// the returned key is the cursor to resume from with AddExclusiveStartKey;
+// it is nil once the query is complete. The pages fetched so far and the
+// capacity they consumed are always returned. q itself is left as it is.
+func (t *Table) QueryWithBudget(q *Query, maxCapacityUnits float64, isRetry bool) ([]map[string]*Attribute, *Key, float64, error) {
+	return t.fetchWithBudget("Query", q, maxCapacityUnits, isRetry)
+}
+
+// ScanWithBudget is the Scan counterpart of QueryWithBudget.
+func (t *Table) ScanWithBudget(q *Query, maxCapacityUnits float64, isRetry bool) ([]map[string]*Attribute, *Key, float64, error) {
+	return t.fetchWithBudget("Scan", q, maxCapacityUnits, isRetry)
+}
+
+func (t *Table) fetchWithBudget(operation string, q *Query, maxCapacityUnits float64, isRetry bool) ([]map[string]*Attribute, *Key, float64, error) {
+	q = q.copy()
+	q.AddReturnConsumedCapacity(RETURN_CONSUMED_CAPACITY_TOTAL)
+
+	var items []map[string]*Attribute
+	var consumed float64
+	for {
+		page, err := t.fetchPage(operation, q, isRetry)
+		if err != nil {
+			return items, nil, consumed, err
+		}
+		items = append(items, page.Items...)
+		if page.ConsumedCapacity != nil {
+			consumed += page.ConsumedCapacity.CapacityUnits
+		}
+
+		if page.LastEvaluatedKey == nil {
+			return items, nil, consumed, nil
+		}
+		if consumed >= maxCapacityUnits {
+			return items, page.LastEvaluatedKey, consumed, nil
+		}
+		q.AddExclusiveStartKey(t, page.LastEvaluatedKey)
+	}
+}
@@ -0,0 +1,156 @@
+package dynamodb_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+// BudgetSuite pages through five items, one per page, each page
+// consuming one capacity unit.
+type BudgetSuite struct {
+	http  *httptest.Server
+	table *dynamodb.Table
+	pages int
+}
+
+var _ = check.Suite(&BudgetSuite{})
+
+func (s *BudgetSuite) SetUpTest(c *check.C) {
+	s.pages = 0
+	s.http = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ReturnConsumedCapacity string
+			ExclusiveStartKey      map[string]map[string]string
+		}
+		c.Check(json.NewDecoder(r.Body).Decode(&body), check.IsNil)
+		c.Check(body.ReturnConsumedCapacity, check.Equals, dynamodb.RETURN_CONSUMED_CAPACITY_TOTAL)
+		s.pages++
+
+		next := 0
+		if start, ok := body.ExclusiveStartKey["id"]; ok {
+			next, _ = strconv.Atoi(strings.TrimPrefix(start["S"], "i"))
+			next++
+		}
+		id := fmt.Sprintf("i%d", next)
+		response := fmt.Sprintf(`{"Count":1,"Items":[{"id":{"S":%q}}],"ConsumedCapacity":{"TableName":"events","CapacityUnits":1}`, id)
+		if next < 4 {
+			response += fmt.Sprintf(`,"LastEvaluatedKey":{"id":{"S":%q}}`, id)
+		}
+		w.Write([]byte(response + "}"))
+	}))
+	server := dynamodb.New(aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}, aws.Region{DynamoDBEndpoint: s.http.URL})
+	s.table = server.NewTable("events", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+}
+
+func (s *BudgetSuite) TearDownTest(c *check.C) {
+	s.http.Close()
+}
+
+func (s *BudgetSuite) TestQueryWithBudget(c *check.C) {
+	items, last, consumed, err := s.table.QueryWithBudget(dynamodb.NewQuery(s.table), 2.5, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 3)
+	c.Check(last, check.DeepEquals, &dynamodb.Key{HashKey: "i2"})
+	c.Check(consumed, check.Equals, 3.0)
+
+	q := dynamodb.NewQuery(s.table)
+	q.AddExclusiveStartKey(s.table, last)
+	items, last, consumed, err = s.table.QueryWithBudget(q, 100, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 2)
+	c.Check(items[0]["id"].Value, check.Equals, "i3")
+	c.Check(last, check.IsNil)
+	c.Check(consumed, check.Equals, 2.0)
+	c.Check(s.pages, check.Equals, 5)
+}
+
+func (s *BudgetSuite) TestScanWithBudget(c *check.C) {
+	items, last, consumed, err := s.table.ScanWithBudget(dynamodb.NewQuery(s.table), 0, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 1)
+	c.Check(last, check.DeepEquals, &dynamodb.Key{HashKey: "i0"})
+	c.Check(consumed, check.Equals, 1.0)
+}
+
+type CapacitySuite struct {
+	table *dynamodb.Table
+}
+
+var _ = check.Suite(&CapacitySuite{})
+
+func (s *CapacitySuite) SetUpTest(c *check.C) {
+	s.table = newFakeTable(c, "events", userSeqKey{})
+	// Items of about 3KB, each read costing half a unit.
+	payload := strings.Repeat("x", 3000)
+	for i := 0; i < 6; i++ {
+		_, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("u1", strconv.Itoa(i)), *dynamodb.NewStringAttribute("payload", payload)), nil)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *CapacitySuite) query() *dynamodb.Query {
+	q := dynamodb.NewQuery(s.table)
+	q.AddKeyConditions([]dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("user", "u1")})
+	q.AddLimit(1)
+	return q
+}
+
+func (s *CapacitySuite) TestQueryWithBudget(c *check.C) {
+	items, last, consumed, err := s.table.QueryWithBudget(s.query(), 1, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 2)
+	c.Check(consumed, check.Equals, 1.0)
+	c.Assert(last, check.NotNil)
+	c.Check(last.RangeKey, check.Equals, "1")
+
+	q := s.query()
+	q.AddExclusiveStartKey(s.table, last)
+	items, last, consumed, err = s.table.QueryWithBudget(q, 100, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 4)
+	c.Check(last, check.IsNil)
+	c.Check(consumed, check.Equals, 2.0)
+	c.Check(items[3]["seq"].Value, check.Equals, "5")
+}
+
+func (s *CapacitySuite) TestQueryIsNotModified(c *check.C) {
+	q := s.query()
+	before := q.String()
+	_, last, _, err := s.table.QueryWithBudget(q, 1, false)
+	c.Assert(err, check.IsNil)
+	c.Check(last, check.NotNil)
+	c.Check(q.String(), check.Equals, before)
+
+	// Running it again starts from the beginning.
+	items, _, _, err := s.table.QueryWithBudget(q, 1, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items[0]["seq"].Value, check.Equals, "0")
+}
+
+func (s *CapacitySuite) TestConsistentRead(c *check.C) {
+	q := s.query()
+	q.ConsistentRead(true)
+	items, last, consumed, err := s.table.QueryWithBudget(q, 1, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 1)
+	c.Check(last, check.NotNil)
+	c.Check(consumed, check.Equals, 1.0)
+}
+
+func (s *CapacitySuite) TestScanWithBudget(c *check.C) {
+	// A page is never cut short: the budget is checked between pages.
+	items, last, consumed, err := s.table.ScanWithBudget(dynamodb.NewQuery(s.table), 0.5, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 6)
+	c.Check(last, check.IsNil)
+	c.Check(consumed, check.Equals, 2.5)
+}
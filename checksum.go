@@ -0,0 +1,35 @@
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"strconv"
+)
+
+// ErrChecksumMismatch is returned (wrapped) when a response body does not
+// match its x-amz-crc32 header, i.e. it was truncated or corrupted on the
+// way. Such responses are retried like throttled requests.
+var ErrChecksumMismatch = errors.New("Response checksum mismatch")
+
+const crc32Header = "X-Amz-Crc32"
+
+// verifyChecksum checks the raw body of resp, as received on the wire,
+// against its x-amz-crc32 header. Responses without the header are
+// accepted, as are those the http.Transport already decompressed since the
+// checksum covers the compressed bytes.
+func verifyChecksum(resp *http.Response, body []byte) error {
+	value := resp.Header.Get(crc32Header)
+	if value == "" || resp.Uncompressed {
+		return nil
+	}
+	want, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w: invalid header %q", ErrChecksumMismatch, value)
+	}
+	if got := crc32.ChecksumIEEE(body); got != uint32(want) {
+		return fmt.Errorf("%w: got %d, want %d", ErrChecksumMismatch, got, want)
+	}
+	return nil
+}
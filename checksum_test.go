@@ -0,0 +1,48 @@
+package dynamodb_test
+
+import (
+	"errors"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type ChecksumSuite struct{}
+
+var _ = check.Suite(&ChecksumSuite{})
+
+func (s *ChecksumSuite) TestMismatchIsRetried(c *check.C) {
+	body := []byte(`{"Item":{"id":{"S":"u1"}}}`)
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		sum := crc32.ChecksumIEEE(body)
+		if attempts == 1 {
+			sum++
+		}
+		w.Header().Set("x-amz-crc32", strconv.FormatUint(uint64(sum), 10))
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: ts.URL})
+	server.Logger = dynamodb.NopLogger
+	server.RetryPolicy = &dynamodb.RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	table := server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+
+	_, err := table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Check(errors.Is(err, dynamodb.ErrChecksumMismatch), check.Equals, true)
+	c.Check(dynamodb.IsRetryable(err), check.Equals, true)
+
+	attempts = 0
+	item, err := table.GetItem(&dynamodb.Key{HashKey: "u1"}, true)
+	c.Assert(err, check.IsNil)
+	c.Check(item["id"].Value, check.Equals, "u1")
+	c.Check(attempts, check.Equals, 2)
+}
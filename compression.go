@@ -0,0 +1,37 @@
+package dynamodb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+var gzipReaders sync.Pool
+
+// gunzip decompresses a gzip encoded response body.
+func gunzip(body []byte) ([]byte, error) {
+	var zr *gzip.Reader
+	if r, ok := gzipReaders.Get().(*gzip.Reader); ok {
+		zr = r
+		if err := zr.Reset(bytes.NewReader(body)); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if zr, err = gzip.NewReader(bytes.NewReader(body)); err != nil {
+			return nil, err
+		}
+	}
+	defer gzipReaders.Put(zr)
+	return ioutil.ReadAll(zr)
+}
+
+// decodeBody undoes the content encoding of a response body.
+func decodeBody(header http.Header, body []byte) ([]byte, error) {
+	if header.Get("Content-Encoding") == "gzip" {
+		return gunzip(body)
+	}
+	return body, nil
+}
@@ -0,0 +1,42 @@
+package dynamodb_test
+
+import (
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type CompressionSuite struct{}
+
+var _ = check.Suite(&CompressionSuite{})
+
+func (s *CompressionSuite) TestGzipResponses(c *check.C) {
+	var acceptEncoding []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = append(acceptEncoding, r.Header.Get("Accept-Encoding"))
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			w.Write([]byte(`{"Item":{"id":{"S":"plain"}}}`))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte(`{"Item":{"id":{"S":"compressed"}}}`))
+		zw.Close()
+	}))
+	defer ts.Close()
+
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: ts.URL})
+	server.Gzip = true
+	table := server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+
+	for i := 0; i < 2; i++ {
+		item, err := table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+		c.Assert(err, check.IsNil)
+		c.Check(item["id"].Value, check.Equals, "compressed")
+	}
+	c.Check(acceptEncoding, check.DeepEquals, []string{"gzip", "gzip"})
+}
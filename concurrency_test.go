@@ -0,0 +1,51 @@
+package dynamodb_test
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+// Run with -race: a Server and its Tables are shared by many goroutines.
+type ConcurrencySuite struct {
+}
+
+var _ = check.Suite(&ConcurrencySuite{})
+
+func (s *ConcurrencySuite) TestSharedServer(c *check.C) {
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"})
+	server.Timeouts.Connect = dynamodb.DefaultRetryPolicy.MaxDelay
+	server.Metrics = dynamodb.NewPrometheusCollector()
+	limiter := dynamodb.NewRateLimiter(1e6, 1e6)
+	server.Use(limiter.Middleware())
+	server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			return []byte(`{"Item":{"id":{"S":"u1"}},"ConsumedCapacity":{"TableName":"users","CapacityUnits":1}}`), nil
+		}
+	})
+	table := server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			_, err := table.GetItem(&dynamodb.Key{HashKey: fmt.Sprint(i)}, true)
+			errs <- err
+		}(i)
+		go func() {
+			defer wg.Done()
+			server.Use(func(next dynamodb.Handler) dynamodb.Handler { return next })
+			errs <- nil
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		c.Check(err, check.IsNil)
+	}
+}
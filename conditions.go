@@ -0,0 +1,62 @@
+package dynamodb
+
+import (
+	"fmt"
+)
+
+// Equals returns the condition attributeName = value for Query key
+// conditions, QueryFilter or ScanFilter. Values are typed after their Go
+// type as in NewKey: strings, integers, floats, *big.Int or []byte.
+func Equals(attributeName string, value interface{}) (*AttributeComparison, error) {
+	return newComparison(attributeName, COMPARISON_EQUAL, value)
+}
+
+func NotEquals(attributeName string, value interface{}) (*AttributeComparison, error) {
+	return newComparison(attributeName, COMPARISON_NOT_EQUAL, value)
+}
+
+func LessThan(attributeName string, value interface{}) (*AttributeComparison, error) {
+	return newComparison(attributeName, COMPARISON_LESS_THAN, value)
+}
+
+func LessThanOrEqual(attributeName string, value interface{}) (*AttributeComparison, error) {
+	return newComparison(attributeName, COMPARISON_LESS_THAN_OR_EQUAL, value)
+}
+
+func GreaterThan(attributeName string, value interface{}) (*AttributeComparison, error) {
+	return newComparison(attributeName, COMPARISON_GREATER_THAN, value)
+}
+
+func GreaterThanOrEqual(attributeName string, value interface{}) (*AttributeComparison, error) {
+	return newComparison(attributeName, COMPARISON_GREATER_THAN_OR_EQUAL, value)
+}
+
+// Between returns the condition low <= attributeName <= high. Both bounds
+// must be of the same Dynamodb type.
+func Between(attributeName string, low, high interface{}) (*AttributeComparison, error) {
+	return newComparison(attributeName, COMPARISON_BETWEEN, low, high)
+}
+
+// BeginsWith returns the condition that attributeName starts with prefix,
+// a string or a []byte.
+func BeginsWith(attributeName string, prefix interface{}) (*AttributeComparison, error) {
+	return newComparison(attributeName, COMPARISON_BEGINS_WITH, prefix)
+}
+
+func newComparison(attributeName, operator string, values ...interface{}) (*AttributeComparison, error) {
+	c := &AttributeComparison{AttributeName: attributeName, ComparisonOperator: operator}
+	for _, v := range values {
+		s, typ, err := goKeyValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s condition on %s: %s", operator, attributeName, err)
+		}
+		if len(c.AttributeValueList) > 0 && c.AttributeValueList[0].Type != typ {
+			return nil, fmt.Errorf("%s condition on %s: values of types %s and %s.", operator, attributeName, c.AttributeValueList[0].Type, typ)
+		}
+		c.AttributeValueList = append(c.AttributeValueList, Attribute{Type: typ, Name: attributeName, Value: s})
+	}
+	if operator == COMPARISON_BEGINS_WITH && c.AttributeValueList[0].Type == TYPE_NUMBER {
+		return nil, fmt.Errorf("%s condition on %s: a string or binary prefix is required.", operator, attributeName)
+	}
+	return c, nil
+}
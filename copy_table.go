@@ -0,0 +1,139 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CopyTableOptions tunes CopyTable. The zero value is usable.
+type CopyTableOptions struct {
+	// Number of parallel scan segments, 4 when zero.
+	TotalSegments int
+	// Upper bound on the items written per second across all segments,
+	// to leave capacity to other clients of the tables. Unlimited when
+	// zero.
+	MaxItemsPerSecond int
+	// Called after every copied page with the running totals.
+	Progress func(CopyProgress)
+	IsRetry  bool
+}
+
+// CopyProgress reports how far a CopyTable run has got.
+type CopyProgress struct {
+	Scanned int64 // items read from the source
+	Written int64 // items written to the destination
+}
+
+// CopyTable writes every item of src into dst, which must exist and have
+// the same primary key. Items already in dst with the same key are
+// overwritten, so an interrupted copy can be run again from scratch.
+func CopyTable(ctx context.Context, src, dst *Table, opts *CopyTableOptions) (CopyProgress, error) {
+	if src.Key.KeyAttribute.Name != dst.Key.KeyAttribute.Name || src.Key.HasRange() != dst.Key.HasRange() ||
+		(src.Key.HasRange() && src.Key.RangeAttribute.Name != dst.Key.RangeAttribute.Name) {
+		return CopyProgress{}, errors.New("Source and destination tables must have the same primary key.")
+	}
+	if opts == nil {
+		opts = &CopyTableOptions{}
+	}
+	segments := opts.TotalSegments
+	if segments <= 0 {
+		segments = 4
+	}
+
+	var (
+		mu       sync.Mutex
+		progress CopyProgress
+	)
+	p := newPacer(opts.MaxItemsPerSecond)
+	err := runSegments(ctx, segments, func(ctx context.Context, segment int) error {
+		return copySegment(ctx, src, dst, segment, segments, opts.IsRetry, p, func(scanned, written int64) {
+			mu.Lock()
+			progress.Scanned += scanned
+			progress.Written += written
+			current := progress
+			if opts.Progress != nil {
+				opts.Progress(current)
+			}
+			mu.Unlock()
+		})
+	})
+	return progress, err
+}
+
+func copySegment(ctx context.Context, src, dst *Table, segment, totalSegments int, isRetry bool, p *pacer, report func(scanned, written int64)) error {
+	q := NewQuery(src)
+	if totalSegments > 1 {
+		q.AddParallelScanConfiguration(segment, totalSegments)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		items, lastEvaluatedKey, err := src.FetchPartialResults(q, isRetry)
+		if err != nil {
+			return err
+		}
+
+		var written int64
+		for start := 0; start < len(items); start += maxBatchWriteItems {
+			end := start + maxBatchWriteItems
+			if end > len(items) {
+				end = len(items)
+			}
+			if !p.wait(ctx, end-start) {
+				return ctx.Err()
+			}
+
+			puts := make([][]Attribute, 0, end-start)
+			for _, item := range items[start:end] {
+				puts = append(puts, attributeSlice(item))
+			}
+			if err := dst.batchWrite(ctx, puts, nil, isRetry); err != nil {
+				return err
+			}
+			written += int64(len(puts))
+		}
+		report(int64(len(items)), written)
+
+		if lastEvaluatedKey == nil {
+			return nil
+		}
+		q.AddExclusiveStartKey(src, lastEvaluatedKey)
+	}
+}
+
+// pacer spreads work evenly so that no more than perSecond units are done
+// per second. A nil pacer never waits.
+type pacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newPacer(perSecond int) *pacer {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &pacer{interval: time.Second / time.Duration(perSecond)}
+}
+
+// wait blocks until n more units may be done, returning false if ctx is
+// done first.
+func (p *pacer) wait(ctx context.Context, n int) bool {
+	if p == nil {
+		return true
+	}
+	p.mu.Lock()
+	now := time.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	at := p.next
+	p.next = p.next.Add(time.Duration(n) * p.interval)
+	p.mu.Unlock()
+
+	return sleepContext(ctx, time.Until(at))
+}
@@ -0,0 +1,117 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+)
+
+// DefaultCredentialsExpiryWindow is how long before they expire a
+// CredentialsCache renews credentials.
+const DefaultCredentialsExpiryWindow = 5 * time.Minute
+
+// Credentials sign requests. They mirror those of aws-sdk-go-v2.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Whether Expires is set.
+	CanExpire bool
+	Expires   time.Time
+}
+
+// A CredentialsProvider hands out the credentials requests are signed
+// with. Set Server.Credentials to one to sign with credentials which
+// change over time, such as temporary ones from STS, rather than with
+// Server.Auth: they are retrieved again for every attempt, so long running
+// loops and retries pick up renewed session tokens. Providers with an
+// Invalidate method, such as CredentialsCache, are invalidated when
+// Dynamodb reports an expired token, and the request re-sent once.
+type CredentialsProvider interface {
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+// CredentialsFunc adapts a function to a CredentialsProvider.
+type CredentialsFunc func(ctx context.Context) (Credentials, error)
+
+func (f CredentialsFunc) Retrieve(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// A CredentialsCache retrieves credentials from Provider only once they
+// are about to expire, sharing them meanwhile. It is safe for concurrent
+// use.
+type CredentialsCache struct {
+	Provider CredentialsProvider
+	// How long before they expire credentials are renewed,
+	// DefaultCredentialsExpiryWindow when zero.
+	ExpiryWindow time.Duration
+
+	mu          sync.Mutex
+	credentials *Credentials
+}
+
+func NewCredentialsCache(provider CredentialsProvider) *CredentialsCache {
+	return &CredentialsCache{Provider: provider}
+}
+
+func (c *CredentialsCache) Retrieve(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	window := c.ExpiryWindow
+	if window <= 0 {
+		window = DefaultCredentialsExpiryWindow
+	}
+	if creds := c.credentials; creds != nil && (!creds.CanExpire || time.Now().Add(window).Before(creds.Expires)) {
+		return *creds, nil
+	}
+
+	creds, err := c.Provider.Retrieve(ctx)
+	if err != nil {
+		return Credentials{}, err
+	}
+	c.credentials = &creds
+	return creds, nil
+}
+
+// Invalidate drops the cached credentials, so that the next Retrieve gets
+// new ones. Servers call it when Dynamodb reports them expired, before
+// re-sending the request.
+func (c *CredentialsCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.credentials = nil
+}
+
+// invalidateCredentials drops cached credentials which Dynamodb reported
+// expired, reporting whether new ones may be retrieved.
+func (s *Server) invalidateCredentials() bool {
+	cache, ok := s.Credentials.(interface{ Invalidate() })
+	if ok {
+		cache.Invalidate()
+	}
+	return ok
+}
+
+// authNeverExpires is the expiration of the aws.Auth built from provider
+// credentials. Their expiry is the provider's (or CredentialsCache's)
+// business: goamz re-reads the ambient credentials in place of those which
+// expire within 30 seconds.
+var authNeverExpires = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// auth returns the credentials to sign an attempt with, and their session
+// token: those of Credentials when set, else Auth.
+func (s *Server) auth(ctx context.Context) (aws.Auth, string, error) {
+	if s.Credentials == nil {
+		auth := s.Auth
+		return auth, auth.Token(), nil
+	}
+	creds, err := s.Credentials.Retrieve(ctx)
+	if err != nil {
+		return aws.Auth{}, "", fmt.Errorf("Could not retrieve credentials: %w", err)
+	}
+	return *aws.NewAuth(creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken, authNeverExpires), creds.SessionToken, nil
+}
@@ -0,0 +1,224 @@
+package dynamodb_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+// sdkCredentials and sdkConfig have the shape of aws.Credentials and
+// aws.Config of aws-sdk-go-v2.
+type sdkCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Source          string
+	CanExpire       bool
+	Expires         time.Time
+	AccountID       string
+}
+
+type sdkProvider struct {
+	calls int
+	err   error
+}
+
+func (p *sdkProvider) Retrieve(ctx context.Context) (sdkCredentials, error) {
+	p.calls++
+	if p.err != nil {
+		return sdkCredentials{}, p.err
+	}
+	return sdkCredentials{AccessKeyID: "SDK_KEY", SecretAccessKey: "SDK_SECRET", SessionToken: "SDK_TOKEN", CanExpire: true, Expires: time.Now().Add(time.Hour)}, nil
+}
+
+type sdkCredentialsProvider interface {
+	Retrieve(ctx context.Context) (sdkCredentials, error)
+}
+
+type sdkConfig struct {
+	Region       string
+	Credentials  sdkCredentialsProvider
+	BaseEndpoint *string
+}
+
+type CredentialsSuite struct{}
+
+var _ = check.Suite(&CredentialsSuite{})
+
+// signed returns the request made with server in dry-run mode.
+func (s *CredentialsSuite) signed(c *check.C, server *dynamodb.Server) *dynamodb.SignedRequest {
+	var request *dynamodb.SignedRequest
+	server.DryRun = func(req *dynamodb.SignedRequest) { request = req }
+	_, err := server.ListTables(false)
+	c.Check(err, check.Equals, dynamodb.ErrDryRun)
+	c.Assert(request, check.NotNil)
+	return request
+}
+
+func (s *CredentialsSuite) TestCredentials(c *check.C) {
+	server := dynamodb.New(aws.Auth{AccessKey: "STATIC_KEY", SecretKey: "STATIC_SECRET"}, aws.Region{Name: "us-east-1", DynamoDBEndpoint: "http://127.0.0.1:1"})
+	server.Credentials = dynamodb.CredentialsFunc(func(ctx context.Context) (dynamodb.Credentials, error) {
+		return dynamodb.Credentials{AccessKeyID: "TEMP_KEY", SecretAccessKey: "TEMP_SECRET", SessionToken: "TEMP_TOKEN"}, nil
+	})
+	request := s.signed(c, server)
+	c.Check(strings.HasPrefix(request.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=TEMP_KEY/"), check.Equals, true)
+	c.Check(request.Header.Get("X-Amz-Security-Token"), check.Equals, "TEMP_TOKEN")
+
+	server.Credentials = dynamodb.CredentialsFunc(func(ctx context.Context) (dynamodb.Credentials, error) {
+		return dynamodb.Credentials{}, errors.New("no credentials")
+	})
+	server.DryRun = nil
+	_, err := server.ListTables(false)
+	c.Check(err, check.ErrorMatches, "Could not retrieve credentials: no credentials")
+}
+
+func (s *CredentialsSuite) TestCredentialsCache(c *check.C) {
+	expires := time.Now().Add(time.Hour)
+	calls := 0
+	cache := dynamodb.NewCredentialsCache(dynamodb.CredentialsFunc(func(ctx context.Context) (dynamodb.Credentials, error) {
+		calls++
+		return dynamodb.Credentials{AccessKeyID: "KEY", CanExpire: true, Expires: expires}, nil
+	}))
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err := cache.Retrieve(ctx)
+		c.Assert(err, check.IsNil)
+	}
+	c.Check(calls, check.Equals, 1)
+
+	// Renewed within the expiry window.
+	expires = time.Now().Add(time.Minute)
+	cache.Invalidate()
+	cache.Retrieve(ctx)
+	cache.Retrieve(ctx)
+	c.Check(calls, check.Equals, 3)
+}
+
+func (s *CredentialsSuite) TestSDKCredentialsProvider(c *check.C) {
+	sdk := &sdkProvider{}
+	provider, err := dynamodb.NewSDKCredentialsProvider(sdk)
+	c.Assert(err, check.IsNil)
+	creds, err := provider.Retrieve(context.Background())
+	c.Assert(err, check.IsNil)
+	c.Check(creds.AccessKeyID, check.Equals, "SDK_KEY")
+	c.Check(creds.SecretAccessKey, check.Equals, "SDK_SECRET")
+	c.Check(creds.SessionToken, check.Equals, "SDK_TOKEN")
+	c.Check(creds.CanExpire, check.Equals, true)
+
+	sdk.err = errors.New("expired")
+	_, err = provider.Retrieve(context.Background())
+	c.Check(err, check.ErrorMatches, "expired")
+
+	_, err = dynamodb.NewSDKCredentialsProvider(struct{}{})
+	c.Check(err, check.ErrorMatches, "struct {} has no Retrieve method.")
+}
+
+func (s *CredentialsSuite) TestNewFromSDKConfig(c *check.C) {
+	server, err := dynamodb.NewFromSDKConfig(sdkConfig{Region: "eu-west-1", Credentials: &sdkProvider{}})
+	c.Assert(err, check.IsNil)
+	c.Check(server.Region.Name, check.Equals, "eu-west-1")
+	request := s.signed(c, server)
+	c.Check(request.URL, check.Equals, "https://dynamodb.eu-west-1.amazonaws.com/")
+	c.Check(strings.HasPrefix(request.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=SDK_KEY/"), check.Equals, true)
+
+	endpoint := "http://localhost:8000"
+	server, err = dynamodb.NewFromSDKConfig(&sdkConfig{Region: "local", Credentials: &sdkProvider{}, BaseEndpoint: &endpoint})
+	c.Assert(err, check.IsNil)
+	c.Check(server.Region.DynamoDBEndpoint, check.Equals, endpoint)
+
+	_, err = dynamodb.NewFromSDKConfig(sdkConfig{Region: "eu-west-1"})
+	c.Check(err, check.ErrorMatches, "dynamodb_test.sdkConfig has no Credentials.")
+}
+
+// rotatingServer answers GetItem requests, the first failing ones with
+// body, and records the session token of each.
+type rotatingServer struct {
+	mu      sync.Mutex
+	tokens  []string
+	failing func(token string) string // the error body, "" for success
+}
+
+func (r *rotatingServer) start() (*dynamodb.Server, *httptest.Server) {
+	h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token := req.Header.Get("X-Amz-Security-Token")
+		r.mu.Lock()
+		r.tokens = append(r.tokens, token)
+		r.mu.Unlock()
+		if body := r.failing(token); body != "" {
+			w.WriteHeader(400)
+			w.Write([]byte(body))
+			return
+		}
+		w.Write([]byte(`{"Item":{"id":{"S":"u1"}}}`))
+	}))
+	server := dynamodb.New(aws.Auth{}, aws.Region{Name: "us-east-1", DynamoDBEndpoint: h.URL})
+	server.Logger = dynamodb.NopLogger
+	server.RetryPolicy = &dynamodb.RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+	return server, h
+}
+
+// rotatingTokens returns a provider handing out a new session token on each call.
+func rotatingTokens(expires time.Duration) dynamodb.CredentialsProvider {
+	n := 0
+	return dynamodb.CredentialsFunc(func(ctx context.Context) (dynamodb.Credentials, error) {
+		n++
+		return dynamodb.Credentials{
+			AccessKeyID: "KEY", SecretAccessKey: "SECRET", SessionToken: "token-" + strconv.Itoa(n),
+			CanExpire: true, Expires: time.Now().Add(expires),
+		}, nil
+	})
+}
+
+func (s *CredentialsSuite) TestTokenRotatesBetweenRetries(c *check.C) {
+	r := &rotatingServer{failing: func(token string) string {
+		if token == "token-1" {
+			return `{"__type":"com.amazonaws.dynamodb.v20120810#ThrottlingException","message":"slow down"}`
+		}
+		return ""
+	}}
+	server, h := r.start()
+	defer h.Close()
+	// Short lived credentials, renewed for every attempt.
+	server.Credentials = dynamodb.NewCredentialsCache(rotatingTokens(time.Second))
+	table := server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+
+	_, err := table.GetItem(&dynamodb.Key{HashKey: "u1"}, true)
+	c.Assert(err, check.IsNil)
+	c.Check(r.tokens, check.DeepEquals, []string{"token-1", "token-2"})
+}
+
+func (s *CredentialsSuite) TestExpiredTokenIsRenewed(c *check.C) {
+	r := &rotatingServer{failing: func(token string) string {
+		if token == "token-1" {
+			return `{"__type":"com.amazon.coral.service#ExpiredTokenException","message":"The security token included in the request is expired"}`
+		}
+		return ""
+	}}
+	server, h := r.start()
+	defer h.Close()
+	server.Credentials = dynamodb.NewCredentialsCache(rotatingTokens(time.Hour))
+	table := server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+
+	// Re-sent once even without isRetry.
+	_, err := table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Assert(err, check.IsNil)
+	_, err = table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(r.tokens, check.DeepEquals, []string{"token-1", "token-2", "token-2"})
+
+	// Providers which cannot be invalidated get the error.
+	server.Credentials = dynamodb.CredentialsFunc(func(ctx context.Context) (dynamodb.Credentials, error) {
+		return dynamodb.Credentials{AccessKeyID: "KEY", SessionToken: "token-1"}, nil
+	})
+	_, err = table.GetItem(&dynamodb.Key{HashKey: "u1"}, true)
+	c.Check(dynamodb.ErrorCode(err), check.Equals, dynamodb.ExpiredTokenException)
+}
@@ -0,0 +1,194 @@
+package dynamodb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// attributeValue is an attribute value as encoded by Dynamodb, e.g.
+// {"S": "abc"}. Types the package does not support leave typ empty.
+type attributeValue struct {
+	typ   string
+	value string
+	set   []string
+	list  []attributeValue
+	m     map[string]attributeValue
+}
+
+// wireValue is the generic form of attributeValue, for the values the
+// fast path of UnmarshalJSON does not handle.
+type wireValue struct {
+	S, N, B    *string
+	SS, NS, BS []string
+	L          []attributeValue
+	M          map[string]attributeValue
+	NULL       *bool
+}
+
+var scalarTypes = map[string]string{TYPE_STRING: TYPE_STRING, TYPE_NUMBER: TYPE_NUMBER, TYPE_BINARY: TYPE_BINARY}
+
+// UnmarshalJSON decodes the common {"S":"..."} shape without going
+// through reflection, and anything else with encoding/json.
+func (v *attributeValue) UnmarshalJSON(data []byte) error {
+	if typ, value, ok := scanScalar(data); ok {
+		v.typ, v.value, v.set, v.list, v.m = typ, value, nil, nil, nil
+		return nil
+	}
+
+	var w wireValue
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	*v = attributeValue{}
+	switch {
+	case w.S != nil:
+		v.typ, v.value = TYPE_STRING, *w.S
+	case w.N != nil:
+		v.typ, v.value = TYPE_NUMBER, *w.N
+	case w.B != nil:
+		v.typ, v.value = TYPE_BINARY, *w.B
+	case w.SS != nil:
+		v.typ, v.set = TYPE_STRING_SET, w.SS
+	case w.NS != nil:
+		v.typ, v.set = TYPE_NUMBER_SET, w.NS
+	case w.BS != nil:
+		v.typ, v.set = TYPE_BINARY_SET, w.BS
+	case w.L != nil:
+		v.typ, v.list = TYPE_LIST, w.L
+	case w.M != nil:
+		v.typ, v.m = TYPE_MAP, w.M
+	case w.NULL != nil && *w.NULL:
+		v.typ, v.value = TYPE_NULL, "true"
+	}
+	return nil
+}
+
+// scanScalar recognizes {"T":"value"} where T is S, N or B and value has
+// no escape sequences. data is known to be valid JSON.
+func scanScalar(data []byte) (string, string, bool) {
+	data = bytes.TrimSpace(data)
+	if len(data) < 2 || data[0] != '{' || data[len(data)-1] != '}' {
+		return "", "", false
+	}
+	data = bytes.TrimSpace(data[1 : len(data)-1])
+
+	key, rest, ok := scanString(data)
+	if !ok {
+		return "", "", false
+	}
+	typ, ok := scalarTypes[string(key)]
+	if !ok {
+		return "", "", false
+	}
+	rest = bytes.TrimSpace(rest)
+	if len(rest) == 0 || rest[0] != ':' {
+		return "", "", false
+	}
+	value, rest, ok := scanString(bytes.TrimSpace(rest[1:]))
+	if !ok || len(bytes.TrimSpace(rest)) != 0 {
+		return "", "", false
+	}
+	return typ, string(value), true
+}
+
+// scanString returns the content of the JSON string data starts with,
+// failing on escape sequences.
+func scanString(data []byte) ([]byte, []byte, bool) {
+	if len(data) == 0 || data[0] != '"' {
+		return nil, nil, false
+	}
+	for i := 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			return nil, nil, false
+		case '"':
+			return data[1:i], data[i+1:], true
+		}
+	}
+	return nil, nil, false
+}
+
+// attribute returns the named Attribute, false when the value is of an
+// unsupported type.
+func (v *attributeValue) attribute(name string) (Attribute, bool) {
+	a := Attribute{Type: v.typ, Name: name, Value: v.value, SetValues: v.set}
+	if v.list != nil {
+		a.ListValues = make([]Attribute, 0, len(v.list))
+		for i := range v.list {
+			// Elements of types not supported are skipped.
+			if element, ok := v.list[i].attribute(""); ok {
+				a.ListValues = append(a.ListValues, element)
+			}
+		}
+	}
+	if v.m != nil {
+		a.MapValues = make(map[string]*Attribute, len(v.m))
+		for key, member := range v.m {
+			// Members of types not supported are skipped.
+			if member, ok := member.attribute(key); ok {
+				a.MapValues[key] = &member
+			}
+		}
+	}
+	return a, v.typ != ""
+}
+
+// scalar returns the value of a S, N or B attribute value of type typ.
+func (v *attributeValue) scalar(typ string) (string, bool) {
+	return v.value, v.typ == typ && v.set == nil && v.list == nil && v.m == nil
+}
+
+// attributeMap is an item, or a key, as encoded by Dynamodb.
+type attributeMap map[string]attributeValue
+
+// attributes converts the map, allocating every Attribute at once.
+func (m attributeMap) attributes() map[string]*Attribute {
+	results := make(map[string]*Attribute, len(m))
+	attributes := make([]Attribute, 0, len(m))
+	for name, v := range m {
+		if a, ok := v.attribute(name); ok {
+			attributes = append(attributes, a)
+			results[name] = &attributes[len(attributes)-1]
+		}
+	}
+	return results
+}
+
+// reset empties the map, keeping its storage for the next decoding.
+func (m attributeMap) reset() {
+	for name := range m {
+		delete(m, name)
+	}
+}
+
+// decodeResponse decodes a JSON response body into v, a response struct.
+func decodeResponse(jsonResponse []byte, v interface{}) error {
+	if err := json.Unmarshal(jsonResponse, v); err != nil {
+		return fmt.Errorf("Unexpected response %s", jsonResponse)
+	}
+	return nil
+}
+
+// Query and Scan responses are decoded into pooled pageResponses, whose
+// item maps are reused from one page to the next.
+var pageResponses = sync.Pool{
+	New: func() interface{} { return new(pageResponse) },
+}
+
+func getPageResponse() *pageResponse {
+	return pageResponses.Get().(*pageResponse)
+}
+
+func putPageResponse(r *pageResponse) {
+	for _, item := range r.Items {
+		item.reset()
+	}
+	r.Items = r.Items[:0]
+	r.Count = nil
+	r.ScannedCount = 0
+	r.LastEvaluatedKey = nil
+	r.ConsumedCapacity = nil
+	pageResponses.Put(r)
+}
@@ -0,0 +1,94 @@
+package dynamodb_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+// newCannedTable returns a table whose calls are all answered by response.
+func newCannedTable(response string) *dynamodb.Table {
+	server := dynamodb.New(aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"})
+	server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			return []byte(response), nil
+		}
+	})
+	pk := dynamodb.PrimaryKey{
+		KeyAttribute:   dynamodb.NewStringAttribute("user", ""),
+		RangeAttribute: dynamodb.NewNumericAttribute("seq", ""),
+	}
+	return server.NewTable("events", pk)
+}
+
+func benchmarkItem(i int) string {
+	return fmt.Sprintf(`{"user":{"S":"user-%d"},"seq":{"N":"%d"},"kind":{"S":"click"},"path":{"S":"/index.html"},"tags":{"SS":["a","b","c"]},"size":{"N":"1024"}}`, i, i)
+}
+
+func benchmarkPage(n int) string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = benchmarkItem(i)
+	}
+	return fmt.Sprintf(`{"Count":%d,"ScannedCount":%d,"Items":[%s],"LastEvaluatedKey":{"user":{"S":"user-1"},"seq":{"N":"%d"}}}`,
+		n, n, strings.Join(items, ","), n-1)
+}
+
+type DecodeSuite struct{}
+
+var _ = check.Suite(&DecodeSuite{})
+
+func (s *DecodeSuite) TestEscapedAndUnsupportedValues(c *check.C) {
+	table := newCannedTable(`{"Item":{"user":{"S":"a\"bé"},"seq":{"N":"1"},"note":{ "S" : "spaced" },"ok":{"BOOL":true},"null":{"NULL":true},"m":{"M":{"x":{"S":"y"}}},"bs":{"BS":["AA=="]}}}`)
+	item, err := table.GetItem(&dynamodb.Key{HashKey: "u", RangeKey: "1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(item, check.DeepEquals, map[string]*dynamodb.Attribute{
+		"user": dynamodb.NewStringAttribute("user", "a\"bé"),
+		"seq":  dynamodb.NewNumericAttribute("seq", "1"),
+		"note": dynamodb.NewStringAttribute("note", "spaced"),
+		"bs":   dynamodb.NewBinarySetAttribute("bs", []string{"AA=="}),
+		"m":    dynamodb.NewMapAttribute("m", []dynamodb.Attribute{*dynamodb.NewStringAttribute("x", "y")}),
+		"null": dynamodb.NewNullAttribute("null"),
+	})
+}
+
+func (s *DecodeSuite) TestPagesDoNotShareItems(c *check.C) {
+	first, err := newCannedTable(benchmarkPage(3)).FetchResults(dynamodb.NewEmptyQuery(), false)
+	c.Assert(err, check.IsNil)
+	second, err := newCannedTable(`{"Count":1,"Items":[{"user":{"S":"other"},"seq":{"N":"9"}}]}`).FetchResults(dynamodb.NewEmptyQuery(), false)
+	c.Assert(err, check.IsNil)
+
+	c.Check(first, check.HasLen, 3)
+	c.Check(first[0]["kind"].Value, check.Equals, "click")
+	c.Check(second, check.HasLen, 1)
+	c.Check(second[0], check.HasLen, 2)
+	c.Check(second[0]["user"].Value, check.Equals, "other")
+}
+
+func BenchmarkGetItem(b *testing.B) {
+	table := newCannedTable(`{"Item":` + benchmarkItem(1) + `}`)
+	key := &dynamodb.Key{HashKey: "user-1", RangeKey: "1"}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := table.GetItem(key, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkQueryPage(b *testing.B) {
+	table := newCannedTable(benchmarkPage(100))
+	q := dynamodb.NewQuery(table)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := table.QueryTable(q, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
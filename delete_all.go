@@ -0,0 +1,72 @@
+package dynamodb
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+)
+
+// DeleteAllItems empties the table, keeping it and its settings, and
+// returns the number of items deleted. The table is scanned in parallelism
+// segments (1 when not positive) fetching only the key attributes, and
+// items are removed with BatchWriteItem. Throttled requests and
+// unprocessed items are retried. Items written concurrently may survive.
+func (t *Table) DeleteAllItems(parallelism int) (int64, error) {
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+
+	var deleted int64
+	err := runSegments(context.Background(), parallelism, func(ctx context.Context, segment int) error {
+		return t.deleteSegment(ctx, segment, parallelism, func(n int) {
+			atomic.AddInt64(&deleted, int64(n))
+		})
+	})
+	return atomic.LoadInt64(&deleted), err
+}
+
+func (t *Table) deleteSegment(ctx context.Context, segment, totalSegments int, report func(int)) error {
+	names := map[string]string{"#h": t.Key.KeyAttribute.Name}
+	projection := []string{"#h"}
+	if t.Key.HasRange() {
+		names["#r"] = t.Key.RangeAttribute.Name
+		projection = append(projection, "#r")
+	}
+
+	q := NewQuery(t)
+	q.AddProjectionExpression(strings.Join(projection, ", "))
+	q.AddExpressionAttributeNames(names)
+	if totalSegments > 1 {
+		q.AddParallelScanConfiguration(segment, totalSegments)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		items, lastEvaluatedKey, err := t.FetchPartialResults(q, true)
+		if err != nil {
+			return err
+		}
+
+		for start := 0; start < len(items); start += maxBatchWriteItems {
+			end := start + maxBatchWriteItems
+			if end > len(items) {
+				end = len(items)
+			}
+			keys := make([][]Attribute, 0, end-start)
+			for _, item := range items[start:end] {
+				keys = append(keys, attributeSlice(item))
+			}
+			if err := t.batchWrite(ctx, nil, keys, true); err != nil {
+				return err
+			}
+			report(len(keys))
+		}
+
+		if lastEvaluatedKey == nil {
+			return nil
+		}
+		q.AddExclusiveStartKey(t, lastEvaluatedKey)
+	}
+}
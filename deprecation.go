@@ -0,0 +1,21 @@
+package dynamodb
+
+import (
+	"sync"
+)
+
+// When DeprecationWarnings is true, the first call to each deprecated
+// method logs a warning naming its replacement through the Server's Logger.
+var DeprecationWarnings = false
+
+var deprecationWarned sync.Map
+
+func deprecated(s *Server, method, replacement string) {
+	if !DeprecationWarnings {
+		return
+	}
+	if _, warned := deprecationWarned.LoadOrStore(method, true); warned {
+		return
+	}
+	s.logger().Log(LogWarn, "deprecated method called", "method", "Table."+method, "replacement", "Table."+replacement)
+}
@@ -0,0 +1,40 @@
+package dynamodb
+
+// DiffItems returns the update turning item old into item new: a SET for
+// each attribute added or changed and a REMOVE for each attribute
+// dropped, attributes being compared with AttributeEqual. Maps present in
+// both items are diffed member by member, so that only the changed part
+// of a document is sent. The expression is Empty when the items are
+// equal; key attributes, being equal, never appear in it.
+//
+//	item, _ := t.GetItem(key, true)
+//	updated := ... // a modified copy of item
+//	if u := DiffItems(item, updated); !u.Empty() {
+//		opts := &WriteOptions{}
+//		if err := u.ApplyWrite(opts); err != nil { ... }
+//		t.UpdateItemWithOptions(ctx, key, nil, "", opts)
+//	}
+func DiffItems(old, new map[string]*Attribute) *UpdateExpression {
+	u := NewUpdateExpression()
+	u.diff("", old, new)
+	return u
+}
+
+// diff adds the actions turning the members of the map at placeholder
+// path prefix, or the item when prefix is empty, from old into new.
+func (u *UpdateExpression) diff(prefix string, old, new map[string]*Attribute) {
+	for _, change := range ChangedAttributes(old, new) {
+		path := u.p.Name(change.Name)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		switch {
+		case change.New == nil:
+			u.remove = append(u.remove, path)
+		case change.Old != nil && change.Old.Type == TYPE_MAP && change.New.Type == TYPE_MAP:
+			u.diff(path, change.Old.MapValues, change.New.MapValues)
+		default:
+			u.set = append(u.set, path+" = "+u.p.Value(change.New))
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package dynamodb_test
+
+import (
+	"context"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+func (s *UpdateExpressionSuite) TestDiffItems(c *check.C) {
+	old := map[string]*dynamodb.Attribute{
+		"id":     dynamodb.NewStringAttribute("id", "o1"),
+		"status": dynamodb.NewStringAttribute("status", "new"),
+		"total":  dynamodb.NewNumericAttribute("total", "10"),
+		"note":   dynamodb.NewStringAttribute("note", "fragile"),
+		"address": dynamodb.NewMapAttribute("address", []dynamodb.Attribute{
+			*dynamodb.NewStringAttribute("city", "Paris"),
+			*dynamodb.NewStringAttribute("zip", "75001"),
+		}),
+	}
+	updated := map[string]*dynamodb.Attribute{
+		"id":     dynamodb.NewStringAttribute("id", "o1"),
+		"status": dynamodb.NewStringAttribute("status", "shipped"),
+		"total":  dynamodb.NewNumericAttribute("total", "10.0"),
+		"tags":   dynamodb.NewStringSetAttribute("tags", []string{"gift"}),
+		"address": dynamodb.NewMapAttribute("address", []dynamodb.Attribute{
+			*dynamodb.NewStringAttribute("city", "Lyon"),
+			*dynamodb.NewStringAttribute("zip", "75001"),
+		}),
+	}
+
+	c.Check(dynamodb.DiffItems(old, old).Empty(), check.Equals, true)
+
+	u := dynamodb.DiffItems(old, updated)
+	c.Check(u.String(), check.Equals, "SET #n0.#n1 = :v0, #n3 = :v1, #n4 = :v2 REMOVE #n2")
+	c.Check(u.Names(), check.DeepEquals, map[string]string{
+		"#n0": "address", "#n1": "city", "#n2": "note", "#n3": "status", "#n4": "tags",
+	})
+
+	// Applying the diff turns the stored item into the new one.
+	_, err := s.table.PutItemWithOptions(context.Background(), attributesOf(old), nil)
+	c.Assert(err, check.IsNil)
+	opts := &dynamodb.WriteOptions{}
+	c.Assert(u.ApplyWrite(opts), check.IsNil)
+	_, err = s.table.UpdateItemWithOptions(context.Background(), s.key, nil, "", opts)
+	c.Assert(err, check.IsNil)
+	item, err := s.table.GetItem(s.key, false)
+	c.Assert(err, check.IsNil)
+	c.Check(dynamodb.ItemsEqual(item, updated), check.Equals, true)
+}
+
+func attributesOf(item map[string]*dynamodb.Attribute) []dynamodb.Attribute {
+	attrs := make([]dynamodb.Attribute, 0, len(item))
+	for _, a := range item {
+		attrs = append(attrs, *a)
+	}
+	return attrs
+}
@@ -0,0 +1,40 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ErrDryRun is returned by calls made in dry-run mode, after the request
+// was built and signed but not sent.
+var ErrDryRun = errors.New("Dry run: the request was not sent.")
+
+// SignedRequest is a request exactly as it would be sent to Dynamodb.
+type SignedRequest struct {
+	Method string
+	URL    string
+	// Headers, signature included.
+	Header http.Header
+	Body   []byte
+}
+
+// DryRunFunc receives the requests of calls made in dry-run mode.
+type DryRunFunc func(req *SignedRequest)
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context putting the calls made with it in dry-run
+// mode: fn receives every request instead of Dynamodb, and calls fail
+// with ErrDryRun.
+func WithDryRun(ctx context.Context, fn DryRunFunc) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, fn)
+}
+
+// dryRun returns the dry-run function for a call made with ctx, if any.
+func (s *Server) dryRun(ctx context.Context) DryRunFunc {
+	if fn, ok := ctx.Value(dryRunKey{}).(DryRunFunc); ok && fn != nil {
+		return fn
+	}
+	return s.DryRun
+}
@@ -0,0 +1,44 @@
+package dynamodb_test
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type DryRunSuite struct{}
+
+var _ = check.Suite(&DryRunSuite{})
+
+func (s *DryRunSuite) TestDryRun(c *check.C) {
+	var requests []*dynamodb.SignedRequest
+	server := dynamodb.New(aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}, aws.Region{Name: "us-east-1", DynamoDBEndpoint: "http://127.0.0.1:1"})
+	server.Logger = dynamodb.NopLogger
+	server.DryRun = func(req *dynamodb.SignedRequest) {
+		requests = append(requests, req)
+	}
+	table := server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+
+	_, err := table.DeleteItem(&dynamodb.Key{HashKey: "u1"}, true)
+	c.Check(err, check.Equals, dynamodb.ErrDryRun)
+	c.Assert(requests, check.HasLen, 1)
+	c.Check(requests[0].URL, check.Equals, "http://127.0.0.1:1/")
+	c.Check(requests[0].Header.Get("X-Amz-Target"), check.Equals, "DynamoDB_20120810.DeleteItem")
+	c.Check(strings.HasPrefix(requests[0].Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=DUMMY_KEY/"), check.Equals, true)
+	c.Check(string(requests[0].Body), check.Equals, `{"Key":{"id":{"S":"u1"}},"TableName":"users"}`)
+}
+
+func (s *DryRunSuite) TestDryRunContext(c *check.C) {
+	var request *dynamodb.SignedRequest
+	server := dynamodb.New(aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}, aws.Region{Name: "us-east-1", DynamoDBEndpoint: "http://127.0.0.1:1"})
+	table := server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+
+	ctx := dynamodb.WithDryRun(context.Background(), func(req *dynamodb.SignedRequest) { request = req })
+	_, err := table.PutItemWithOptions(ctx, []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", "u1")}, nil)
+	c.Check(err, check.Equals, dynamodb.ErrDryRun)
+	c.Assert(request, check.NotNil)
+	c.Check(string(request.Body), check.Equals, `{"Item":{"id":{"S":"u1"}},"TableName":"users"}`)
+}
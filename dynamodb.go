@@ -1,23 +1,76 @@
 package dynamodb
 
-import simplejson "github.com/bitly/go-simplejson"
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/goamz/goamz/aws"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
+// A Server is safe for concurrent use by multiple goroutines, and should
+// be shared rather than created per call. Its fields are configuration:
+// set them before making calls and do not change them afterwards, except
+// for adding middleware with Use.
 type Server struct {
 	Auth   aws.Auth
 	Region aws.Region
+	// Logger receives the client's log output, including requests and
+	// responses at LogDebug. DefaultLogger is used when nil.
+	Logger Logger
+	// Middleware wraps every request sent to Dynamodb, the first one
+	// being the outermost. See Use.
+	Middleware []Middleware
+	// Metrics, when set, observes every call.
+	Metrics MetricsCollector
+	// Tracer, when set, wraps every call in a span.
+	Tracer Tracer
+	// RetryPolicy applies to requests made with isRetry.
+	// DefaultRetryPolicy is used when nil.
+	RetryPolicy *RetryPolicy
+	// Timeouts applies to every call; see also WithTimeouts.
+	Timeouts Timeouts
+	// Gzip, when true, asks Dynamodb to compress responses, cutting the
+	// bandwidth used by large Query and Scan results for some CPU. It
+	// works with any HTTPClient; Go's default transport negotiates gzip
+	// on its own otherwise. Dynamodb does not accept compressed requests.
+	Gzip bool
+	// DryRun, when set, puts every call in dry-run mode, as WithDryRun
+	// does for a single one. Use a dedicated Server for that.
+	DryRun DryRunFunc
+	// HTTPClient, when set, sends the requests instead of a client
+	// built by the Server with NewTransport.
+	HTTPClient *http.Client
+	// Transport, when set, sends the requests instead of HTTP, e.g.
+	// through a DAX client. HTTPClient, Auth, Credentials and Gzip are
+	// then unused.
+	Transport Transport
+	// Credentials, when set, provides the credentials of each attempt in
+	// place of Auth. It is called for every attempt, so wrap providers
+	// which do not cache in a CredentialsCache.
+	Credentials CredentialsProvider
+	// TableNames, when set, maps every table name sent to Dynamodb, e.g.
+	// TableNameAffix{Prefix: "staging-"}, and maps the names in responses
+	// back, so that code uses the same names in every environment.
+	// Middleware, metrics and traces see the names sent to Dynamodb.
+	// PartiQL statements and ARNs are not rewritten.
+	TableNames TableNameTransformer
+
+	mu                   sync.Mutex
+	client               *http.Client
+	clientConnectTimeout time.Duration
+
+	tablesMu sync.Mutex
+	tables   map[string]*tableEntry
 }
 
 func New(auth aws.Auth, region aws.Region) *Server {
-	return &Server{auth, region}
+	return &Server{Auth: auth, Region: region}
 }
 
 const (
@@ -33,89 +86,273 @@ type Error struct {
 	Status     string
 	Code       string // Dynamodb error code ("MalformedQueryString", ...)
 	Message    string // The human-oriented error message
+	// The x-amzn-RequestId of the response, to quote to AWS support.
+	RequestID string
+	// The item which failed the condition of a write made with
+	// ReturnValuesOnConditionCheckFailure set to ALL_OLD, nil when there
+	// is no such item.
+	Item map[string]*Attribute
+	// Why each action of a cancelled transaction failed, in the order of
+	// the actions, Code being "None" for those which did not.
+	CancellationReasons []CancellationReasonT
+}
+
+// CancellationReasonT is an entry of Error.CancellationReasons.
+type CancellationReasonT struct {
+	Code    string
+	Message string
+	// The item which failed the condition, with
+	// ReturnValuesOnConditionCheckFailure set to ALL_OLD.
+	Item map[string]*Attribute
 }
 
 func (e Error) Error() string {
+	if e.RequestID != "" {
+		return e.Code + ": " + e.Message + " (request id " + e.RequestID + ")"
+	}
 	return e.Code + ": " + e.Message
 }
 
-func buildError(r *http.Response, jsonBody []byte) *Error {
+func buildError(logger Logger, r *http.Response, jsonBody []byte) *Error {
+	ddbError, err := parseError(r.StatusCode, jsonBody)
+	if err != nil {
+		logger.Log(LogError, "failed to parse error body as JSON", "status", r.Status)
+	}
+	ddbError.Status = r.Status
+	ddbError.RequestID = r.Header.Get(requestIDHeader)
+	return ddbError
+}
+
+// ParseError returns the error described by the JSON body of an error
+// response of Dynamodb, e.g. for Transports relaying them.
+func ParseError(statusCode int, jsonBody []byte) *Error {
+	e, _ := parseError(statusCode, jsonBody)
+	return e
+}
 
+func parseError(statusCode int, jsonBody []byte) (*Error, error) {
 	ddbError := Error{
-		StatusCode: r.StatusCode,
-		Status:     r.Status,
+		StatusCode: statusCode,
+		Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
 	}
 
-	json, err := simplejson.NewJson(jsonBody)
-	if err != nil {
-		log.Printf("Failed to parse body as JSON")
+	var body struct {
+		Type                string `json:"__type"`
+		Message             string `json:"message"`
+		Item                attributeMap
+		CancellationReasons []struct {
+			Code    string
+			Message string
+			Item    attributeMap
+		}
+	}
+	if err := json.Unmarshal(jsonBody, &body); err != nil {
 		ddbError.Code = "Failed to parse body as JSON"
-		return &ddbError
+		return &ddbError, err
+	}
+	ddbError.Message = body.Message
+	if body.Item != nil {
+		ddbError.Item = body.Item.attributes()
+	}
+	for _, r := range body.CancellationReasons {
+		reason := CancellationReasonT{Code: r.Code, Message: r.Message}
+		if r.Item != nil {
+			reason.Item = r.Item.attributes()
+		}
+		ddbError.CancellationReasons = append(ddbError.CancellationReasons, reason)
 	}
-	ddbError.Message = json.Get("message").MustString()
 
 	// Of the form: com.amazon.coral.validate#ValidationException
 	// We only want the last part
-	codeStr := json.Get("__type").MustString()
+	codeStr := body.Type
 	hashIndex := strings.Index(codeStr, "#")
 	if hashIndex > 0 {
 		codeStr = codeStr[hashIndex+1:]
 	}
 	ddbError.Code = codeStr
 
-	return &ddbError
+	return &ddbError, nil
 }
 
 func (s *Server) rawQueryServer(target string, query string, retryCount int) ([]byte, error) {
+	return s.rawQueryEndpoint(s.Region.DynamoDBEndpoint, target, query, retryCount)
+}
+
+func (s *Server) rawQueryEndpoint(endpoint string, target string, query string, retryCount int) ([]byte, error) {
+	return s.rawQueryEndpointContext(context.Background(), endpoint, target, query, retryCount)
+}
+
+func (s *Server) rawQueryEndpointContext(ctx context.Context, endpoint string, target string, query string, retryCount int) ([]byte, error) {
+	return s.rawQueryNames(ctx, endpoint, target, query, retryCount, s.TableNames)
+}
+
+// rawQueryNames maps table names with names, when not nil, in place of
+// s.TableNames.
+func (s *Server) rawQueryNames(ctx context.Context, endpoint string, target string, query string, retryCount int, names TableNameTransformer) ([]byte, error) {
+	req := &Request{
+		Context:   ctx,
+		Operation: target[strings.LastIndex(target, ".")+1:],
+		Target:    target,
+		Endpoint:  endpoint,
+		Body:      []byte(query),
+	}
+	if names != nil {
+		req.Body = tableNames{names}.request(req.Operation, req.Body)
+	}
+	handler := s.handler(func(req *Request) ([]byte, error) {
+		return s.send(req.Context, req.Endpoint, req.Target, string(req.Body), retryCount)
+	})
+
+	started := time.Now()
+	if d := s.timeouts(ctx).Operation; d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	ctx, stats := withCallStats(ctx)
+	ctx, span := s.startSpan(ctx, req)
+	req.Context = ctx
+
+	response, err := handler(req)
+	if err == nil && names != nil {
+		response = tableNames{names}.response(req.Operation, response)
+	}
+
+	endSpan(span, req, stats, response, err)
+	s.observe(req, started, stats, response, err)
+	if md, ok := ctx.Value(responseMetadataKey{}).(*ResponseMetadata); ok {
+		md.RequestID = stats.requestID
+	}
+	return response, err
+}
+
+// send performs the signed HTTP request. When retryCount is not negative,
+// throttled requests and server errors (see IsRetryable) are retried
+// following the retry policy.
+func (s *Server) send(ctx context.Context, endpoint string, target string, query string, retryCount int) ([]byte, error) {
+	policy := s.retryPolicyFor(ctx)
+	logger := s.logger()
+	stats := statsFromContext(ctx)
+	started := time.Now()
+
+	refreshed := false
+	for {
+		body, wait, err := s.sendOnce(ctx, endpoint, target, query)
+		if !refreshed && ErrorCode(err) == ExpiredTokenException && s.invalidateCredentials() {
+			// Dynamodb rejected the request, so re-sending it with new
+			// credentials is safe whatever retryCount.
+			refreshed = true
+			logger.Log(LogWarn, "retrying request with new credentials", "target", target)
+			continue
+		}
+		if err == nil || !IsRetryable(err) {
+			return body, err
+		}
+
+		if IsThrottle(err) {
+			stats.throttles++
+		}
+		if retryCount < 0 {
+			return nil, err
+		}
+		delay := policy.Backoff(retryCount)
+		if wait > delay {
+			delay = wait
+		}
+		if !policy.allows(retryCount, time.Since(started), delay) {
+			return nil, err
+		}
+
+		stats.retries++
+		retryCount += 1
+		logger.Log(LogWarn, "retrying request", "target", target, "retry", retryCount, "delay", delay, "error", err)
+		if !sleepContext(ctx, delay) {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// sendOnce performs one attempt of the request. On error responses it also
+// returns the delay the service asked for with Retry-After, if any.
+func (s *Server) sendOnce(ctx context.Context, endpoint string, target string, query string) ([]byte, time.Duration, error) {
+	ctx, cancel, classify := withAttemptTimeout(ctx, s.timeouts(ctx).Attempt)
+	defer cancel()
+
+	if s.Transport != nil {
+		body, err := s.roundTrip(ctx, endpoint, target, query)
+		return body, 0, classify(err)
+	}
+
 	reader := strings.NewReader(query)
-	hreq, err := http.NewRequest("POST", s.Region.DynamoDBEndpoint+"/", reader)
+	hreq, err := http.NewRequestWithContext(ctx, "POST", endpoint+"/", reader)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	hreq.Header.Set("Content-Type", "application/x-amz-json-1.0")
 	hreq.Header.Set("X-Amz-Date", time.Now().UTC().Format(aws.ISO8601BasicFormat))
 	hreq.Header.Set("X-Amz-Target", target)
+	if s.Gzip {
+		hreq.Header.Set("Accept-Encoding", "gzip")
+	}
 
-	token := s.Auth.Token()
+	auth, token, err := s.auth(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
 	if token != "" {
 		hreq.Header.Set("X-Amz-Security-Token", token)
 	}
 
-	signer := aws.NewV4Signer(s.Auth, "dynamodb", s.Region)
+	signer := aws.NewV4Signer(auth, "dynamodb", s.Region)
 	signer.Sign(hreq)
 
-	resp, err := http.DefaultClient.Do(hreq)
+	if dryRun := s.dryRun(ctx); dryRun != nil {
+		dryRun(&SignedRequest{
+			Method: hreq.Method,
+			URL:    hreq.URL.String(),
+			Header: hreq.Header.Clone(),
+			Body:   []byte(query),
+		})
+		return nil, 0, ErrDryRun
+	}
+
+	logger := s.logger()
+	logger.Log(LogDebug, "request", "target", target, "body", query)
+
+	resp, err := s.httpClient().Do(hreq)
 
 	if err != nil {
-		log.Printf("Error calling Amazon")
-		return nil, err
+		err = classify(err)
+		logger.Log(LogError, "error calling Amazon", "target", target, "error", err)
+		return nil, 0, err
 	}
 
 	defer resp.Body.Close()
+	statsFromContext(ctx).requestID = resp.Header.Get(requestIDHeader)
 
 	body, err := ioutil.ReadAll(resp.Body)
+	if err == nil {
+		err = verifyChecksum(resp, body)
+	}
+	if err == nil {
+		body, err = decodeBody(resp.Header, body)
+	}
 	if err != nil {
-		log.Printf("Could not read response body")
-		return nil, err
+		err = classify(err)
+		logger.Log(LogError, "could not read response body", "target", target, "error", err)
+		return nil, 0, err
 	}
 
+	logger.Log(LogDebug, "response", "target", target, "status", resp.StatusCode, "body", string(body))
+
 	// http://docs.aws.amazon.com/amazondynamodb/latest/developerguide/ErrorHandling.html
 	// "A response code of 200 indicates the operation was successful."
 	if resp.StatusCode != 200 {
-		ddbErr := buildError(resp, body)
-		if ddbErr.Code == ProvisionedThroughputExceeded {
-			if retryCount >= 0 {
-				retryCount += 1
-				log.Printf("Retry query: %v.", query)
-				time.Sleep(time.Duration(retryCount) * time.Second)
-				return s.rawQueryServer(target, query, retryCount)
-			}
-		}
-		return nil, ddbErr
+		return nil, retryAfter(resp.Header), buildError(logger, resp, body)
 	}
 
-	return body, nil
+	return body, 0, nil
 }
 
 func (s *Server) queryServer(target string, query *Query, isRetry bool) ([]byte, error) {
@@ -123,9 +360,19 @@ func (s *Server) queryServer(target string, query *Query, isRetry bool) ([]byte,
 	if !isRetry {
 		retryCount = -1
 	}
+	query.escapeReservedWords()
 	return s.rawQueryServer(target, query.String(), retryCount)
 }
 
+func (s *Server) queryServerContext(ctx context.Context, target string, query *Query, isRetry bool) ([]byte, error) {
+	var retryCount = 0
+	if !isRetry {
+		retryCount = -1
+	}
+	query.escapeReservedWords()
+	return s.rawQueryEndpointContext(ctx, s.Region.DynamoDBEndpoint, target, query.String(), retryCount)
+}
+
 func target(name string) string {
 	return "DynamoDB_20120810." + name
 }
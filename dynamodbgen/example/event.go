@@ -0,0 +1,18 @@
+// Package example shows the code dynamodbgen generates.
+package example
+
+//go:generate go run github.com/bluele/dynamodb/dynamodbgen -type=Event,Account
+
+// Event is stored in a table keyed by user and sequence number.
+type Event struct {
+	User    string `dynamodb:"user,hash"`
+	Seq     int64  `dynamodb:"seq,range"`
+	Kind    string `dynamodb:"kind"`
+	Payload []byte `dynamodb:"payload,omitempty"`
+}
+
+// Account is stored in a table keyed by id only.
+type Account struct {
+	ID    string `json:"id,hash"`
+	Email string `json:"email"`
+}
@@ -0,0 +1,130 @@
+// Code generated by "dynamodbgen -type=Event,Account"; DO NOT EDIT.
+
+package example
+
+import (
+	"context"
+
+	"github.com/bluele/dynamodb"
+)
+
+// EventTable reads and writes Event items.
+type EventTable struct {
+	Table *dynamodb.Table
+}
+
+// NewEventTable returns the EventTable of the table name.
+func NewEventTable(server *dynamodb.Server, name string) *EventTable {
+	return &EventTable{server.NewTable(name, dynamodb.PrimaryKey{
+		KeyAttribute:   dynamodb.NewStringAttribute("user", ""),
+		RangeAttribute: dynamodb.NewNumericAttribute("seq", ""),
+	})}
+}
+
+// Get returns the item with the given key, or dynamodb.ErrNotFound.
+func (t *EventTable) Get(hash string, rangeKey int64) (*Event, error) {
+	key, err := t.Table.NewKey(hash, rangeKey)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := t.Table.GetItem(key, true)
+	if err != nil {
+		return nil, err
+	}
+	v := &Event{}
+	if err := dynamodb.UnmarshalAttributes(&attrs, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Put writes v, replacing any item with the same key.
+func (t *EventTable) Put(ctx context.Context, v *Event) error {
+	attrs, err := dynamodb.MarshalAttributes(v)
+	if err != nil {
+		return err
+	}
+	_, err = t.Table.PutItemWithOptions(ctx, attrs, &dynamodb.WriteOptions{IsRetry: true})
+	return err
+}
+
+// Delete deletes the item with the given key, if any.
+func (t *EventTable) Delete(ctx context.Context, hash string, rangeKey int64) error {
+	key, err := t.Table.NewKey(hash, rangeKey)
+	if err != nil {
+		return err
+	}
+	_, err = t.Table.DeleteItemWithOptions(ctx, key, &dynamodb.WriteOptions{IsRetry: true})
+	return err
+}
+
+// Query returns the items with the given hash key, in range key order
+// unless opts say otherwise. The returned key is the cursor to pass as
+// opts.ExclusiveStartKey to fetch the next page; it is nil on the last one.
+func (t *EventTable) Query(ctx context.Context, hash string, opts *dynamodb.QueryOptions) ([]*Event, *dynamodb.Key, error) {
+	cond, err := dynamodb.Equals("user", hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, last, err := t.Table.QueryWithOptions(ctx, []dynamodb.AttributeComparison{*cond}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	vs := make([]*Event, len(items))
+	for i := range items {
+		vs[i] = &Event{}
+		if err := dynamodb.UnmarshalAttributes(&items[i], vs[i]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return vs, last, nil
+}
+
+// AccountTable reads and writes Account items.
+type AccountTable struct {
+	Table *dynamodb.Table
+}
+
+// NewAccountTable returns the AccountTable of the table name.
+func NewAccountTable(server *dynamodb.Server, name string) *AccountTable {
+	return &AccountTable{server.NewTable(name, dynamodb.PrimaryKey{
+		KeyAttribute: dynamodb.NewStringAttribute("id", ""),
+	})}
+}
+
+// Get returns the item with the given key, or dynamodb.ErrNotFound.
+func (t *AccountTable) Get(hash string) (*Account, error) {
+	key, err := t.Table.NewKey(hash, nil)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := t.Table.GetItem(key, true)
+	if err != nil {
+		return nil, err
+	}
+	v := &Account{}
+	if err := dynamodb.UnmarshalAttributes(&attrs, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Put writes v, replacing any item with the same key.
+func (t *AccountTable) Put(ctx context.Context, v *Account) error {
+	attrs, err := dynamodb.MarshalAttributes(v)
+	if err != nil {
+		return err
+	}
+	_, err = t.Table.PutItemWithOptions(ctx, attrs, &dynamodb.WriteOptions{IsRetry: true})
+	return err
+}
+
+// Delete deletes the item with the given key, if any.
+func (t *AccountTable) Delete(ctx context.Context, hash string) error {
+	key, err := t.Table.NewKey(hash, nil)
+	if err != nil {
+		return err
+	}
+	_, err = t.Table.DeleteItemWithOptions(ctx, key, &dynamodb.WriteOptions{IsRetry: true})
+	return err
+}
@@ -0,0 +1,77 @@
+package example_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbgen/example"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) {
+	check.TestingT(t)
+}
+
+type GeneratedSuite struct {
+	events   *example.EventTable
+	accounts *example.AccountTable
+}
+
+var _ = check.Suite(&GeneratedSuite{})
+
+func (s *GeneratedSuite) SetUpTest(c *check.C) {
+	server, _ := dynamodbtest.NewServer()
+	for _, v := range []interface{}{example.Event{}, example.Account{}} {
+		description, err := dynamodb.TableSchemaFromStruct(v, nil)
+		c.Assert(err, check.IsNil)
+		_, err = server.CreateTable(description, false)
+		c.Assert(err, check.IsNil)
+	}
+	s.events = example.NewEventTable(server, "Event")
+	s.accounts = example.NewAccountTable(server, "Account")
+}
+
+func (s *GeneratedSuite) TestHashRangeTable(c *check.C) {
+	ctx := context.Background()
+	for seq, kind := range []string{"login", "click", "logout"} {
+		err := s.events.Put(ctx, &example.Event{User: "alice", Seq: int64(seq + 1), Kind: kind})
+		c.Assert(err, check.IsNil)
+	}
+	c.Assert(s.events.Put(ctx, &example.Event{User: "bob", Seq: 1, Kind: "click", Payload: []byte{1, 2}}), check.IsNil)
+
+	event, err := s.events.Get("bob", 1)
+	c.Assert(err, check.IsNil)
+	c.Check(*event, check.DeepEquals, example.Event{User: "bob", Seq: 1, Kind: "click", Payload: []byte{1, 2}})
+
+	events, last, err := s.events.Query(ctx, "alice", &dynamodb.QueryOptions{Limit: 2})
+	c.Assert(err, check.IsNil)
+	c.Assert(events, check.HasLen, 2)
+	c.Check(events[0].Kind, check.Equals, "login")
+	c.Check(events[1].Kind, check.Equals, "click")
+	c.Assert(last, check.NotNil)
+
+	events, last, err = s.events.Query(ctx, "alice", &dynamodb.QueryOptions{ExclusiveStartKey: last})
+	c.Assert(err, check.IsNil)
+	c.Assert(events, check.HasLen, 1)
+	c.Check(events[0].Kind, check.Equals, "logout")
+	c.Check(last, check.IsNil)
+
+	c.Assert(s.events.Delete(ctx, "alice", 2), check.IsNil)
+	_, err = s.events.Get("alice", 2)
+	c.Check(err, check.Equals, dynamodb.ErrNotFound)
+}
+
+func (s *GeneratedSuite) TestHashTable(c *check.C) {
+	ctx := context.Background()
+	c.Assert(s.accounts.Put(ctx, &example.Account{ID: "a1", Email: "a@example.com"}), check.IsNil)
+
+	account, err := s.accounts.Get("a1")
+	c.Assert(err, check.IsNil)
+	c.Check(account.Email, check.Equals, "a@example.com")
+
+	c.Assert(s.accounts.Delete(ctx, "a1"), check.IsNil)
+	_, err = s.accounts.Get("a1")
+	c.Check(err, check.Equals, dynamodb.ErrNotFound)
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// A pkg holds the parsed non-test files of one package directory.
+type pkg struct {
+	name  string
+	files []*ast.File
+}
+
+func parsePackage(dir string) (*pkg, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	p := &pkg{}
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, path, src, 0)
+		if err != nil {
+			return nil, err
+		}
+		if p.name != "" && f.Name.Name != p.name {
+			return nil, fmt.Errorf("Multiple packages in %s: %s and %s.", dir, p.name, f.Name.Name)
+		}
+		p.name = f.Name.Name
+		p.files = append(p.files, f)
+	}
+	if p.name == "" {
+		return nil, fmt.Errorf("No Go files in %s.", dir)
+	}
+	return p, nil
+}
+
+// A keyField is a struct field holding a key attribute.
+type keyField struct {
+	Attribute string // attribute name
+	GoType    string // as written in the struct
+	Type      string // dynamodb.TYPE_STRING or dynamodb.TYPE_NUMBER
+}
+
+// AttributeFunc names the constructor of the key's attribute definition.
+func (k *keyField) AttributeFunc() string {
+	if k.Type == "N" {
+		return "NewNumericAttribute"
+	}
+	return "NewStringAttribute"
+}
+
+type tableType struct {
+	Name  string
+	Hash  *keyField
+	Range *keyField
+}
+
+// findType returns the key fields of the struct type name.
+func (p *pkg) findType(name string) (*tableType, error) {
+	for _, f := range p.files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.Name.Name != name {
+					continue
+				}
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					return nil, fmt.Errorf("%s is not a struct type.", name)
+				}
+				return structKeys(name, st)
+			}
+		}
+	}
+	return nil, fmt.Errorf("Type %s not found in package %s.", name, p.name)
+}
+
+func structKeys(name string, st *ast.StructType) (*tableType, error) {
+	t := &tableType{Name: name}
+	for _, f := range st.Fields.List {
+		if f.Tag == nil || len(f.Names) == 0 {
+			continue
+		}
+		raw, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return nil, err
+		}
+		tag, ok := reflect.StructTag(raw).Lookup("dynamodb")
+		if !ok {
+			tag = reflect.StructTag(raw).Get("json")
+		}
+		attr, opts := parseTag(tag)
+		isHash, isRange := opts.contains("hash"), opts.contains("range")
+		if !isHash && !isRange {
+			continue
+		}
+		if len(f.Names) > 1 {
+			return nil, fmt.Errorf("Key tag of %s is shared by several fields.", f.Names[0].Name)
+		}
+		fieldName := f.Names[0].Name
+		if !ast.IsExported(fieldName) {
+			return nil, fmt.Errorf("Key field %s of %s is not exported.", fieldName, name)
+		}
+		if attr == "" {
+			attr = fieldName
+		}
+		if w := opts.values("write"); len(w) > 0 {
+			attr = w[len(w)-1]
+		}
+
+		goType := exprString(f.Type)
+		typ, err := keyAttributeType(goType)
+		if err != nil {
+			return nil, fmt.Errorf("Field %s of %s: %s", fieldName, name, err)
+		}
+		k := &keyField{Attribute: attr, GoType: goType, Type: typ}
+		if isHash {
+			if t.Hash != nil {
+				return nil, fmt.Errorf("Both %s and %s of %s are tagged as hash key.", t.Hash.Attribute, attr, name)
+			}
+			t.Hash = k
+		}
+		if isRange {
+			if t.Range != nil {
+				return nil, fmt.Errorf("Both %s and %s of %s are tagged as range key.", t.Range.Attribute, attr, name)
+			}
+			t.Range = k
+		}
+	}
+	if t.Hash == nil {
+		return nil, fmt.Errorf("No field of %s is tagged as hash key.", name)
+	}
+	return t, nil
+}
+
+// keyAttributeType maps the Go type of a key field to its attribute type.
+// Only predeclared types are understood, as the package is not type
+// checked.
+func keyAttributeType(goType string) (string, error) {
+	switch goType {
+	case "string":
+		return "S", nil
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		return "N", nil
+	}
+	return "", fmt.Errorf("key attributes must be strings or numbers, not %s.", goType)
+}
+
+func exprString(e ast.Expr) string {
+	switch x := e.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		return exprString(x.X) + "." + x.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(x.X)
+	case *ast.ArrayType:
+		if x.Len == nil {
+			return "[]" + exprString(x.Elt)
+		}
+	}
+	return fmt.Sprintf("%T", e)
+}
+
+// parseTag splits a tag into its name and options, like the dynamodb
+// package does.
+func parseTag(tag string) (string, tagOptions) {
+	if idx := strings.Index(tag, ","); idx != -1 {
+		return tag[:idx], strings.Split(tag[idx+1:], ",")
+	}
+	return tag, nil
+}
+
+type tagOptions []string
+
+func (o tagOptions) contains(name string) bool {
+	for _, opt := range o {
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (o tagOptions) values(name string) []string {
+	var values []string
+	for _, opt := range o {
+		if strings.HasPrefix(opt, name+"=") {
+			values = append(values, opt[len(name)+1:])
+		}
+	}
+	return values
+}
+
+// generate returns the formatted source of the accessors of types.
+func generate(p *pkg, types []string) ([]byte, error) {
+	data := struct {
+		Package string
+		Command string
+		Types   []*tableType
+	}{Package: p.name, Command: "dynamodbgen -type=" + strings.Join(types, ",")}
+
+	for _, name := range types {
+		t, err := p.findType(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		data.Types = append(data.Types, t)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("Generated invalid code: %s", err)
+	}
+	return src, nil
+}
+
+var tmpl = template.Must(template.New("").Parse(`// Code generated by "{{.Command}}"; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+
+	"github.com/bluele/dynamodb"
+)
+{{range .Types}}{{$t := .}}
+// {{.Name}}Table reads and writes {{.Name}} items.
+type {{.Name}}Table struct {
+	Table *dynamodb.Table
+}
+
+// New{{.Name}}Table returns the {{.Name}}Table of the table name.
+func New{{.Name}}Table(server *dynamodb.Server, name string) *{{.Name}}Table {
+	return &{{.Name}}Table{server.NewTable(name, dynamodb.PrimaryKey{
+		KeyAttribute: dynamodb.{{.Hash.AttributeFunc}}("{{.Hash.Attribute}}", ""),
+{{- if .Range}}
+		RangeAttribute: dynamodb.{{.Range.AttributeFunc}}("{{.Range.Attribute}}", ""),
+{{- end}}
+	})}
+}
+
+// Get returns the item with the given key, or dynamodb.ErrNotFound.
+func (t *{{.Name}}Table) Get(hash {{.Hash.GoType}}{{if .Range}}, rangeKey {{.Range.GoType}}{{end}}) (*{{.Name}}, error) {
+	key, err := t.Table.NewKey(hash, {{if .Range}}rangeKey{{else}}nil{{end}})
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := t.Table.GetItem(key, true)
+	if err != nil {
+		return nil, err
+	}
+	v := &{{.Name}}{}
+	if err := dynamodb.UnmarshalAttributes(&attrs, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Put writes v, replacing any item with the same key.
+func (t *{{.Name}}Table) Put(ctx context.Context, v *{{.Name}}) error {
+	attrs, err := dynamodb.MarshalAttributes(v)
+	if err != nil {
+		return err
+	}
+	_, err = t.Table.PutItemWithOptions(ctx, attrs, &dynamodb.WriteOptions{IsRetry: true})
+	return err
+}
+
+// Delete deletes the item with the given key, if any.
+func (t *{{.Name}}Table) Delete(ctx context.Context, hash {{.Hash.GoType}}{{if .Range}}, rangeKey {{.Range.GoType}}{{end}}) error {
+	key, err := t.Table.NewKey(hash, {{if .Range}}rangeKey{{else}}nil{{end}})
+	if err != nil {
+		return err
+	}
+	_, err = t.Table.DeleteItemWithOptions(ctx, key, &dynamodb.WriteOptions{IsRetry: true})
+	return err
+}
+{{if .Range}}
+// Query returns the items with the given hash key, in range key order
+// unless opts say otherwise. The returned key is the cursor to pass as
+// opts.ExclusiveStartKey to fetch the next page; it is nil on the last one.
+func (t *{{.Name}}Table) Query(ctx context.Context, hash {{.Hash.GoType}}, opts *dynamodb.QueryOptions) ([]*{{.Name}}, *dynamodb.Key, error) {
+	cond, err := dynamodb.Equals("{{.Hash.Attribute}}", hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, last, err := t.Table.QueryWithOptions(ctx, []dynamodb.AttributeComparison{*cond}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	vs := make([]*{{.Name}}, len(items))
+	for i := range items {
+		vs[i] = &{{.Name}}{}
+		if err := dynamodb.UnmarshalAttributes(&items[i], vs[i]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return vs, last, nil
+}
+{{end}}{{end}}`))
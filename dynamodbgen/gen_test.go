@@ -0,0 +1,47 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"regexp"
+	"testing"
+
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) {
+	check.TestingT(t)
+}
+
+type GenSuite struct{}
+
+var _ = check.Suite(&GenSuite{})
+
+func (s *GenSuite) TestExampleIsUpToDate(c *check.C) {
+	p, err := parsePackage("example")
+	c.Assert(err, check.IsNil)
+	src, err := generate(p, []string{"Event", "Account"})
+	c.Assert(err, check.IsNil)
+
+	want, err := ioutil.ReadFile("example/event_dynamodb.go")
+	c.Assert(err, check.IsNil)
+	c.Check(string(src), check.Equals, string(want), check.Commentf("run go generate in dynamodbgen/example"))
+}
+
+func (s *GenSuite) TestKeyErrors(c *check.C) {
+	for src, msg := range map[string]string{
+		"type T struct{ A string }": "No field of T is tagged as hash key.",
+		"type T struct{ A string `dynamodb:\"a,hash\"`; B string `dynamodb:\"b,hash\"` }": "Both a and b of T are tagged as hash key.",
+		"type T struct{ A bool `dynamodb:\"a,hash\"` }":                                   "Field A of T: key attributes must be strings or numbers, not bool.",
+		"type T struct{ a string `dynamodb:\"a,hash\"` }":                                 "Key field a of T is not exported.",
+		"type T int":      "T is not a struct type.",
+		"type U struct{}": "Type T not found in package p.",
+	} {
+		f, err := parser.ParseFile(token.NewFileSet(), "t.go", "package p\n"+src, 0)
+		c.Assert(err, check.IsNil)
+		_, err = generate(&pkg{name: "p", files: []*ast.File{f}}, []string{"T"})
+		c.Check(err, check.ErrorMatches, regexp.QuoteMeta(msg), check.Commentf(src))
+	}
+}
@@ -0,0 +1,76 @@
+// Command dynamodbgen generates typed accessors for structs stored in
+// Dynamodb, so that application code does not marshal attributes by hand.
+// It is meant to be run by go generate:
+//
+//	//go:generate go run github.com/bluele/dynamodb/dynamodbgen -type=Event
+//	type Event struct {
+//		User string `dynamodb:"user,hash"`
+//		Seq  int64  `dynamodb:"seq,range"`
+//		Kind string `dynamodb:"kind"`
+//	}
+//
+// For each type it writes a <Type>Table wrapping a *dynamodb.Table, with
+// Get, Put and Delete methods taking the key fields, and for tables with a
+// range key a Query method returning the items of one hash key. The key
+// fields are found with the hash and range options of the dynamodb tag
+// (see dynamodb.TableSchemaFromStruct); attribute names follow the same
+// rules as dynamodb.MarshalAttributes.
+//
+// The output goes to <type>_dynamodb.go in the package directory unless
+// -output is given.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of struct type names; required")
+	output    = flag.String("output", "", "output file name; default <dir>/<type>_dynamodb.go")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage of dynamodbgen:\n")
+	fmt.Fprintf(os.Stderr, "\tdynamodbgen -type T [directory]\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("dynamodbgen: ")
+	flag.Usage = usage
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if args := flag.Args(); len(args) > 0 {
+		dir = args[0]
+	}
+
+	pkg, err := parsePackage(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	names := strings.Split(*typeNames, ",")
+	src, err := generate(pkg, names)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out := *output
+	if out == "" {
+		out = filepath.Join(dir, strings.ToLower(names[0])+"_dynamodb.go")
+	}
+	if err := ioutil.WriteFile(out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,59 @@
+package dynamodbtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/bluele/dynamodb"
+)
+
+var _ dynamodb.BlobStore = (*BlobStore)(nil)
+
+// BlobStore is an in-memory dynamodb.BlobStore, for tests of
+// OverflowTable. It is safe for concurrent use.
+type BlobStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func NewBlobStore() *BlobStore {
+	return &BlobStore{blobs: make(map[string][]byte)}
+}
+
+func (s *BlobStore) Put(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (s *BlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, fmt.Errorf("No blob %s.", key)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (s *BlobStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, key)
+	return nil
+}
+
+// Keys returns the keys of the stored blobs, sorted.
+func (s *BlobStore) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.blobs))
+	for key := range s.blobs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,485 @@
+package dynamodbtest
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// expression evaluates the subset of condition, update and projection
+// expressions the fake understands, against one item. Attribute paths are
+// document paths, e.g. #profile.#city or tags[2]; projections only take
+// top level names.
+type expression struct {
+	tokens []string
+	pos    int
+	names  map[string]string
+	values map[string]value
+	item   item
+	// updated collects the attributes an update expression touched.
+	updated map[string]bool
+	// removals collects the paths of REMOVE clauses, applied at the end.
+	removals []docPath
+}
+
+func tokenize(s string) []string {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.HasPrefix(s[i:], "<>"), strings.HasPrefix(s[i:], "<="), strings.HasPrefix(s[i:], ">="):
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+		case strings.ContainsRune("()=<>,+-", c):
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			j := i
+			for j < len(s) && !unicode.IsSpace(rune(s[j])) && !strings.ContainsRune("()=<>,+-", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
+
+func newExpression(s string, names map[string]string, values map[string]value, i item) *expression {
+	return &expression{tokens: tokenize(s), names: names, values: values, item: i, updated: map[string]bool{}}
+}
+
+func (e *expression) peek() string {
+	if e.pos < len(e.tokens) {
+		return e.tokens[e.pos]
+	}
+	return ""
+}
+
+func (e *expression) next() string {
+	t := e.peek()
+	e.pos++
+	return t
+}
+
+func (e *expression) keyword(k string) bool {
+	if strings.EqualFold(e.peek(), k) {
+		e.pos++
+		return true
+	}
+	return false
+}
+
+func (e *expression) expect(t string) error {
+	if got := e.next(); got != t {
+		return validationError("Invalid expression: expected %q, got %q.", t, got)
+	}
+	return nil
+}
+
+func (e *expression) done() error {
+	if e.pos < len(e.tokens) {
+		return validationError("Invalid expression: unexpected %q.", e.peek())
+	}
+	return nil
+}
+
+// path resolves a path token, substituting #placeholders.
+func (e *expression) path() (docPath, error) {
+	return parsePath(e.next(), e.names)
+}
+
+// operand returns a value, nil when it refers to a missing attribute.
+func (e *expression) operand() (value, error) {
+	t := e.peek()
+	if strings.HasPrefix(t, ":") {
+		e.pos++
+		v, ok := e.values[t]
+		if !ok {
+			return nil, validationError("Undefined expression attribute value %s.", t)
+		}
+		return v, nil
+	}
+	if strings.EqualFold(t, "size") && e.pos+1 < len(e.tokens) && e.tokens[e.pos+1] == "(" {
+		e.pos += 2
+		v, err := e.operand()
+		if err != nil {
+			return nil, err
+		}
+		if err := e.expect(")"); err != nil {
+			return nil, err
+		}
+		return size(v), nil
+	}
+	p, err := e.path()
+	if err != nil {
+		return nil, err
+	}
+	return e.item.get(p), nil
+}
+
+func size(v value) value {
+	if v == nil {
+		return nil
+	}
+	n := 0
+	if _, s, ok := v.scalar(); ok {
+		n = len(s)
+	} else if _, members, ok := v.set(); ok {
+		n = len(members)
+	} else if l, ok := v["L"].([]interface{}); ok {
+		n = len(l)
+	} else if m, ok := v["M"].(map[string]interface{}); ok {
+		n = len(m)
+	}
+	return value{"N": strconv.Itoa(n)}
+}
+
+// condition parses and evaluates a whole condition expression.
+func (e *expression) condition() (bool, error) {
+	ok, err := e.or()
+	if err != nil {
+		return false, err
+	}
+	return ok, e.done()
+}
+
+func (e *expression) or() (bool, error) {
+	result, err := e.and()
+	for err == nil && e.keyword("OR") {
+		var ok bool
+		ok, err = e.and()
+		result = result || ok
+	}
+	return result, err
+}
+
+func (e *expression) and() (bool, error) {
+	result, err := e.not()
+	for err == nil && e.keyword("AND") {
+		var ok bool
+		ok, err = e.not()
+		result = result && ok
+	}
+	return result, err
+}
+
+func (e *expression) not() (bool, error) {
+	if e.keyword("NOT") {
+		ok, err := e.not()
+		return !ok, err
+	}
+	return e.primary()
+}
+
+func (e *expression) primary() (bool, error) {
+	if e.peek() == "(" {
+		e.pos++
+		ok, err := e.or()
+		if err != nil {
+			return false, err
+		}
+		return ok, e.expect(")")
+	}
+
+	if e.pos+1 < len(e.tokens) && e.tokens[e.pos+1] == "(" {
+		switch fn := strings.ToLower(e.peek()); fn {
+		case "attribute_exists", "attribute_not_exists", "begins_with", "contains":
+			e.pos += 2
+			return e.function(fn)
+		}
+	}
+
+	left, err := e.operand()
+	if err != nil {
+		return false, err
+	}
+	switch op := e.next(); {
+	case strings.EqualFold(op, "BETWEEN"):
+		low, err := e.operand()
+		if err != nil {
+			return false, err
+		}
+		if err := e.expect("AND"); err != nil {
+			return false, err
+		}
+		high, err := e.operand()
+		if err != nil {
+			return false, err
+		}
+		return left != nil && evaluate("BETWEEN", left, []value{low, high}), nil
+	case strings.EqualFold(op, "IN"):
+		if err := e.expect("("); err != nil {
+			return false, err
+		}
+		var list []value
+		for {
+			v, err := e.operand()
+			if err != nil {
+				return false, err
+			}
+			list = append(list, v)
+			if e.peek() != "," {
+				break
+			}
+			e.pos++
+		}
+		if err := e.expect(")"); err != nil {
+			return false, err
+		}
+		return left != nil && evaluate("IN", left, list), nil
+	default:
+		comparator, ok := comparators[op]
+		if !ok {
+			return false, validationError("Invalid expression: unexpected %q.", op)
+		}
+		right, err := e.operand()
+		if err != nil {
+			return false, err
+		}
+		if left == nil || right == nil {
+			return false, nil
+		}
+		return evaluate(comparator, left, []value{right}), nil
+	}
+}
+
+var comparators = map[string]string{
+	"=":  "EQ",
+	"<>": "NE",
+	"<":  "LT",
+	"<=": "LE",
+	">":  "GT",
+	">=": "GE",
+}
+
+func (e *expression) function(fn string) (bool, error) {
+	var result bool
+	switch fn {
+	case "attribute_exists", "attribute_not_exists":
+		p, err := e.path()
+		if err != nil {
+			return false, err
+		}
+		exists := e.item.get(p) != nil
+		result = exists == (fn == "attribute_exists")
+	default:
+		v, err := e.operand()
+		if err != nil {
+			return false, err
+		}
+		if err := e.expect(","); err != nil {
+			return false, err
+		}
+		operand, err := e.operand()
+		if err != nil {
+			return false, err
+		}
+		if v != nil && operand != nil {
+			if fn == "begins_with" {
+				result = beginsWith(v, operand)
+			} else {
+				result = contains(v, operand)
+			}
+		}
+	}
+	return result, e.expect(")")
+}
+
+// projection parses a projection expression into attribute names.
+func (e *expression) projection() ([]string, error) {
+	var names []string
+	for {
+		p, err := e.path()
+		if err != nil {
+			return nil, err
+		}
+		if len(p.elements) > 0 {
+			return nil, validationError("Unsupported projection of a document path in dynamodbtest.")
+		}
+		names = append(names, p.name)
+		if e.peek() != "," {
+			break
+		}
+		e.pos++
+	}
+	return names, e.done()
+}
+
+// update applies an update expression to the expression's item.
+func (e *expression) update() error {
+	if e.peek() == "" {
+		return validationError("Invalid UpdateExpression: empty expression.")
+	}
+	for e.peek() != "" {
+		clause := strings.ToUpper(e.next())
+		for {
+			var err error
+			switch clause {
+			case "SET":
+				err = e.set()
+			case "REMOVE":
+				var p docPath
+				if p, err = e.path(); err == nil {
+					e.removals = append(e.removals, p)
+					e.updated[p.name] = true
+				}
+			case "ADD", "DELETE":
+				err = e.addOrDelete(clause)
+			default:
+				err = validationError("Invalid UpdateExpression: unexpected %q.", clause)
+			}
+			if err != nil {
+				return err
+			}
+			if e.peek() != "," {
+				break
+			}
+			e.pos++
+		}
+	}
+	e.item.removeAll(e.removals)
+	return nil
+}
+
+func (e *expression) set() error {
+	p, err := e.path()
+	if err != nil {
+		return err
+	}
+	if err := e.expect("="); err != nil {
+		return err
+	}
+	v, err := e.setOperand()
+	if err != nil {
+		return err
+	}
+	if op := e.peek(); op == "+" || op == "-" {
+		e.pos++
+		right, err := e.setOperand()
+		if err != nil {
+			return err
+		}
+		v, err = arithmetic(v, right, op == "-")
+		if err != nil {
+			return err
+		}
+	}
+	if v == nil {
+		return validationError("The provided expression refers to an attribute that does not exist in the item.")
+	}
+	if err := e.item.set(p, v); err != nil {
+		return err
+	}
+	e.updated[p.name] = true
+	return nil
+}
+
+func (e *expression) setOperand() (value, error) {
+	if e.pos+1 < len(e.tokens) && e.tokens[e.pos+1] == "(" {
+		switch fn := strings.ToLower(e.peek()); fn {
+		case "if_not_exists", "list_append":
+			e.pos += 2
+			first, err := e.setOperand()
+			if err != nil {
+				return nil, err
+			}
+			if err := e.expect(","); err != nil {
+				return nil, err
+			}
+			second, err := e.setOperand()
+			if err != nil {
+				return nil, err
+			}
+			if err := e.expect(")"); err != nil {
+				return nil, err
+			}
+			if fn == "if_not_exists" {
+				if first != nil {
+					return first, nil
+				}
+				return second, nil
+			}
+			a, okA := first["L"].([]interface{})
+			b, okB := second["L"].([]interface{})
+			if !okA || !okB {
+				return nil, validationError("An operand in the update expression has an incorrect data type.")
+			}
+			return value{"L": append(append([]interface{}{}, a...), b...)}, nil
+		}
+	}
+	return e.operand()
+}
+
+func arithmetic(a, b value, negate bool) (value, error) {
+	ta, sa, okA := a.scalar()
+	tb, sb, okB := b.scalar()
+	if !okA || !okB || ta != "N" || tb != "N" {
+		return nil, validationError("An operand in the update expression has an incorrect data type.")
+	}
+	sum, ok := addNumbers(sa, sb, negate)
+	if !ok {
+		return nil, validationError("Invalid number in the update expression.")
+	}
+	return value{"N": sum}, nil
+}
+
+func (e *expression) addOrDelete(action string) error {
+	p, err := e.path()
+	if err != nil {
+		return err
+	}
+	v, err := e.operand()
+	if err != nil {
+		return err
+	}
+	updated, err := applyUpdate(e.item.get(p), action, v)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		e.item.remove(p)
+	} else if err := e.item.set(p, updated); err != nil {
+		return err
+	}
+	e.updated[p.name] = true
+	return nil
+}
+
+// applyUpdate implements ADD and DELETE actions, shared by update
+// expressions and AttributeUpdates. A nil result removes the attribute.
+func applyUpdate(current value, action string, v value) (value, error) {
+	if t, members, ok := v.set(); ok {
+		if current == nil {
+			if action == "DELETE" {
+				return nil, nil
+			}
+			return v, nil
+		}
+		ct, existing, ok := current.set()
+		if !ok || ct != t {
+			return nil, validationError("An operand in the update expression has an incorrect data type.")
+		}
+		if action == "ADD" {
+			return newSet(t, union(t, existing, members)), nil
+		}
+		remaining := difference(t, existing, members)
+		if len(remaining) == 0 {
+			return nil, nil
+		}
+		return newSet(t, remaining), nil
+	}
+
+	if action == "ADD" {
+		if t, _, ok := v.scalar(); !ok || t != "N" {
+			return nil, validationError("ADD supports only number and set values.")
+		}
+		if current == nil {
+			return v, nil
+		}
+		return arithmetic(current, v, false)
+	}
+	return nil, validationError("DELETE supports only set values.")
+}
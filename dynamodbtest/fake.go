@@ -0,0 +1,489 @@
+// Package dynamodbtest provides an in-memory fake of Dynamodb for unit
+// tests of code using the dynamodb package.
+//
+//	server, fake := dynamodbtest.NewServer()
+//	server.CreateTable(description, false)
+//	table := server.NewTable("users", pk)
+//
+// The fake implements CreateTable, DeleteTable, DescribeTable, UpdateTable
+// (billing, throughput, encryption and global secondary indexes), ListTables,
+// UpdateTimeToLive, DescribeTimeToLive, TagResource, UntagResource and
+// ListTagsOfResource (on tables), GetItem, PutItem, UpdateItem, DeleteItem,
+// Query and Scan (on the table or its indexes), BatchGetItem and
+// BatchWriteItem. Both the legacy
+// parameters (Expected, AttributeUpdates, KeyConditions, QueryFilter,
+// ScanFilter) and expressions over document paths are understood.
+// Other operations fail with a ValidationException. Query and Scan report
+// the read capacity they consume when asked to; capacity limits,
+// throttling and eventual consistency are not simulated.
+//
+// BlobStore is an in-memory store for tests of dynamodb.OverflowTable.
+package dynamodbtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+)
+
+// Fake holds the tables. It is safe for concurrent use.
+type Fake struct {
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+func New() *Fake {
+	return &Fake{tables: make(map[string]*table)}
+}
+
+// NewServer returns a Server whose requests are all answered by a new
+// Fake, and never leave the process. The middleware runs ahead of the
+// fake, e.g. to count, delay or fail requests.
+func NewServer(middleware ...dynamodb.Middleware) (*dynamodb.Server, *Fake) {
+	f := New()
+	server := dynamodb.New(aws.Auth{AccessKey: "FAKE", SecretKey: "FAKE"}, aws.Region{Name: "fake", DynamoDBEndpoint: "http://dynamodbtest.invalid"})
+	server.Use(append(middleware, f.Middleware())...)
+	return server, f
+}
+
+// Middleware answers every request from the fake instead of calling next.
+func (f *Fake) Middleware() dynamodb.Middleware {
+	return func(next dynamodb.Handler) dynamodb.Handler {
+		return f.Handle
+	}
+}
+
+type operation func(f *Fake, body []byte) (interface{}, error)
+
+var operations = map[string]operation{
+	"CreateTable":        (*Fake).createTable,
+	"DeleteTable":        (*Fake).deleteTable,
+	"DescribeTable":      (*Fake).describeTable,
+	"UpdateTable":        (*Fake).updateTable,
+	"ListTables":         (*Fake).listTables,
+	"UpdateTimeToLive":   (*Fake).updateTimeToLive,
+	"DescribeTimeToLive": (*Fake).describeTimeToLive,
+	"TagResource":        (*Fake).tagResource,
+	"UntagResource":      (*Fake).untagResource,
+	"ListTagsOfResource": (*Fake).listTagsOfResource,
+	"GetItem":            (*Fake).getItem,
+	"PutItem":            (*Fake).putItem,
+	"UpdateItem":         (*Fake).updateItem,
+	"DeleteItem":         (*Fake).deleteItem,
+	"Query":              (*Fake).query,
+	"Scan":               (*Fake).scan,
+	"BatchGetItem":       (*Fake).batchGetItem,
+	"BatchWriteItem":     (*Fake).batchWriteItem,
+}
+
+// Handle answers one request like Dynamodb would.
+func (f *Fake) Handle(req *dynamodb.Request) ([]byte, error) {
+	op, ok := operations[req.Operation]
+	if !ok {
+		return nil, validationError("Operation %s is not supported by dynamodbtest.", req.Operation)
+	}
+
+	f.mu.Lock()
+	response, err := op(f, req.Body)
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(response)
+}
+
+func newError(code string, format string, args ...interface{}) error {
+	return &dynamodb.Error{
+		StatusCode: 400,
+		Status:     "400 Bad Request",
+		Code:       code,
+		Message:    fmt.Sprintf(format, args...),
+	}
+}
+
+func validationError(format string, args ...interface{}) error {
+	return newError(dynamodb.ValidationException, format, args...)
+}
+
+// table is one fake table. Items are kept by their encoded primary key.
+type table struct {
+	description dynamodb.TableDescriptionT
+	schema      keySchema
+	indexes     map[string]keySchema
+	items       map[string]item
+	ttl         dynamodb.TimeToLiveDescriptionT
+	tags        map[string]string
+}
+
+type keySchema struct {
+	hash, rangeKey string // attribute names, rangeKey empty if none
+}
+
+func (f *Fake) table(name string) (*table, error) {
+	t, ok := f.tables[name]
+	if !ok {
+		return nil, newError(dynamodb.ResourceNotFoundException, "Requested resource not found: Table: %s not found", name)
+	}
+	return t, nil
+}
+
+func schemaOf(keys []dynamodb.KeySchemaT) keySchema {
+	var s keySchema
+	for _, k := range keys {
+		switch k.KeyType {
+		case "HASH":
+			s.hash = k.AttributeName
+		case "RANGE":
+			s.rangeKey = k.AttributeName
+		}
+	}
+	return s
+}
+
+func (f *Fake) createTable(body []byte) (interface{}, error) {
+	var d dynamodb.TableDescriptionT
+	if err := json.Unmarshal(body, &d); err != nil {
+		return nil, validationError("%s", err)
+	}
+	if _, exists := f.tables[d.TableName]; exists {
+		return nil, newError(dynamodb.ResourceInUseException, "Table already exists: %s", d.TableName)
+	}
+
+	schema := schemaOf(d.KeySchema)
+	if schema.hash == "" {
+		return nil, validationError("No hash key in the key schema of %s.", d.TableName)
+	}
+
+	var billing struct{ BillingMode string }
+	json.Unmarshal(body, &billing)
+	if billing.BillingMode != "" {
+		d.BillingModeSummary.BillingMode = billing.BillingMode
+	}
+
+	now := time.Now()
+	d.TableStatus = "ACTIVE"
+	d.CreationDateTime = float64(now.Unix())
+	d.TableArn = "arn:aws:dynamodb:fake:000000000000:table/" + d.TableName
+	d.TableId = fmt.Sprintf("%08x-0000-0000-0000-000000000000", len(f.tables))
+	if d.StreamSpecification.StreamEnabled {
+		d.LatestStreamLabel = now.UTC().Format("2006-01-02T15:04:05.000")
+		d.LatestStreamArn = d.TableArn + "/stream/" + d.LatestStreamLabel
+	}
+	d.SSEDescription = sseDescription(d.SSESpecification)
+	d.SSESpecification = dynamodb.SSESpecificationT{}
+	for i := range d.LocalSecondaryIndexes {
+		d.LocalSecondaryIndexes[i].IndexArn = d.TableArn + "/index/" + d.LocalSecondaryIndexes[i].IndexName
+	}
+	for i := range d.GlobalSecondaryIndexes {
+		d.GlobalSecondaryIndexes[i].IndexArn = d.TableArn + "/index/" + d.GlobalSecondaryIndexes[i].IndexName
+		d.GlobalSecondaryIndexes[i].IndexStatus = "ACTIVE"
+	}
+	t := &table{
+		description: d,
+		schema:      schema,
+		indexes:     make(map[string]keySchema),
+		items:       make(map[string]item),
+		tags:        make(map[string]string),
+	}
+	for _, index := range d.LocalSecondaryIndexes {
+		t.indexes[index.IndexName] = schemaOf(index.KeySchema)
+	}
+	for _, index := range d.GlobalSecondaryIndexes {
+		t.indexes[index.IndexName] = schemaOf(index.KeySchema)
+	}
+	f.tables[d.TableName] = t
+
+	return map[string]interface{}{"TableDescription": t.describe()}, nil
+}
+
+// sseDescription returns what DescribeTable reports for a table encrypted
+// as spec says. Key ids are reported as they were given.
+func sseDescription(spec dynamodb.SSESpecificationT) dynamodb.SSEDescriptionT {
+	if !spec.Enabled {
+		return dynamodb.SSEDescriptionT{}
+	}
+	arn := spec.KMSMasterKeyId
+	if arn == "" {
+		arn = "arn:aws:kms:fake:000000000000:key/aws-dynamodb"
+	}
+	return dynamodb.SSEDescriptionT{
+		Status:          dynamodb.SSE_STATUS_ENABLED,
+		SSEType:         dynamodb.SSE_TYPE_KMS,
+		KMSMasterKeyArn: arn,
+	}
+}
+
+func (t *table) describe() dynamodb.TableDescriptionT {
+	d := t.description
+	d.ItemCount = int64(len(t.items))
+	return d
+}
+
+type tableNameRequest struct {
+	TableName string
+}
+
+func (f *Fake) deleteTable(body []byte) (interface{}, error) {
+	var r tableNameRequest
+	json.Unmarshal(body, &r)
+	t, err := f.table(r.TableName)
+	if err != nil {
+		return nil, err
+	}
+	delete(f.tables, r.TableName)
+
+	d := t.describe()
+	d.TableStatus = "DELETING"
+	return map[string]interface{}{"TableDescription": d}, nil
+}
+
+func (f *Fake) describeTable(body []byte) (interface{}, error) {
+	var r tableNameRequest
+	json.Unmarshal(body, &r)
+	t, err := f.table(r.TableName)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"Table": t.describe()}, nil
+}
+
+func (f *Fake) updateTable(body []byte) (interface{}, error) {
+	var r struct {
+		TableName                   string
+		BillingMode                 string
+		ProvisionedThroughput       *dynamodb.ProvisionedThroughputT
+		SSESpecification            *dynamodb.SSESpecificationT
+		AttributeDefinitions        []dynamodb.AttributeDefinitionT
+		GlobalSecondaryIndexUpdates []struct {
+			Create *dynamodb.GlobalSecondaryIndexT
+			Delete *struct{ IndexName string }
+		}
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, validationError("%s", err)
+	}
+	t, err := f.table(r.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &t.description
+	if r.BillingMode != "" {
+		d.BillingModeSummary.BillingMode = r.BillingMode
+	}
+	if r.ProvisionedThroughput != nil {
+		d.ProvisionedThroughput.ReadCapacityUnits = r.ProvisionedThroughput.ReadCapacityUnits
+		d.ProvisionedThroughput.WriteCapacityUnits = r.ProvisionedThroughput.WriteCapacityUnits
+	}
+	if r.SSESpecification != nil {
+		d.SSEDescription = sseDescription(*r.SSESpecification)
+	}
+	for _, a := range r.AttributeDefinitions {
+		defined := false
+		for _, existing := range d.AttributeDefinitions {
+			defined = defined || existing.Name == a.Name
+		}
+		if !defined {
+			d.AttributeDefinitions = append(d.AttributeDefinitions, a)
+		}
+	}
+	for _, update := range r.GlobalSecondaryIndexUpdates {
+		switch {
+		case update.Create != nil:
+			index := *update.Create
+			if _, exists := t.indexes[index.IndexName]; exists {
+				return nil, validationError("Index %s already exists.", index.IndexName)
+			}
+			index.IndexArn = d.TableArn + "/index/" + index.IndexName
+			index.IndexStatus = "ACTIVE"
+			d.GlobalSecondaryIndexes = append(d.GlobalSecondaryIndexes, index)
+			t.indexes[index.IndexName] = schemaOf(index.KeySchema)
+		case update.Delete != nil:
+			name := update.Delete.IndexName
+			if d.GlobalSecondaryIndex(name) == nil {
+				return nil, newError(dynamodb.ResourceNotFoundException, "Requested resource not found: Index: %s not found", name)
+			}
+			kept := d.GlobalSecondaryIndexes[:0]
+			for _, index := range d.GlobalSecondaryIndexes {
+				if index.IndexName != name {
+					kept = append(kept, index)
+				}
+			}
+			d.GlobalSecondaryIndexes = kept
+			delete(t.indexes, name)
+		}
+	}
+
+	return map[string]interface{}{"TableDescription": t.describe()}, nil
+}
+
+// updateTimeToLive changes the status at once, where Dynamodb goes through
+// ENABLING and DISABLING.
+func (f *Fake) updateTimeToLive(body []byte) (interface{}, error) {
+	var r struct {
+		TableName               string
+		TimeToLiveSpecification struct {
+			AttributeName string
+			Enabled       bool
+		}
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, validationError("%s", err)
+	}
+	t, err := f.table(r.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := r.TimeToLiveSpecification
+	enabled := t.ttl.TimeToLiveStatus == dynamodb.TTL_STATUS_ENABLED
+	switch {
+	case spec.Enabled && enabled:
+		return nil, validationError("TimeToLive is already enabled")
+	case !spec.Enabled && !enabled:
+		return nil, validationError("TimeToLive is already disabled")
+	case !spec.Enabled && spec.AttributeName != t.ttl.AttributeName:
+		return nil, validationError("TimeToLive is active on a different AttributeName: current AttributeName is %s", t.ttl.AttributeName)
+	}
+	if spec.Enabled {
+		t.ttl = dynamodb.TimeToLiveDescriptionT{AttributeName: spec.AttributeName, TimeToLiveStatus: dynamodb.TTL_STATUS_ENABLED}
+	} else {
+		t.ttl = dynamodb.TimeToLiveDescriptionT{}
+	}
+	return map[string]interface{}{"TimeToLiveSpecification": spec}, nil
+}
+
+func (f *Fake) describeTimeToLive(body []byte) (interface{}, error) {
+	var r tableNameRequest
+	json.Unmarshal(body, &r)
+	t, err := f.table(r.TableName)
+	if err != nil {
+		return nil, err
+	}
+	ttl := t.ttl
+	if ttl.TimeToLiveStatus == "" {
+		ttl.TimeToLiveStatus = dynamodb.TTL_STATUS_DISABLED
+	}
+	return map[string]interface{}{"TimeToLiveDescription": ttl}, nil
+}
+
+func (f *Fake) listTables(body []byte) (interface{}, error) {
+	var r struct {
+		ExclusiveStartTableName string
+		Limit                   int
+	}
+	json.Unmarshal(body, &r)
+
+	names := make([]string, 0, len(f.tables))
+	for name := range f.tables {
+		if name > r.ExclusiveStartTableName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	response := map[string]interface{}{}
+	if r.Limit > 0 && len(names) > r.Limit {
+		names = names[:r.Limit]
+		response["LastEvaluatedTableName"] = names[len(names)-1]
+	}
+	response["TableNames"] = names
+	return response, nil
+}
+
+// Number of tags ListTagsOfResource returns per page.
+const tagsPerPage = 10
+
+// tableByArn returns the table whose ARN is arn, the only resources the
+// fake can tag.
+func (f *Fake) tableByArn(arn string) (*table, error) {
+	for _, t := range f.tables {
+		if t.description.TableArn == arn {
+			return t, nil
+		}
+	}
+	return nil, newError(dynamodb.ResourceNotFoundException, "Requested resource not found: ResourceArn: %s not found", arn)
+}
+
+type tagsRequest struct {
+	ResourceArn string
+	Tags        []dynamodb.TagT
+	TagKeys     []string
+	NextToken   string
+}
+
+func (f *Fake) tagResource(body []byte) (interface{}, error) {
+	var r tagsRequest
+	if err := decode(body, &r); err != nil {
+		return nil, err
+	}
+	t, err := f.tableByArn(r.ResourceArn)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Tags) == 0 {
+		return nil, validationError("1 validation error detected: Value null at 'tags' failed to satisfy constraint: Member must not be null")
+	}
+	for _, tag := range r.Tags {
+		if tag.Key == "" {
+			return nil, validationError("1 validation error detected: Value '' at 'tags.key' failed to satisfy constraint: Member must have length greater than or equal to 1")
+		}
+	}
+	for _, tag := range r.Tags {
+		t.tags[tag.Key] = tag.Value
+	}
+	return map[string]interface{}{}, nil
+}
+
+func (f *Fake) untagResource(body []byte) (interface{}, error) {
+	var r tagsRequest
+	if err := decode(body, &r); err != nil {
+		return nil, err
+	}
+	t, err := f.tableByArn(r.ResourceArn)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range r.TagKeys {
+		delete(t.tags, key)
+	}
+	return map[string]interface{}{}, nil
+}
+
+// listTagsOfResource returns the tags sorted by key, the NextToken being
+// the last key of the page.
+func (f *Fake) listTagsOfResource(body []byte) (interface{}, error) {
+	var r tagsRequest
+	if err := decode(body, &r); err != nil {
+		return nil, err
+	}
+	t, err := f.tableByArn(r.ResourceArn)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(t.tags))
+	for key := range t.tags {
+		if key > r.NextToken {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	response := map[string]interface{}{}
+	if len(keys) > tagsPerPage {
+		keys = keys[:tagsPerPage]
+		response["NextToken"] = keys[len(keys)-1]
+	}
+	tags := make([]dynamodb.TagT, len(keys))
+	for i, key := range keys {
+		tags[i] = dynamodb.TagT{Key: key, Value: t.tags[key]}
+	}
+	response["Tags"] = tags
+	return response, nil
+}
@@ -0,0 +1,342 @@
+package dynamodbtest_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) {
+	check.TestingT(t)
+}
+
+type FakeSuite struct {
+	server *dynamodb.Server
+	fake   *dynamodbtest.Fake
+	table  *dynamodb.Table
+}
+
+var _ = check.Suite(&FakeSuite{})
+
+func (s *FakeSuite) SetUpTest(c *check.C) {
+	s.server, s.fake = dynamodbtest.NewServer()
+	description := dynamodb.TableDescriptionT{
+		TableName: "events",
+		AttributeDefinitions: []dynamodb.AttributeDefinitionT{
+			{Name: "user", Type: "S"},
+			{Name: "seq", Type: "N"},
+			{Name: "kind", Type: "S"},
+		},
+		KeySchema: []dynamodb.KeySchemaT{
+			{AttributeName: "user", KeyType: "HASH"},
+			{AttributeName: "seq", KeyType: "RANGE"},
+		},
+		GlobalSecondaryIndexes: []dynamodb.GlobalSecondaryIndexT{{
+			IndexName:  "kind-index",
+			KeySchema:  []dynamodb.KeySchemaT{{AttributeName: "kind", KeyType: "HASH"}},
+			Projection: dynamodb.ProjectionT{ProjectionType: "ALL"},
+		}},
+		ProvisionedThroughput: dynamodb.ProvisionedThroughputT{ReadCapacityUnits: 1, WriteCapacityUnits: 1},
+	}
+	_, err := s.server.CreateTable(description, false)
+	c.Assert(err, check.IsNil)
+
+	pk, err := description.BuildPrimaryKey()
+	c.Assert(err, check.IsNil)
+	s.table = s.server.NewTable("events", pk)
+
+	for seq, kind := range []string{"login", "click", "click", "logout"} {
+		_, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("alice", strconv.Itoa(seq+1)), *dynamodb.NewStringAttribute("kind", kind)), nil)
+		c.Assert(err, check.IsNil)
+	}
+	_, err = s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("bob", "10"), *dynamodb.NewStringAttribute("kind", "click")), nil)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *FakeSuite) TestTables(c *check.C) {
+	names, err := s.server.ListTables(false)
+	c.Assert(err, check.IsNil)
+	c.Check(names, check.DeepEquals, []string{"events"})
+
+	description, err := s.table.DescribeTable(false)
+	c.Assert(err, check.IsNil)
+	c.Check(description.TableStatus, check.Equals, "ACTIVE")
+	c.Check(description.ItemCount, check.Equals, int64(5))
+
+	c.Check(description.BillingMode(), check.Equals, dynamodb.BILLING_MODE_PROVISIONED)
+	c.Check(description.CreationTime().IsZero(), check.Equals, false)
+	c.Check(description.GlobalSecondaryIndex("kind-index").IndexStatus, check.Equals, "ACTIVE")
+	c.Check(description.GlobalSecondaryIndex("missing"), check.IsNil)
+
+	_, err = s.server.DescribeTable("missing", false)
+	c.Check(dynamodb.IsNotFound(err), check.Equals, true)
+}
+
+func (s *FakeSuite) TestServerMiddleware(c *check.C) {
+	var operations []string
+	server, _ := dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			operations = append(operations, req.Operation)
+			return next(req)
+		}
+	})
+	names, err := server.ListTables(false)
+	c.Assert(err, check.IsNil)
+	c.Check(names, check.HasLen, 0)
+	c.Check(operations, check.DeepEquals, []string{"ListTables"})
+}
+
+func (s *FakeSuite) TestCreateOnDemandTableWithStream(c *check.C) {
+	_, err := s.server.CreateTable(dynamodb.TableDescriptionT{
+		TableName:            "audit",
+		AttributeDefinitions: []dynamodb.AttributeDefinitionT{{Name: "id", Type: "S"}},
+		KeySchema:            []dynamodb.KeySchemaT{{AttributeName: "id", KeyType: "HASH"}},
+		BillingModeSummary:   dynamodb.BillingModeSummaryT{BillingMode: dynamodb.BILLING_MODE_PAY_PER_REQUEST},
+		StreamSpecification:  dynamodb.StreamSpecificationT{StreamEnabled: true, StreamViewType: dynamodb.STREAM_VIEW_TYPE_KEYS_ONLY},
+	}, false)
+	c.Assert(err, check.IsNil)
+
+	description, err := s.server.DescribeTable("audit", false)
+	c.Assert(err, check.IsNil)
+	c.Check(description.BillingMode(), check.Equals, dynamodb.BILLING_MODE_PAY_PER_REQUEST)
+	c.Check(description.StreamSpecification.StreamViewType, check.Equals, dynamodb.STREAM_VIEW_TYPE_KEYS_ONLY)
+	c.Check(description.LatestStreamArn, check.Not(check.Equals), "")
+}
+
+func (s *FakeSuite) TestGetPutDelete(c *check.C) {
+	item, err := s.table.GetItem(&dynamodb.Key{HashKey: "alice", RangeKey: "2"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(item["kind"].Value, check.Equals, "click")
+
+	_, err = s.table.GetItem(&dynamodb.Key{HashKey: "alice", RangeKey: "9"}, false)
+	c.Check(err, check.Equals, dynamodb.ErrNotFound)
+
+	exists := dynamodb.NewStringAttribute("user", "")
+	exists.SetExists(false)
+	_, err = s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("alice", "2"), *dynamodb.NewStringAttribute("kind", "view")), &dynamodb.WriteOptions{
+		Expected: []dynamodb.Attribute{*exists},
+	})
+	c.Check(dynamodb.IsConditionalCheckFailed(err), check.Equals, true)
+
+	_, err = s.table.DeleteItem(&dynamodb.Key{HashKey: "alice", RangeKey: "2"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(s.fake.Items("events"), check.HasLen, 4)
+
+	_, err = s.table.GetItem(&dynamodb.Key{HashKey: "alice"}, false)
+	c.Check(dynamodb.IsValidationError(err), check.Equals, true)
+}
+
+func (s *FakeSuite) TestConditionFailedItem(c *check.C) {
+	exists := dynamodb.NewStringAttribute("user", "")
+	exists.SetExists(false)
+	_, err := s.table.PutItemWithOptions(context.Background(), []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("user", "alice"),
+		*dynamodb.NewNumericAttribute("seq", "2"),
+	}, &dynamodb.WriteOptions{
+		Expected:                            []dynamodb.Attribute{*exists},
+		ReturnValuesOnConditionCheckFailure: dynamodb.RETURN_VALUES_ALL_OLD,
+	})
+	c.Assert(dynamodb.IsConditionalCheckFailed(err), check.Equals, true)
+	item := dynamodb.ConditionFailedItem(err)
+	c.Assert(item, check.NotNil)
+	c.Check(item["kind"].Value, check.Equals, "click")
+}
+
+func (s *FakeSuite) TestUpdate(c *check.C) {
+	key := &dynamodb.Key{HashKey: "bob", RangeKey: "10"}
+	n, err := s.table.Increment(key, "views", 2, false)
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, int64(2))
+	n, err = s.table.Increment(key, "views", -5, false)
+	c.Assert(err, check.IsNil)
+	c.Check(n, check.Equals, int64(-3))
+
+	result, err := s.table.UpdateItemWithOptions(context.Background(), key, nil, "", &dynamodb.WriteOptions{
+		UpdateExpression:          "SET #k = :k REMOVE views",
+		ConditionExpression:       "attribute_exists(views) AND views < :zero",
+		ExpressionAttributeNames:  map[string]string{"#k": "kind"},
+		ExpressionAttributeValues: []dynamodb.Attribute{*dynamodb.NewStringAttribute(":k", "view"), *dynamodb.NewNumericAttribute(":zero", "0")},
+		ReturnValues:              dynamodb.RETURN_VALUES_ALL_NEW,
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(result.Attributes["kind"].Value, check.Equals, "view")
+	c.Check(result.Attributes["views"], check.IsNil)
+}
+
+func (s *FakeSuite) TestQuery(c *check.C) {
+	conditions := []dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("user", "alice"),
+		*dynamodb.NewNumericAttributeComparison("seq", dynamodb.COMPARISON_GREATER_THAN_OR_EQUAL, 2),
+	}
+	items, _, err := s.table.QueryWithOptions(context.Background(), conditions, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(seqs(items), check.DeepEquals, []string{"2", "3", "4"})
+
+	var pages [][]string
+	var last *dynamodb.Key
+	for {
+		items, last, err = s.table.QueryWithOptions(context.Background(), conditions, &dynamodb.QueryOptions{
+			Limit:             2,
+			Descending:        true,
+			ExclusiveStartKey: last,
+			QueryFilter:       []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("kind", "click")},
+		})
+		c.Assert(err, check.IsNil)
+		pages = append(pages, seqs(items))
+		if last == nil {
+			break
+		}
+	}
+	c.Check(pages, check.DeepEquals, [][]string{{"3"}, {"2"}})
+
+	count, err := s.table.CountQuery(conditions, false)
+	c.Assert(err, check.IsNil)
+	c.Check(count, check.Equals, int64(3))
+
+	count, err = s.table.CountQueryConsistent(conditions, true, false)
+	c.Assert(err, check.IsNil)
+	c.Check(count, check.Equals, int64(3))
+
+	items, _, err = s.table.QueryWithOptions(context.Background(), []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("kind", "click")}, &dynamodb.QueryOptions{
+		IndexName: "kind-index",
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 3)
+
+	_, _, err = s.table.QueryWithOptions(context.Background(), []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("kind", "click")}, &dynamodb.QueryOptions{
+		IndexName:      "kind-index",
+		ConsistentRead: true,
+	})
+	c.Check(err, check.ErrorMatches, "ValidationException: Consistent reads are not supported on global secondary indexes")
+
+	// Index pages resume from the table and index keys of the last item.
+	clicks := []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("kind", "click")}
+	_, _, err = s.table.QueryWithOptions(context.Background(), clicks, &dynamodb.QueryOptions{
+		IndexName:         "kind-index",
+		ExclusiveStartKey: &dynamodb.Key{HashKey: "alice", RangeKey: "2"},
+	})
+	c.Check(err, check.ErrorMatches, "ValidationException: The provided starting key is invalid: .*")
+	var kinds []string
+	opts := &dynamodb.QueryOptions{IndexName: "kind-index", Limit: 2}
+	for {
+		items, last, err := s.table.QueryWithOptions(context.Background(), clicks, opts)
+		c.Assert(err, check.IsNil)
+		for _, item := range items {
+			kinds = append(kinds, item["user"].Value+"/"+item["seq"].Value)
+		}
+		if last == nil {
+			break
+		}
+		opts.ExclusiveStartKey = last
+	}
+	c.Check(kinds, check.HasLen, 3)
+}
+
+func (s *FakeSuite) TestQueryAllPages(c *check.C) {
+	conditions := []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("user", "alice")}
+	filter := []dynamodb.AttributeComparison{*dynamodb.NewStringAttributeComparison("kind", dynamodb.COMPARISON_NOT_EQUAL, "login")}
+
+	items, last, err := s.table.QueryAllPages(context.Background(), conditions, &dynamodb.QueryOptions{Limit: 2, QueryFilter: filter})
+	c.Assert(err, check.IsNil)
+	c.Check(seqs(items), check.DeepEquals, []string{"2", "3"})
+	c.Assert(last, check.NotNil)
+	c.Check(last.RangeKey, check.Equals, "3")
+
+	items, last, err = s.table.QueryAllPages(context.Background(), conditions, &dynamodb.QueryOptions{Limit: 5, QueryFilter: filter, ExclusiveStartKey: last})
+	c.Assert(err, check.IsNil)
+	c.Check(seqs(items), check.DeepEquals, []string{"4"})
+	c.Check(last, check.IsNil)
+
+	items, _, err = s.table.QueryAllPages(context.Background(), conditions, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 4)
+}
+
+func (s *FakeSuite) TestPageCounts(c *check.C) {
+	filter := []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("kind", "click")}
+	page, err := s.table.QueryPage(context.Background(), []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("user", "alice")}, &dynamodb.QueryOptions{QueryFilter: filter})
+	c.Assert(err, check.IsNil)
+	c.Check(page.Items, check.HasLen, 2)
+	c.Check(page.Count, check.Equals, int64(2))
+	c.Check(page.ScannedCount, check.Equals, int64(4))
+
+	page, err = s.table.ScanPage(context.Background(), &dynamodb.ScanOptions{ScanFilter: filter})
+	c.Assert(err, check.IsNil)
+	c.Check(page.Count, check.Equals, int64(3))
+	c.Check(page.ScannedCount, check.Equals, int64(5))
+	c.Check(page.LastEvaluatedKey, check.IsNil)
+}
+
+func (s *FakeSuite) TestScanCount(c *check.C) {
+	clicks := []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("kind", "click")}
+	count, err := s.table.ScanCount(clicks, false)
+	c.Assert(err, check.IsNil)
+	c.Check(count, check.Equals, int64(3))
+
+	count, err = s.table.ScanCountOnIndex(nil, "kind-index", false)
+	c.Assert(err, check.IsNil)
+	c.Check(count, check.Equals, int64(5))
+
+	// Pages of two items are followed to the end.
+	count, scanned, err := s.table.ScanCountWithOptions(context.Background(), &dynamodb.ScanOptions{ScanFilter: clicks, Limit: 2})
+	c.Assert(err, check.IsNil)
+	c.Check(count, check.Equals, int64(3))
+	c.Check(scanned, check.Equals, int64(5))
+
+	_, err = s.table.ScanCountOnIndex(nil, "missing-index", false)
+	c.Check(dynamodb.IsValidationError(err), check.Equals, true)
+}
+
+func (s *FakeSuite) TestScanWithOptions(c *check.C) {
+	items, last, err := s.table.ScanWithOptions(context.Background(), &dynamodb.ScanOptions{
+		ScanFilter: []dynamodb.AttributeComparison{
+			*dynamodb.NewEqualStringAttributeComparison("kind", "login"),
+			*dynamodb.NewEqualStringAttributeComparison("user", "bob"),
+		},
+		ConditionalOperator: dynamodb.CONDITIONAL_OPERATOR_OR,
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(last, check.IsNil)
+	c.Check(seqs(items), check.DeepEquals, []string{"1", "10"})
+
+	items, last, err = s.table.ScanWithOptions(context.Background(), &dynamodb.ScanOptions{Limit: 2})
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 2)
+	c.Check(last, check.NotNil)
+}
+
+func (s *FakeSuite) TestScanAndBatches(c *check.C) {
+	items, err := s.table.Scan([]dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("kind", "click")}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 3)
+
+	var total int
+	for segment := 0; segment < 3; segment++ {
+		items, err := s.table.ParallelScan(nil, segment, 3, false)
+		c.Assert(err, check.IsNil)
+		total += len(items)
+	}
+	c.Check(total, check.Equals, 5)
+
+	_, err = s.table.BatchWriteItems(map[string][][]dynamodb.Attribute{
+		"Put":    {{*dynamodb.NewStringAttribute("user", "carol"), *dynamodb.NewNumericAttribute("seq", "1")}},
+		"Delete": {{*dynamodb.NewStringAttribute("user", "bob"), *dynamodb.NewNumericAttribute("seq", "10")}},
+	}).Execute(false)
+	c.Assert(err, check.IsNil)
+
+	results, err := s.table.BatchGetItems([]dynamodb.Key{{HashKey: "carol", RangeKey: "1"}, {HashKey: "bob", RangeKey: "10"}}).Execute(false)
+	c.Assert(err, check.IsNil)
+	c.Check(results["events"], check.HasLen, 1)
+}
+
+func seqs(items []map[string]*dynamodb.Attribute) []string {
+	out := []string{}
+	for _, item := range items {
+		out = append(out, item["seq"].Value)
+	}
+	return out
+}
@@ -0,0 +1,370 @@
+package dynamodbtest
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/bluele/dynamodb"
+)
+
+// comparison is a KeyConditions, QueryFilter or ScanFilter entry.
+type comparison struct {
+	AttributeValueList []value
+	ComparisonOperator string
+}
+
+type expectedValue struct {
+	Exists             interface{} // true, false, "true" or "false"
+	Value              value
+	ComparisonOperator string
+	AttributeValueList []value
+}
+
+type attributeUpdate struct {
+	Action string
+	Value  value
+}
+
+type itemRequest struct {
+	TableName                 string
+	Key                       item
+	Item                      item
+	AttributesToGet           []string
+	ProjectionExpression      string
+	Expected                  map[string]expectedValue
+	ConditionalOperator       string
+	ConditionExpression       string
+	UpdateExpression          string
+	AttributeUpdates          map[string]attributeUpdate
+	ExpressionAttributeNames  map[string]string
+	ExpressionAttributeValues map[string]value
+	ReturnValues              string
+
+	ReturnValuesOnConditionCheckFailure string
+}
+
+func decode(body []byte, v interface{}) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return validationError("Invalid request: %s", err)
+	}
+	return nil
+}
+
+// evaluate applies a legacy comparison operator to a present value.
+func evaluate(operator string, v value, list []value) bool {
+	operand := func(i int) value {
+		if i < len(list) {
+			return list[i]
+		}
+		return nil
+	}
+	ordered := func(test func(int) bool) bool {
+		c, ok := compare(v, operand(0))
+		return ok && test(c)
+	}
+
+	switch operator {
+	case "EQ":
+		return operand(0) != nil && equal(v, operand(0))
+	case "NE":
+		return operand(0) != nil && !equal(v, operand(0))
+	case "LT":
+		return ordered(func(c int) bool { return c < 0 })
+	case "LE":
+		return ordered(func(c int) bool { return c <= 0 })
+	case "GT":
+		return ordered(func(c int) bool { return c > 0 })
+	case "GE":
+		return ordered(func(c int) bool { return c >= 0 })
+	case "BEGINS_WITH":
+		return operand(0) != nil && beginsWith(v, operand(0))
+	case "CONTAINS":
+		return operand(0) != nil && contains(v, operand(0))
+	case "NOT_CONTAINS":
+		return operand(0) != nil && !contains(v, operand(0))
+	case "IN":
+		for _, o := range list {
+			if o != nil && equal(v, o) {
+				return true
+			}
+		}
+		return false
+	case "BETWEEN":
+		low, okLow := compare(v, operand(0))
+		high, okHigh := compare(v, operand(1))
+		return okLow && okHigh && low >= 0 && high <= 0
+	case "NOT_NULL":
+		return true
+	}
+	return false
+}
+
+func validOperator(operator string) bool {
+	switch operator {
+	case "EQ", "NE", "LT", "LE", "GT", "GE", "BEGINS_WITH", "CONTAINS", "NOT_CONTAINS", "IN", "BETWEEN", "NULL", "NOT_NULL":
+		return true
+	}
+	return false
+}
+
+// matchComparisons evaluates legacy conditions, joined by operator.
+func matchComparisons(i item, comparisons map[string]comparison, operator string) (bool, error) {
+	or := strings.EqualFold(operator, "OR")
+	for name, c := range comparisons {
+		if !validOperator(c.ComparisonOperator) {
+			return false, validationError("Unsupported ComparisonOperator %q.", c.ComparisonOperator)
+		}
+		v, present := i[name]
+		var ok bool
+		switch {
+		case c.ComparisonOperator == "NULL":
+			ok = !present
+		case present:
+			ok = evaluate(c.ComparisonOperator, v, c.AttributeValueList)
+		}
+		if ok == or {
+			return ok, nil
+		}
+	}
+	return !or || len(comparisons) == 0, nil
+}
+
+func isFalse(v interface{}) bool {
+	return v == false || v == "false"
+}
+
+func isTrue(v interface{}) bool {
+	return v == true || v == "true"
+}
+
+// checkConditions returns a ConditionalCheckFailedException when the
+// Expected or ConditionExpression of the request fails on current, which
+// is empty when there is no such item yet.
+func checkConditions(r *itemRequest, current item) error {
+	if len(r.Expected) > 0 && r.ConditionExpression != "" {
+		return validationError("Can not use both expression and non-expression parameters in the same request.")
+	}
+
+	ok := true
+	if len(r.Expected) > 0 {
+		comparisons := make(map[string]comparison, len(r.Expected))
+		for name, e := range r.Expected {
+			switch {
+			case e.ComparisonOperator != "":
+				comparisons[name] = comparison{e.AttributeValueList, e.ComparisonOperator}
+			case isFalse(e.Exists):
+				comparisons[name] = comparison{nil, "NULL"}
+			case e.Value != nil:
+				comparisons[name] = comparison{[]value{e.Value}, "EQ"}
+			default:
+				comparisons[name] = comparison{nil, "NOT_NULL"}
+			}
+		}
+		var err error
+		if ok, err = matchComparisons(current, comparisons, r.ConditionalOperator); err != nil {
+			return err
+		}
+	}
+	if r.ConditionExpression != "" {
+		var err error
+		e := newExpression(r.ConditionExpression, r.ExpressionAttributeNames, r.ExpressionAttributeValues, current)
+		if ok, err = e.condition(); err != nil {
+			return err
+		}
+	}
+	if !ok {
+		if r.ReturnValuesOnConditionCheckFailure == "ALL_OLD" && len(current) > 0 {
+			body, _ := json.Marshal(map[string]interface{}{
+				"__type":  "com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException",
+				"message": "The conditional request failed",
+				"Item":    current,
+			})
+			return dynamodb.ParseError(400, body)
+		}
+		return newError("ConditionalCheckFailedException", "The conditional request failed")
+	}
+	return nil
+}
+
+// returnValues builds the response of a write from the item before and
+// after it, and the names of the attributes an update touched.
+func returnValues(r *itemRequest, old, new item, updated map[string]bool) (interface{}, error) {
+	response := map[string]interface{}{}
+	var attributes item
+	switch r.ReturnValues {
+	case "", "NONE":
+	case "ALL_OLD":
+		attributes = old
+	case "ALL_NEW":
+		attributes = new
+	case "UPDATED_OLD", "UPDATED_NEW":
+		from := new
+		if r.ReturnValues == "UPDATED_OLD" {
+			from = old
+		}
+		attributes = item{}
+		for name := range updated {
+			if v, ok := from[name]; ok {
+				attributes[name] = v
+			}
+		}
+	default:
+		return nil, validationError("Invalid ReturnValues %q.", r.ReturnValues)
+	}
+	if len(attributes) > 0 {
+		response["Attributes"] = attributes
+	}
+	return response, nil
+}
+
+func (r *itemRequest) projection() ([]string, error) {
+	if r.ProjectionExpression != "" {
+		return newExpression(r.ProjectionExpression, r.ExpressionAttributeNames, nil, nil).projection()
+	}
+	return r.AttributesToGet, nil
+}
+
+func (f *Fake) getItem(body []byte) (interface{}, error) {
+	var r itemRequest
+	if err := decode(body, &r); err != nil {
+		return nil, err
+	}
+	t, err := f.table(r.TableName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := t.keyOf(r.Key, true)
+	if err != nil {
+		return nil, err
+	}
+	names, err := r.projection()
+	if err != nil {
+		return nil, err
+	}
+
+	response := map[string]interface{}{}
+	if i, ok := t.items[key]; ok {
+		response["Item"] = project(i, names)
+	}
+	return response, nil
+}
+
+func (f *Fake) putItem(body []byte) (interface{}, error) {
+	var r itemRequest
+	if err := decode(body, &r); err != nil {
+		return nil, err
+	}
+	t, err := f.table(r.TableName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := t.keyOf(r.Item, false)
+	if err != nil {
+		return nil, err
+	}
+	if r.ReturnValues != "" && r.ReturnValues != "NONE" && r.ReturnValues != "ALL_OLD" {
+		return nil, validationError("ReturnValues can only be ALL_OLD or NONE.")
+	}
+
+	old := t.items[key]
+	if err := checkConditions(&r, old); err != nil {
+		return nil, err
+	}
+	t.items[key] = copyItem(r.Item)
+	return returnValues(&r, old, r.Item, nil)
+}
+
+func (f *Fake) deleteItem(body []byte) (interface{}, error) {
+	var r itemRequest
+	if err := decode(body, &r); err != nil {
+		return nil, err
+	}
+	t, err := f.table(r.TableName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := t.keyOf(r.Key, true)
+	if err != nil {
+		return nil, err
+	}
+	if r.ReturnValues != "" && r.ReturnValues != "NONE" && r.ReturnValues != "ALL_OLD" {
+		return nil, validationError("ReturnValues can only be ALL_OLD or NONE.")
+	}
+
+	old := t.items[key]
+	if err := checkConditions(&r, old); err != nil {
+		return nil, err
+	}
+	delete(t.items, key)
+	return returnValues(&r, old, nil, nil)
+}
+
+func (f *Fake) updateItem(body []byte) (interface{}, error) {
+	var r itemRequest
+	if err := decode(body, &r); err != nil {
+		return nil, err
+	}
+	t, err := f.table(r.TableName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := t.keyOf(r.Key, true)
+	if err != nil {
+		return nil, err
+	}
+	if r.UpdateExpression != "" && len(r.AttributeUpdates) > 0 {
+		return nil, validationError("Can not use both expression and non-expression parameters in the same request.")
+	}
+
+	old := t.items[key]
+	if err := checkConditions(&r, old); err != nil {
+		return nil, err
+	}
+
+	updated := copyItem(old)
+	for name, v := range r.Key {
+		updated[name] = v
+	}
+	names := map[string]bool{}
+	if r.UpdateExpression != "" {
+		e := newExpression(r.UpdateExpression, r.ExpressionAttributeNames, r.ExpressionAttributeValues, updated)
+		if err := e.update(); err != nil {
+			return nil, err
+		}
+		names = e.updated
+	}
+	for name, u := range r.AttributeUpdates {
+		names[name] = true
+		if _, isKey := r.Key[name]; isKey {
+			return nil, validationError("Cannot update attribute %s. This attribute is part of the key", name)
+		}
+		switch strings.ToUpper(u.Action) {
+		case "", "PUT":
+			updated[name] = u.Value
+		case "DELETE":
+			if u.Value == nil {
+				delete(updated, name)
+				continue
+			}
+			fallthrough
+		case "ADD":
+			v, err := applyUpdate(updated[name], strings.ToUpper(u.Action), u.Value)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil {
+				delete(updated, name)
+			} else {
+				updated[name] = v
+			}
+		default:
+			return nil, validationError("Invalid AttributeUpdates action %q.", u.Action)
+		}
+	}
+	if _, err := t.keyOf(updated, false); err != nil {
+		return nil, err
+	}
+
+	t.items[key] = updated
+	return returnValues(&r, old, updated, names)
+}
@@ -0,0 +1,213 @@
+package dynamodbtest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// docPath is a document path: an attribute name followed by map keys and
+// list indexes, e.g. profile.addresses[2].city.
+type docPath struct {
+	name     string
+	elements []pathElement
+}
+
+// pathElement is a map key, or a list index when key is empty.
+type pathElement struct {
+	key   string
+	index int
+}
+
+// parsePath parses a path token, substituting #placeholders.
+func parsePath(t string, names map[string]string) (docPath, error) {
+	var p docPath
+	if t == "" || strings.HasPrefix(t, ":") || strings.ContainsAny(t, "()") {
+		return p, validationError("Invalid document path %q.", t)
+	}
+	for i, segment := range strings.Split(t, ".") {
+		head, rest := segment, ""
+		if j := strings.IndexByte(segment, '['); j >= 0 {
+			head, rest = segment[:j], segment[j:]
+		}
+		if head == "" || strings.Contains(head, "]") {
+			return p, validationError("Invalid document path %q.", t)
+		}
+		if strings.HasPrefix(head, "#") {
+			name, ok := names[head]
+			if !ok {
+				return p, validationError("Undefined expression attribute name %s.", head)
+			}
+			head = name
+		}
+		if i == 0 {
+			p.name = head
+		} else {
+			p.elements = append(p.elements, pathElement{key: head})
+		}
+
+		for rest != "" {
+			end := strings.IndexByte(rest, ']')
+			if rest[0] != '[' || end < 0 {
+				return p, validationError("Invalid document path %q.", t)
+			}
+			n, err := strconv.Atoi(rest[1:end])
+			if err != nil || n < 0 {
+				return p, validationError("Invalid list index in document path %q.", t)
+			}
+			p.elements = append(p.elements, pathElement{index: n})
+			rest = rest[end+1:]
+		}
+	}
+	return p, nil
+}
+
+// get returns the value at p, nil when there is none.
+func (i item) get(p docPath) value {
+	v := i[p.name]
+	for _, e := range p.elements {
+		if v = v.child(e); v == nil {
+			return nil
+		}
+	}
+	return v
+}
+
+// set stores v at p. The map or list holding the last element of p must
+// exist; setting an index past the end of a list appends to it, as
+// Dynamodb does.
+func (i item) set(p docPath, v value) error {
+	if len(p.elements) == 0 {
+		i[p.name] = v
+		return nil
+	}
+	root := cloneValue(i[p.name])
+	parent := root.descendant(p.elements[:len(p.elements)-1])
+	last := p.elements[len(p.elements)-1]
+	switch {
+	case last.key != "" && parent.members() != nil:
+		parent.members()[last.key] = map[string]interface{}(v)
+	case last.key == "" && parent.elements() != nil:
+		if l := parent.elements(); last.index >= len(l) {
+			parent["L"] = append(l, map[string]interface{}(v))
+		} else {
+			l[last.index] = map[string]interface{}(v)
+		}
+	default:
+		return validationError("The document path provided in the update expression is invalid for update")
+	}
+	i[p.name] = root
+	return nil
+}
+
+// remove deletes the value at p, if any, shifting the following elements
+// of its list.
+func (i item) remove(p docPath) {
+	if len(p.elements) == 0 {
+		delete(i, p.name)
+		return
+	}
+	root := cloneValue(i[p.name])
+	parent := root.descendant(p.elements[:len(p.elements)-1])
+	last := p.elements[len(p.elements)-1]
+	switch {
+	case last.key != "" && parent.members() != nil:
+		delete(parent.members(), last.key)
+	case last.key == "" && last.index < len(parent.elements()):
+		l := parent.elements()
+		parent["L"] = append(l[:last.index:last.index], l[last.index+1:]...)
+	default:
+		return
+	}
+	i[p.name] = root
+}
+
+// removeAll removes paths as of the item before any removal, as a REMOVE
+// clause does: the last elements of a list go first so that the indexes
+// of the others do not shift.
+func (i item) removeAll(paths []docPath) {
+	sort.SliceStable(paths, func(a, b int) bool {
+		return lastIndex(paths[a]) > lastIndex(paths[b])
+	})
+	for _, p := range paths {
+		i.remove(p)
+	}
+}
+
+func lastIndex(p docPath) int {
+	if len(p.elements) == 0 || p.elements[len(p.elements)-1].key != "" {
+		return -1
+	}
+	return p.elements[len(p.elements)-1].index
+}
+
+// child returns the member or element of v designated by e, nil when there
+// is none.
+func (v value) child(e pathElement) value {
+	if e.key != "" {
+		return asValue(v.members()[e.key])
+	}
+	if l := v.elements(); e.index < len(l) {
+		return asValue(l[e.index])
+	}
+	return nil
+}
+
+// descendant walks elements from v, nil when a step is missing.
+func (v value) descendant(elements []pathElement) value {
+	for _, e := range elements {
+		if v = v.child(e); v == nil {
+			return nil
+		}
+	}
+	return v
+}
+
+func (v value) elements() []interface{} {
+	l, _ := v["L"].([]interface{})
+	return l
+}
+
+func (v value) members() map[string]interface{} {
+	m, _ := v["M"].(map[string]interface{})
+	return m
+}
+
+func asValue(x interface{}) value {
+	switch v := x.(type) {
+	case value:
+		return v
+	case map[string]interface{}:
+		return value(v)
+	}
+	return nil
+}
+
+// cloneValue copies the lists and maps of v, so that a nested update does
+// not modify the stored item in place.
+func cloneValue(v value) value {
+	if v == nil {
+		return nil
+	}
+	return value(cloneJSON(map[string]interface{}(v)).(map[string]interface{}))
+}
+
+func cloneJSON(x interface{}) interface{} {
+	switch v := x.(type) {
+	case value:
+		return cloneJSON(map[string]interface{}(v))
+	case map[string]interface{}:
+		clone := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			clone[k] = cloneJSON(e)
+		}
+		return clone
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for k, e := range v {
+			clone[k] = cloneJSON(e)
+		}
+		return clone
+	}
+	return x
+}
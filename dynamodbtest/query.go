@@ -0,0 +1,472 @@
+package dynamodbtest
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"math/big"
+	"sort"
+)
+
+// attributeType returns the declared type of a key attribute.
+func (t *table) attributeType(name string) string {
+	for _, a := range t.description.AttributeDefinitions {
+		if a.Name == name {
+			return a.Type
+		}
+	}
+	return ""
+}
+
+// keyValue returns the encoded value of the key attribute name of i.
+func (t *table) keyValue(i item, name string) (string, error) {
+	v, ok := i[name]
+	if !ok {
+		return "", validationError("One of the required keys was not given a value: %s", name)
+	}
+	typ, s, ok := v.scalar()
+	if !ok || typ != t.attributeType(name) {
+		return "", validationError("The provided key element %s does not match the schema", name)
+	}
+	if s == "" {
+		return "", validationError("One or more parameter values are not valid. The AttributeValue for a key attribute cannot contain an empty string value. Key: %s", name)
+	}
+	if typ == "N" {
+		// Dynamodb identifies items by the value of their numeric keys:
+		// "1" and "1.0" are the same item.
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return "", validationError("The parameter cannot be converted to a numeric value: %s", s)
+		}
+		s = r.RatString()
+	}
+	return typ + ":" + s, nil
+}
+
+// keyOf encodes the primary key of i. When exact, i may hold nothing but
+// the key attributes, as for the Key of a request.
+func (t *table) keyOf(i item, exact bool) (string, error) {
+	key, err := t.keyValue(i, t.schema.hash)
+	if err != nil {
+		return "", err
+	}
+	n := 1
+	if t.schema.rangeKey != "" {
+		r, err := t.keyValue(i, t.schema.rangeKey)
+		if err != nil {
+			return "", err
+		}
+		key += "\x00" + r
+		n++
+	}
+	if exact && len(i) != n {
+		return "", validationError("The provided key element does not match the schema")
+	}
+	return key, nil
+}
+
+// keyAttributes returns the attributes of i which are keys of the table
+// or of schema.
+func (t *table) keyAttributes(i item, schema keySchema) item {
+	out := item{}
+	for _, name := range []string{t.schema.hash, t.schema.rangeKey, schema.hash, schema.rangeKey} {
+		if v, ok := i[name]; ok && name != "" {
+			out[name] = v
+		}
+	}
+	return out
+}
+
+type readRequest struct {
+	TableName                 string
+	IndexName                 string
+	KeyConditions             map[string]comparison
+	KeyConditionExpression    string
+	QueryFilter               map[string]comparison
+	ScanFilter                map[string]comparison
+	ConditionalOperator       string
+	FilterExpression          string
+	ProjectionExpression      string
+	AttributesToGet           []string
+	ExpressionAttributeNames  map[string]string
+	ExpressionAttributeValues map[string]value
+	Select                    string
+	Limit                     int
+	ExclusiveStartKey         item
+	ScanIndexForward          interface{} // a bool, or the strings "true" and "false"
+	ConsistentRead            interface{} // likewise
+	Segment                   int
+	TotalSegments             int
+	ReturnConsumedCapacity    string
+}
+
+// entry is an item along with its encoded primary key.
+type entry struct {
+	key  string
+	item item
+}
+
+// entries returns the items in schema, those having its key attributes,
+// ordered by range key then primary key.
+func (t *table) entries(schema keySchema) []entry {
+	var entries []entry
+	for key, i := range t.items {
+		if _, ok := i[schema.hash]; !ok {
+			continue
+		}
+		if _, ok := i[schema.rangeKey]; schema.rangeKey != "" && !ok {
+			continue
+		}
+		entries = append(entries, entry{key, i})
+	}
+	sort.Slice(entries, func(a, b int) bool {
+		return t.less(schema, entries[a], entries[b])
+	})
+	return entries
+}
+
+func (t *table) less(schema keySchema, a, b entry) bool {
+	if schema.rangeKey != "" {
+		if c, ok := compare(a.item[schema.rangeKey], b.item[schema.rangeKey]); ok && c != 0 {
+			return c < 0
+		}
+	}
+	return a.key < b.key
+}
+
+// schemaFor returns the key schema of the table or of the index of r.
+func (t *table) schemaFor(r *readRequest) (keySchema, error) {
+	if r.IndexName == "" {
+		return t.schema, nil
+	}
+	schema, ok := t.indexes[r.IndexName]
+	if !ok {
+		return keySchema{}, validationError("The table does not have the specified index: %s", r.IndexName)
+	}
+	if isTrue(r.ConsistentRead) && t.description.GlobalSecondaryIndex(r.IndexName) != nil {
+		return keySchema{}, validationError("Consistent reads are not supported on global secondary indexes")
+	}
+	return schema, nil
+}
+
+// bounds returns the positions, in the ascending entries, of the first
+// entry not before and of the first entry after the exclusive start key
+// of r.
+func (t *table) bounds(r *readRequest, schema keySchema, entries []entry) (int, int, error) {
+	if r.ExclusiveStartKey == nil {
+		return len(entries), 0, nil
+	}
+	key, err := t.keyOf(r.ExclusiveStartKey, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	// Like Dynamodb, require the index key of the last item too: it is
+	// where the index resumes, the item may have changed since.
+	if len(r.ExclusiveStartKey) != len(t.keyAttributes(r.ExclusiveStartKey, schema)) {
+		return 0, 0, validationError("The provided starting key is invalid: The provided key element does not match the schema")
+	}
+	for _, name := range []string{schema.hash, schema.rangeKey} {
+		if _, ok := r.ExclusiveStartKey[name]; name != "" && !ok {
+			return 0, 0, validationError("The provided starting key is invalid: The provided key element does not match the schema")
+		}
+	}
+	last := entry{key, r.ExclusiveStartKey}
+	before := sort.Search(len(entries), func(i int) bool {
+		return !t.less(schema, entries[i], last)
+	})
+	after := sort.Search(len(entries), func(i int) bool {
+		return t.less(schema, last, entries[i])
+	})
+	return before, after, nil
+}
+
+// read runs a Query or Scan on the entries matching keys.
+func (f *Fake) read(r *readRequest, keys func(item) (bool, error), filter map[string]comparison) (interface{}, error) {
+	t, err := f.table(r.TableName)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := t.schemaFor(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(filter) > 0 && r.FilterExpression != "" {
+		return nil, validationError("Can not use both expression and non-expression parameters in the same request.")
+	}
+	var names []string
+	if r.ProjectionExpression != "" {
+		if names, err = newExpression(r.ProjectionExpression, r.ExpressionAttributeNames, nil, nil).projection(); err != nil {
+			return nil, err
+		}
+	} else {
+		names = r.AttributesToGet
+	}
+
+	var candidates []entry
+	for _, e := range t.entries(schema) {
+		ok, err := keys(e.item)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			candidates = append(candidates, e)
+		}
+	}
+	before, after, err := t.bounds(r, schema, candidates)
+	if err != nil {
+		return nil, err
+	}
+	if isFalse(r.ScanIndexForward) {
+		candidates = candidates[:before]
+		for i, j := 0, len(candidates)-1; i < j; i, j = i+1, j-1 {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		}
+	} else {
+		candidates = candidates[after:]
+	}
+
+	response := map[string]interface{}{}
+	if r.Limit > 0 && len(candidates) > r.Limit {
+		candidates = candidates[:r.Limit]
+		response["LastEvaluatedKey"] = t.keyAttributes(candidates[len(candidates)-1].item, schema)
+	}
+
+	items := []item{}
+	for _, e := range candidates {
+		ok, err := matchComparisons(e.item, filter, r.ConditionalOperator)
+		if err == nil && ok && r.FilterExpression != "" {
+			ok, err = newExpression(r.FilterExpression, r.ExpressionAttributeNames, r.ExpressionAttributeValues, e.item).condition()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			items = append(items, project(e.item, names))
+		}
+	}
+
+	response["Count"] = len(items)
+	response["ScannedCount"] = len(candidates)
+	if r.ReturnConsumedCapacity == "TOTAL" || r.ReturnConsumedCapacity == "INDEXES" {
+		response["ConsumedCapacity"] = map[string]interface{}{
+			"TableName":     r.TableName,
+			"CapacityUnits": readCapacity(candidates, isTrue(r.ConsistentRead)),
+		}
+	}
+	if r.Select != "COUNT" {
+		response["Items"] = items
+	}
+	return response, nil
+}
+
+// readCapacity returns the read capacity units Dynamodb charges for
+// reading entries: half a unit per 4KB, a whole one when consistent. The
+// size of an item is approximated by its JSON encoding.
+func readCapacity(entries []entry, consistent bool) float64 {
+	size := 0
+	for _, e := range entries {
+		b, _ := json.Marshal(e.item)
+		size += len(b)
+	}
+	units := float64((size + 4095) / 4096)
+	if units == 0 {
+		units = 1
+	}
+	if !consistent {
+		units /= 2
+	}
+	return units
+}
+
+func (f *Fake) query(body []byte) (interface{}, error) {
+	var r readRequest
+	if err := decode(body, &r); err != nil {
+		return nil, err
+	}
+	if len(r.ScanFilter) > 0 {
+		return nil, validationError("ScanFilter is not valid for Query.")
+	}
+	if len(r.KeyConditions) > 0 && r.KeyConditionExpression != "" {
+		return nil, validationError("Can not use both expression and non-expression parameters in the same request.")
+	}
+
+	if r.KeyConditionExpression != "" {
+		return f.read(&r, func(i item) (bool, error) {
+			return newExpression(r.KeyConditionExpression, r.ExpressionAttributeNames, r.ExpressionAttributeValues, i).condition()
+		}, r.QueryFilter)
+	}
+
+	if t, ok := f.tables[r.TableName]; ok {
+		schema, err := t.schemaFor(&r)
+		if err != nil {
+			return nil, err
+		}
+		if c, ok := r.KeyConditions[schema.hash]; !ok || c.ComparisonOperator != "EQ" {
+			return nil, validationError("Query condition missed key schema element: %s", schema.hash)
+		}
+		for name := range r.KeyConditions {
+			if name != schema.hash && name != schema.rangeKey {
+				return nil, validationError("Query condition on %s, which is not a key attribute.", name)
+			}
+		}
+	}
+	return f.read(&r, func(i item) (bool, error) {
+		return matchComparisons(i, r.KeyConditions, "AND")
+	}, r.QueryFilter)
+}
+
+func (f *Fake) scan(body []byte) (interface{}, error) {
+	var r readRequest
+	if err := decode(body, &r); err != nil {
+		return nil, err
+	}
+	if len(r.KeyConditions) > 0 || r.KeyConditionExpression != "" || len(r.QueryFilter) > 0 {
+		return nil, validationError("Key conditions are not valid for Scan.")
+	}
+	if r.TotalSegments > 0 && (r.Segment < 0 || r.Segment >= r.TotalSegments) {
+		return nil, validationError("Segment must be less than TotalSegments.")
+	}
+
+	var hashKey string
+	if t, ok := f.tables[r.TableName]; ok {
+		hashKey = t.schema.hash
+	}
+	return f.read(&r, func(i item) (bool, error) {
+		if r.TotalSegments <= 1 {
+			return true, nil
+		}
+		_, s, _ := i[hashKey].scalar()
+		h := fnv.New32a()
+		h.Write([]byte(s))
+		return int(h.Sum32()%uint32(r.TotalSegments)) == r.Segment, nil
+	}, r.ScanFilter)
+}
+
+type batchGetRequest struct {
+	RequestItems map[string]struct {
+		Keys                     []item
+		AttributesToGet          []string
+		ProjectionExpression     string
+		ExpressionAttributeNames map[string]string
+	}
+}
+
+func (f *Fake) batchGetItem(body []byte) (interface{}, error) {
+	var r batchGetRequest
+	if err := decode(body, &r); err != nil {
+		return nil, err
+	}
+
+	responses := map[string][]item{}
+	n := 0
+	for name, request := range r.RequestItems {
+		t, err := f.table(name)
+		if err != nil {
+			return nil, err
+		}
+		names := request.AttributesToGet
+		if request.ProjectionExpression != "" {
+			if names, err = newExpression(request.ProjectionExpression, request.ExpressionAttributeNames, nil, nil).projection(); err != nil {
+				return nil, err
+			}
+		}
+		responses[name] = []item{}
+		for _, k := range request.Keys {
+			key, err := t.keyOf(k, true)
+			if err != nil {
+				return nil, err
+			}
+			if i, ok := t.items[key]; ok {
+				responses[name] = append(responses[name], project(i, names))
+			}
+			n++
+		}
+	}
+	if n > 100 {
+		return nil, validationError("Too many items requested for the BatchGetItem call")
+	}
+	return map[string]interface{}{"Responses": responses, "UnprocessedKeys": map[string]interface{}{}}, nil
+}
+
+type batchWriteRequest struct {
+	RequestItems map[string][]struct {
+		PutRequest *struct {
+			Item item
+		}
+		DeleteRequest *struct {
+			Key item
+		}
+	}
+}
+
+func (f *Fake) batchWriteItem(body []byte) (interface{}, error) {
+	var r batchWriteRequest
+	if err := decode(body, &r); err != nil {
+		return nil, err
+	}
+
+	// Validate every request first: a batch is applied entirely or not at
+	// all.
+	type write struct {
+		table *table
+		key   string
+		item  item // nil for deletes
+	}
+	var writes []write
+	seen := map[string]bool{}
+	for name, requests := range r.RequestItems {
+		t, err := f.table(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, request := range requests {
+			var w write
+			switch {
+			case request.PutRequest != nil:
+				w.key, err = t.keyOf(request.PutRequest.Item, false)
+				w.item = copyItem(request.PutRequest.Item)
+			case request.DeleteRequest != nil:
+				w.key, err = t.keyOf(request.DeleteRequest.Key, true)
+			default:
+				err = validationError("A write request must have a PutRequest or a DeleteRequest.")
+			}
+			if err != nil {
+				return nil, err
+			}
+			if seen[name+"\x00"+w.key] {
+				return nil, validationError("Provided list of item keys contains duplicates")
+			}
+			seen[name+"\x00"+w.key] = true
+			w.table = t
+			writes = append(writes, w)
+		}
+	}
+	if len(writes) > 25 {
+		return nil, validationError("Too many items requested for the BatchWriteItem call")
+	}
+
+	for _, w := range writes {
+		if w.item == nil {
+			delete(w.table.items, w.key)
+		} else {
+			w.table.items[w.key] = w.item
+		}
+	}
+	return map[string]interface{}{"UnprocessedItems": map[string]interface{}{}}, nil
+}
+
+// Items returns a copy of every item of the table, sorted by primary key,
+// so that tests can check what was written.
+func (f *Fake) Items(tableName string) []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, ok := f.tables[tableName]
+	if !ok {
+		return nil
+	}
+	var items []map[string]interface{}
+	for _, e := range t.entries(keySchema{hash: t.schema.hash}) {
+		items = append(items, normalize(e.item).(map[string]interface{}))
+	}
+	return items
+}
@@ -0,0 +1,244 @@
+package dynamodbtest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Recorder modes.
+const (
+	// Requests are sent and their responses captured, to be written by
+	// Save.
+	MODE_RECORD = "record"
+	// Requests are answered from the fixture file and never sent.
+	MODE_REPLAY = "replay"
+)
+
+// Interaction is one recorded request and its response. Only the target
+// and body of requests are kept: signatures, credentials and session
+// tokens never reach the fixture file.
+type Interaction struct {
+	Target     string
+	Request    json.RawMessage
+	StatusCode int
+	Header     http.Header `json:",omitempty"`
+	Response   json.RawMessage
+}
+
+// Recorder is an http.RoundTripper capturing the traffic of a Server to a
+// fixture file, or replaying it, for deterministic integration tests:
+//
+//	recorder, err := dynamodbtest.NewRecorder("testdata/users.json", mode)
+//	server.HTTPClient = recorder.Client()
+//	...
+//	recorder.Save() // when recording
+//
+// Replayed requests are matched on their target and JSON body, less any
+// ClientRequestToken, each interaction being used once in the recorded
+// order. A Recorder is safe for concurrent use, though replaying
+// concurrent calls is only deterministic when they send different
+// requests.
+type Recorder struct {
+	Mode string
+	Path string
+	// Transport sends the requests while recording, http.DefaultTransport
+	// when nil.
+	Transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder returns a Recorder, reading the fixture file at path when
+// replaying.
+func NewRecorder(path string, mode string) (*Recorder, error) {
+	r := &Recorder{Mode: mode, Path: path}
+	switch mode {
+	case MODE_RECORD:
+	case MODE_REPLAY:
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &r.interactions); err != nil {
+			return nil, fmt.Errorf("Invalid fixture file %s: %v", path, err)
+		}
+		r.used = make([]bool, len(r.interactions))
+	default:
+		return nil, fmt.Errorf("Unknown recorder mode %q.", mode)
+	}
+	return r, nil
+}
+
+// Client returns an http.Client using the Recorder, to be set as the
+// Server's HTTPClient.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	target := req.Header.Get("X-Amz-Target")
+
+	if r.Mode == MODE_REPLAY {
+		return r.replay(req, target, body)
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	forwarded := req.Clone(req.Context())
+	forwarded.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := transport.RoundTrip(forwarded)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := responseBody
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		// Fixtures hold plain JSON; replayed responses are not encoded.
+		if recorded, err = gunzip(responseBody); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Target:     target,
+		Request:    rawJSON(body),
+		StatusCode: resp.StatusCode,
+		Header:     recordedHeader(resp.Header),
+		Response:   rawJSON(recorded),
+	})
+	r.mu.Unlock()
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, target string, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	want := canonicalJSON(body)
+	for i, interaction := range r.interactions {
+		if r.used[i] || interaction.Target != target || canonicalJSON(interaction.Request) != want {
+			continue
+		}
+		r.used[i] = true
+		header := http.Header{}
+		for k, v := range interaction.Header {
+			header[k] = v
+		}
+		return &http.Response{
+			Status:        strconv.Itoa(interaction.StatusCode) + " " + http.StatusText(interaction.StatusCode),
+			StatusCode:    interaction.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(interaction.Response)),
+			ContentLength: int64(len(interaction.Response)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("No recorded interaction left for %s %s.", target, body)
+}
+
+// Unused returns the recorded interactions a replay did not use.
+func (r *Recorder) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []Interaction
+	for i, used := range r.used {
+		if !used {
+			unused = append(unused, r.interactions[i])
+		}
+	}
+	return unused
+}
+
+// Save writes the recorded interactions to the fixture file. It does
+// nothing when replaying.
+func (r *Recorder) Save() error {
+	if r.Mode != MODE_RECORD {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.Path, append(data, '\n'), 0644)
+}
+
+// recordedHeader keeps the response headers worth replaying. X-Amz-Crc32
+// is dropped: it covers the body as sent, which fixtures store decoded and
+// which may be edited by hand.
+func recordedHeader(h http.Header) http.Header {
+	out := http.Header{}
+	for _, name := range []string{"Content-Type", "X-Amzn-Requestid", "Retry-After"} {
+		if v, ok := h[name]; ok {
+			out[name] = v
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(zr)
+}
+
+// rawJSON keeps valid JSON as is, and anything else as a JSON string.
+func rawJSON(data []byte) json.RawMessage {
+	if json.Valid(data) {
+		return json.RawMessage(data)
+	}
+	s, _ := json.Marshal(string(data))
+	return json.RawMessage(s)
+}
+
+// canonicalJSON re-encodes data so that formatting and key order do not
+// matter when matching requests. ClientRequestToken is dropped: the
+// Server generates a new one for every transaction not given one.
+func canonicalJSON(data []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return string(data)
+	}
+	if fields, ok := v.(map[string]interface{}); ok {
+		delete(fields, "ClientRequestToken")
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
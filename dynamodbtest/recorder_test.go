@@ -0,0 +1,100 @@
+package dynamodbtest_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type RecorderSuite struct{}
+
+var _ = check.Suite(&RecorderSuite{})
+
+func newRecordedServer(endpoint string, recorder *dynamodbtest.Recorder) *dynamodb.Table {
+	auth := aws.Auth{AccessKey: "AKIDSECRETACCESS", SecretKey: "SECRETKEY"}
+	server := dynamodb.New(auth, aws.Region{DynamoDBEndpoint: endpoint})
+	server.Logger = dynamodb.NopLogger
+	server.HTTPClient = recorder.Client()
+	return server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+}
+
+func (s *RecorderSuite) TestRecordAndReplay(c *check.C) {
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if strings.Contains(string(body), "missing") {
+			w.WriteHeader(400)
+			w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"gone"}`))
+			return
+		}
+		w.Write([]byte(`{"Item":{"id":{"S":"u1"},"name":{"S":"Alice"}}}`))
+	}))
+	defer live.Close()
+	path := filepath.Join(c.MkDir(), "fixture.json")
+
+	recorder, err := dynamodbtest.NewRecorder(path, dynamodbtest.MODE_RECORD)
+	c.Assert(err, check.IsNil)
+	table := newRecordedServer(live.URL, recorder)
+	_, err = table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Assert(err, check.IsNil)
+	_, err = table.GetItem(&dynamodb.Key{HashKey: "missing"}, false)
+	c.Assert(dynamodb.IsNotFound(err), check.Equals, true)
+	c.Assert(recorder.Save(), check.IsNil)
+
+	fixture, err := ioutil.ReadFile(path)
+	c.Assert(err, check.IsNil)
+	c.Check(strings.Contains(string(fixture), "AKIDSECRETACCESS"), check.Equals, false)
+	c.Check(strings.Contains(string(fixture), "Signature"), check.Equals, false)
+
+	recorder, err = dynamodbtest.NewRecorder(path, dynamodbtest.MODE_REPLAY)
+	c.Assert(err, check.IsNil)
+	table = newRecordedServer("http://127.0.0.1:1", recorder)
+	item, err := table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(item["name"].Value, check.Equals, "Alice")
+	c.Check(recorder.Unused(), check.HasLen, 1)
+
+	_, err = table.GetItem(&dynamodb.Key{HashKey: "missing"}, false)
+	c.Check(dynamodb.IsNotFound(err), check.Equals, true)
+	c.Check(recorder.Unused(), check.HasLen, 0)
+
+	_, err = table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Check(err, check.ErrorMatches, ".*No recorded interaction left for DynamoDB_20120810.GetItem.*")
+}
+
+func (s *RecorderSuite) TestReplayTransaction(c *check.C) {
+	var tokens []string
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ ClientRequestToken string }
+		c.Check(json.NewDecoder(r.Body).Decode(&body), check.IsNil)
+		tokens = append(tokens, body.ClientRequestToken)
+		w.Write([]byte(`{}`))
+	}))
+	defer live.Close()
+	path := filepath.Join(c.MkDir(), "fixture.json")
+	payload := []byte(`{"TransactItems":[{"Put":{"TableName":"users","Item":{"id":{"S":"u1"}}}}]}`)
+
+	recorder, err := dynamodbtest.NewRecorder(path, dynamodbtest.MODE_RECORD)
+	c.Assert(err, check.IsNil)
+	server := newRecordedServer(live.URL, recorder).Server
+	_, err = server.Do(dynamodb.OPERATION_TRANSACT_WRITE_ITEMS, payload)
+	c.Assert(err, check.IsNil)
+	c.Assert(recorder.Save(), check.IsNil)
+	c.Assert(tokens, check.HasLen, 1)
+	c.Check(tokens[0], check.Not(check.Equals), "")
+
+	// The replayed call generates a token of its own.
+	recorder, err = dynamodbtest.NewRecorder(path, dynamodbtest.MODE_REPLAY)
+	c.Assert(err, check.IsNil)
+	server = newRecordedServer("http://127.0.0.1:1", recorder).Server
+	_, err = server.Do(dynamodb.OPERATION_TRANSACT_WRITE_ITEMS, payload)
+	c.Assert(err, check.IsNil)
+	c.Check(recorder.Unused(), check.HasLen, 0)
+}
@@ -0,0 +1,264 @@
+package dynamodbtest
+
+import (
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// value is an attribute value in the wire format, e.g. {"S": "abc"}.
+type value map[string]interface{}
+
+// item maps attribute names to their values.
+type item map[string]value
+
+func (v value) typ() string {
+	for t := range v {
+		return t
+	}
+	return ""
+}
+
+// scalar returns the type and the string form of S, N and B values.
+func (v value) scalar() (string, string, bool) {
+	t := v.typ()
+	switch t {
+	case "S", "N", "B":
+		s, ok := v[t].(string)
+		return t, s, ok
+	}
+	return t, "", false
+}
+
+// set returns the type and members of SS, NS and BS values.
+func (v value) set() (string, []string, bool) {
+	t := v.typ()
+	switch t {
+	case "SS", "NS", "BS":
+	default:
+		return t, nil, false
+	}
+	var members []string
+	switch s := v[t].(type) {
+	case []string:
+		members = s
+	case []interface{}:
+		for _, m := range s {
+			str, ok := m.(string)
+			if !ok {
+				return t, nil, false
+			}
+			members = append(members, str)
+		}
+	}
+	return t, members, true
+}
+
+func newSet(t string, members []string) value {
+	return value{t: members}
+}
+
+// compare orders two scalar values of the same type, numbers numerically.
+func compare(a, b value) (int, bool) {
+	ta, sa, ok := a.scalar()
+	if !ok {
+		return 0, false
+	}
+	tb, sb, ok := b.scalar()
+	if !ok || ta != tb {
+		return 0, false
+	}
+	if ta == "N" {
+		return compareNumbers(sa, sb)
+	}
+	return strings.Compare(sa, sb), true
+}
+
+func compareNumbers(a, b string) (int, bool) {
+	fa, _, err := big.ParseFloat(a, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return 0, false
+	}
+	fb, _, err := big.ParseFloat(b, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return 0, false
+	}
+	return fa.Cmp(fb), true
+}
+
+func equal(a, b value) bool {
+	if c, ok := compare(a, b); ok {
+		return c == 0
+	}
+	ta, ma, ok := a.set()
+	if ok {
+		tb, mb, ok := b.set()
+		return ok && ta == tb && sameMembers(ta, ma, mb)
+	}
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func sameMembers(t string, a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, m := range a {
+		if indexOf(t, b, m) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// indexOf finds member in the members of a set of type t.
+func indexOf(t string, members []string, member string) int {
+	for i, m := range members {
+		if m == member {
+			return i
+		}
+		if t == "NS" {
+			if c, ok := compareNumbers(m, member); ok && c == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// normalize turns typed slices and maps into their generic JSON forms so
+// that values decoded and built by the fake compare equal.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case value:
+		return normalize(map[string]interface{}(v))
+	case item:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			out[k] = normalize(e)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			out[k] = normalize(e)
+		}
+		return out
+	case []string:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = e
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = normalize(e)
+		}
+		return out
+	}
+	return v
+}
+
+// contains implements the CONTAINS operator and function: a substring of
+// a string, or a member of a set or list.
+func contains(v, operand value) bool {
+	if t, s, ok := v.scalar(); ok {
+		ot, os, ok := operand.scalar()
+		return ok && (t == "S" || t == "B") && ot == t && strings.Contains(s, os)
+	}
+	if t, members, ok := v.set(); ok {
+		_, os, ok := operand.scalar()
+		return ok && indexOf(t, members, os) >= 0
+	}
+	if list, ok := v["L"].([]interface{}); ok {
+		for _, e := range list {
+			if m, ok := e.(map[string]interface{}); ok && equal(value(m), operand) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func beginsWith(v, prefix value) bool {
+	t, s, ok := v.scalar()
+	if !ok || t == "N" {
+		return false
+	}
+	pt, p, ok := prefix.scalar()
+	return ok && pt == t && strings.HasPrefix(s, p)
+}
+
+// copyItem returns a copy safe from later changes to the stored item.
+func copyItem(i item) item {
+	out := make(item, len(i))
+	for k, v := range i {
+		out[k] = v
+	}
+	return out
+}
+
+func project(i item, names []string) item {
+	if len(names) == 0 {
+		return copyItem(i)
+	}
+	out := make(item, len(names))
+	for _, name := range names {
+		if v, ok := i[name]; ok {
+			out[name] = v
+		}
+	}
+	return out
+}
+
+// addNumbers returns a + b for the N values, as Dynamodb would print it.
+func addNumbers(a, b string, negate bool) (string, bool) {
+	fa, _, err := big.ParseFloat(a, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return "", false
+	}
+	fb, _, err := big.ParseFloat(b, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return "", false
+	}
+	if negate {
+		fb.Neg(fb)
+	}
+	sum := new(big.Float).SetPrec(256).Add(fa, fb)
+	if sum.IsInt() {
+		i, _ := sum.Int(nil)
+		return i.String(), true
+	}
+	return strings.TrimRight(strings.TrimRight(sum.Text('f', 38), "0"), "."), true
+}
+
+// union and difference implement ADD and DELETE on sets.
+func union(t string, a, b []string) []string {
+	out := append([]string{}, a...)
+	for _, m := range b {
+		if indexOf(t, out, m) < 0 {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func difference(t string, a, b []string) []string {
+	out := []string{}
+	for _, m := range a {
+		if indexOf(t, b, m) < 0 {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func sortedNames(i item) []string {
+	names := make([]string, 0, len(i))
+	for name := range i {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
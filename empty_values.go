@@ -0,0 +1,52 @@
+package dynamodb
+
+import "reflect"
+
+// EmptyValuePolicy decides how MarshalAttributesWithOptions stores the
+// fields holding empty values.
+type EmptyValuePolicy int
+
+const (
+	// EmptyOmit leaves the attribute out of the item, the default.
+	EmptyOmit EmptyValuePolicy = iota
+	// EmptyNull stores a NULL value.
+	EmptyNull
+	// EmptyError fails marshaling with a *ValidationError.
+	EmptyError
+	// EmptyKeep stores the empty value as is. Dynamodb accepts empty
+	// strings and binary values outside of keys, but no empty set.
+	EmptyKeep
+)
+
+// MarshalOptions tunes MarshalAttributesWithOptions.
+type MarshalOptions struct {
+	// EmptyStrings applies to empty strings and byte slices.
+	EmptyStrings EmptyValuePolicy
+	// EmptySets applies to empty slices, arrays and maps, which would
+	// marshal as sets or JSON. EmptyKeep is not supported.
+	EmptySets EmptyValuePolicy
+}
+
+// emptyAttribute applies the policy to v, the empty value of f; a nil
+// attribute omits it. Nil pointers and interfaces are always omitted, as
+// are the fields tagged omitempty before the policy applies.
+func (opts *MarshalOptions) emptyAttribute(f *field, v reflect.Value) (*Attribute, error) {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		return nil, nil
+	}
+	policy := opts.EmptySets
+	if v.Kind() == reflect.String || v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		policy = opts.EmptyStrings
+	}
+
+	name := f.writeName()
+	switch policy {
+	case EmptyNull:
+		return NewNullAttribute(name), nil
+	case EmptyError:
+		return nil, validationErrorf("Attribute %s is empty.", name)
+	case EmptyKeep:
+		return NewStringAttribute(name, ""), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,63 @@
+package dynamodb_test
+
+import (
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type EmptyValuesSuite struct{}
+
+var _ = check.Suite(&EmptyValuesSuite{})
+
+type emptyRecord struct {
+	ID    string   `dynamodb:"id"`
+	Note  string   `dynamodb:"note"`
+	Blob  []byte   `dynamodb:"blob"`
+	Tags  []string `dynamodb:"tags"`
+	Count int      `dynamodb:"count"`
+	Memo  string   `dynamodb:"memo,omitempty"`
+	Ref   *string  `dynamodb:"ref"`
+}
+
+func (s *EmptyValuesSuite) TestPolicies(c *check.C) {
+	r := &emptyRecord{ID: "r1"}
+
+	// Empty values are omitted by default; zero numbers are kept.
+	attrs, err := dynamodb.MarshalAttributes(r)
+	c.Assert(err, check.IsNil)
+	c.Check(attrs, check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", "r1"),
+		*dynamodb.NewNumericAttribute("count", "0"),
+	})
+
+	attrs, err = dynamodb.MarshalAttributesWithOptions(r, &dynamodb.MarshalOptions{
+		EmptyStrings: dynamodb.EmptyKeep,
+		EmptySets:    dynamodb.EmptyNull,
+	})
+	c.Assert(err, check.IsNil)
+	c.Check(attrs, check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", "r1"),
+		*dynamodb.NewStringAttribute("note", ""),
+		*dynamodb.NewStringAttribute("blob", ""),
+		*dynamodb.NewNullAttribute("tags"),
+		*dynamodb.NewNumericAttribute("count", "0"),
+	})
+
+	_, err = dynamodb.MarshalAttributesWithOptions(r, &dynamodb.MarshalOptions{EmptyStrings: dynamodb.EmptyError})
+	c.Check(err, check.ErrorMatches, "Attribute note is empty.")
+	c.Check(dynamodb.IsValidationError(err), check.Equals, true)
+
+	_, err = dynamodb.MarshalAttributesWithOptions(r, &dynamodb.MarshalOptions{EmptySets: dynamodb.EmptyKeep})
+	c.Check(err, check.NotNil)
+}
+
+func (s *EmptyValuesSuite) TestUnmarshalNull(c *check.C) {
+	item := map[string]*dynamodb.Attribute{
+		"note":  dynamodb.NewNullAttribute("note"),
+		"tags":  dynamodb.NewNullAttribute("tags"),
+		"count": dynamodb.NewNullAttribute("count"),
+	}
+	r := &emptyRecord{Note: "old", Tags: []string{"a"}, Count: 3}
+	c.Assert(dynamodb.UnmarshalAttributes(&item, r), check.IsNil)
+	c.Check(r, check.DeepEquals, &emptyRecord{})
+}
@@ -0,0 +1,366 @@
+package dynamodb
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+)
+
+// ErrInvalidSignature is returned when reading an encrypted item whose
+// signed attributes were modified, removed or moved from another item.
+var ErrInvalidSignature = errors.New("Invalid item signature")
+
+// Names of the attributes an ItemEncryptor stores along with the item: the
+// encrypted data key and the signature of the signed attributes.
+var (
+	EncryptedDataKeyAttribute = "*enc-key*"
+	SignatureAttribute        = "*enc-sig*"
+)
+
+// A KeyProvider hands out the data keys items are encrypted with, each
+// encrypted under a master key it protects, typically in KMS.
+type KeyProvider interface {
+	// GenerateDataKey returns a new 256-bit key, in clear and encrypted.
+	GenerateDataKey(ctx context.Context) (plaintext, encrypted []byte, err error)
+	// DecryptDataKey returns the key encrypted by GenerateDataKey.
+	DecryptDataKey(ctx context.Context, encrypted []byte) ([]byte, error)
+}
+
+type staticKeyProvider struct {
+	master cipher.AEAD
+}
+
+// NewStaticKeyProvider returns a KeyProvider wrapping data keys with
+// AES-GCM under masterKey, which must be 16, 24 or 32 bytes long. It suits
+// tests and keys kept outside of a key management service.
+func NewStaticKeyProvider(masterKey []byte) (KeyProvider, error) {
+	aead, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return &staticKeyProvider{aead}, nil
+}
+
+func (p *staticKeyProvider) GenerateDataKey(ctx context.Context) ([]byte, []byte, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, nil, err
+	}
+	encrypted, err := seal(p.master, key, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, encrypted, nil
+}
+
+func (p *staticKeyProvider) DecryptDataKey(ctx context.Context, encrypted []byte) ([]byte, error) {
+	return open(p.master, encrypted, nil)
+}
+
+// An ItemEncryptor encrypts attributes of items with AES-GCM on the client,
+// so that Dynamodb only stores their ciphertext, in binary attributes.
+// Every item gets its own data key from Keys, stored encrypted in the item.
+//
+// The encrypted and signed attributes are covered by an HMAC-SHA256
+// signature, also stored in the item, so that reading an item detects
+// their modification or removal. Signing the key attributes prevents
+// moving encrypted values from one item to another: EncryptedTable always
+// signs them, list them in Sign when using EncryptItem directly.
+//
+// Encrypted attributes cannot be used in keys, indexes or conditions, and
+// reads must return every signed attribute, so no projections.
+type ItemEncryptor struct {
+	Keys KeyProvider
+	// Names of the attributes to encrypt and sign.
+	Encrypt []string
+	// Names of the attributes to sign but store in clear.
+	Sign []string
+}
+
+// EncryptItem returns a copy of item for tableName with the Encrypt
+// attributes encrypted, and the data key and signature attributes added.
+func (e *ItemEncryptor) EncryptItem(ctx context.Context, tableName string, item []Attribute) ([]Attribute, error) {
+	key, encryptedKey, err := e.Keys.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aead, mac, err := itemCiphers(key)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted := make([]Attribute, 0, len(item)+2)
+	for _, a := range item {
+		if a.Name == EncryptedDataKeyAttribute || a.Name == SignatureAttribute {
+			return nil, fmt.Errorf("Attribute name %s is reserved for encryption.", a.Name)
+		}
+		if contains(e.Encrypt, a.Name) {
+			plaintext, err := json.Marshal(attributeList([]Attribute{a})[a.Name])
+			if err != nil {
+				return nil, err
+			}
+			ciphertext, err := seal(aead, plaintext, attributeAAD(tableName, a.Name))
+			if err != nil {
+				return nil, err
+			}
+			a = *NewBytesAttribute(a.Name, ciphertext)
+		}
+		encrypted = append(encrypted, a)
+	}
+
+	keyAttribute := NewBytesAttribute(EncryptedDataKeyAttribute, encryptedKey)
+	byName := make(map[string]*Attribute, len(encrypted))
+	for i := range encrypted {
+		byName[encrypted[i].Name] = &encrypted[i]
+	}
+	signature := e.sign(mac, tableName, byName, keyAttribute)
+	return append(encrypted, *keyAttribute, *NewBytesAttribute(SignatureAttribute, signature)), nil
+}
+
+// DecryptItem verifies the signature of an item of tableName encrypted by
+// EncryptItem and returns it decrypted, without the data key and signature
+// attributes.
+func (e *ItemEncryptor) DecryptItem(ctx context.Context, tableName string, item map[string]*Attribute) (map[string]*Attribute, error) {
+	keyAttribute, sigAttribute := item[EncryptedDataKeyAttribute], item[SignatureAttribute]
+	if keyAttribute == nil || sigAttribute == nil {
+		return nil, errors.New("Item is not encrypted.")
+	}
+	encryptedKey, err := keyAttribute.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	signature, err := sigAttribute.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	key, err := e.Keys.DecryptDataKey(ctx, encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, mac, err := itemCiphers(key)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(signature, e.sign(mac, tableName, item, keyAttribute)) {
+		return nil, ErrInvalidSignature
+	}
+
+	decrypted := make(map[string]*Attribute, len(item))
+	for name, a := range item {
+		if name == EncryptedDataKeyAttribute || name == SignatureAttribute {
+			continue
+		}
+		if contains(e.Encrypt, name) {
+			ciphertext, err := a.Bytes()
+			if err != nil {
+				return nil, err
+			}
+			plaintext, err := open(aead, ciphertext, attributeAAD(tableName, name))
+			if err != nil {
+				return nil, fmt.Errorf("Attribute %s: %s", name, err)
+			}
+			var v attributeValue
+			if err := json.Unmarshal(plaintext, &v); err != nil {
+				return nil, fmt.Errorf("Attribute %s: %s", name, err)
+			}
+			plain, _ := v.attribute(name)
+			a = &plain
+		}
+		decrypted[name] = a
+	}
+	return decrypted, nil
+}
+
+// sign computes the signature of the signed attributes of item, which are
+// missing ones included so that removing them is detected.
+func (e *ItemEncryptor) sign(mac hash.Hash, tableName string, item map[string]*Attribute, encryptedKey *Attribute) []byte {
+	names := make([]string, 0, len(e.Encrypt)+len(e.Sign))
+	names = append(names, e.Encrypt...)
+	names = append(names, e.Sign...)
+	sort.Strings(names)
+
+	writeSigned(mac, tableName)
+	writeSigned(mac, encryptedKey.Value)
+	for i, name := range names {
+		if i > 0 && name == names[i-1] {
+			continue
+		}
+		writeSigned(mac, name)
+		a := item[name]
+		if a == nil {
+			writeSigned(mac, "")
+			continue
+		}
+		writeSignedValue(mac, a)
+	}
+	return mac.Sum(nil)
+}
+
+func writeSignedValue(mac hash.Hash, a *Attribute) {
+	writeSigned(mac, a.Type)
+	switch {
+	case a.Type == TYPE_LIST:
+		binary.Write(mac, binary.BigEndian, uint64(len(a.ListValues)))
+		for i := range a.ListValues {
+			writeSignedValue(mac, &a.ListValues[i])
+		}
+	case a.Type == TYPE_MAP:
+		keys := make([]string, 0, len(a.MapValues))
+		for key := range a.MapValues {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		binary.Write(mac, binary.BigEndian, uint64(len(keys)))
+		for _, key := range keys {
+			writeSigned(mac, key)
+			writeSignedValue(mac, a.MapValues[key])
+		}
+	case a.SetValues == nil:
+		writeSigned(mac, a.Value)
+	default:
+		values := append([]string(nil), a.SetValues...)
+		sort.Strings(values)
+		binary.Write(mac, binary.BigEndian, uint64(len(values)))
+		for _, v := range values {
+			writeSigned(mac, v)
+		}
+	}
+}
+
+// writeSigned writes s length prefixed, so that the signed data cannot be
+// ambiguous.
+func writeSigned(w io.Writer, s string) {
+	binary.Write(w, binary.BigEndian, uint64(len(s)))
+	io.WriteString(w, s)
+}
+
+// itemCiphers derives the encryption and signing keys of an item from its
+// data key.
+func itemCiphers(dataKey []byte) (cipher.AEAD, hash.Hash, error) {
+	derive := func(purpose string) []byte {
+		h := hmac.New(sha256.New, dataKey)
+		io.WriteString(h, purpose)
+		return h.Sum(nil)
+	}
+	aead, err := newGCM(derive("encryption"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, hmac.New(sha256.New, derive("signature")), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal returns the nonce followed by the ciphertext of plaintext.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("Ciphertext too short.")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+// attributeAAD binds a ciphertext to the table and attribute it belongs to.
+func attributeAAD(tableName, name string) []byte {
+	return []byte(tableName + "\x00" + name)
+}
+
+// An EncryptedTable reads and writes the items of Table through an
+// ItemEncryptor. Use Table directly for the other operations, keeping in
+// mind the limits listed on ItemEncryptor.
+type EncryptedTable struct {
+	Table     *Table
+	Encryptor *ItemEncryptor
+}
+
+// NewEncryptedTable checks that the encryptor leaves the key attributes of
+// t in clear. The table uses a copy of e which also signs them, whatever
+// e.Sign, so that encrypted values cannot be moved to another key.
+func NewEncryptedTable(t *Table, e *ItemEncryptor) (*EncryptedTable, error) {
+	signed := *e
+	signed.Sign = append([]string(nil), e.Sign...)
+	for _, key := range []*Attribute{t.Key.KeyAttribute, t.Key.RangeAttribute} {
+		if key == nil {
+			continue
+		}
+		if contains(e.Encrypt, key.Name) {
+			return nil, fmt.Errorf("Key attribute %s of %s cannot be encrypted.", key.Name, t.Name)
+		}
+		if !contains(signed.Sign, key.Name) {
+			signed.Sign = append(signed.Sign, key.Name)
+		}
+	}
+	sort.Strings(signed.Sign)
+	return &EncryptedTable{t, &signed}, nil
+}
+
+func (t *EncryptedTable) PutItemWithOptions(ctx context.Context, item []Attribute, opts *WriteOptions) (*WriteResult, error) {
+	encrypted, err := t.Encryptor.EncryptItem(ctx, t.Table.Name, item)
+	if err != nil {
+		return nil, err
+	}
+	return t.Table.PutItemWithOptions(ctx, encrypted, opts)
+}
+
+func (t *EncryptedTable) GetItem(ctx context.Context, key *Key, isRetry bool) (map[string]*Attribute, error) {
+	return t.GetItemConsistent(ctx, key, false, isRetry)
+}
+
+func (t *EncryptedTable) GetItemConsistent(ctx context.Context, key *Key, consistentRead bool, isRetry bool) (map[string]*Attribute, error) {
+	item, err := t.Table.GetItemConsistent(key, consistentRead, isRetry)
+	if err != nil {
+		return nil, err
+	}
+	return t.Encryptor.DecryptItem(ctx, t.Table.Name, item)
+}
+
+func (t *EncryptedTable) QueryWithOptions(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions) ([]map[string]*Attribute, *Key, error) {
+	items, last, err := t.Table.QueryWithOptions(ctx, keyConditions, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t.decryptAll(ctx, items, last)
+}
+
+func (t *EncryptedTable) ScanWithOptions(ctx context.Context, opts *ScanOptions) ([]map[string]*Attribute, *Key, error) {
+	items, last, err := t.Table.ScanWithOptions(ctx, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t.decryptAll(ctx, items, last)
+}
+
+func (t *EncryptedTable) decryptAll(ctx context.Context, items []map[string]*Attribute, last *Key) ([]map[string]*Attribute, *Key, error) {
+	for i, item := range items {
+		decrypted, err := t.Encryptor.DecryptItem(ctx, t.Table.Name, item)
+		if err != nil {
+			return nil, nil, err
+		}
+		items[i] = decrypted
+	}
+	return items, last, nil
+}
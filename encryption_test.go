@@ -0,0 +1,111 @@
+package dynamodb_test
+
+import (
+	"context"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type EncryptionSuite struct {
+	table     *dynamodb.Table
+	encrypted *dynamodb.EncryptedTable
+}
+
+var _ = check.Suite(&EncryptionSuite{})
+
+func (s *EncryptionSuite) SetUpTest(c *check.C) {
+	s.table = newFakeTable(c, "patients", idKey{})
+
+	keys, err := dynamodb.NewStaticKeyProvider(make([]byte, 32))
+	c.Assert(err, check.IsNil)
+	s.encrypted, err = dynamodb.NewEncryptedTable(s.table, &dynamodb.ItemEncryptor{
+		Keys:    keys,
+		Encrypt: []string{"ssn", "allergies"},
+		Sign:    []string{"name"},
+	})
+	c.Assert(err, check.IsNil)
+
+	for id, ssn := range map[string]string{"p1": "123-45-6789", "p2": "987-65-4321"} {
+		_, err = s.encrypted.PutItemWithOptions(context.Background(), []dynamodb.Attribute{
+			*dynamodb.NewStringAttribute("id", id),
+			*dynamodb.NewStringAttribute("name", "Pat "+id),
+			*dynamodb.NewStringAttribute("ssn", ssn),
+			*dynamodb.NewStringSetAttribute("allergies", []string{"nuts", "pollen"}),
+			*dynamodb.NewNumericAttribute("visits", "3"),
+		}, nil)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *EncryptionSuite) TestRoundTrip(c *check.C) {
+	raw, err := s.table.GetItem(&dynamodb.Key{HashKey: "p1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(raw["ssn"].Type, check.Equals, dynamodb.TYPE_BINARY)
+	c.Check(raw["ssn"].Value, check.Not(check.Matches), ".*123.*")
+	c.Check(raw["allergies"].Type, check.Equals, dynamodb.TYPE_BINARY)
+	c.Check(raw["name"].Value, check.Equals, "Pat p1")
+
+	item, err := s.encrypted.GetItem(context.Background(), &dynamodb.Key{HashKey: "p1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(*item["ssn"], check.DeepEquals, *dynamodb.NewStringAttribute("ssn", "123-45-6789"))
+	c.Check(item["allergies"].SetValues, check.DeepEquals, []string{"nuts", "pollen"})
+	c.Check(item["visits"].Value, check.Equals, "3")
+	c.Check(item[dynamodb.EncryptedDataKeyAttribute], check.IsNil)
+	c.Check(item[dynamodb.SignatureAttribute], check.IsNil)
+
+	items, _, err := s.encrypted.ScanWithOptions(context.Background(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(items, check.HasLen, 2)
+	for _, item := range items {
+		c.Check(item["ssn"].Type, check.Equals, dynamodb.TYPE_STRING)
+	}
+}
+
+func (s *EncryptionSuite) TestTampering(c *check.C) {
+	key := &dynamodb.Key{HashKey: "p1"}
+
+	// Unsigned attributes may change.
+	_, err := s.table.UpdateAttributes(key, []dynamodb.Attribute{*dynamodb.NewNumericAttribute("visits", "4")}, false)
+	c.Assert(err, check.IsNil)
+	_, err = s.encrypted.GetItem(context.Background(), key, false)
+	c.Assert(err, check.IsNil)
+
+	// Encrypted values cannot be moved to another item.
+	other, err := s.table.GetItem(&dynamodb.Key{HashKey: "p2"}, false)
+	c.Assert(err, check.IsNil)
+	_, err = s.table.UpdateAttributes(key, []dynamodb.Attribute{*other["ssn"]}, false)
+	c.Assert(err, check.IsNil)
+	_, err = s.encrypted.GetItem(context.Background(), key, false)
+	c.Check(err, check.Equals, dynamodb.ErrInvalidSignature)
+
+	// Nor can an encrypted item be copied to another key, the key
+	// attributes being signed whatever ItemEncryptor.Sign.
+	moved := []dynamodb.Attribute{}
+	for name, a := range other {
+		if name == "id" {
+			a = dynamodb.NewStringAttribute("id", "p3")
+		}
+		moved = append(moved, *a)
+	}
+	_, err = s.table.PutItemWithOptions(context.Background(), moved, nil)
+	c.Assert(err, check.IsNil)
+	_, err = s.encrypted.GetItem(context.Background(), &dynamodb.Key{HashKey: "p3"}, false)
+	c.Check(err, check.Equals, dynamodb.ErrInvalidSignature)
+	_, err = s.encrypted.GetItem(context.Background(), &dynamodb.Key{HashKey: "p2"}, false)
+	c.Check(err, check.IsNil)
+
+	// Nor can signed attributes be removed.
+	_, err = s.table.DeleteAttributes(&dynamodb.Key{HashKey: "p2"}, []dynamodb.Attribute{*dynamodb.NewStringAttribute("name", "")}, false)
+	c.Assert(err, check.IsNil)
+	_, err = s.encrypted.GetItem(context.Background(), &dynamodb.Key{HashKey: "p2"}, false)
+	c.Check(err, check.Equals, dynamodb.ErrInvalidSignature)
+}
+
+func (s *EncryptionSuite) TestKeyAttributesStayClear(c *check.C) {
+	_, err := dynamodb.NewEncryptedTable(s.table, &dynamodb.ItemEncryptor{Encrypt: []string{"id"}})
+	c.Check(err, check.ErrorMatches, "Key attribute id of patients cannot be encrypted.")
+
+	_, err = dynamodb.NewStaticKeyProvider([]byte("short"))
+	c.Check(err, check.NotNil)
+}
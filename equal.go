@@ -0,0 +1,108 @@
+package dynamodb
+
+import (
+	"math/big"
+	"sort"
+)
+
+// AttributeEqual reports whether a and b hold the same value. Numbers are
+// compared by value, so "1" equals "1.0", sets regardless of the order of
+// their members, and lists and maps member by member. Names are ignored;
+// nil only equals nil.
+func AttributeEqual(a, b *Attribute) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case TYPE_NUMBER:
+		return numbersEqual(a.Value, b.Value)
+	case TYPE_NUMBER_SET:
+		return setsEqual(a.SetValues, b.SetValues, canonicalNumber)
+	case TYPE_STRING_SET, TYPE_BINARY_SET:
+		return setsEqual(a.SetValues, b.SetValues, func(s string) string { return s })
+	case TYPE_LIST:
+		if len(a.ListValues) != len(b.ListValues) {
+			return false
+		}
+		for i := range a.ListValues {
+			if !AttributeEqual(&a.ListValues[i], &b.ListValues[i]) {
+				return false
+			}
+		}
+		return true
+	case TYPE_MAP:
+		return ItemsEqual(a.MapValues, b.MapValues)
+	case TYPE_NULL:
+		return true
+	}
+	return a.Value == b.Value
+}
+
+// ItemsEqual reports whether a and b have the same attributes, compared
+// with AttributeEqual.
+func ItemsEqual(a, b map[string]*Attribute) bool {
+	return len(a) == len(b) && len(ChangedAttributes(a, b)) == 0
+}
+
+// AttributeChange is an attribute which differs between two items. Old
+// is nil for an added attribute, New for a removed one.
+type AttributeChange struct {
+	Name string
+	Old  *Attribute
+	New  *Attribute
+}
+
+// ChangedAttributes returns the attributes of before and after which are
+// not equal, as AttributeEqual compares them, sorted by name.
+func ChangedAttributes(before, after map[string]*Attribute) []AttributeChange {
+	var changes []AttributeChange
+	for name, a := range before {
+		if b := after[name]; !AttributeEqual(a, b) {
+			changes = append(changes, AttributeChange{Name: name, Old: a, New: b})
+		}
+	}
+	for name, b := range after {
+		if _, ok := before[name]; !ok && b != nil {
+			changes = append(changes, AttributeChange{Name: name, New: b})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+func numbersEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	x, okx := new(big.Rat).SetString(a)
+	y, oky := new(big.Rat).SetString(b)
+	return okx && oky && x.Cmp(y) == 0
+}
+
+// canonicalNumber returns the same string for equal numbers, n itself
+// when it does not parse.
+func canonicalNumber(n string) string {
+	if r, ok := new(big.Rat).SetString(n); ok {
+		return r.RatString()
+	}
+	return n
+}
+
+func setsEqual(a, b []string, canonical func(string) string) bool {
+	members := make(map[string]bool, len(a))
+	for _, v := range a {
+		members[canonical(v)] = true
+	}
+	others := make(map[string]bool, len(b))
+	for _, v := range b {
+		v = canonical(v)
+		if !members[v] {
+			return false
+		}
+		others[v] = true
+	}
+	return len(others) == len(members)
+}
@@ -0,0 +1,67 @@
+package dynamodb_test
+
+import (
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type EqualSuite struct{}
+
+var _ = check.Suite(&EqualSuite{})
+
+func (s *EqualSuite) TestAttributeEqual(c *check.C) {
+	equal := [][2]*dynamodb.Attribute{
+		{dynamodb.NewNumericAttribute("a", "1"), dynamodb.NewNumericAttribute("b", "1.0")},
+		{dynamodb.NewNumericAttribute("", "1.5E3"), dynamodb.NewNumericAttribute("", "1500")},
+		{dynamodb.NewStringSetAttribute("", []string{"a", "b"}), dynamodb.NewStringSetAttribute("", []string{"b", "a"})},
+		{dynamodb.NewNumericSetAttribute("", []string{"1", "2.50"}), dynamodb.NewNumericSetAttribute("", []string{"2.5", "1.0"})},
+		{dynamodb.NewListAttribute("", stringList("x", "y")), dynamodb.NewListAttribute("", stringList("x", "y"))},
+		{
+			dynamodb.NewMapAttribute("", []dynamodb.Attribute{*dynamodb.NewNumericAttribute("n", "10")}),
+			dynamodb.NewMapAttribute("", []dynamodb.Attribute{*dynamodb.NewNumericAttribute("n", "1e1")}),
+		},
+		{dynamodb.NewNullAttribute("a"), dynamodb.NewNullAttribute("b")},
+		{nil, nil},
+	}
+	for i, pair := range equal {
+		c.Check(dynamodb.AttributeEqual(pair[0], pair[1]), check.Equals, true, check.Commentf("%d", i))
+	}
+
+	different := [][2]*dynamodb.Attribute{
+		{dynamodb.NewNumericAttribute("", "1"), dynamodb.NewStringAttribute("", "1")},
+		{dynamodb.NewNumericAttribute("", "1"), dynamodb.NewNumericAttribute("", "1.01")},
+		{dynamodb.NewStringAttribute("", "1.0"), dynamodb.NewStringAttribute("", "1")},
+		{dynamodb.NewStringSetAttribute("", []string{"a", "b"}), dynamodb.NewStringSetAttribute("", []string{"a"})},
+		{dynamodb.NewStringSetAttribute("", []string{"a", "a"}), dynamodb.NewStringSetAttribute("", []string{"a", "b"})},
+		{dynamodb.NewListAttribute("", stringList("x", "y")), dynamodb.NewListAttribute("", stringList("y", "x"))},
+		{dynamodb.NewStringAttribute("", ""), nil},
+	}
+	for i, pair := range different {
+		c.Check(dynamodb.AttributeEqual(pair[0], pair[1]), check.Equals, false, check.Commentf("%d", i))
+	}
+}
+
+func (s *EqualSuite) TestChangedAttributes(c *check.C) {
+	old := map[string]*dynamodb.Attribute{
+		"id":    dynamodb.NewStringAttribute("id", "u1"),
+		"n":     dynamodb.NewNumericAttribute("n", "1"),
+		"tags":  dynamodb.NewStringSetAttribute("tags", []string{"a", "b"}),
+		"stale": dynamodb.NewStringAttribute("stale", "x"),
+	}
+	updated := map[string]*dynamodb.Attribute{
+		"id":    dynamodb.NewStringAttribute("id", "u1"),
+		"n":     dynamodb.NewNumericAttribute("n", "1.0"),
+		"tags":  dynamodb.NewStringSetAttribute("tags", []string{"b", "c"}),
+		"added": dynamodb.NewStringAttribute("added", "y"),
+	}
+	c.Check(dynamodb.ChangedAttributes(old, updated), check.DeepEquals, []dynamodb.AttributeChange{
+		{Name: "added", New: updated["added"]},
+		{Name: "stale", Old: old["stale"]},
+		{Name: "tags", Old: old["tags"], New: updated["tags"]},
+	})
+	c.Check(dynamodb.ItemsEqual(old, updated), check.Equals, false)
+
+	delete(old, "stale")
+	old["tags"], old["added"] = updated["tags"], updated["added"]
+	c.Check(dynamodb.ItemsEqual(old, updated), check.Equals, true)
+}
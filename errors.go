@@ -0,0 +1,125 @@
+package dynamodb
+
+import (
+	"errors"
+)
+
+// Dynamodb error codes the client knows how to classify.
+const (
+	ThrottlingException             = "ThrottlingException"
+	RequestLimitExceeded            = "RequestLimitExceeded"
+	ConditionalCheckFailedException = "ConditionalCheckFailedException"
+	ResourceNotFoundException       = "ResourceNotFoundException"
+	InternalServerError             = "InternalServerError"
+	ServiceUnavailable              = "ServiceUnavailable"
+	ItemCollectionSizeLimitExceeded = "ItemCollectionSizeLimitExceededException"
+	TransactionConflictException    = "TransactionConflictException"
+	TransactionCanceledException    = "TransactionCanceledException"
+	ValidationException             = "ValidationException"
+	ResourceInUseException          = "ResourceInUseException"
+	LimitExceededException          = "LimitExceededException"
+	UnrecognizedClientException     = "UnrecognizedClientException"
+	ExpiredTokenException           = "ExpiredTokenException"
+
+	IdempotentParameterMismatchException = "IdempotentParameterMismatchException"
+	TransactionInProgressException       = "TransactionInProgressException"
+)
+
+// asError unwraps err looking for a Dynamodb *Error (or Error value).
+func asError(err error) (*Error, bool) {
+	if err == nil {
+		return nil, false
+	}
+	var pe *Error
+	if errors.As(err, &pe) && pe != nil {
+		return pe, true
+	}
+	var ve Error
+	if errors.As(err, &ve) {
+		return &ve, true
+	}
+	return nil, false
+}
+
+// ErrorCode returns the Dynamodb error code carried by err, or "" if err
+// is not (and does not wrap) a Dynamodb error.
+func ErrorCode(err error) string {
+	if e, ok := asError(err); ok {
+		return e.Code
+	}
+	return ""
+}
+
+// IsThrottle reports whether err is a throttling error returned by Dynamodb.
+func IsThrottle(err error) bool {
+	switch ErrorCode(err) {
+	case ProvisionedThroughputExceeded, ThrottlingException, RequestLimitExceeded:
+		return true
+	}
+	return false
+}
+
+// IsRetryable reports whether the operation that returned err may succeed
+// if retried: throttling errors, server side (5xx) failures, attempts that
+// timed out and transactions still in progress under the same
+// ClientRequestToken.
+// See http://docs.aws.amazon.com/amazondynamodb/latest/developerguide/ErrorHandling.html#APIRetries
+func IsRetryable(err error) bool {
+	if IsThrottle(err) || errors.Is(err, ErrAttemptTimeout) || errors.Is(err, ErrChecksumMismatch) {
+		return true
+	}
+	e, ok := asError(err)
+	if !ok {
+		return false
+	}
+	switch e.Code {
+	case InternalServerError, ServiceUnavailable, TransactionConflictException, TransactionInProgressException:
+		return true
+	}
+	return e.StatusCode >= 500
+}
+
+// ConditionFailedItem returns the item which failed the condition of a
+// write made with ReturnValuesOnConditionCheckFailure set to ALL_OLD, and
+// of the first such action of a cancelled transaction. It returns nil for
+// other errors, and when the item does not exist.
+func ConditionFailedItem(err error) map[string]*Attribute {
+	e, ok := asError(err)
+	if !ok {
+		return nil
+	}
+	if e.Item != nil {
+		return e.Item
+	}
+	for _, r := range e.CancellationReasons {
+		if r.Code == "ConditionalCheckFailed" && r.Item != nil {
+			return r.Item
+		}
+	}
+	return nil
+}
+
+// IsConditionalCheckFailed reports whether err is the result of a failed
+// Expected/ConditionExpression on a write.
+func IsConditionalCheckFailed(err error) bool {
+	return ErrorCode(err) == ConditionalCheckFailedException
+}
+
+// IsNotFound reports whether err means the requested item or table does
+// not exist.
+func IsNotFound(err error) bool {
+	if errors.Is(err, ErrNotFound) {
+		return true
+	}
+	return ErrorCode(err) == ResourceNotFoundException
+}
+
+// IsValidationError reports whether err is a *ValidationError found before
+// sending a request, or a ValidationException returned by Dynamodb.
+func IsValidationError(err error) bool {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return true
+	}
+	return ErrorCode(err) == ValidationException
+}
@@ -0,0 +1,65 @@
+package dynamodb_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type ErrorsSuite struct {
+}
+
+var _ = check.Suite(&ErrorsSuite{})
+
+func (s *ErrorsSuite) TestPredicates(c *check.C) {
+	throttle := &dynamodb.Error{StatusCode: 400, Code: dynamodb.ProvisionedThroughputExceeded}
+	c.Check(dynamodb.IsThrottle(throttle), check.Equals, true)
+	c.Check(dynamodb.IsRetryable(throttle), check.Equals, true)
+	c.Check(dynamodb.IsConditionalCheckFailed(throttle), check.Equals, false)
+
+	internal := &dynamodb.Error{StatusCode: 500, Code: dynamodb.InternalServerError}
+	c.Check(dynamodb.IsThrottle(internal), check.Equals, false)
+	c.Check(dynamodb.IsRetryable(internal), check.Equals, true)
+
+	conditional := dynamodb.Error{StatusCode: 400, Code: dynamodb.ConditionalCheckFailedException}
+	c.Check(dynamodb.IsConditionalCheckFailed(conditional), check.Equals, true)
+	c.Check(dynamodb.IsRetryable(conditional), check.Equals, false)
+
+	c.Check(dynamodb.IsNotFound(dynamodb.ErrNotFound), check.Equals, true)
+	c.Check(dynamodb.IsNotFound(&dynamodb.Error{Code: dynamodb.ResourceNotFoundException}), check.Equals, true)
+	c.Check(dynamodb.IsRetryable(errors.New("plain")), check.Equals, false)
+	c.Check(dynamodb.IsRetryable(nil), check.Equals, false)
+}
+
+func (s *ErrorsSuite) TestWrappedErrors(c *check.C) {
+	wrapped := fmt.Errorf("putting item: %w", &dynamodb.Error{StatusCode: 400, Code: dynamodb.ThrottlingException})
+	c.Check(dynamodb.IsThrottle(wrapped), check.Equals, true)
+	c.Check(dynamodb.ErrorCode(wrapped), check.Equals, dynamodb.ThrottlingException)
+
+	notFound := fmt.Errorf("loading user: %w", dynamodb.ErrNotFound)
+	c.Check(dynamodb.IsNotFound(notFound), check.Equals, true)
+}
+
+func (s *ErrorsSuite) TestParseErrorCancellationReasons(c *check.C) {
+	body := `{"__type":"com.amazonaws.dynamodb.v20120810#TransactionCanceledException",
+		"message":"Transaction cancelled",
+		"CancellationReasons":[
+			{"Code":"None"},
+			{"Code":"ConditionalCheckFailed","Message":"The conditional request failed","Item":{"id":{"S":"a"},"n":{"N":"2"}}}
+		]}`
+	err := dynamodb.ParseError(400, []byte(body))
+	c.Check(err.Code, check.Equals, "TransactionCanceledException")
+	c.Check(err.Status, check.Equals, "400 Bad Request")
+	c.Assert(err.CancellationReasons, check.HasLen, 2)
+	c.Check(err.CancellationReasons[0].Code, check.Equals, "None")
+	c.Check(err.CancellationReasons[0].Item, check.IsNil)
+
+	item := dynamodb.ConditionFailedItem(fmt.Errorf("transfer: %w", err))
+	c.Assert(item, check.NotNil)
+	c.Check(item["id"], check.DeepEquals, dynamodb.NewStringAttribute("id", "a"))
+	c.Check(item["n"], check.DeepEquals, dynamodb.NewNumericAttribute("n", "2"))
+
+	c.Check(dynamodb.ConditionFailedItem(errors.New("plain")), check.IsNil)
+}
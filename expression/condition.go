@@ -0,0 +1,233 @@
+package expression
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bluele/dynamodb"
+)
+
+// OperandBuilder is a name, a value, a size or an update operand.
+type OperandBuilder interface {
+	operand(p *dynamodb.Placeholders) string
+}
+
+// NameBuilder is an attribute, designated by a document path such as
+// "profile.tags[0]".
+type NameBuilder struct {
+	path string
+}
+
+func Name(path string) NameBuilder {
+	return NameBuilder{path: path}
+}
+
+func (n NameBuilder) operand(p *dynamodb.Placeholders) string {
+	return p.Path(n.path)
+}
+
+// ValueBuilder is a value, any value dynamodb.Placeholders.Value accepts.
+type ValueBuilder struct {
+	value interface{}
+}
+
+func Value(v interface{}) ValueBuilder {
+	return ValueBuilder{value: v}
+}
+
+func (v ValueBuilder) operand(p *dynamodb.Placeholders) string {
+	return p.Value(v.value)
+}
+
+// SizeBuilder is the size of an attribute: the length of a string or
+// binary value, or the number of members of a set, list or map.
+type SizeBuilder struct {
+	name NameBuilder
+}
+
+func (n NameBuilder) Size() SizeBuilder {
+	return SizeBuilder{name: n}
+}
+
+func (s SizeBuilder) operand(p *dynamodb.Placeholders) string {
+	return "size(" + s.name.operand(p) + ")"
+}
+
+// ConditionBuilder is a condition, for ConditionExpression and
+// FilterExpression. The zero value is invalid.
+type ConditionBuilder struct {
+	render func(p *dynamodb.Placeholders) string
+}
+
+func (c ConditionBuilder) build(p *dynamodb.Placeholders) (string, error) {
+	if c.render == nil {
+		return "", errors.New("The condition is empty.")
+	}
+	return c.render(p), nil
+}
+
+func comparison(left OperandBuilder, op string, right OperandBuilder) ConditionBuilder {
+	return ConditionBuilder{func(p *dynamodb.Placeholders) string {
+		return left.operand(p) + " " + op + " " + right.operand(p)
+	}}
+}
+
+func Equal(left, right OperandBuilder) ConditionBuilder {
+	return comparison(left, "=", right)
+}
+
+func NotEqual(left, right OperandBuilder) ConditionBuilder {
+	return comparison(left, "<>", right)
+}
+
+func LessThan(left, right OperandBuilder) ConditionBuilder {
+	return comparison(left, "<", right)
+}
+
+func LessThanEqual(left, right OperandBuilder) ConditionBuilder {
+	return comparison(left, "<=", right)
+}
+
+func GreaterThan(left, right OperandBuilder) ConditionBuilder {
+	return comparison(left, ">", right)
+}
+
+func GreaterThanEqual(left, right OperandBuilder) ConditionBuilder {
+	return comparison(left, ">=", right)
+}
+
+// Between is true when low <= operand <= high.
+func Between(operand, low, high OperandBuilder) ConditionBuilder {
+	return ConditionBuilder{func(p *dynamodb.Placeholders) string {
+		return operand.operand(p) + " BETWEEN " + low.operand(p) + " AND " + high.operand(p)
+	}}
+}
+
+// In is true when operand equals one of the others.
+func In(operand, first OperandBuilder, others ...OperandBuilder) ConditionBuilder {
+	return ConditionBuilder{func(p *dynamodb.Placeholders) string {
+		list := []string{first.operand(p)}
+		for _, o := range others {
+			list = append(list, o.operand(p))
+		}
+		return operand.operand(p) + " IN (" + strings.Join(list, ", ") + ")"
+	}}
+}
+
+func function(name string, operands ...OperandBuilder) ConditionBuilder {
+	return ConditionBuilder{func(p *dynamodb.Placeholders) string {
+		args := make([]string, len(operands))
+		for i, o := range operands {
+			args[i] = o.operand(p)
+		}
+		return name + "(" + strings.Join(args, ", ") + ")"
+	}}
+}
+
+func AttributeExists(n NameBuilder) ConditionBuilder {
+	return function("attribute_exists", n)
+}
+
+func AttributeNotExists(n NameBuilder) ConditionBuilder {
+	return function("attribute_not_exists", n)
+}
+
+// AttributeType is true when the attribute is of type t, one of the
+// dynamodb.TYPE_ constants or "BOOL".
+func AttributeType(n NameBuilder, t string) ConditionBuilder {
+	return function("attribute_type", n, Value(t))
+}
+
+func BeginsWith(n NameBuilder, prefix string) ConditionBuilder {
+	return function("begins_with", n, Value(prefix))
+}
+
+// Contains is true when the string attribute contains the substring v, or
+// the set or list attribute has the member v.
+func Contains(n NameBuilder, v interface{}) ConditionBuilder {
+	return function("contains", n, Value(v))
+}
+
+func logical(op string, conditions []ConditionBuilder) ConditionBuilder {
+	for _, c := range conditions {
+		if c.render == nil {
+			return ConditionBuilder{}
+		}
+	}
+	return ConditionBuilder{func(p *dynamodb.Placeholders) string {
+		parts := make([]string, len(conditions))
+		for i, c := range conditions {
+			parts[i] = "(" + c.render(p) + ")"
+		}
+		return strings.Join(parts, " "+op+" ")
+	}}
+}
+
+// And is true when every condition is.
+func And(left, right ConditionBuilder, others ...ConditionBuilder) ConditionBuilder {
+	return logical("AND", append([]ConditionBuilder{left, right}, others...))
+}
+
+// Or is true when any condition is.
+func Or(left, right ConditionBuilder, others ...ConditionBuilder) ConditionBuilder {
+	return logical("OR", append([]ConditionBuilder{left, right}, others...))
+}
+
+func Not(c ConditionBuilder) ConditionBuilder {
+	if c.render == nil {
+		return c
+	}
+	return ConditionBuilder{func(p *dynamodb.Placeholders) string {
+		return "NOT (" + c.render(p) + ")"
+	}}
+}
+
+func (c ConditionBuilder) And(right ConditionBuilder, others ...ConditionBuilder) ConditionBuilder {
+	return And(c, right, others...)
+}
+
+func (c ConditionBuilder) Or(right ConditionBuilder, others ...ConditionBuilder) ConditionBuilder {
+	return Or(c, right, others...)
+}
+
+func (c ConditionBuilder) Not() ConditionBuilder {
+	return Not(c)
+}
+
+func (n NameBuilder) Equal(right OperandBuilder) ConditionBuilder { return Equal(n, right) }
+func (n NameBuilder) NotEqual(right OperandBuilder) ConditionBuilder {
+	return NotEqual(n, right)
+}
+func (n NameBuilder) LessThan(right OperandBuilder) ConditionBuilder {
+	return LessThan(n, right)
+}
+func (n NameBuilder) LessThanEqual(right OperandBuilder) ConditionBuilder {
+	return LessThanEqual(n, right)
+}
+func (n NameBuilder) GreaterThan(right OperandBuilder) ConditionBuilder {
+	return GreaterThan(n, right)
+}
+func (n NameBuilder) GreaterThanEqual(right OperandBuilder) ConditionBuilder {
+	return GreaterThanEqual(n, right)
+}
+func (n NameBuilder) Between(low, high OperandBuilder) ConditionBuilder {
+	return Between(n, low, high)
+}
+func (n NameBuilder) In(first OperandBuilder, others ...OperandBuilder) ConditionBuilder {
+	return In(n, first, others...)
+}
+func (n NameBuilder) AttributeExists() ConditionBuilder    { return AttributeExists(n) }
+func (n NameBuilder) AttributeNotExists() ConditionBuilder { return AttributeNotExists(n) }
+func (n NameBuilder) AttributeType(t string) ConditionBuilder {
+	return AttributeType(n, t)
+}
+func (n NameBuilder) BeginsWith(prefix string) ConditionBuilder { return BeginsWith(n, prefix) }
+func (n NameBuilder) Contains(v interface{}) ConditionBuilder   { return Contains(n, v) }
+
+func (s SizeBuilder) Equal(right OperandBuilder) ConditionBuilder { return Equal(s, right) }
+func (s SizeBuilder) LessThan(right OperandBuilder) ConditionBuilder {
+	return LessThan(s, right)
+}
+func (s SizeBuilder) GreaterThan(right OperandBuilder) ConditionBuilder {
+	return GreaterThan(s, right)
+}
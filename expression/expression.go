@@ -0,0 +1,167 @@
+// Package expression builds Dynamodb expressions out of typed values
+// rather than strings: names, values and keys compose into conditions,
+// key conditions, projections and updates, which a Builder renders along
+// with their ExpressionAttributeNames and ExpressionAttributeValues. Every
+// name is aliased, so reserved words and document paths need no care:
+//
+//	cond := expression.Name("status").Equal(expression.Value("active")).
+//		And(expression.Name("profile.age").GreaterThanEqual(expression.Value(18)))
+//	update := expression.Set(expression.Name("visits"), expression.Name("visits").Plus(expression.Value(1)))
+//	expr, err := expression.NewBuilder().WithCondition(cond).WithUpdate(update).Build()
+//	if err != nil { ... }
+//	opts := &dynamodb.WriteOptions{}
+//	expr.ApplyWrite(opts)
+//	table.UpdateItemWithOptions(ctx, key, nil, "", opts)
+//
+// Mistakes such as an empty condition or an invalid document path are
+// reported by Build.
+package expression
+
+import (
+	"errors"
+
+	"github.com/bluele/dynamodb"
+)
+
+// Builder gathers the parts of an expression. The zero value is ready to
+// use.
+type Builder struct {
+	keyCondition *KeyConditionBuilder
+	condition    *ConditionBuilder
+	filter       *ConditionBuilder
+	projection   *ProjectionBuilder
+	update       *UpdateBuilder
+}
+
+func NewBuilder() Builder {
+	return Builder{}
+}
+
+// WithKeyCondition sets the KeyConditionExpression of a Query.
+func (b Builder) WithKeyCondition(k KeyConditionBuilder) Builder {
+	b.keyCondition = &k
+	return b
+}
+
+// WithCondition sets the ConditionExpression of a write.
+func (b Builder) WithCondition(c ConditionBuilder) Builder {
+	b.condition = &c
+	return b
+}
+
+// WithFilter sets the FilterExpression of a Query or Scan.
+func (b Builder) WithFilter(c ConditionBuilder) Builder {
+	b.filter = &c
+	return b
+}
+
+// WithProjection sets the ProjectionExpression of a read.
+func (b Builder) WithProjection(p ProjectionBuilder) Builder {
+	b.projection = &p
+	return b
+}
+
+// WithUpdate sets the UpdateExpression of an UpdateItem.
+func (b Builder) WithUpdate(u UpdateBuilder) Builder {
+	b.update = &u
+	return b
+}
+
+// Build renders the expressions, sharing one set of placeholders.
+func (b Builder) Build() (Expression, error) {
+	e := Expression{p: dynamodb.NewPlaceholders()}
+	var err error
+	if b.keyCondition != nil {
+		if e.keyCondition, err = b.keyCondition.build(e.p); err != nil {
+			return Expression{}, err
+		}
+	}
+	if b.condition != nil {
+		if e.condition, err = b.condition.build(e.p); err != nil {
+			return Expression{}, err
+		}
+	}
+	if b.filter != nil {
+		if e.filter, err = b.filter.build(e.p); err != nil {
+			return Expression{}, err
+		}
+	}
+	if b.projection != nil {
+		if e.projection, err = b.projection.build(e.p); err != nil {
+			return Expression{}, err
+		}
+	}
+	if b.update != nil {
+		if e.update, err = b.update.build(e.p); err != nil {
+			return Expression{}, err
+		}
+	}
+	if e.keyCondition == "" && e.condition == "" && e.filter == "" && e.projection == "" && e.update == "" {
+		return Expression{}, errors.New("The expression is empty.")
+	}
+	if err := e.p.Err(); err != nil {
+		return Expression{}, err
+	}
+	return e, nil
+}
+
+// Expression is a rendered set of expressions.
+type Expression struct {
+	p            *dynamodb.Placeholders
+	keyCondition string
+	condition    string
+	filter       string
+	projection   string
+	update       string
+}
+
+func (e Expression) KeyCondition() string { return e.keyCondition }
+func (e Expression) Condition() string    { return e.condition }
+func (e Expression) Filter() string       { return e.filter }
+func (e Expression) Projection() string   { return e.projection }
+func (e Expression) Update() string       { return e.update }
+
+// Names returns ExpressionAttributeNames, placeholder to attribute name.
+func (e Expression) Names() map[string]string {
+	return e.p.Names()
+}
+
+// Values returns ExpressionAttributeValues, each attribute named after
+// its placeholder.
+func (e Expression) Values() []dynamodb.Attribute {
+	return e.p.Values()
+}
+
+// Apply adds the expressions to a Query or Scan request, along with the
+// names and values.
+func (e Expression) Apply(q *dynamodb.Query) {
+	if e.keyCondition != "" {
+		q.AddKeyConditionExpression(e.keyCondition)
+	}
+	if e.condition != "" {
+		q.AddConditionExpression(e.condition)
+	}
+	if e.filter != "" {
+		q.AddFilterExpression(e.filter)
+	}
+	if e.projection != "" {
+		q.AddProjectionExpression(e.projection)
+	}
+	if e.update != "" {
+		q.AddUpdateExpression(e.update)
+	}
+	e.p.Apply(q)
+}
+
+// ApplyWrite sets the condition and update expressions on opts, merging
+// the names and values with any already present. Key conditions, filters
+// and projections do not apply to writes and are ignored.
+func (e Expression) ApplyWrite(opts *dynamodb.WriteOptions) {
+	if e.condition != "" {
+		opts.ConditionExpression = e.condition
+	}
+	if e.update != "" {
+		opts.UpdateExpression = e.update
+	}
+	e.p.ApplyWrite(opts)
+}
@@ -0,0 +1,167 @@
+package expression_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"github.com/bluele/dynamodb/expression"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+
+type ExpressionSuite struct {
+	table *dynamodb.Table
+}
+
+var _ = check.Suite(&ExpressionSuite{})
+
+func (s *ExpressionSuite) SetUpTest(c *check.C) {
+	server, _ := dynamodbtest.NewServer()
+	d, err := dynamodb.TableSchemaFromStruct(struct {
+		User string `dynamodb:"user,hash"`
+		Seq  int64  `dynamodb:"seq,range"`
+	}{}, &dynamodb.SchemaOptions{TableName: "events"})
+	c.Assert(err, check.IsNil)
+	_, err = server.CreateTable(d, false)
+	c.Assert(err, check.IsNil)
+	s.table, err = server.Table("events")
+	c.Assert(err, check.IsNil)
+	for _, e := range []struct{ user, seq, kind string }{
+		{"alice", "1", "login"}, {"alice", "2", "click"}, {"alice", "3", "logout"}, {"bob", "1", "login"},
+	} {
+		_, err := s.table.PutItemWithOptions(context.Background(), []dynamodb.Attribute{
+			*dynamodb.NewStringAttribute("user", e.user),
+			*dynamodb.NewNumericAttribute("seq", e.seq),
+			*dynamodb.NewStringAttribute("kind", e.kind),
+		}, nil)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *ExpressionSuite) query(c *check.C, expr expression.Expression) []map[string]*dynamodb.Attribute {
+	q := dynamodb.NewQuery(s.table)
+	expr.Apply(q)
+	items, _, err := s.table.QueryTable(q, false)
+	c.Assert(err, check.IsNil)
+	return items
+}
+
+func kinds(items []map[string]*dynamodb.Attribute) []string {
+	var kinds []string
+	for _, item := range items {
+		kinds = append(kinds, item["kind"].Value)
+	}
+	return kinds
+}
+
+func (s *ExpressionSuite) TestRender(c *check.C) {
+	cond := expression.Name("status").Equal(expression.Value("active")).
+		And(expression.Name("profile.age").Between(expression.Value(18), expression.Value(65))).
+		Or(expression.Name("tags").Contains("vip").Not())
+	expr, err := expression.NewBuilder().
+		WithCondition(cond).
+		WithProjection(expression.NamesList(expression.Name("status"), expression.Name("profile.tags[0]"))).
+		Build()
+	c.Assert(err, check.IsNil)
+	c.Check(expr.Condition(), check.Equals, "((#n0 = :v0) AND (#n1.#n2 BETWEEN :v1 AND :v2)) OR (NOT (contains(#n3, :v3)))")
+	c.Check(expr.Projection(), check.Equals, "#n0, #n1.#n3[0]")
+	c.Check(expr.Names(), check.DeepEquals, map[string]string{"#n0": "status", "#n1": "profile", "#n2": "age", "#n3": "tags"})
+	c.Check(expr.Values(), check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute(":v0", "active"),
+		*dynamodb.NewNumericAttribute(":v1", "18"),
+		*dynamodb.NewNumericAttribute(":v2", "65"),
+		*dynamodb.NewStringAttribute(":v3", "vip"),
+	})
+
+	expr, err = expression.NewBuilder().
+		WithFilter(expression.And(
+			expression.Name("name").Size().GreaterThan(expression.Value(3)),
+			expression.Name("kind").In(expression.Value("a"), expression.Value("b")),
+			expression.Name("score").AttributeType(dynamodb.TYPE_NUMBER),
+		)).Build()
+	c.Assert(err, check.IsNil)
+	c.Check(expr.Filter(), check.Equals, "(size(#n0) > :v0) AND (#n1 IN (:v1, :v2)) AND (attribute_type(#n2, :v3))")
+}
+
+func (s *ExpressionSuite) TestUpdateRender(c *check.C) {
+	update := expression.Delete(expression.Name("tags"), expression.Value([]string{"old"})).
+		Add(expression.Name("visits"), expression.Value(1)).
+		Remove(expression.Name("note")).
+		Set(expression.Name("events"), expression.Name("events").ListAppend(expression.Value([]dynamodb.Attribute{}))).
+		Set(expression.Name("created"), expression.Name("created").IfNotExists(expression.Value(100))).
+		Set(expression.Name("balance"), expression.Name("balance").Minus(expression.Value(5)))
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	c.Assert(err, check.IsNil)
+	c.Check(expr.Update(), check.Equals,
+		"SET #n3 = list_append(#n3, :v2), #n4 = if_not_exists(#n4, :v3), #n5 = #n5 - :v4 REMOVE #n2 ADD #n1 :v1 DELETE #n0 :v0")
+}
+
+func (s *ExpressionSuite) TestQuery(c *check.C) {
+	key := expression.Key("user").Equal(expression.Value("alice")).
+		And(expression.Key("seq").GreaterThanEqual(expression.Value(2)))
+	expr, err := expression.NewBuilder().WithKeyCondition(key).Build()
+	c.Assert(err, check.IsNil)
+	c.Check(kinds(s.query(c, expr)), check.DeepEquals, []string{"click", "logout"})
+
+	// The hash key condition is rendered first whatever the order.
+	key = expression.Key("seq").Between(expression.Value(1), expression.Value(2)).
+		And(expression.Key("user").Equal(expression.Value("alice")))
+	expr, err = expression.NewBuilder().
+		WithKeyCondition(key).
+		WithFilter(expression.Name("kind").BeginsWith("log")).
+		Build()
+	c.Assert(err, check.IsNil)
+	c.Check(expr.KeyCondition(), check.Equals, "(#n0 = :v0) AND (#n1 BETWEEN :v1 AND :v2)")
+	c.Check(kinds(s.query(c, expr)), check.DeepEquals, []string{"login"})
+}
+
+func (s *ExpressionSuite) TestUpdate(c *check.C) {
+	key := &dynamodb.Key{HashKey: "alice", RangeKey: "1"}
+	expr, err := expression.NewBuilder().
+		WithCondition(expression.Name("kind").Equal(expression.Value("login"))).
+		WithUpdate(expression.Set(expression.Name("kind"), expression.Value("signin")).
+			Set(expression.Name("count"), expression.Plus(expression.Name("count").IfNotExists(expression.Value(0)), expression.Value(1)))).
+		Build()
+	c.Assert(err, check.IsNil)
+	opts := &dynamodb.WriteOptions{}
+	expr.ApplyWrite(opts)
+	_, err = s.table.UpdateItemWithOptions(context.Background(), key, nil, "", opts)
+	c.Assert(err, check.IsNil)
+
+	item, err := s.table.GetItem(key, false)
+	c.Assert(err, check.IsNil)
+	c.Check(item["kind"].Value, check.Equals, "signin")
+
+	// The condition no longer holds.
+	_, err = s.table.UpdateItemWithOptions(context.Background(), key, nil, "", opts)
+	c.Check(dynamodb.IsConditionalCheckFailed(err), check.Equals, true)
+}
+
+func (s *ExpressionSuite) TestErrors(c *check.C) {
+	_, err := expression.NewBuilder().Build()
+	c.Check(err, check.ErrorMatches, "The expression is empty.")
+	_, err = expression.NewBuilder().WithCondition(expression.ConditionBuilder{}).Build()
+	c.Check(err, check.ErrorMatches, "The condition is empty.")
+	_, err = expression.NewBuilder().WithUpdate(expression.UpdateBuilder{}).Build()
+	c.Check(err, check.ErrorMatches, "The update is empty.")
+	_, err = expression.NewBuilder().WithCondition(expression.Name("a..b").AttributeExists()).Build()
+	c.Check(err, check.ErrorMatches, `Invalid document path "a..b".`)
+	_, err = expression.NewBuilder().WithCondition(expression.Name("a").Equal(expression.Value(map[string]int{}))).Build()
+	c.Check(err, check.ErrorMatches, "UnsupportedTypeError.*")
+
+	for _, t := range []struct {
+		key expression.KeyConditionBuilder
+		msg string
+	}{
+		{expression.KeyConditionBuilder{}, "The key condition is empty."},
+		{expression.Key("seq").LessThan(expression.Value(1)), "The key condition on seq must be an equality, the hash key is required."},
+		{expression.Key("user").Equal(expression.Value("a")).And(expression.Key("user").Equal(expression.Value("b"))), "The key condition has two conditions on user."},
+		{expression.Key("a").BeginsWith("x").And(expression.Key("b").LessThan(expression.Value(1))), "The key condition has no equality, the hash key is required."},
+	} {
+		_, err = expression.NewBuilder().WithKeyCondition(t.key).Build()
+		c.Check(err, check.ErrorMatches, t.msg)
+	}
+}
@@ -0,0 +1,96 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bluele/dynamodb"
+)
+
+// KeyBuilder is a hash or range key attribute.
+type KeyBuilder struct {
+	name string
+}
+
+func Key(name string) KeyBuilder {
+	return KeyBuilder{name: name}
+}
+
+// KeyConditionBuilder is a KeyConditionExpression: an equality on the
+// hash key, optionally combined with a condition on the range key.
+type KeyConditionBuilder struct {
+	conditions []keyCondition
+}
+
+type keyCondition struct {
+	name   string
+	equal  bool
+	render func(p *dynamodb.Placeholders) string
+}
+
+func (k KeyBuilder) condition(equal bool, c ConditionBuilder) KeyConditionBuilder {
+	return KeyConditionBuilder{conditions: []keyCondition{{k.name, equal, c.render}}}
+}
+
+func (k KeyBuilder) key() NameBuilder {
+	return NameBuilder{path: k.name}
+}
+
+func (k KeyBuilder) Equal(v ValueBuilder) KeyConditionBuilder {
+	return k.condition(true, Equal(k.key(), v))
+}
+
+func (k KeyBuilder) LessThan(v ValueBuilder) KeyConditionBuilder {
+	return k.condition(false, LessThan(k.key(), v))
+}
+
+func (k KeyBuilder) LessThanEqual(v ValueBuilder) KeyConditionBuilder {
+	return k.condition(false, LessThanEqual(k.key(), v))
+}
+
+func (k KeyBuilder) GreaterThan(v ValueBuilder) KeyConditionBuilder {
+	return k.condition(false, GreaterThan(k.key(), v))
+}
+
+func (k KeyBuilder) GreaterThanEqual(v ValueBuilder) KeyConditionBuilder {
+	return k.condition(false, GreaterThanEqual(k.key(), v))
+}
+
+func (k KeyBuilder) Between(low, high ValueBuilder) KeyConditionBuilder {
+	return k.condition(false, Between(k.key(), low, high))
+}
+
+func (k KeyBuilder) BeginsWith(prefix string) KeyConditionBuilder {
+	return k.condition(false, BeginsWith(k.key(), prefix))
+}
+
+// And combines a hash key and a range key condition.
+func (k KeyConditionBuilder) And(right KeyConditionBuilder) KeyConditionBuilder {
+	conditions := append([]keyCondition{}, k.conditions...)
+	return KeyConditionBuilder{conditions: append(conditions, right.conditions...)}
+}
+
+func (k KeyConditionBuilder) build(p *dynamodb.Placeholders) (string, error) {
+	switch len(k.conditions) {
+	case 0:
+		return "", errors.New("The key condition is empty.")
+	case 1:
+		if !k.conditions[0].equal {
+			return "", fmt.Errorf("The key condition on %s must be an equality, the hash key is required.", k.conditions[0].name)
+		}
+		return k.conditions[0].render(p), nil
+	case 2:
+		first, second := k.conditions[0], k.conditions[1]
+		if first.name == second.name {
+			return "", fmt.Errorf("The key condition has two conditions on %s.", first.name)
+		}
+		if !first.equal && !second.equal {
+			return "", errors.New("The key condition has no equality, the hash key is required.")
+		}
+		if !first.equal {
+			first, second = second, first
+		}
+		return "(" + first.render(p) + ") AND (" + second.render(p) + ")", nil
+	}
+	return "", fmt.Errorf("The key condition has %d conditions, at most 2 are allowed.", len(k.conditions))
+}
@@ -0,0 +1,35 @@
+package expression
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bluele/dynamodb"
+)
+
+// ProjectionBuilder is a ProjectionExpression, the attributes a read
+// returns.
+type ProjectionBuilder struct {
+	names []NameBuilder
+}
+
+func NamesList(first NameBuilder, others ...NameBuilder) ProjectionBuilder {
+	return ProjectionBuilder{names: append([]NameBuilder{first}, others...)}
+}
+
+// AddNames returns the projection with the names added.
+func (pb ProjectionBuilder) AddNames(names ...NameBuilder) ProjectionBuilder {
+	all := append([]NameBuilder{}, pb.names...)
+	return ProjectionBuilder{names: append(all, names...)}
+}
+
+func (pb ProjectionBuilder) build(p *dynamodb.Placeholders) (string, error) {
+	if len(pb.names) == 0 {
+		return "", errors.New("The projection is empty.")
+	}
+	paths := make([]string, len(pb.names))
+	for i, n := range pb.names {
+		paths[i] = n.operand(p)
+	}
+	return strings.Join(paths, ", "), nil
+}
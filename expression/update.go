@@ -0,0 +1,131 @@
+package expression
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bluele/dynamodb"
+)
+
+// SetValueBuilder is the right hand side of a SET action.
+type SetValueBuilder struct {
+	render func(p *dynamodb.Placeholders) string
+}
+
+func (s SetValueBuilder) operand(p *dynamodb.Placeholders) string {
+	return s.render(p)
+}
+
+func arithmetic(left OperandBuilder, op string, right OperandBuilder) SetValueBuilder {
+	return SetValueBuilder{func(p *dynamodb.Placeholders) string {
+		return left.operand(p) + " " + op + " " + right.operand(p)
+	}}
+}
+
+func updateFunction(name string, first, second OperandBuilder) SetValueBuilder {
+	return SetValueBuilder{func(p *dynamodb.Placeholders) string {
+		return name + "(" + first.operand(p) + ", " + second.operand(p) + ")"
+	}}
+}
+
+// Plus adds two numbers, as in SET #n = #n + :v.
+func Plus(left, right OperandBuilder) SetValueBuilder {
+	return arithmetic(left, "+", right)
+}
+
+func Minus(left, right OperandBuilder) SetValueBuilder {
+	return arithmetic(left, "-", right)
+}
+
+// ListAppend concatenates two lists.
+func ListAppend(first, second OperandBuilder) SetValueBuilder {
+	return updateFunction("list_append", first, second)
+}
+
+// IfNotExists is the attribute when it exists, fallback otherwise.
+func IfNotExists(n NameBuilder, fallback OperandBuilder) SetValueBuilder {
+	return updateFunction("if_not_exists", n, fallback)
+}
+
+func (n NameBuilder) Plus(right OperandBuilder) SetValueBuilder  { return Plus(n, right) }
+func (n NameBuilder) Minus(right OperandBuilder) SetValueBuilder { return Minus(n, right) }
+func (n NameBuilder) ListAppend(right OperandBuilder) SetValueBuilder {
+	return ListAppend(n, right)
+}
+func (n NameBuilder) IfNotExists(fallback OperandBuilder) SetValueBuilder {
+	return IfNotExists(n, fallback)
+}
+
+// UpdateBuilder is an UpdateExpression. Actions render in the order SET,
+// REMOVE, ADD, DELETE, each in the order they were added.
+type UpdateBuilder struct {
+	actions []updateAction
+}
+
+type updateAction struct {
+	clause int
+	render func(p *dynamodb.Placeholders) string
+}
+
+const (
+	clauseSet = iota
+	clauseRemove
+	clauseAdd
+	clauseDelete
+)
+
+var clauseNames = []string{"SET", "REMOVE", "ADD", "DELETE"}
+
+func (u UpdateBuilder) with(clause int, render func(p *dynamodb.Placeholders) string) UpdateBuilder {
+	actions := append([]updateAction{}, u.actions...)
+	return UpdateBuilder{actions: append(actions, updateAction{clause, render})}
+}
+
+// Set sets the attribute to v, a Name, a Value or a SetValueBuilder.
+func (u UpdateBuilder) Set(n NameBuilder, v OperandBuilder) UpdateBuilder {
+	return u.with(clauseSet, func(p *dynamodb.Placeholders) string {
+		return n.operand(p) + " = " + v.operand(p)
+	})
+}
+
+func (u UpdateBuilder) Remove(n NameBuilder) UpdateBuilder {
+	return u.with(clauseRemove, n.operand)
+}
+
+// Add adds a number to a number attribute, or members to a set attribute.
+func (u UpdateBuilder) Add(n NameBuilder, v ValueBuilder) UpdateBuilder {
+	return u.with(clauseAdd, func(p *dynamodb.Placeholders) string {
+		return n.operand(p) + " " + v.operand(p)
+	})
+}
+
+// Delete removes members from a set attribute.
+func (u UpdateBuilder) Delete(n NameBuilder, v ValueBuilder) UpdateBuilder {
+	return u.with(clauseDelete, func(p *dynamodb.Placeholders) string {
+		return n.operand(p) + " " + v.operand(p)
+	})
+}
+
+func Set(n NameBuilder, v OperandBuilder) UpdateBuilder { return UpdateBuilder{}.Set(n, v) }
+func Remove(n NameBuilder) UpdateBuilder                { return UpdateBuilder{}.Remove(n) }
+func Add(n NameBuilder, v ValueBuilder) UpdateBuilder   { return UpdateBuilder{}.Add(n, v) }
+func Delete(n NameBuilder, v ValueBuilder) UpdateBuilder {
+	return UpdateBuilder{}.Delete(n, v)
+}
+
+func (u UpdateBuilder) build(p *dynamodb.Placeholders) (string, error) {
+	if len(u.actions) == 0 {
+		return "", errors.New("The update is empty.")
+	}
+	clauses := make([][]string, len(clauseNames))
+	for _, a := range u.actions {
+		clauses[a.clause] = append(clauses[a.clause], a.render(p))
+	}
+	var parts []string
+	for i, actions := range clauses {
+		if len(actions) > 0 {
+			parts = append(parts, clauseNames[i]+" "+strings.Join(actions, ", "))
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
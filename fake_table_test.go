@@ -0,0 +1,41 @@
+package dynamodb_test
+
+import (
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+// idKey is the schema of the tables keyed by a string id.
+type idKey struct {
+	ID string `dynamodb:"id,hash"`
+}
+
+// userSeqKey is the schema of the tables keyed by user and sequence.
+type userSeqKey struct {
+	User string `dynamodb:"user,hash"`
+	Seq  int64  `dynamodb:"seq,range"`
+}
+
+// tableSchema returns the description of the table name whose attributes
+// are those of the struct schema.
+func tableSchema(c *check.C, name string, schema interface{}) dynamodb.TableDescriptionT {
+	d, err := dynamodb.TableSchemaFromStruct(schema, &dynamodb.SchemaOptions{TableName: name})
+	c.Assert(err, check.IsNil)
+	return d
+}
+
+// createTable creates the table d on server, usually backed by a fake.
+func createTable(c *check.C, server *dynamodb.Server, d dynamodb.TableDescriptionT) *dynamodb.Table {
+	_, err := server.CreateTable(d, false)
+	c.Assert(err, check.IsNil)
+	pk, err := d.BuildPrimaryKey()
+	c.Assert(err, check.IsNil)
+	return server.NewTable(d.TableName, pk)
+}
+
+// newFakeTable creates the table name of schema on a new fake server.
+func newFakeTable(c *check.C, name string, schema interface{}) *dynamodb.Table {
+	server, _ := dynamodbtest.NewServer()
+	return createTable(c, server, tableSchema(c, name, schema))
+}
@@ -0,0 +1,104 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+)
+
+// Maximum number of keys Dynamodb accepts in one BatchGetItem request.
+const maxBatchGetKeys = 100
+
+// UnprocessedKeysError is returned by GetFirstExisting and
+// BatchGetItem.Execute when Dynamodb left keys unprocessed after the retries allowed by the Server's RetryPolicy,
+// or when the context was done before they were re-requested.
+type UnprocessedKeysError struct {
+	Keys []Key
+	// The context error which stopped the retries, if any.
+	Err error
+}
+
+func (e *UnprocessedKeysError) Error() string {
+	message := fmt.Sprintf("%d keys were left unprocessed.", len(e.Keys))
+	if e.Err != nil {
+		message += " " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *UnprocessedKeysError) Unwrap() error {
+	return e.Err
+}
+
+// GetFirstExisting returns the first item, in the order of keys, which
+// exists in the table along with its index in keys. This is handy for
+// fallback lookups such as user, then group, then global settings.
+// Keys are fetched with BatchGetItem, up to 100 at a time, and later
+// batches are only requested when no key of the earlier ones exists.
+// Numeric keys are compared by value, and keys repeated in a batch are
+// requested once. ErrNotFound is returned when none of the keys exist.
+func (t *Table) GetFirstExisting(keys []Key, isRetry bool) (map[string]*Attribute, int, error) {
+	return t.GetFirstExistingContext(context.Background(), keys, isRetry)
+}
+
+// GetFirstExistingContext is GetFirstExisting with a context, which stops
+// the requests and the retries of unprocessed keys when done.
+func (t *Table) GetFirstExistingContext(ctx context.Context, keys []Key, isRetry bool) (map[string]*Attribute, int, error) {
+	for start := 0; start < len(keys); start += maxBatchGetKeys {
+		end := start + maxBatchGetKeys
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		found, err := t.batchGetKeys(ctx, keys[start:end], isRetry)
+		if err != nil {
+			return nil, -1, err
+		}
+		for i := start; i < end; i++ {
+			if item, ok := found[t.canonicalKey(keys[i])]; ok {
+				return item, i, nil
+			}
+		}
+	}
+	return nil, -1, ErrNotFound
+}
+
+// canonicalKey returns key with its numeric values in canonical form, so
+// that keys such as "1" and "1.0", which designate the same item, are equal.
+func (t *Table) canonicalKey(key Key) Key {
+	if t.Key.KeyAttribute != nil && t.Key.KeyAttribute.Type == TYPE_NUMBER {
+		key.HashKey = canonicalNumber(key.HashKey)
+	}
+	if t.Key.HasRange() && t.Key.RangeAttribute.Type == TYPE_NUMBER && key.RangeKey != "" {
+		key.RangeKey = canonicalNumber(key.RangeKey)
+	}
+	return key
+}
+
+// batchGetKeys fetches keys (at most 100) from the table with batchGet.
+// The items found are indexed by their canonical key.
+func (t *Table) batchGetKeys(ctx context.Context, keys []Key, isRetry bool) (map[Key]map[string]*Attribute, error) {
+	found := make(map[Key]map[string]*Attribute, len(keys))
+
+	// Dynamodb rejects a request holding the same key twice.
+	var pending []Key
+	requested := make(map[Key]bool, len(keys))
+	for _, key := range keys {
+		if canonical := t.canonicalKey(key); !requested[canonical] {
+			requested[canonical] = true
+			pending = append(pending, key)
+		}
+	}
+
+	err := t.Server.batchGet(ctx, map[*Table][]Key{t: pending}, isRetry, func(t *Table, item map[string]*Attribute) error {
+		key, err := t.KeyFromItem(item)
+		if err != nil {
+			return err
+		}
+		found[t.canonicalKey(*key)] = item
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
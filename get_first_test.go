@@ -0,0 +1,102 @@
+package dynamodb_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type GetFirstSuite struct {
+	server *dynamodb.Server
+	table  *dynamodb.Table
+
+	requested   [][]json.RawMessage // keys of each BatchGetItem
+	unprocessed bool                // leave every key unprocessed
+}
+
+var _ = check.Suite(&GetFirstSuite{})
+
+func (s *GetFirstSuite) SetUpTest(c *check.C) {
+	s.requested, s.unprocessed = nil, false
+	s.server, _ = dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation != "BatchGetItem" {
+				return next(req)
+			}
+			var body struct {
+				RequestItems map[string]struct {
+					Keys []json.RawMessage
+				}
+			}
+			c.Assert(json.Unmarshal(req.Body, &body), check.IsNil)
+			keys := body.RequestItems["settings"].Keys
+			s.requested = append(s.requested, keys)
+			if s.unprocessed {
+				raw, _ := json.Marshal(map[string]interface{}{"settings": map[string]interface{}{"Keys": keys}})
+				return []byte(`{"Responses":{},"UnprocessedKeys":` + string(raw) + `}`), nil
+			}
+			return next(req)
+		}
+	})
+
+	s.table = createTable(c, s.server, tableSchema(c, "settings", struct {
+		Level int `dynamodb:"level,hash"`
+	}{}))
+
+	_, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("2", ""), *dynamodb.NewStringAttribute("theme", "dark")), nil)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *GetFirstSuite) TestFirstExisting(c *check.C) {
+	item, i, err := s.table.GetFirstExisting([]dynamodb.Key{{HashKey: "1"}, {HashKey: "2"}, {HashKey: "3"}}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(i, check.Equals, 1)
+	c.Check(item["theme"].Value, check.Equals, "dark")
+
+	_, i, err = s.table.GetFirstExisting([]dynamodb.Key{{HashKey: "1"}, {HashKey: "3"}}, false)
+	c.Check(err, check.Equals, dynamodb.ErrNotFound)
+	c.Check(i, check.Equals, -1)
+}
+
+func (s *GetFirstSuite) TestNumericKeys(c *check.C) {
+	item, i, err := s.table.GetFirstExisting([]dynamodb.Key{{HashKey: "1.0"}, {HashKey: "2.0"}}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(i, check.Equals, 1)
+	c.Check(item["theme"].Value, check.Equals, "dark")
+}
+
+func (s *GetFirstSuite) TestDuplicateKeys(c *check.C) {
+	_, i, err := s.table.GetFirstExisting([]dynamodb.Key{{HashKey: "1"}, {HashKey: "1.0"}, {HashKey: "1"}, {HashKey: "2"}}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(i, check.Equals, 3)
+	c.Assert(s.requested, check.HasLen, 1)
+	c.Check(s.requested[0], check.HasLen, 2)
+}
+
+func (s *GetFirstSuite) TestUnprocessedKeysAreBounded(c *check.C) {
+	s.server.RetryPolicy = &dynamodb.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	s.unprocessed = true
+
+	_, _, err := s.table.GetFirstExisting([]dynamodb.Key{{HashKey: "1"}, {HashKey: "2"}}, false)
+	var unprocessed *dynamodb.UnprocessedKeysError
+	c.Assert(errors.As(err, &unprocessed), check.Equals, true)
+	c.Check(unprocessed.Keys, check.DeepEquals, []dynamodb.Key{{HashKey: "1"}, {HashKey: "2"}})
+	c.Check(err, check.ErrorMatches, "2 keys were left unprocessed.")
+	c.Check(s.requested, check.HasLen, 3)
+}
+
+func (s *GetFirstSuite) TestUnprocessedKeysAreCancelled(c *check.C) {
+	s.server.RetryPolicy = &dynamodb.RetryPolicy{BaseDelay: time.Hour, MaxDelay: time.Hour}
+	s.unprocessed = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, err := s.table.GetFirstExistingContext(ctx, []dynamodb.Key{{HashKey: "1"}}, false)
+	c.Check(errors.Is(err, context.DeadlineExceeded), check.Equals, true)
+	c.Check(s.requested, check.HasLen, 1)
+}
@@ -0,0 +1,63 @@
+package dynamodb
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	REPLICA_STATUS_CREATING = "CREATING"
+	REPLICA_STATUS_UPDATING = "UPDATING"
+	REPLICA_STATUS_DELETING = "DELETING"
+	REPLICA_STATUS_ACTIVE   = "ACTIVE"
+
+	REPLICA_STATUS_CREATION_FAILED = "CREATION_FAILED"
+)
+
+// AddReplica turns the table into a global table (version 2019.11.21) or
+// adds a region to it. The table must have streams enabled with
+// NEW_AND_OLD_IMAGES. Use WaitUntilReplicaActive to wait for the replica.
+func (t *Table) AddReplica(regionName string, isRetry bool) (*TableDescriptionT, error) {
+	q := NewQuery(t)
+	q.AddReplicaUpdate("Create", regionName)
+	return t.Server.updateTable(q, isRetry)
+}
+
+// RemoveReplica deletes the table's replica in regionName.
+func (t *Table) RemoveReplica(regionName string, isRetry bool) (*TableDescriptionT, error) {
+	q := NewQuery(t)
+	q.AddReplicaUpdate("Delete", regionName)
+	return t.Server.updateTable(q, isRetry)
+}
+
+// Replica returns the description of the replica in regionName, or nil.
+func (t *TableDescriptionT) Replica(regionName string) *ReplicaDescriptionT {
+	for i := range t.Replicas {
+		if t.Replicas[i].RegionName == regionName {
+			return &t.Replicas[i]
+		}
+	}
+	return nil
+}
+
+// WaitUntilReplicaActive waits for the replica in regionName to become
+// ACTIVE and for the table itself to leave the UPDATING status.
+func (t *Table) WaitUntilReplicaActive(regionName string, timeout time.Duration) error {
+	return t.WaitUntil(timeout, func(desc *TableDescriptionT) (bool, error) {
+		r := desc.Replica(regionName)
+		if r == nil {
+			return false, nil
+		}
+		if r.ReplicaStatus == REPLICA_STATUS_CREATION_FAILED {
+			return false, errors.New("Replica creation failed: " + r.ReplicaStatusDescription)
+		}
+		return r.ReplicaStatus == REPLICA_STATUS_ACTIVE && desc.TableStatus == "ACTIVE", nil
+	})
+}
+
+// WaitUntilReplicaDeleted waits for the replica in regionName to disappear.
+func (t *Table) WaitUntilReplicaDeleted(regionName string, timeout time.Duration) error {
+	return t.WaitUntil(timeout, func(desc *TableDescriptionT) (bool, error) {
+		return desc.Replica(regionName) == nil, nil
+	})
+}
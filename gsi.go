@@ -0,0 +1,115 @@
+package dynamodb
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	INDEX_STATUS_CREATING = "CREATING"
+	INDEX_STATUS_UPDATING = "UPDATING"
+	INDEX_STATUS_DELETING = "DELETING"
+	INDEX_STATUS_ACTIVE   = "ACTIVE"
+)
+
+// GlobalSecondaryIndexSpec describes a global secondary index to add to an
+// existing table.
+type GlobalSecondaryIndexSpec struct {
+	IndexName string
+	HashKey   AttributeDefinitionT
+	// Optional range key of the index.
+	RangeKey *AttributeDefinitionT
+	// ALL when zero.
+	Projection ProjectionT
+	// Ignored for tables with on demand billing.
+	ProvisionedThroughput ProvisionedThroughputT
+}
+
+// CreateGSI adds a global secondary index to the table. Dynamodb backfills
+// the index from the existing items in the background; use
+// WaitUntilIndexActive to wait for the index to be usable. Like
+// UpdateThroughput, it waits for the table to be ACTIVE first and is a
+// no-op when the index already exists.
+func (t *Table) CreateGSI(spec GlobalSecondaryIndexSpec) (*TableDescriptionT, error) {
+	return t.CreateGSIContext(context.Background(), spec)
+}
+
+// CreateGSIContext is CreateGSI with a context, which stops the wait for
+// the table and the requests when done.
+func (t *Table) CreateGSIContext(ctx context.Context, spec GlobalSecondaryIndexSpec) (*TableDescriptionT, error) {
+	return t.updateWhenActive(ctx, func(desc *TableDescriptionT) *Query {
+		if desc.GlobalSecondaryIndex(spec.IndexName) != nil {
+			return nil
+		}
+
+		index := GlobalSecondaryIndexT{
+			IndexName:             spec.IndexName,
+			KeySchema:             []KeySchemaT{{spec.HashKey.Name, "HASH"}},
+			Projection:            spec.Projection,
+			ProvisionedThroughput: spec.ProvisionedThroughput,
+		}
+		attributes := []AttributeDefinitionT{spec.HashKey}
+		if spec.RangeKey != nil {
+			index.KeySchema = append(index.KeySchema, KeySchemaT{spec.RangeKey.Name, "RANGE"})
+			attributes = append(attributes, *spec.RangeKey)
+		}
+		if index.Projection.ProjectionType == "" {
+			index.Projection.ProjectionType = "ALL"
+		}
+
+		q := NewQuery(t)
+		q.AddAttributeDefinitions(attributes)
+		q.AddGlobalSecondaryIndexCreate(index, desc.BillingMode() == BILLING_MODE_PROVISIONED)
+		return q
+	})
+}
+
+// DeleteGSI removes a global secondary index from the table. It waits for
+// the table to be ACTIVE first and is a no-op when there is no such index.
+// Use WaitUntilIndexDeleted to wait for the deletion to complete.
+func (t *Table) DeleteGSI(name string) (*TableDescriptionT, error) {
+	return t.DeleteGSIContext(context.Background(), name)
+}
+
+// DeleteGSIContext is DeleteGSI with a context, which stops the wait for
+// the table and the requests when done.
+func (t *Table) DeleteGSIContext(ctx context.Context, name string) (*TableDescriptionT, error) {
+	return t.updateWhenActive(ctx, func(desc *TableDescriptionT) *Query {
+		if desc.GlobalSecondaryIndex(name) == nil {
+			return nil
+		}
+
+		q := NewQuery(t)
+		q.AddGlobalSecondaryIndexDelete(name)
+		return q
+	})
+}
+
+// WaitUntilIndexActive waits for the named global secondary index to be
+// ACTIVE and done backfilling.
+func (t *Table) WaitUntilIndexActive(name string, timeout time.Duration) error {
+	return t.WaitUntilIndexActiveContext(context.Background(), name, timeout)
+}
+
+// WaitUntilIndexActiveContext is WaitUntilIndexActive with a context, whose
+// error is returned when it is done first.
+func (t *Table) WaitUntilIndexActiveContext(ctx context.Context, name string, timeout time.Duration) error {
+	return t.WaitUntilContext(ctx, timeout, func(desc *TableDescriptionT) (bool, error) {
+		index := desc.GlobalSecondaryIndex(name)
+		return index != nil && index.IndexStatus == INDEX_STATUS_ACTIVE && !index.Backfilling, nil
+	})
+}
+
+// WaitUntilIndexDeleted waits for the named global secondary index to
+// disappear.
+func (t *Table) WaitUntilIndexDeleted(name string, timeout time.Duration) error {
+	return t.WaitUntilIndexDeletedContext(context.Background(), name, timeout)
+}
+
+// WaitUntilIndexDeletedContext is WaitUntilIndexDeleted with a context,
+// whose error is returned when it is done first.
+func (t *Table) WaitUntilIndexDeletedContext(ctx context.Context, name string, timeout time.Duration) error {
+	return t.WaitUntilContext(ctx, timeout, func(desc *TableDescriptionT) (bool, error) {
+		return desc.GlobalSecondaryIndex(name) == nil, nil
+	})
+}
@@ -0,0 +1,67 @@
+package dynamodb_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type GSISuite struct {
+	table *dynamodb.Table
+}
+
+var _ = check.Suite(&GSISuite{})
+
+func (s *GSISuite) SetUpTest(c *check.C) {
+	s.table = newFakeTable(c, "users", idKey{})
+
+	for id, email := range map[string]string{"u1": "a@example.com", "u2": "b@example.com"} {
+		_, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone(id, ""), *dynamodb.NewStringAttribute("email", email)), nil)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *GSISuite) TestCreateAndDeleteGSI(c *check.C) {
+	spec := dynamodb.GlobalSecondaryIndexSpec{
+		IndexName: "by-email",
+		HashKey:   dynamodb.AttributeDefinitionT{Name: "email", Type: "S"},
+	}
+	desc, err := s.table.CreateGSI(spec)
+	c.Assert(err, check.IsNil)
+	index := desc.GlobalSecondaryIndex("by-email")
+	c.Assert(index, check.NotNil)
+	c.Check(index.Projection.ProjectionType, check.Equals, "ALL")
+	c.Check(s.table.WaitUntilIndexActive("by-email", time.Second), check.IsNil)
+
+	// Creating it again is a no-op.
+	_, err = s.table.CreateGSI(spec)
+	c.Assert(err, check.IsNil)
+
+	items, _, err := s.table.QueryWithOptions(context.Background(), []dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("email", "b@example.com"),
+	}, &dynamodb.QueryOptions{IndexName: "by-email"})
+	c.Assert(err, check.IsNil)
+	c.Assert(items, check.HasLen, 1)
+	c.Check(items[0]["id"].Value, check.Equals, "u2")
+
+	desc, err = s.table.DeleteGSI("by-email")
+	c.Assert(err, check.IsNil)
+	c.Check(desc.GlobalSecondaryIndex("by-email"), check.IsNil)
+	c.Check(s.table.WaitUntilIndexDeleted("by-email", time.Second), check.IsNil)
+
+	_, err = s.table.DeleteGSI("by-email")
+	c.Check(err, check.IsNil)
+}
+
+func (s *GSISuite) TestAddGlobalSecondaryIndexCreate(c *check.C) {
+	q := dynamodb.NewEmptyQuery()
+	q.AddGlobalSecondaryIndexCreate(dynamodb.GlobalSecondaryIndexT{
+		IndexName:             "by-email",
+		KeySchema:             []dynamodb.KeySchemaT{{AttributeName: "email", KeyType: "HASH"}},
+		Projection:            dynamodb.ProjectionT{ProjectionType: "KEYS_ONLY"},
+		ProvisionedThroughput: dynamodb.ProvisionedThroughputT{ReadCapacityUnits: 2, WriteCapacityUnits: 1},
+	}, true)
+	c.Check(q.String(), check.Equals, `{"GlobalSecondaryIndexUpdates":[{"Create":{"IndexName":"by-email","KeySchema":[{"AttributeName":"email","KeyType":"HASH"}],"Projection":{"ProjectionType":"KEYS_ONLY","NonKeyAttributes":null},"ProvisionedThroughput":{"ReadCapacityUnits":2,"WriteCapacityUnits":1}}}]}`)
+}
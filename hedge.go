@@ -0,0 +1,142 @@
+package dynamodb
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Number of recent latencies a Hedger keeps per operation, and how many it
+// needs before using Percentile.
+const (
+	hedgeSamples    = 256
+	minHedgeSamples = 20
+)
+
+// A Hedger reduces the tail latency of GetItem and Query calls: when a
+// call has not returned after a delay, it sends the same request again and
+// returns whichever response arrives first, cancelling the other request.
+// Hedged calls cost the capacity of two when both reach Dynamodb, so keep
+// the delay at a high percentile. Install it with
+// Server.Use(h.Middleware()); middleware installed after it sees every
+// request sent.
+type Hedger struct {
+	// When positive, the delay is this percentile, between 0 and 1, of the
+	// latencies of the recent successful calls of the same operation,
+	// e.g. 0.95.
+	Percentile float64
+	// The delay until enough calls were made to compute Percentile, or
+	// always when Percentile is zero. With a zero Delay, calls are not
+	// hedged until Percentile can be computed, and never without one.
+	Delay time.Duration
+
+	mu        sync.Mutex
+	latencies map[string]*latencyWindow
+}
+
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+func NewHedger(percentile float64, delay time.Duration) *Hedger {
+	return &Hedger{Percentile: percentile, Delay: delay}
+}
+
+// Middleware returns the middleware hedging the calls.
+func (h *Hedger) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) ([]byte, error) {
+			if req.Operation != "GetItem" && req.Operation != "Query" {
+				return next(req)
+			}
+			delay := h.delay(req.Operation)
+			if delay <= 0 {
+				started := time.Now()
+				response, err := next(req)
+				if err == nil && h.Percentile > 0 {
+					h.observe(req.Operation, time.Since(started))
+				}
+				return response, err
+			}
+
+			ctx, cancel := context.WithCancel(req.Context)
+			defer cancel()
+			type outcome struct {
+				response []byte
+				err      error
+				latency  time.Duration
+			}
+			outcomes := make(chan outcome, 2)
+			send := func() {
+				attempt := *req
+				attempt.Context = ctx
+				started := time.Now()
+				response, err := next(&attempt)
+				outcomes <- outcome{response, err, time.Since(started)}
+			}
+
+			go send()
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			pending, hedged := 1, false
+			for {
+				select {
+				case o := <-outcomes:
+					pending--
+					// Wait for the hedge when the first attempt to return
+					// failed.
+					if o.err == nil || pending == 0 {
+						if o.err == nil && h.Percentile > 0 {
+							h.observe(req.Operation, o.latency)
+						}
+						return o.response, o.err
+					}
+				case <-timer.C:
+					if !hedged {
+						hedged = true
+						pending++
+						go send()
+					}
+				}
+			}
+		}
+	}
+}
+
+// delay returns how long to wait before hedging a call of operation.
+func (h *Hedger) delay(operation string) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	w := h.latencies[operation]
+	if h.Percentile <= 0 || w == nil || len(w.samples) < minHedgeSamples {
+		return h.Delay
+	}
+	sorted := append([]time.Duration(nil), w.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	i := int(h.Percentile * float64(len(sorted)))
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+func (h *Hedger) observe(operation string, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.latencies == nil {
+		h.latencies = make(map[string]*latencyWindow)
+	}
+	w := h.latencies[operation]
+	if w == nil {
+		w = &latencyWindow{}
+		h.latencies[operation] = w
+	}
+	if len(w.samples) < hedgeSamples {
+		w.samples = append(w.samples, latency)
+		return
+	}
+	w.samples[w.next] = latency
+	w.next = (w.next + 1) % hedgeSamples
+}
@@ -0,0 +1,145 @@
+package dynamodb_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type HedgeSuite struct {
+	table  *dynamodb.Table
+	hedger *dynamodb.Hedger
+
+	mu        sync.Mutex
+	sent      int
+	slow      map[int]bool // numbers of the requests which hang
+	cancelled int
+}
+
+var _ = check.Suite(&HedgeSuite{})
+
+func (s *HedgeSuite) SetUpTest(c *check.C) {
+	s.mu.Lock()
+	s.sent, s.slow, s.cancelled = 0, map[int]bool{}, 0
+	s.mu.Unlock()
+	s.hedger = dynamodb.NewHedger(0, 20*time.Millisecond)
+	server, _ := dynamodbtest.NewServer(s.hedger.Middleware(), func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation != "GetItem" && req.Operation != "Query" {
+				return next(req)
+			}
+			s.mu.Lock()
+			s.sent++
+			slow := s.slow[s.sent]
+			s.mu.Unlock()
+			if slow {
+				select {
+				case <-time.After(time.Second):
+				case <-req.Context.Done():
+					s.mu.Lock()
+					s.cancelled++
+					s.mu.Unlock()
+					return nil, req.Context.Err()
+				}
+			}
+			return next(req)
+		}
+	})
+
+	s.table = createTable(c, server, tableSchema(c, "users", idKey{}))
+
+	_, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("u1", ""), *dynamodb.NewStringAttribute("name", "Alice")), nil)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *HedgeSuite) counts() (sent, cancelled int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sent, s.cancelled
+}
+
+// waitCancelled waits for the loser of a hedged call, cancelled as the
+// call returns.
+func (s *HedgeSuite) waitCancelled(c *check.C) {
+	for i := 0; i < 100; i++ {
+		if _, cancelled := s.counts(); cancelled > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	sent, cancelled := s.counts()
+	c.Check(sent, check.Equals, 2)
+	c.Check(cancelled, check.Equals, 1)
+}
+
+func (s *HedgeSuite) TestFastCallIsNotHedged(c *check.C) {
+	_, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Assert(err, check.IsNil)
+	sent, _ := s.counts()
+	c.Check(sent, check.Equals, 1)
+}
+
+func (s *HedgeSuite) TestSlowCallIsHedged(c *check.C) {
+	s.slow[1] = true
+	started := time.Now()
+	item, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(item["name"].Value, check.Equals, "Alice")
+	c.Check(time.Since(started) < 500*time.Millisecond, check.Equals, true)
+
+	s.waitCancelled(c)
+}
+
+func (s *HedgeSuite) TestQuery(c *check.C) {
+	s.slow[1] = true
+	items, _, err := s.table.QueryWithOptions(context.Background(), []dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("id", "u1"),
+	}, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 1)
+	s.waitCancelled(c)
+}
+
+func (s *HedgeSuite) TestPercentile(c *check.C) {
+	s.hedger.Percentile = 0.9
+	s.hedger.Delay = time.Hour
+	for i := 0; i < 20; i++ {
+		_, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+		c.Assert(err, check.IsNil)
+	}
+	// The recent calls were fast, so the slow one is hedged well before
+	// Delay.
+	s.mu.Lock()
+	s.slow[21] = true
+	s.mu.Unlock()
+	started := time.Now()
+	_, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(time.Since(started) < 500*time.Millisecond, check.Equals, true)
+	sent, _ := s.counts()
+	c.Check(sent, check.Equals, 22)
+}
+
+func (s *HedgeSuite) TestPercentileWithoutDelay(c *check.C) {
+	// Calls are not hedged until the percentile can be computed, but
+	// their latencies are recorded meanwhile.
+	s.hedger.Percentile = 0.9
+	s.hedger.Delay = 0
+	for i := 0; i < 20; i++ {
+		_, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+		c.Assert(err, check.IsNil)
+	}
+	s.mu.Lock()
+	s.slow[21] = true
+	s.mu.Unlock()
+	started := time.Now()
+	_, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(time.Since(started) < 500*time.Millisecond, check.Equals, true)
+	sent, _ := s.counts()
+	c.Check(sent, check.Equals, 22)
+}
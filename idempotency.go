@@ -0,0 +1,51 @@
+package dynamodb
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Idempotent reports whether op takes a ClientRequestToken, making
+// requests sent again with the same token within 10 minutes return the
+// outcome of the first one instead of being applied again.
+func (op Operation) Idempotent() bool {
+	switch op {
+	case OPERATION_TRANSACT_WRITE_ITEMS, OPERATION_EXECUTE_TRANSACTION:
+		return true
+	}
+	return false
+}
+
+// NewClientRequestToken returns a random token for Idempotent operations,
+// a version 4 UUID. Reuse it when sending the same request again in a
+// later call, e.g. after a restart; retries within a call reuse the token
+// on their own.
+func NewClientRequestToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// withClientRequestToken adds a new ClientRequestToken to payload unless it
+// has one.
+func withClientRequestToken(payload []byte) ([]byte, error) {
+	var request map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &request); err != nil {
+		return nil, err
+	}
+	if _, ok := request["ClientRequestToken"]; ok {
+		return payload, nil
+	}
+	token, err := NewClientRequestToken()
+	if err != nil {
+		return nil, err
+	}
+	request["ClientRequestToken"], _ = json.Marshal(token)
+	return json.Marshal(request)
+}
@@ -0,0 +1,80 @@
+package dynamodb_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type IdempotencySuite struct{}
+
+var _ = check.Suite(&IdempotencySuite{})
+
+func (s *IdempotencySuite) TestTokenIsReusedAcrossRetries(c *check.C) {
+	var tokens []string
+	h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var request struct{ ClientRequestToken string }
+		c.Check(json.Unmarshal(body, &request), check.IsNil)
+		tokens = append(tokens, request.ClientRequestToken)
+		if len(tokens) == 1 {
+			w.WriteHeader(500)
+			w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#InternalServerError","message":"oops"}`))
+			return
+		}
+		if len(tokens) == 2 {
+			w.WriteHeader(400)
+			w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#TransactionInProgressException","message":"in progress"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer h.Close()
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: h.URL})
+	server.Logger = dynamodb.NopLogger
+	server.RetryPolicy = &dynamodb.RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+	_, err := server.Do(dynamodb.OPERATION_TRANSACT_WRITE_ITEMS, []byte(`{"TransactItems":[]}`))
+	c.Assert(err, check.IsNil)
+	c.Assert(tokens, check.HasLen, 3)
+	c.Check(tokens[0], check.Matches, "[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}")
+	c.Check(tokens[1], check.Equals, tokens[0])
+	c.Check(tokens[2], check.Equals, tokens[0])
+
+	// Each call gets its own token, unless given one.
+	tokens = tokens[:2]
+	_, err = server.Do(dynamodb.OPERATION_TRANSACT_WRITE_ITEMS, []byte(`{"TransactItems":[]}`))
+	c.Assert(err, check.IsNil)
+	c.Check(tokens[2], check.Not(check.Equals), tokens[0])
+	_, err = server.Do(dynamodb.OPERATION_TRANSACT_WRITE_ITEMS, []byte(`{"ClientRequestToken":"mine","TransactItems":[]}`))
+	c.Assert(err, check.IsNil)
+	c.Check(tokens[3], check.Equals, "mine")
+}
+
+func (s *IdempotencySuite) TestOtherOperations(c *check.C) {
+	var body string
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"})
+	server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			body = string(req.Body)
+			return []byte(`{}`), nil
+		}
+	})
+	_, err := server.Do(dynamodb.OPERATION_TRANSACT_GET_ITEMS, []byte(`{"TransactItems":[]}`))
+	c.Assert(err, check.IsNil)
+	c.Check(body, check.Equals, `{"TransactItems":[]}`)
+
+	_, err = server.Do(dynamodb.OPERATION_TRANSACT_WRITE_ITEMS, []byte(`not json`))
+	c.Check(err, check.NotNil)
+
+	token, err := dynamodb.NewClientRequestToken()
+	c.Assert(err, check.IsNil)
+	c.Check(regexp.MustCompile("^[0-9a-f-]{36}$").MatchString(token), check.Equals, true)
+}
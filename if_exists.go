@@ -0,0 +1,50 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrAlreadyExists is returned by PutIfNotExists when an item with the
+// same key exists.
+var ErrAlreadyExists = errors.New("Item already exists")
+
+// PutIfNotExists writes item, which must include the primary key
+// attributes, only if no item with its key exists; otherwise it returns
+// ErrAlreadyExists. The put is retried after server errors, as
+// PutItemWithOptions does with or without isRetry, so a put whose response
+// was lost may report ErrAlreadyExists for the item it wrote itself.
+func (t *Table) PutIfNotExists(item []Attribute, isRetry bool) error {
+	_, err := t.PutItemWithOptions(context.Background(), item, t.keyCondition("attribute_not_exists", isRetry))
+	if IsConditionalCheckFailed(err) {
+		return ErrAlreadyExists
+	}
+	return err
+}
+
+// DeleteIfExists deletes the item with the given key and returns it, or
+// returns ErrNotFound when there is no such item. With isRetry, a delete
+// retried after a response was lost may report ErrNotFound for the item
+// it deleted itself.
+func (t *Table) DeleteIfExists(key *Key, isRetry bool) (map[string]*Attribute, error) {
+	opts := t.keyCondition("attribute_exists", isRetry)
+	opts.ReturnValues = RETURN_VALUES_ALL_OLD
+	result, err := t.DeleteItemWithOptions(context.Background(), key, opts)
+	if IsConditionalCheckFailed(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.Attributes, nil
+}
+
+// keyCondition returns options conditioning a write on the hash key
+// attribute, which every item has, passed to function.
+func (t *Table) keyCondition(function string, isRetry bool) *WriteOptions {
+	return &WriteOptions{
+		ConditionExpression:      function + "(#key)",
+		ExpressionAttributeNames: map[string]string{"#key": t.Key.KeyAttribute.Name},
+		IsRetry:                  isRetry,
+	}
+}
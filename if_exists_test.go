@@ -0,0 +1,44 @@
+package dynamodb_test
+
+import (
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type IfExistsSuite struct {
+	table *dynamodb.Table
+}
+
+var _ = check.Suite(&IfExistsSuite{})
+
+func (s *IfExistsSuite) SetUpTest(c *check.C) {
+	s.table = newFakeTable(c, "events", userSeqKey{})
+}
+
+func (s *IfExistsSuite) item(seq, kind string) []dynamodb.Attribute {
+	return append(s.table.Key.Clone("alice", seq), *dynamodb.NewStringAttribute("kind", kind))
+}
+
+func (s *IfExistsSuite) TestPutIfNotExists(c *check.C) {
+	c.Assert(s.table.PutIfNotExists(s.item("1", "login"), false), check.IsNil)
+	c.Check(s.table.PutIfNotExists(s.item("1", "logout"), false), check.Equals, dynamodb.ErrAlreadyExists)
+	// Same hash key, other range key.
+	c.Check(s.table.PutIfNotExists(s.item("2", "logout"), false), check.IsNil)
+
+	item, err := s.table.GetItem(&dynamodb.Key{HashKey: "alice", RangeKey: "1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(item["kind"].Value, check.Equals, "login")
+}
+
+func (s *IfExistsSuite) TestDeleteIfExists(c *check.C) {
+	c.Assert(s.table.PutIfNotExists(s.item("1", "login"), false), check.IsNil)
+	key := &dynamodb.Key{HashKey: "alice", RangeKey: "1"}
+
+	old, err := s.table.DeleteIfExists(key, false)
+	c.Assert(err, check.IsNil)
+	c.Check(old["kind"].Value, check.Equals, "login")
+
+	_, err = s.table.DeleteIfExists(key, false)
+	c.Check(err, check.Equals, dynamodb.ErrNotFound)
+	c.Check(dynamodb.IsNotFound(err), check.Equals, true)
+}
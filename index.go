@@ -0,0 +1,117 @@
+package dynamodb
+
+import (
+	"context"
+)
+
+// IndexT describes a secondary index of a Table. Key is the key of the
+// index; the hash key of a local index is the one of its table.
+type IndexT struct {
+	IndexName  string
+	Local      bool
+	Key        PrimaryKey
+	Projection ProjectionT
+}
+
+// Index is a handle on a secondary index of a Table, whose queries set
+// IndexName and are checked against the key of the index before being
+// sent.
+type Index struct {
+	Table *Table
+	IndexT
+}
+
+// Index returns a handle on the named index of t.Indexes, or nil.
+func (t *Table) Index(name string) *Index {
+	if index := findIndex(t.Indexes, name); index != nil {
+		return &Index{Table: t, IndexT: *index}
+	}
+	return nil
+}
+
+// QueryWithOptions is Table.QueryWithOptions on the index.
+func (i *Index) QueryWithOptions(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions) ([]map[string]*Attribute, *Key, error) {
+	o, err := i.options(keyConditions, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return i.Table.QueryWithOptions(ctx, keyConditions, o)
+}
+
+// QueryPage is Table.QueryPage on the index.
+func (i *Index) QueryPage(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions) (*PageResult, error) {
+	o, err := i.options(keyConditions, opts)
+	if err != nil {
+		return nil, err
+	}
+	return i.Table.QueryPage(ctx, keyConditions, o)
+}
+
+// QueryAllPages is Table.QueryAllPages on the index.
+func (i *Index) QueryAllPages(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions) ([]map[string]*Attribute, *Key, error) {
+	o, err := i.options(keyConditions, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return i.Table.QueryAllPages(ctx, keyConditions, o)
+}
+
+// CountQueryAll is Table.CountQueryAll on the index, with the QueryFilter
+// and ConsistentRead of opts.
+func (i *Index) CountQueryAll(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions) (int64, int64, error) {
+	o, err := i.options(keyConditions, opts)
+	if err != nil {
+		return 0, 0, err
+	}
+	return i.Table.countPages(ctx, "Query", i.Table.queryQuery(keyConditions, o), o.IsRetry)
+}
+
+// options returns a copy of opts querying the index, after checking that
+// the index can serve the query.
+func (i *Index) options(keyConditions []AttributeComparison, opts *QueryOptions) (*QueryOptions, error) {
+	o := QueryOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.IndexName = i.IndexName
+	if o.ConsistentRead && !i.Local {
+		return nil, validationErrorf("Consistent reads are not supported on global secondary index %s.", i.IndexName)
+	}
+	return &o, i.validateKeyConditions(keyConditions)
+}
+
+// validateKeyConditions checks that keyConditions are an EQ condition on
+// the hash key of the index and at most one condition on its range key.
+func (i *Index) validateKeyConditions(keyConditions []AttributeComparison) error {
+	var hash, rangeKey bool
+	for _, c := range keyConditions {
+		switch {
+		case i.Key.KeyAttribute != nil && c.AttributeName == i.Key.KeyAttribute.Name:
+			if c.ComparisonOperator != COMPARISON_EQUAL {
+				return validationErrorf("Hash key %s of index %s only supports EQ, got %s.", c.AttributeName, i.IndexName, c.ComparisonOperator)
+			}
+			if hash {
+				return validationErrorf("Duplicate condition on key %s of index %s.", c.AttributeName, i.IndexName)
+			}
+			hash = true
+		case i.Key.HasRange() && c.AttributeName == i.Key.RangeAttribute.Name:
+			switch c.ComparisonOperator {
+			case COMPARISON_EQUAL, COMPARISON_LESS_THAN_OR_EQUAL, COMPARISON_LESS_THAN,
+				COMPARISON_GREATER_THAN_OR_EQUAL, COMPARISON_GREATER_THAN,
+				COMPARISON_BEGINS_WITH, COMPARISON_BETWEEN:
+			default:
+				return validationErrorf("Range key %s of index %s does not support %s.", c.AttributeName, i.IndexName, c.ComparisonOperator)
+			}
+			if rangeKey {
+				return validationErrorf("Duplicate condition on key %s of index %s.", c.AttributeName, i.IndexName)
+			}
+			rangeKey = true
+		default:
+			return validationErrorf("Attribute %s is not a key of index %s.", c.AttributeName, i.IndexName)
+		}
+	}
+	if !hash && i.Key.KeyAttribute != nil {
+		return validationErrorf("Missing EQ condition on hash key %s of index %s.", i.Key.KeyAttribute.Name, i.IndexName)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package dynamodb_test
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type IndexSuite struct {
+	table *dynamodb.Table
+}
+
+var _ = check.Suite(&IndexSuite{})
+
+func (s *IndexSuite) SetUpTest(c *check.C) {
+	server, _ := dynamodbtest.NewServer()
+	desc := dynamodb.TableDescriptionT{
+		TableName: "orders",
+		AttributeDefinitions: []dynamodb.AttributeDefinitionT{
+			{Name: "customer", Type: "S"},
+			{Name: "id", Type: "S"},
+			{Name: "total", Type: "N"},
+			{Name: "status", Type: "S"},
+		},
+		KeySchema: []dynamodb.KeySchemaT{
+			{AttributeName: "customer", KeyType: "HASH"},
+			{AttributeName: "id", KeyType: "RANGE"},
+		},
+		LocalSecondaryIndexes: []dynamodb.LocalSecondaryIndexT{{
+			IndexName:  "by-total",
+			KeySchema:  []dynamodb.KeySchemaT{{AttributeName: "customer", KeyType: "HASH"}, {AttributeName: "total", KeyType: "RANGE"}},
+			Projection: dynamodb.ProjectionT{ProjectionType: "ALL"},
+		}},
+		GlobalSecondaryIndexes: []dynamodb.GlobalSecondaryIndexT{{
+			IndexName:  "by-status",
+			KeySchema:  []dynamodb.KeySchemaT{{AttributeName: "status", KeyType: "HASH"}},
+			Projection: dynamodb.ProjectionT{ProjectionType: "KEYS_ONLY"},
+		}},
+	}
+	_, err := server.CreateTable(desc, false)
+	c.Assert(err, check.IsNil)
+	s.table, err = server.NewTableFromDescription(&desc)
+	c.Assert(err, check.IsNil)
+
+	for i, total := range []string{"30", "10", "20"} {
+		_, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("alice", "o"+strconv.Itoa(i)),
+			*dynamodb.NewNumericAttribute("total", total),
+			*dynamodb.NewStringAttribute("status", "open"),
+		), nil)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *IndexSuite) TestIndexes(c *check.C) {
+	c.Assert(s.table.Indexes, check.HasLen, 2)
+	local := s.table.Index("by-total")
+	c.Assert(local, check.NotNil)
+	c.Check(local.Local, check.Equals, true)
+	c.Check(local.Key.RangeAttribute, check.DeepEquals, &dynamodb.Attribute{Type: dynamodb.TYPE_NUMBER, Name: "total"})
+
+	global := s.table.Index("by-status")
+	c.Assert(global, check.NotNil)
+	c.Check(global.Local, check.Equals, false)
+	c.Check(global.Key.HasRange(), check.Equals, false)
+	c.Check(global.Projection.ProjectionType, check.Equals, "KEYS_ONLY")
+
+	c.Check(s.table.Index("missing"), check.IsNil)
+}
+
+func (s *IndexSuite) TestQuery(c *check.C) {
+	index := s.table.Index("by-total")
+	items, last, err := index.QueryWithOptions(context.Background(), []dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("customer", "alice"),
+		*dynamodb.NewNumericAttributeComparison("total", dynamodb.COMPARISON_GREATER_THAN_OR_EQUAL, 20),
+	}, &dynamodb.QueryOptions{ConsistentRead: true})
+	c.Assert(err, check.IsNil)
+	c.Check(last, check.IsNil)
+	c.Assert(items, check.HasLen, 2)
+	c.Check(items[0]["total"].Value, check.Equals, "20")
+	c.Check(items[1]["total"].Value, check.Equals, "30")
+
+	count, _, err := s.table.Index("by-status").CountQueryAll(context.Background(), []dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("status", "open"),
+	}, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(count, check.Equals, int64(3))
+}
+
+func (s *IndexSuite) TestPaging(c *check.C) {
+	// Resuming an index page needs the index key of the last item in
+	// ExclusiveStartKey, besides the table key.
+	index := s.table.Index("by-total")
+	alice := []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("customer", "alice")}
+	var totals []string
+	opts := &dynamodb.QueryOptions{Limit: 1}
+	for {
+		items, last, err := index.QueryWithOptions(context.Background(), alice, opts)
+		c.Assert(err, check.IsNil)
+		for _, item := range items {
+			totals = append(totals, item["total"].Value)
+		}
+		if last == nil {
+			break
+		}
+		c.Check(last.HashKey, check.Equals, "alice")
+		opts.ExclusiveStartKey = last
+	}
+	c.Check(totals, check.DeepEquals, []string{"10", "20", "30"})
+
+	count, scanned, err := s.table.Index("by-status").CountQueryAll(context.Background(), []dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("status", "open"),
+	}, &dynamodb.QueryOptions{Limit: 1})
+	c.Assert(err, check.IsNil)
+	c.Check(count, check.Equals, int64(3))
+	c.Check(scanned, check.Equals, int64(3))
+
+	count, _, err = s.table.ScanCountWithOptions(context.Background(), &dynamodb.ScanOptions{IndexName: "by-status", Limit: 1})
+	c.Assert(err, check.IsNil)
+	c.Check(count, check.Equals, int64(3))
+
+	items, last, err := index.QueryAllPages(context.Background(), alice, &dynamodb.QueryOptions{Limit: 2})
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 2)
+	items, last, err = index.QueryAllPages(context.Background(), alice, &dynamodb.QueryOptions{ExclusiveStartKey: last})
+	c.Assert(err, check.IsNil)
+	c.Check(last, check.IsNil)
+	c.Assert(items, check.HasLen, 1)
+	c.Check(items[0]["total"].Value, check.Equals, "30")
+}
+
+func (s *IndexSuite) TestValidation(c *check.C) {
+	alice := *dynamodb.NewEqualStringAttributeComparison("customer", "alice")
+	cases := []struct {
+		index      string
+		conditions []dynamodb.AttributeComparison
+		opts       *dynamodb.QueryOptions
+		message    string
+	}{
+		{"by-total", nil, nil, "Missing EQ condition on hash key customer of index by-total."},
+		{"by-total", []dynamodb.AttributeComparison{alice, *dynamodb.NewEqualStringAttributeComparison("id", "o1")}, nil,
+			"Attribute id is not a key of index by-total."},
+		{"by-total", []dynamodb.AttributeComparison{*dynamodb.NewStringAttributeComparison("customer", dynamodb.COMPARISON_BEGINS_WITH, "a")}, nil,
+			"Hash key customer of index by-total only supports EQ, got BEGINS_WITH."},
+		{"by-total", []dynamodb.AttributeComparison{alice, *dynamodb.NewNumericAttributeComparison("total", dynamodb.COMPARISON_NOT_EQUAL, 1)}, nil,
+			"Range key total of index by-total does not support NE."},
+		{"by-status", []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("status", "open")}, &dynamodb.QueryOptions{ConsistentRead: true},
+			"Consistent reads are not supported on global secondary index by-status."},
+	}
+	for _, t := range cases {
+		_, err := s.table.Index(t.index).QueryPage(context.Background(), t.conditions, t.opts)
+		c.Check(dynamodb.IsValidationError(err), check.Equals, true)
+		c.Check(err, check.ErrorMatches, regexp.QuoteMeta(t.message))
+	}
+}
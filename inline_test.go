@@ -0,0 +1,85 @@
+package dynamodb_test
+
+import (
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type InlineSuite struct{}
+
+var _ = check.Suite(&InlineSuite{})
+
+type Audit struct {
+	CreatedBy string `dynamodb:"created_by"`
+	UpdatedBy string `dynamodb:"updated_by"`
+}
+
+type address struct {
+	City string `dynamodb:"city"`
+	Zip  string `dynamodb:"zip"`
+}
+
+type customer struct {
+	*Audit
+	ID      string                 `dynamodb:"id"`
+	Address address                `dynamodb:",inline"`
+	Extra   map[string]interface{} `dynamodb:",inline"`
+}
+
+func itemOf(attrs []dynamodb.Attribute) map[string]*dynamodb.Attribute {
+	item := make(map[string]*dynamodb.Attribute, len(attrs))
+	for i := range attrs {
+		item[attrs[i].Name] = &attrs[i]
+	}
+	return item
+}
+
+func (s *InlineSuite) TestRoundTrip(c *check.C) {
+	in := &customer{
+		Audit:   &Audit{CreatedBy: "ann", UpdatedBy: "bob"},
+		ID:      "c1",
+		Address: address{City: "Paris", Zip: "75001"},
+		Extra:   map[string]interface{}{"tier": "gold", "visits": int64(3)},
+	}
+	attrs, err := dynamodb.MarshalAttributes(in)
+	c.Assert(err, check.IsNil)
+	c.Check(itemOf(attrs), check.DeepEquals, map[string]*dynamodb.Attribute{
+		"created_by": dynamodb.NewStringAttribute("created_by", "ann"),
+		"updated_by": dynamodb.NewStringAttribute("updated_by", "bob"),
+		"id":         dynamodb.NewStringAttribute("id", "c1"),
+		"city":       dynamodb.NewStringAttribute("city", "Paris"),
+		"zip":        dynamodb.NewStringAttribute("zip", "75001"),
+		"tier":       dynamodb.NewStringAttribute("tier", "gold"),
+		"visits":     dynamodb.NewNumericAttribute("visits", "3"),
+	})
+
+	// The embedded pointer is allocated, and the attributes of no field
+	// land in the inline map.
+	item := itemOf(attrs)
+	out := &customer{}
+	c.Assert(dynamodb.UnmarshalAttributes(&item, out), check.IsNil)
+	c.Check(out, check.DeepEquals, in)
+
+	// A nil embedded pointer contributes nothing.
+	attrs, err = dynamodb.MarshalAttributes(&customer{ID: "c2"})
+	c.Assert(err, check.IsNil)
+	c.Check(attrs, check.DeepEquals, []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", "c2")})
+}
+
+func (s *InlineSuite) TestRawAttributes(c *check.C) {
+	type record struct {
+		ID   string                         `dynamodb:"id"`
+		Rest map[string]*dynamodb.Attribute `dynamodb:",inline"`
+	}
+	item := map[string]*dynamodb.Attribute{
+		"id":   dynamodb.NewStringAttribute("id", "r1"),
+		"tags": dynamodb.NewStringSetAttribute("tags", []string{"a"}),
+	}
+	var r record
+	c.Assert(dynamodb.UnmarshalAttributes(&item, &r), check.IsNil)
+	c.Check(r.Rest, check.DeepEquals, map[string]*dynamodb.Attribute{"tags": item["tags"]})
+
+	attrs, err := dynamodb.MarshalAttributes(&r)
+	c.Assert(err, check.IsNil)
+	c.Check(itemOf(attrs), check.DeepEquals, item)
+}
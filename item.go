@@ -1,18 +1,36 @@
 package dynamodb
 
-import simplejson "github.com/bitly/go-simplejson"
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log"
+	"strconv"
+	"sync"
 	"time"
 )
 
+// maxNumberOfRetry is the number of times PutItemWithOptions re-sends a
+// throttled or failed write made without IsRetry.
 const maxNumberOfRetry = 4
 
+const (
+	RETURN_VALUES_NONE        = "NONE"
+	RETURN_VALUES_ALL_OLD     = "ALL_OLD"
+	RETURN_VALUES_UPDATED_OLD = "UPDATED_OLD"
+	RETURN_VALUES_ALL_NEW     = "ALL_NEW"
+	RETURN_VALUES_UPDATED_NEW = "UPDATED_NEW"
+)
+
+// DefaultBatchGetConcurrency is the number of requests BatchGetItem.Execute
+// runs at once by default.
+const DefaultBatchGetConcurrency = 4
+
 type BatchGetItem struct {
 	Server *Server
 	Keys   map[*Table][]Key
+	// Number of requests Execute runs at once when there are more than 100
+	// keys, DefaultBatchGetConcurrency when zero.
+	Concurrency int
 }
 
 type BatchWriteItem struct {
@@ -20,8 +38,26 @@ type BatchWriteItem struct {
 	ItemActions map[*Table]map[string][][]Attribute
 }
 
+type batchGetResponse struct {
+	Responses       map[string][]attributeMap
+	UnprocessedKeys map[string]struct {
+		Keys []attributeMap
+	}
+}
+
+type batchWriteResponse struct {
+	UnprocessedItems map[string][]struct {
+		PutRequest *struct {
+			Item attributeMap
+		}
+		DeleteRequest *struct {
+			Key attributeMap
+		}
+	}
+}
+
 func (t *Table) BatchGetItems(keys []Key) *BatchGetItem {
-	batchGetItem := &BatchGetItem{t.Server, make(map[*Table][]Key)}
+	batchGetItem := &BatchGetItem{Server: t.Server, Keys: make(map[*Table][]Key)}
 
 	batchGetItem.Keys[t] = keys
 	return batchGetItem
@@ -44,56 +80,195 @@ func (batchWriteItem *BatchWriteItem) AddTable(t *Table, itemActions *map[string
 	return batchWriteItem
 }
 
+// Execute reads the items of the keys. Dynamodb accepts at most 100 keys
+// per request: more are split into requests of 100 keys, Concurrency of
+// them running at once, whose results are merged. Unprocessed keys are
+// re-requested with backoff as the Server's RetryPolicy allows; those
+// still left are reported by an *UnprocessedKeysError, returned along with
+// the items read. Any other failed request fails Execute.
 func (batchGetItem *BatchGetItem) Execute(isRetry bool) (map[string][]map[string]*Attribute, error) {
-	q := NewEmptyQuery()
-	q.AddGetRequestItems(batchGetItem.Keys)
-
-	jsonResponse, err := batchGetItem.Server.queryServer("DynamoDB_20120810.BatchGetItem", q, isRetry)
-	if err != nil {
+	if err := validateBatchGet(batchGetItem.Keys); err != nil {
 		return nil, err
 	}
+	chunks := splitBatchGet(batchGetItem.Keys)
+	if len(chunks) == 1 {
+		return batchGetItem.execute(chunks[0], isRetry)
+	}
 
-	json, err := simplejson.NewJson(jsonResponse)
+	concurrency := batchGetItem.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchGetConcurrency
+	}
+	var (
+		mu          sync.Mutex
+		wg          sync.WaitGroup
+		firstErr    error
+		unprocessed *UnprocessedKeysError
+	)
+	results := make(map[string][]map[string]*Attribute)
+	slots := make(chan struct{}, concurrency)
+	for _, chunk := range chunks {
+		slots <- struct{}{}
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			<-slots
+			break
+		}
 
-	if err != nil {
-		return nil, err
+		wg.Add(1)
+		go func(chunk map[*Table][]Key) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			chunkResults, err := batchGetItem.execute(chunk, isRetry)
+			mu.Lock()
+			defer mu.Unlock()
+			var left *UnprocessedKeysError
+			if errors.As(err, &left) {
+				if unprocessed == nil {
+					unprocessed = &UnprocessedKeysError{}
+				}
+				unprocessed.Keys = append(unprocessed.Keys, left.Keys...)
+			} else if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			for table, items := range chunkResults {
+				results[table] = append(results[table], items...)
+			}
+		}(chunk)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if unprocessed != nil {
+		return results, unprocessed
 	}
+	return results, nil
+}
 
+// execute reads the items of at most 100 keys.
+func (batchGetItem *BatchGetItem) execute(keys map[*Table][]Key, isRetry bool) (map[string][]map[string]*Attribute, error) {
 	results := make(map[string][]map[string]*Attribute)
+	err := batchGetItem.Server.batchGet(context.Background(), keys, isRetry, func(t *Table, item map[string]*Attribute) error {
+		results[t.Name] = append(results[t.Name], item)
+		return nil
+	})
+	var unprocessed *UnprocessedKeysError
+	if err != nil && !errors.As(err, &unprocessed) {
+		return nil, err
+	}
+	return results, err
+}
 
-	tables, err := json.Get("Responses").Map()
-	if err != nil {
-		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-		return nil, errors.New(message)
+// batchGet requests keys (at most 100), re-requesting unprocessed keys
+// with backoff as the RetryPolicy allows, and calls found with every item
+// read. The keys still left are reported by an *UnprocessedKeysError.
+func (s *Server) batchGet(ctx context.Context, keys map[*Table][]Key, isRetry bool, found func(t *Table, item map[string]*Attribute) error) error {
+	tables := make(map[string]*Table, len(keys))
+	for t := range keys {
+		tables[t.Name] = t
 	}
 
-	for table, entries := range tables {
-		var tableResult []map[string]*Attribute
+	pending := keys
+	policy := s.retryPolicyFor(ctx)
+	started := time.Now()
+	for attempt := 0; len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			// Unprocessed keys are a sign of throttling.
+			delay := policy.Backoff(attempt - 1)
+			if !policy.allows(attempt-1, time.Since(started), delay) {
+				return &UnprocessedKeysError{Keys: flattenKeys(pending)}
+			}
+			if !sleepContext(ctx, delay) {
+				return &UnprocessedKeysError{Keys: flattenKeys(pending), Err: ctx.Err()}
+			}
+		}
+
+		q := NewEmptyQuery()
+		q.AddGetRequestItems(pending)
 
-		jsonEntriesArray, ok := entries.([]interface{})
-		if !ok {
-			message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-			return nil, errors.New(message)
+		jsonResponse, err := s.queryServerContext(ctx, target("BatchGetItem"), q, isRetry)
+		if err != nil {
+			return err
 		}
 
-		for _, entry := range jsonEntriesArray {
-			item, ok := entry.(map[string]interface{})
+		var r batchGetResponse
+		if err := decodeResponse(jsonResponse, &r); err != nil {
+			return err
+		}
+		if r.Responses == nil {
+			return fmt.Errorf("Unexpected response %s", jsonResponse)
+		}
+
+		for name, entries := range r.Responses {
+			t, ok := tables[name]
 			if !ok {
-				message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-				return nil, errors.New(message)
+				continue
+			}
+			for _, entry := range entries {
+				if err := found(t, entry.attributes()); err != nil {
+					return err
+				}
 			}
+		}
 
-			unmarshalledItem := parseAttributes(item)
-			tableResult = append(tableResult, unmarshalledItem)
+		pending = make(map[*Table][]Key)
+		for name, unprocessed := range r.UnprocessedKeys {
+			t, ok := tables[name]
+			if !ok {
+				continue
+			}
+			for _, u := range unprocessed.Keys {
+				if key := parseKey(t, u); key != nil {
+					pending[t] = append(pending[t], *key)
+				}
+			}
 		}
+	}
+	return nil
+}
 
-		results[table] = tableResult
+func flattenKeys(keys map[*Table][]Key) []Key {
+	var flat []Key
+	for _, tableKeys := range keys {
+		flat = append(flat, tableKeys...)
 	}
+	return flat
+}
 
-	return results, nil
+// splitBatchGet splits keys into requests of at most 100 keys.
+func splitBatchGet(keys map[*Table][]Key) []map[*Table][]Key {
+	var chunks []map[*Table][]Key
+	chunk, n := make(map[*Table][]Key), 0
+	for t, tableKeys := range keys {
+		for len(tableKeys) > 0 {
+			if n == maxBatchGetKeys {
+				chunks = append(chunks, chunk)
+				chunk, n = make(map[*Table][]Key), 0
+			}
+			size := maxBatchGetKeys - n
+			if size > len(tableKeys) {
+				size = len(tableKeys)
+			}
+			chunk[t] = append(chunk[t], tableKeys[:size]...)
+			n += size
+			tableKeys = tableKeys[size:]
+		}
+	}
+	return append(chunks, chunk)
 }
 
 func (batchWriteItem *BatchWriteItem) Execute(isRetry bool) (map[string]interface{}, error) {
+	if err := validateBatchWrite(batchWriteItem.ItemActions); err != nil {
+		return nil, err
+	}
 	q := NewEmptyQuery()
 	q.AddWriteRequestItems(batchWriteItem.ItemActions)
 
@@ -103,14 +278,14 @@ func (batchWriteItem *BatchWriteItem) Execute(isRetry bool) (map[string]interfac
 		return nil, err
 	}
 
-	json, err := simplejson.NewJson(jsonResponse)
-
-	if err != nil {
+	var r struct {
+		UnprocessedItems map[string]interface{}
+	}
+	if err := decodeResponse(jsonResponse, &r); err != nil {
 		return nil, err
 	}
-
-	unprocessed, err := json.Get("UnprocessedItems").Map()
-	if err != nil {
+	unprocessed := r.UnprocessedItems
+	if unprocessed == nil {
 		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
 		return nil, errors.New(message)
 	}
@@ -132,6 +307,13 @@ func (t *Table) GetItemConsistent(key *Key, consistentRead bool, isRetry bool) (
 }
 
 func (t *Table) getItem(key *Key, consistentRead bool, isRetry bool) (map[string]*Attribute, error) {
+	return t.getItemContext(context.Background(), key, consistentRead, isRetry)
+}
+
+func (t *Table) getItemContext(ctx context.Context, key *Key, consistentRead bool, isRetry bool) (map[string]*Attribute, error) {
+	if err := t.validateKey(key); err != nil {
+		return nil, err
+	}
 	q := NewQuery(t)
 	q.AddKey(t, key)
 
@@ -139,37 +321,35 @@ func (t *Table) getItem(key *Key, consistentRead bool, isRetry bool) (map[string
 		q.ConsistentRead(consistentRead)
 	}
 
-	jsonResponse, err := t.Server.queryServer(target("GetItem"), q, isRetry)
+	jsonResponse, err := t.Server.queryServerContext(ctx, target("GetItem"), q, isRetry)
 	if err != nil {
 		return nil, err
 	}
 
-	json, err := simplejson.NewJson(jsonResponse)
-	if err != nil {
+	var r struct {
+		Item attributeMap
+	}
+	if err := decodeResponse(jsonResponse, &r); err != nil {
 		return nil, err
 	}
-
-	itemJson, ok := json.CheckGet("Item")
-	if !ok {
+	if r.Item == nil {
 		// We got an empty from amz. The item doesn't exist.
 		return nil, ErrNotFound
 	}
 
-	item, err := itemJson.Map()
-	if err != nil {
-		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-		return nil, errors.New(message)
-	}
-
-	return parseAttributes(item), nil
+	return r.Item.attributes(), nil
 
 }
 
+// Deprecated: use PutItemWithOptions.
 func (t *Table) PutItem(hashKey string, rangeKey string, attributes []Attribute, isRetry bool) (bool, error) {
+	deprecated(t.Server, "PutItem", "PutItemWithOptions")
 	return t.putItem(hashKey, rangeKey, attributes, nil, isRetry)
 }
 
+// Deprecated: use PutItemWithOptions with Expected or ConditionExpression.
 func (t *Table) ConditionalPutItem(hashKey, rangeKey string, attributes, expected []Attribute, isRetry bool) (bool, error) {
+	deprecated(t.Server, "ConditionalPutItem", "PutItemWithOptions")
 	return t.putItem(hashKey, rangeKey, attributes, expected, isRetry)
 }
 
@@ -178,77 +358,21 @@ func (t *Table) putItem(hashKey, rangeKey string, attributes, expected []Attribu
 		return false, errors.New("At least one attribute is required.")
 	}
 
-	q := NewQuery(t)
-
 	keys := t.Key.Clone(hashKey, rangeKey)
 	attributes = append(attributes, keys...)
 
-	q.AddItem(attributes)
-	if expected != nil {
-		q.AddExpected(expected)
-	}
-
-	var jsonResponse []byte
-	var err error
-	// based on:
-	// http://docs.aws.amazon.com/amazondynamodb/latest/developerguide/ErrorHandling.html#APIRetries
-	currentRetry := uint(0)
-	for {
-		jsonResponse, err = t.Server.queryServer(target("PutItem"), q, isRetry)
-		if currentRetry >= maxNumberOfRetry {
-			break
-		}
-
-		retry := false
-		if err != nil {
-			log.Printf("Error requesting from Amazon, request was: %#v\n response is:%#v\n and error is: %#v\n", q, string(jsonResponse), err)
-			if err, ok := err.(*Error); ok {
-				retry = (err.StatusCode == 500) ||
-					(err.Code == "ThrottlingException") ||
-					(err.Code == "ProvisionedThroughputExceededException")
-			}
-		}
-
-		if !retry {
-			break
-		}
-
-		log.Printf("Retrying in %v ms\n", (1<<currentRetry)*50)
-		<-time.After((1 << currentRetry) * 50 * time.Millisecond)
-		currentRetry += 1
-	}
-
+	_, err := t.PutItemWithOptions(context.Background(), attributes, &WriteOptions{Expected: expected, IsRetry: isRetry})
 	if err != nil {
 		return false, err
 	}
-
-	_, err = simplejson.NewJson(jsonResponse)
-	if err != nil {
-		return false, err
-	}
-
 	return true, nil
 }
 
 func (t *Table) deleteItem(key *Key, expected []Attribute, isRetry bool) (bool, error) {
-	q := NewQuery(t)
-	q.AddKey(t, key)
-
-	if expected != nil {
-		q.AddExpected(expected)
-	}
-
-	jsonResponse, err := t.Server.queryServer(target("DeleteItem"), q, isRetry)
-
-	if err != nil {
-		return false, err
-	}
-
-	_, err = simplejson.NewJson(jsonResponse)
+	_, err := t.DeleteItemWithOptions(context.Background(), key, &WriteOptions{Expected: expected, IsRetry: isRetry})
 	if err != nil {
 		return false, err
 	}
-
 	return true, nil
 }
 
@@ -284,99 +408,29 @@ func (t *Table) ConditionalDeleteAttributes(key *Key, attributes, expected []Att
 	return t.modifyAttributes(key, attributes, expected, "DELETE", isRetry)
 }
 
-func (t *Table) modifyAttributes(key *Key, attributes, expected []Attribute, action string, isRetry bool) (bool, error) {
-
-	if len(attributes) == 0 {
-		return false, errors.New("At least one attribute is required.")
+// Increment atomically adds delta (which may be negative) to the numeric
+// attribute, creating it with value delta if absent, and returns the new
+// value.
+func (t *Table) Increment(key *Key, attribute string, delta int64, isRetry bool) (int64, error) {
+	updates := []Attribute{*NewNumericAttribute(attribute, strconv.FormatInt(delta, 10))}
+	result, err := t.UpdateItemWithOptions(context.Background(), key, updates, "ADD",
+		&WriteOptions{ReturnValues: RETURN_VALUES_UPDATED_NEW, IsRetry: isRetry})
+	if err != nil {
+		return 0, err
 	}
 
-	q := NewQuery(t)
-	q.AddKey(t, key)
-	q.AddUpdates(attributes, action)
-
-	if expected != nil {
-		q.AddExpected(expected)
+	counter, ok := result.Attributes[attribute]
+	if !ok || counter.Type != TYPE_NUMBER {
+		return 0, errors.New("Unexpected response: the counter attribute was not returned.")
 	}
 
-	jsonResponse, err := t.Server.queryServer(target("UpdateItem"), q, isRetry)
-
-	if err != nil {
-		return false, err
-	}
+	return counter.Int64()
+}
 
-	_, err = simplejson.NewJson(jsonResponse)
+func (t *Table) modifyAttributes(key *Key, attributes, expected []Attribute, action string, isRetry bool) (bool, error) {
+	_, err := t.UpdateItemWithOptions(context.Background(), key, attributes, action, &WriteOptions{Expected: expected, IsRetry: isRetry})
 	if err != nil {
 		return false, err
 	}
-
 	return true, nil
 }
-
-func parseAttributes(s map[string]interface{}) map[string]*Attribute {
-	results := map[string]*Attribute{}
-
-	for key, value := range s {
-		if v, ok := value.(map[string]interface{}); ok {
-			if val, ok := v[TYPE_STRING].(string); ok {
-				results[key] = &Attribute{
-					Type:  TYPE_STRING,
-					Name:  key,
-					Value: val,
-				}
-			} else if val, ok := v[TYPE_NUMBER].(string); ok {
-				results[key] = &Attribute{
-					Type:  TYPE_NUMBER,
-					Name:  key,
-					Value: val,
-				}
-			} else if val, ok := v[TYPE_BINARY].(string); ok {
-				results[key] = &Attribute{
-					Type:  TYPE_BINARY,
-					Name:  key,
-					Value: val,
-				}
-			} else if vals, ok := v[TYPE_STRING_SET].([]interface{}); ok {
-				arry := make([]string, len(vals))
-				for i, ivalue := range vals {
-					if val, ok := ivalue.(string); ok {
-						arry[i] = val
-					}
-				}
-				results[key] = &Attribute{
-					Type:      TYPE_STRING_SET,
-					Name:      key,
-					SetValues: arry,
-				}
-			} else if vals, ok := v[TYPE_NUMBER_SET].([]interface{}); ok {
-				arry := make([]string, len(vals))
-				for i, ivalue := range vals {
-					if val, ok := ivalue.(string); ok {
-						arry[i] = val
-					}
-				}
-				results[key] = &Attribute{
-					Type:      TYPE_NUMBER_SET,
-					Name:      key,
-					SetValues: arry,
-				}
-			} else if vals, ok := v[TYPE_BINARY_SET].([]interface{}); ok {
-				arry := make([]string, len(vals))
-				for i, ivalue := range vals {
-					if val, ok := ivalue.(string); ok {
-						arry[i] = val
-					}
-				}
-				results[key] = &Attribute{
-					Type:      TYPE_BINARY_SET,
-					Name:      key,
-					SetValues: arry,
-				}
-			}
-		} else {
-			log.Printf("type assertion to map[string] interface{} failed for : %s\n ", value)
-		}
-
-	}
-
-	return results
-}
@@ -1,6 +1,8 @@
 package dynamodb_test
 
 import (
+	"context"
+
 	"github.com/bluele/dynamodb"
 	"gopkg.in/check.v1"
 )
@@ -413,3 +415,162 @@ func (s *ItemSuite) TestUpdateItemWithSet(c *check.C) {
 		}
 	}
 }
+
+func (s *ItemSuite) TestRenameAttribute(c *check.C) {
+	attrs := []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("user_id", "u1"),
+	}
+
+	var rk string
+	if s.WithRange {
+		rk = "1"
+	}
+
+	if _, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("NewHashKeyVal", rk), attrs...), nil); err != nil {
+		c.Fatal(err)
+	}
+
+	progress, err := dynamodb.RenameAttribute(context.Background(), s.table, "user_id", "userId", &dynamodb.RenameAttributeOptions{RemoveOld: true})
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(progress.Updated, check.Equals, int64(1))
+
+	pk := &dynamodb.Key{HashKey: "NewHashKeyVal", RangeKey: rk}
+	if item, err := s.table.GetItemConsistent(pk, true, false); err != nil {
+		c.Error(err)
+	} else {
+		c.Check(item["userId"], check.DeepEquals, dynamodb.NewStringAttribute("userId", "u1"))
+		if _, ok := item["user_id"]; ok {
+			c.Error("Expect user_id to be removed")
+		}
+	}
+}
+
+func (s *ItemSuite) TestPutItemWithOptions(c *check.C) {
+	var rk string
+	if s.WithRange {
+		rk = "1"
+	}
+	item := append([]dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("Attr1", "Attr1Val"),
+	}, s.table.Key.Clone("NewHashKeyVal", rk)...)
+
+	opts := &dynamodb.WriteOptions{
+		ConditionExpression:      "attribute_not_exists(#h)",
+		ExpressionAttributeNames: map[string]string{"#h": "TestHashKey"},
+	}
+	if _, err := s.table.PutItemWithOptions(context.Background(), item, opts); err != nil {
+		c.Fatal(err)
+	}
+
+	// The item exists now, the same condition must fail
+	_, err := s.table.PutItemWithOptions(context.Background(), item, opts)
+	c.Check(dynamodb.IsConditionalCheckFailed(err), check.Equals, true)
+}
+
+func (s *ItemSuite) TestIncrement(c *check.C) {
+	var rk string
+	if s.WithRange {
+		rk = "1"
+	}
+	pk := &dynamodb.Key{HashKey: "NewHashKeyVal", RangeKey: rk}
+
+	if n, err := s.table.Increment(pk, "count", 5, false); err != nil {
+		c.Fatal(err)
+	} else {
+		c.Check(n, check.Equals, int64(5))
+	}
+
+	if n, err := s.table.Increment(pk, "count", -2, false); err != nil {
+		c.Fatal(err)
+	} else {
+		c.Check(n, check.Equals, int64(3))
+	}
+}
+
+func (s *ItemSuite) TestVersionedPutUpdate(c *check.C) {
+	var rk string
+	if s.WithRange {
+		rk = "1"
+	}
+	attrs := []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("Attr1", "Attr1Val"),
+	}
+
+	version, err := s.table.VersionedPut("NewHashKeyVal", rk, attrs, 0, false)
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(version, check.Equals, int64(1))
+
+	// Creating it again must conflict
+	_, err = s.table.VersionedPut("NewHashKeyVal", rk, attrs, 0, false)
+	c.Check(err, check.Equals, dynamodb.ErrVersionConflict)
+
+	pk := &dynamodb.Key{HashKey: "NewHashKeyVal", RangeKey: rk}
+	version, err = s.table.VersionedUpdate(pk, attrs, version, false)
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(version, check.Equals, int64(2))
+
+	// A stale version must conflict
+	_, err = s.table.VersionedUpdate(pk, attrs, 1, false)
+	c.Check(err, check.Equals, dynamodb.ErrVersionConflict)
+}
+
+func (s *ItemSuite) TestDeleteItemWithOptionsReturnsOldItem(c *check.C) {
+	var rk string
+	if s.WithRange {
+		rk = "1"
+	}
+	attrs := []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("Attr1", "Attr1Val"),
+	}
+	if _, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("NewHashKeyVal", rk), attrs...), nil); err != nil {
+		c.Fatal(err)
+	}
+
+	pk := &dynamodb.Key{HashKey: "NewHashKeyVal", RangeKey: rk}
+	result, err := s.table.DeleteItemWithOptions(context.Background(), pk, &dynamodb.WriteOptions{
+		ReturnValues:           dynamodb.RETURN_VALUES_ALL_OLD,
+		ReturnConsumedCapacity: dynamodb.RETURN_CONSUMED_CAPACITY_TOTAL,
+	})
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(result.Attributes["Attr1"], check.DeepEquals, dynamodb.NewStringAttribute("Attr1", "Attr1Val"))
+	c.Check(result.ConsumedCapacity, check.NotNil)
+}
+
+func (s *ItemSuite) TestConditionFailedItem(c *check.C) {
+	var rk string
+	if s.WithRange {
+		rk = "1"
+	}
+	attrs := []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("Attr1", "Attr1Val"),
+	}
+	if _, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("NewHashKeyVal", rk), attrs...), nil); err != nil {
+		c.Fatal(err)
+	}
+
+	pk := &dynamodb.Key{HashKey: "NewHashKeyVal", RangeKey: rk}
+	expected := []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("Attr1", "expectedAttr1Val").SetExists(true),
+	}
+	_, err := s.table.DeleteItemWithOptions(context.Background(), pk, &dynamodb.WriteOptions{
+		Expected:                            expected,
+		ReturnValuesOnConditionCheckFailure: dynamodb.RETURN_VALUES_ALL_OLD,
+	})
+	c.Assert(dynamodb.IsConditionalCheckFailed(err), check.Equals, true)
+	item := dynamodb.ConditionFailedItem(err)
+	c.Assert(item, check.NotNil)
+	c.Check(item["Attr1"], check.DeepEquals, dynamodb.NewStringAttribute("Attr1", "Attr1Val"))
+
+	// Without the option the item is not returned
+	_, err = s.table.DeleteItemWithOptions(context.Background(), pk, &dynamodb.WriteOptions{Expected: expected})
+	c.Assert(dynamodb.IsConditionalCheckFailed(err), check.Equals, true)
+	c.Check(dynamodb.ConditionFailedItem(err), check.IsNil)
+}
@@ -0,0 +1,80 @@
+//go:build go1.23
+
+package dynamodb
+
+import (
+	"context"
+	"iter"
+)
+
+// QueryItems returns an iterator over the items of the query, running Query
+// requests as the iteration goes and following LastEvaluatedKey, so that
+// only one page is held at a time. Breaking out of the loop stops issuing
+// requests. As with QueryAllPages, a positive opts.Limit caps the number of
+// items yielded. A failed request yields its error and ends the iteration.
+// Every range over the iterator starts again from opts.ExclusiveStartKey.
+//
+//	for item, err := range t.QueryItems(ctx, conditions, nil) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (t *Table) QueryItems(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions) iter.Seq2[map[string]*Attribute, error] {
+	page := QueryOptions{}
+	if opts != nil {
+		page = *opts
+	}
+	return iterateItems(page.Limit, func(limit int64, start *Key) ([]map[string]*Attribute, *Key, error) {
+		page := page
+		page.Limit, page.ExclusiveStartKey = limit, start
+		return t.QueryWithOptions(ctx, keyConditions, &page)
+	}, page.ExclusiveStartKey)
+}
+
+// ScanItems is QueryItems for Scan.
+func (t *Table) ScanItems(ctx context.Context, opts *ScanOptions) iter.Seq2[map[string]*Attribute, error] {
+	page := ScanOptions{}
+	if opts != nil {
+		page = *opts
+	}
+	return iterateItems(page.Limit, func(limit int64, start *Key) ([]map[string]*Attribute, *Key, error) {
+		page := page
+		page.Limit, page.ExclusiveStartKey = limit, start
+		return t.ScanWithOptions(ctx, &page)
+	}, page.ExclusiveStartKey)
+}
+
+// iterateItems yields the items of the pages returned by fetch, which is
+// passed the number of items still wanted, zero for all, and the key to
+// resume from.
+func iterateItems(limit int64, fetch func(limit int64, start *Key) ([]map[string]*Attribute, *Key, error), start *Key) iter.Seq2[map[string]*Attribute, error] {
+	return func(yield func(map[string]*Attribute, error) bool) {
+		var yielded int64
+		last := start
+		for {
+			var pageLimit int64
+			if limit > 0 {
+				pageLimit = limit - yielded
+			}
+			items, next, err := fetch(pageLimit, last)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+				yielded++
+				if limit > 0 && yielded >= limit {
+					return
+				}
+			}
+			if next == nil {
+				return
+			}
+			last = next
+		}
+	}
+}
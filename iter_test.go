@@ -0,0 +1,108 @@
+//go:build go1.23
+
+package dynamodb_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type IterSuite struct {
+	table    *dynamodb.Table
+	requests int
+	fail     error
+}
+
+var _ = check.Suite(&IterSuite{})
+
+func (s *IterSuite) SetUpTest(c *check.C) {
+	s.requests, s.fail = 0, nil
+	server, _ := dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation == "Query" || req.Operation == "Scan" {
+				s.requests++
+				if s.fail != nil {
+					return nil, s.fail
+				}
+			}
+			return next(req)
+		}
+	})
+
+	s.table = createTable(c, server, tableSchema(c, "events", userSeqKey{}))
+
+	for i := 0; i < 10; i++ {
+		kind := "click"
+		if i%2 == 1 {
+			kind = "view"
+		}
+		_, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("u1", strconv.Itoa(i)), *dynamodb.NewStringAttribute("kind", kind)), nil)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *IterSuite) conditions() []dynamodb.AttributeComparison {
+	return []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("user", "u1")}
+}
+
+func (s *IterSuite) TestQueryItems(c *check.C) {
+	var seqs []string
+	for item, err := range s.table.QueryItems(context.Background(), s.conditions(), &dynamodb.QueryOptions{Limit: 3}) {
+		c.Assert(err, check.IsNil)
+		seqs = append(seqs, item["seq"].Value)
+		if len(seqs) == 2 {
+			break
+		}
+	}
+	c.Check(seqs, check.DeepEquals, []string{"0", "1"})
+	c.Check(s.requests, check.Equals, 1)
+}
+
+func (s *IterSuite) TestQueryItemsLimit(c *check.C) {
+	n := 0
+	for _, err := range s.table.QueryItems(context.Background(), s.conditions(), &dynamodb.QueryOptions{Limit: 4}) {
+		c.Assert(err, check.IsNil)
+		n++
+	}
+	c.Check(n, check.Equals, 4)
+}
+
+func (s *IterSuite) TestScanItems(c *check.C) {
+	n := 0
+	for _, err := range s.table.ScanItems(context.Background(), nil) {
+		c.Assert(err, check.IsNil)
+		n++
+	}
+	c.Check(n, check.Equals, 10)
+}
+
+func (s *IterSuite) TestFollowsPages(c *check.C) {
+	// Limit applies before the filter, so pages hold fewer items than
+	// asked for and the iterator keeps requesting the remaining ones.
+	var seqs []string
+	for item, err := range s.table.QueryItems(context.Background(), s.conditions(), &dynamodb.QueryOptions{
+		Limit:       3,
+		QueryFilter: []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("kind", "click")},
+	}) {
+		c.Assert(err, check.IsNil)
+		seqs = append(seqs, item["seq"].Value)
+	}
+	c.Check(seqs, check.DeepEquals, []string{"0", "2", "4"})
+	c.Check(s.requests > 1, check.Equals, true)
+}
+
+func (s *IterSuite) TestError(c *check.C) {
+	s.fail = errors.New("unavailable")
+	n := 0
+	for item, err := range s.table.ScanItems(context.Background(), nil) {
+		c.Check(item, check.IsNil)
+		c.Check(err, check.ErrorMatches, ".*unavailable")
+		n++
+	}
+	c.Check(n, check.Equals, 1)
+}
@@ -0,0 +1,211 @@
+package dynamodb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	// Items as the API represents them: {"id":{"S":"a"},"n":{"N":"1"}}.
+	// Every attribute type round-trips.
+	JSON_FORMAT_DYNAMODB = "DYNAMODB"
+	// Items as plain JSON objects: {"id":"a","n":1}. Numbers become N,
+	// strings S, arrays L and objects M. Sets are exported as arrays and
+	// binary attributes as base64 strings, and so are imported back as L
+	// and S.
+	JSON_FORMAT_PLAIN = "PLAIN"
+)
+
+// JSONOptions tunes ExportToJSON and ImportFromJSON. The zero value uses
+// JSON_FORMAT_DYNAMODB.
+type JSONOptions struct {
+	Format  string
+	IsRetry bool
+}
+
+func (opts *JSONOptions) plain() bool {
+	return opts != nil && opts.Format == JSON_FORMAT_PLAIN
+}
+
+func (opts *JSONOptions) isRetry() bool {
+	return opts != nil && opts.IsRetry
+}
+
+// ExportToJSON scans the table and writes its items to w as JSON lines,
+// one item per line, returning the number of items written.
+func (t *Table) ExportToJSON(w io.Writer, opts *JSONOptions) (int64, error) {
+	enc := json.NewEncoder(w)
+	q := NewQuery(t)
+
+	var count int64
+	for {
+		items, lastEvaluatedKey, err := t.FetchPartialResults(q, opts.isRetry())
+		if err != nil {
+			return count, err
+		}
+		for _, item := range items {
+			var v interface{}
+			if opts.plain() {
+				v = plainItem(item)
+			} else {
+				v = attributeList(attributeSlice(item))
+			}
+			if err := enc.Encode(v); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if lastEvaluatedKey == nil {
+			return count, nil
+		}
+		q.AddExclusiveStartKey(t, lastEvaluatedKey)
+	}
+}
+
+// ImportFromJSON reads items written by ExportToJSON (or any sequence of
+// JSON objects) from r and puts them into the table 25 at a time,
+// returning the number of items written.
+func (t *Table) ImportFromJSON(r io.Reader, opts *JSONOptions) (int64, error) {
+	dec := json.NewDecoder(r)
+
+	var (
+		count int64
+		batch [][]Attribute
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := t.batchWrite(context.Background(), batch, nil, opts.isRetry()); err != nil {
+			return err
+		}
+		count += int64(len(batch))
+		batch = nil
+		return nil
+	}
+
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+
+		var item []Attribute
+		if opts.plain() {
+			var m map[string]interface{}
+			plain := json.NewDecoder(bytes.NewReader(raw))
+			plain.UseNumber()
+			if err = plain.Decode(&m); err == nil {
+				item, err = itemFromPlain(m)
+			}
+		} else {
+			var m attributeMap
+			if err = json.Unmarshal(raw, &m); err == nil {
+				item = attributeSlice(m.attributes())
+			}
+		}
+		if err != nil {
+			return count, fmt.Errorf("Item %d: %s", count+int64(len(batch))+1, err)
+		}
+
+		batch = append(batch, item)
+		if len(batch) == maxBatchWriteItems {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+	return count, flush()
+}
+
+func plainItem(item map[string]*Attribute) map[string]interface{} {
+	out := make(map[string]interface{}, len(item))
+	for name, a := range item {
+		out[name] = plainValue(a)
+	}
+	return out
+}
+
+func plainValue(a *Attribute) interface{} {
+	switch a.Type {
+	case TYPE_NUMBER:
+		return json.Number(a.Value)
+	case TYPE_NUMBER_SET:
+		numbers := make([]json.Number, len(a.SetValues))
+		for i, v := range a.SetValues {
+			numbers[i] = json.Number(v)
+		}
+		return numbers
+	case TYPE_LIST:
+		values := make([]interface{}, len(a.ListValues))
+		for i := range a.ListValues {
+			values[i] = plainValue(&a.ListValues[i])
+		}
+		return values
+	case TYPE_NULL:
+		return nil
+	case TYPE_MAP:
+		members := make(map[string]interface{}, len(a.MapValues))
+		for key, member := range a.MapValues {
+			members[key] = plainValue(member)
+		}
+		return members
+	}
+	if a.SetType() {
+		return a.SetValues
+	}
+	return a.Value
+}
+
+func itemFromPlain(m map[string]interface{}) ([]Attribute, error) {
+	item := make(map[string]*Attribute, len(m))
+	for name, value := range m {
+		a, err := plainAttribute(name, value)
+		if err != nil {
+			return nil, err
+		}
+		item[name] = a
+	}
+	return attributeSlice(item), nil
+}
+
+func plainAttribute(name string, value interface{}) (*Attribute, error) {
+	switch v := value.(type) {
+	case nil:
+		return NewNullAttribute(name), nil
+	case string:
+		return NewStringAttribute(name, v), nil
+	case json.Number:
+		return NewNumericAttribute(name, v.String()), nil
+	case bool:
+		return NewNumericAttribute(name, map[bool]string{true: "1", false: "0"}[v]), nil
+	case []interface{}:
+		values := make([]Attribute, len(v))
+		for i, member := range v {
+			a, err := plainAttribute("", member)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = *a
+		}
+		return NewListAttribute(name, values), nil
+	case map[string]interface{}:
+		values := make([]Attribute, 0, len(v))
+		for key, member := range v {
+			a, err := plainAttribute(key, member)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, *a)
+		}
+		return NewMapAttribute(name, values), nil
+	}
+	return nil, fmt.Errorf("Attribute %s: unsupported JSON value %v.", name, value)
+}
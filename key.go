@@ -0,0 +1,165 @@
+package dynamodb
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// NewKey returns a Key from typed values: strings, integers, floats,
+// *big.Int or []byte. rangeKey is nil for tables without a range key.
+func NewKey(hashKey, rangeKey interface{}) (*Key, error) {
+	h, _, err := goKeyValue(hashKey)
+	if err != nil {
+		return nil, fmt.Errorf("Hash key: %s", err)
+	}
+	k := &Key{HashKey: h}
+	if rangeKey != nil {
+		k.RangeKey, _, err = goKeyValue(rangeKey)
+		if err != nil {
+			return nil, fmt.Errorf("Range key: %s", err)
+		}
+	}
+	return k, nil
+}
+
+// NewKey is like the package level NewKey but also checks the values
+// against the key types of the table. Strings are accepted for numeric keys
+// when they are valid numbers, and for binary keys when base64 encoded.
+func (t *Table) NewKey(hashKey, rangeKey interface{}) (*Key, error) {
+	h, err := typedKeyValue(t.Key.KeyAttribute, hashKey)
+	if err != nil {
+		return nil, err
+	}
+	k := &Key{HashKey: h}
+
+	if t.Key.HasRange() {
+		if rangeKey == nil {
+			return nil, fmt.Errorf("Table %s requires a range key.", t.Name)
+		}
+		k.RangeKey, err = typedKeyValue(t.Key.RangeAttribute, rangeKey)
+		if err != nil {
+			return nil, err
+		}
+	} else if rangeKey != nil {
+		return nil, fmt.Errorf("Table %s has no range key.", t.Name)
+	}
+	return k, nil
+}
+
+func typedKeyValue(attribute *Attribute, v interface{}) (string, error) {
+	s, typ, err := goKeyValue(v)
+	if err != nil {
+		return "", fmt.Errorf("Key %s: %s", attribute.Name, err)
+	}
+	if typ == attribute.Type {
+		return s, nil
+	}
+
+	if typ == TYPE_STRING {
+		switch attribute.Type {
+		case TYPE_NUMBER:
+			err = checkNumber(s)
+		case TYPE_BINARY:
+			_, err = base64.StdEncoding.DecodeString(s)
+		}
+		if err == nil {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("Key %s is of type %s, got %T %v.", attribute.Name, attribute.Type, v, v)
+}
+
+// goKeyValue returns the wire value of v and its type.
+func goKeyValue(v interface{}) (string, string, error) {
+	switch x := v.(type) {
+	case []byte:
+		return base64.StdEncoding.EncodeToString(x), TYPE_BINARY, nil
+	case *big.Int:
+		if err := checkNumber(x.String()); err != nil {
+			return "", "", err
+		}
+		return x.String(), TYPE_NUMBER, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), TYPE_STRING, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), TYPE_NUMBER, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), TYPE_NUMBER, nil
+	case reflect.Float32, reflect.Float64:
+		s, err := numericReflectedValueString(rv)
+		if err == nil {
+			err = checkNumber(s)
+		}
+		if err != nil {
+			return "", "", err
+		}
+		return s, TYPE_NUMBER, nil
+	}
+	return "", "", fmt.Errorf("Unsupported key type %T.", v)
+}
+
+// HashInt64 parses the hash key of a table with a numeric hash key.
+func (k *Key) HashInt64() (int64, error) {
+	return NewNumericAttribute("HashKey", k.HashKey).Int64()
+}
+
+// RangeInt64 parses the range key of a table with a numeric range key.
+func (k *Key) RangeInt64() (int64, error) {
+	return NewNumericAttribute("RangeKey", k.RangeKey).Int64()
+}
+
+// HashBytes decodes the hash key of a table with a binary hash key.
+func (k *Key) HashBytes() ([]byte, error) {
+	return NewBinaryAttribute("HashKey", k.HashKey).Bytes()
+}
+
+// RangeBytes decodes the range key of a table with a binary range key.
+func (k *Key) RangeBytes() ([]byte, error) {
+	return NewBinaryAttribute("RangeKey", k.RangeKey).Bytes()
+}
+
+// HashKeyCondition returns the EQ condition on the hash key that a Query
+// needs, typed after the table's hash key.
+func (t *Table) HashKeyCondition(hashKey string) *AttributeComparison {
+	hk := t.Key.KeyAttribute
+	return &AttributeComparison{hk.Name,
+		COMPARISON_EQUAL,
+		[]Attribute{{Type: hk.Type, Name: hk.Name, Value: hashKey}},
+	}
+}
+
+// KeyFromItem extracts the table's primary key from a full item, such as
+// one returned by GetItem, Query, Scan or a stream record.
+func (t *Table) KeyFromItem(item map[string]*Attribute) (*Key, error) {
+	hk, err := keyAttributeFromItem(t.Key.KeyAttribute, item)
+	if err != nil {
+		return nil, err
+	}
+	key := &Key{HashKey: hk.Value}
+	if t.Key.HasRange() {
+		rk, err := keyAttributeFromItem(t.Key.RangeAttribute, item)
+		if err != nil {
+			return nil, err
+		}
+		key.RangeKey = rk.Value
+	}
+	return key, nil
+}
+
+func keyAttributeFromItem(attribute *Attribute, item map[string]*Attribute) (*Attribute, error) {
+	a, ok := item[attribute.Name]
+	if !ok || a == nil {
+		return nil, fmt.Errorf("Item does not contain the key attribute %s.", attribute.Name)
+	}
+	if a.Type != attribute.Type {
+		return nil, fmt.Errorf("Key %s is of type %s, got %s.", attribute.Name, attribute.Type, a.Type)
+	}
+	return a, nil
+}
@@ -0,0 +1,165 @@
+package dynamodb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A KeyTemplate formats and parses the composite key values of single
+// table designs, such as "USER#{id}" or "ORDER#{date}#{id}", where literal
+// prefixes tell entities apart and placeholders hold their fields.
+type KeyTemplate struct {
+	pattern  string
+	literals []string // literals[i] precedes fields[i]; one more literal than fields
+	fields   []string
+}
+
+// NewKeyTemplate parses pattern. Placeholders are field names in braces
+// and must be separated by literal text, so that keys can be parsed back.
+func NewKeyTemplate(pattern string) (*KeyTemplate, error) {
+	k := &KeyTemplate{pattern: pattern}
+	rest := pattern
+	for {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return nil, fmt.Errorf("Unbalanced } in key template %q.", pattern)
+			}
+			k.literals = append(k.literals, rest)
+			return k, nil
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 || strings.IndexByte(rest[:open], '}') >= 0 {
+			return nil, fmt.Errorf("Unbalanced braces in key template %q.", pattern)
+		}
+		end += open
+
+		name := rest[open+1 : end]
+		if name == "" || strings.IndexByte(name, '{') >= 0 {
+			return nil, fmt.Errorf("Invalid placeholder in key template %q.", pattern)
+		}
+		if len(k.fields) > 0 && open == 0 {
+			return nil, fmt.Errorf("Placeholders {%s} and {%s} of key template %q need a separator.", k.fields[len(k.fields)-1], name, pattern)
+		}
+		k.literals = append(k.literals, rest[:open])
+		k.fields = append(k.fields, name)
+		rest = rest[end+1:]
+	}
+}
+
+// MustKeyTemplate is like NewKeyTemplate but panics on invalid patterns.
+// It is meant for package level variables.
+func MustKeyTemplate(pattern string) *KeyTemplate {
+	k, err := NewKeyTemplate(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+func (k *KeyTemplate) String() string {
+	return k.pattern
+}
+
+// Fields returns the placeholder names in order.
+func (k *KeyTemplate) Fields() []string {
+	return append([]string(nil), k.fields...)
+}
+
+// Format returns the key for values, which must hold every field. Values
+// are formatted with fmt.Sprint and may not contain the literal following
+// their placeholder.
+func (k *KeyTemplate) Format(values map[string]interface{}) (string, error) {
+	s, n, err := k.format(values)
+	if err != nil {
+		return "", err
+	}
+	if n < len(k.fields) {
+		return "", fmt.Errorf("Missing value for {%s} of key template %q.", k.fields[n], k.pattern)
+	}
+	return s, nil
+}
+
+// Prefix formats the template up to the first field missing from values,
+// literal included: with "USER#{id}#ORDER#{date}" and only id, it returns
+// "USER#42#ORDER#". This is the operand of begins_with conditions listing
+// an entity's items.
+func (k *KeyTemplate) Prefix(values map[string]interface{}) (string, error) {
+	s, _, err := k.format(values)
+	return s, err
+}
+
+// format writes the literals and values up to the first missing field and
+// returns how many fields were written.
+func (k *KeyTemplate) format(values map[string]interface{}) (string, int, error) {
+	var b strings.Builder
+	for i, name := range k.fields {
+		b.WriteString(k.literals[i])
+		v, ok := values[name]
+		if !ok {
+			return b.String(), i, nil
+		}
+		s := fmt.Sprint(v)
+		if next := k.literals[i+1]; next != "" && strings.Contains(s, next) {
+			return "", i, fmt.Errorf("Value %q of {%s} contains the separator %q of key template %q.", s, name, next, k.pattern)
+		}
+		b.WriteString(s)
+	}
+	b.WriteString(k.literals[len(k.fields)])
+	return b.String(), len(k.fields), nil
+}
+
+// Parse extracts the field values of a key formatted with the template.
+func (k *KeyTemplate) Parse(key string) (map[string]string, error) {
+	rest := key
+	if !strings.HasPrefix(rest, k.literals[0]) {
+		return nil, fmt.Errorf("Key %q does not match template %q.", key, k.pattern)
+	}
+	rest = rest[len(k.literals[0]):]
+
+	values := make(map[string]string, len(k.fields))
+	for i, name := range k.fields {
+		next := k.literals[i+1]
+		end := len(rest)
+		if next != "" {
+			end = strings.Index(rest, next)
+			if end < 0 {
+				return nil, fmt.Errorf("Key %q does not match template %q.", key, k.pattern)
+			}
+		}
+		values[name] = rest[:end]
+		rest = rest[end+len(next):]
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("Key %q does not match template %q.", key, k.pattern)
+	}
+	return values, nil
+}
+
+// Matches reports whether key was formatted with the template, e.g. to
+// tell apart the entities returned by a Query on a single table.
+func (k *KeyTemplate) Matches(key string) bool {
+	_, err := k.Parse(key)
+	return err == nil
+}
+
+// Equals returns the condition that attributeName holds the key for
+// values.
+func (k *KeyTemplate) Equals(attributeName string, values map[string]interface{}) (*AttributeComparison, error) {
+	s, err := k.Format(values)
+	if err != nil {
+		return nil, err
+	}
+	return NewStringAttributeComparison(attributeName, COMPARISON_EQUAL, s), nil
+}
+
+// BeginsWith returns the condition that attributeName starts with the
+// Prefix of the template for values, for range key conditions selecting
+// one kind of entity.
+func (k *KeyTemplate) BeginsWith(attributeName string, values map[string]interface{}) (*AttributeComparison, error) {
+	s, err := k.Prefix(values)
+	if err != nil {
+		return nil, err
+	}
+	return NewStringAttributeComparison(attributeName, COMPARISON_BEGINS_WITH, s), nil
+}
@@ -0,0 +1,49 @@
+package dynamodb_test
+
+import (
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type KeyTemplateSuite struct{}
+
+var _ = check.Suite(&KeyTemplateSuite{})
+
+func (s *KeyTemplateSuite) TestFormatAndParse(c *check.C) {
+	order := dynamodb.MustKeyTemplate("USER#{user}#ORDER#{date}#{id}")
+	c.Check(order.Fields(), check.DeepEquals, []string{"user", "date", "id"})
+
+	key, err := order.Format(map[string]interface{}{"user": 42, "date": "2020-01-02", "id": "a1"})
+	c.Assert(err, check.IsNil)
+	c.Check(key, check.Equals, "USER#42#ORDER#2020-01-02#a1")
+
+	values, err := order.Parse(key)
+	c.Assert(err, check.IsNil)
+	c.Check(values, check.DeepEquals, map[string]string{"user": "42", "date": "2020-01-02", "id": "a1"})
+	c.Check(order.Matches("USER#42#PROFILE"), check.Equals, false)
+
+	_, err = order.Format(map[string]interface{}{"user": 42})
+	c.Check(err, check.ErrorMatches, `Missing value for \{date\} of key template .*`)
+	_, err = order.Format(map[string]interface{}{"user": "4#ORDER#2", "date": "d", "id": "i"})
+	c.Check(err, check.ErrorMatches, `Value "4#ORDER#2" of \{user\} contains the separator "#ORDER#" .*`)
+
+	prefix, err := order.Prefix(map[string]interface{}{"user": 42})
+	c.Assert(err, check.IsNil)
+	c.Check(prefix, check.Equals, "USER#42#ORDER#")
+
+	cond, err := order.BeginsWith("SK", map[string]interface{}{"user": 42})
+	c.Assert(err, check.IsNil)
+	c.Check(cond, check.DeepEquals, dynamodb.NewStringAttributeComparison("SK", dynamodb.COMPARISON_BEGINS_WITH, "USER#42#ORDER#"))
+
+	profile := dynamodb.MustKeyTemplate("PROFILE")
+	key, err = profile.Format(nil)
+	c.Assert(err, check.IsNil)
+	c.Check(key, check.Equals, "PROFILE")
+}
+
+func (s *KeyTemplateSuite) TestInvalidTemplates(c *check.C) {
+	for _, pattern := range []string{"USER#{id", "USER#id}", "USER#{}", "{a}{b}", "{a{b}}"} {
+		_, err := dynamodb.NewKeyTemplate(pattern)
+		c.Check(err, check.NotNil, check.Commentf(pattern))
+	}
+}
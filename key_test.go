@@ -0,0 +1,89 @@
+package dynamodb_test
+
+import (
+	"math/big"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type KeySuite struct {
+	table *dynamodb.Table
+}
+
+var _ = check.Suite(&KeySuite{})
+
+func (s *KeySuite) SetUpTest(c *check.C) {
+	pk := dynamodb.PrimaryKey{
+		KeyAttribute:   dynamodb.NewNumericAttribute("userId", ""),
+		RangeAttribute: dynamodb.NewBinaryAttribute("digest", ""),
+	}
+	s.table = dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"}).NewTable("users", pk)
+}
+
+func (s *KeySuite) TestNewKey(c *check.C) {
+	k, err := dynamodb.NewKey(uint8(42), []byte{0xff})
+	c.Assert(err, check.IsNil)
+	c.Check(k, check.DeepEquals, &dynamodb.Key{HashKey: "42", RangeKey: "/w=="})
+
+	n, err := k.HashInt64()
+	c.Check(err, check.IsNil)
+	c.Check(n, check.Equals, int64(42))
+	b, err := k.RangeBytes()
+	c.Check(err, check.IsNil)
+	c.Check(b, check.DeepEquals, []byte{0xff})
+
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	k, err = dynamodb.NewKey(huge, nil)
+	c.Check(err, check.IsNil)
+	c.Check(k.HashKey, check.Equals, "123456789012345678901234567890")
+
+	_, err = dynamodb.NewKey(struct{}{}, nil)
+	c.Check(err, check.ErrorMatches, "Hash key: Unsupported key type.*")
+}
+
+func (s *KeySuite) TestTableNewKey(c *check.C) {
+	k, err := s.table.NewKey(int64(7), []byte("abc"))
+	c.Assert(err, check.IsNil)
+	c.Check(k, check.DeepEquals, &dynamodb.Key{HashKey: "7", RangeKey: "YWJj"})
+
+	// Strings in the wire format are accepted.
+	k, err = s.table.NewKey("7", "YWJj")
+	c.Assert(err, check.IsNil)
+	c.Check(k, check.DeepEquals, &dynamodb.Key{HashKey: "7", RangeKey: "YWJj"})
+
+	_, err = s.table.NewKey("seven", []byte("abc"))
+	c.Check(err, check.ErrorMatches, "Key userId is of type N, got string seven.")
+	_, err = s.table.NewKey(7, 8)
+	c.Check(err, check.ErrorMatches, "Key digest is of type B, got int 8.")
+	_, err = s.table.NewKey(7, nil)
+	c.Check(err, check.ErrorMatches, "Table users requires a range key.")
+}
+
+func (s *KeySuite) TestHashKeyCondition(c *check.C) {
+	c.Check(s.table.HashKeyCondition("7"), check.DeepEquals, &dynamodb.AttributeComparison{
+		AttributeName:      "userId",
+		ComparisonOperator: dynamodb.COMPARISON_EQUAL,
+		AttributeValueList: []dynamodb.Attribute{*dynamodb.NewNumericAttribute("userId", "7")},
+	})
+}
+
+func (s *KeySuite) TestKeyFromItem(c *check.C) {
+	item := map[string]*dynamodb.Attribute{
+		"userId": dynamodb.NewNumericAttribute("userId", "7"),
+		"digest": dynamodb.NewBinaryAttribute("digest", "YWJj"),
+		"name":   dynamodb.NewStringAttribute("name", "Alice"),
+	}
+	k, err := s.table.KeyFromItem(item)
+	c.Assert(err, check.IsNil)
+	c.Check(k, check.DeepEquals, &dynamodb.Key{HashKey: "7", RangeKey: "YWJj"})
+
+	delete(item, "digest")
+	_, err = s.table.KeyFromItem(item)
+	c.Check(err, check.ErrorMatches, "Item does not contain the key attribute digest.")
+
+	item["digest"] = dynamodb.NewStringAttribute("digest", "abc")
+	_, err = s.table.KeyFromItem(item)
+	c.Check(err, check.ErrorMatches, "Key digest is of type B, got S.")
+}
@@ -0,0 +1,91 @@
+package dynamodb
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+)
+
+type LogLevel int
+
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+	LogOff
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "DEBUG"
+	case LogInfo:
+		return "INFO"
+	case LogWarn:
+		return "WARN"
+	case LogError:
+		return "ERROR"
+	}
+	return "OFF"
+}
+
+// Logger receives the client's log messages. keyvals alternate keys and
+// values, e.g. Log(LogWarn, "retrying request", "target", target).
+type Logger interface {
+	Log(level LogLevel, msg string, keyvals ...interface{})
+}
+
+// LoggerFunc adapts a function to the Logger interface.
+type LoggerFunc func(level LogLevel, msg string, keyvals ...interface{})
+
+func (f LoggerFunc) Log(level LogLevel, msg string, keyvals ...interface{}) {
+	f(level, msg, keyvals...)
+}
+
+type stdLogger struct {
+	l        *log.Logger
+	minLevel LogLevel
+}
+
+// NewStdLogger writes messages at minLevel and above to l, or to the
+// standard logger when l is nil.
+func NewStdLogger(l *log.Logger, minLevel LogLevel) Logger {
+	return &stdLogger{l, minLevel}
+}
+
+func (s *stdLogger) Log(level LogLevel, msg string, keyvals ...interface{}) {
+	if level < s.minLevel {
+		return
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "dynamodb: [%s] %s", level, msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		var v interface{} = "(missing)"
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		fmt.Fprintf(&b, " %v=%v", keyvals[i], v)
+	}
+
+	if s.l != nil {
+		s.l.Print(b.String())
+	} else {
+		log.Print(b.String())
+	}
+}
+
+// NopLogger discards every message.
+var NopLogger Logger = LoggerFunc(func(LogLevel, string, ...interface{}) {})
+
+// DefaultLogger is used by Servers without a Logger and by code paths not
+// tied to a Server. It logs warnings and errors to the standard logger.
+var DefaultLogger = NewStdLogger(nil, LogWarn)
+
+func (s *Server) logger() Logger {
+	if s != nil && s.Logger != nil {
+		return s.Logger
+	}
+	return DefaultLogger
+}
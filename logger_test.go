@@ -0,0 +1,29 @@
+package dynamodb_test
+
+import (
+	"bytes"
+	"log"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type LoggerSuite struct {
+}
+
+var _ = check.Suite(&LoggerSuite{})
+
+func (s *LoggerSuite) TestStdLogger(c *check.C) {
+	var buf bytes.Buffer
+	logger := dynamodb.NewStdLogger(log.New(&buf, "", 0), dynamodb.LogInfo)
+
+	logger.Log(dynamodb.LogDebug, "request", "target", "GetItem")
+	c.Check(buf.String(), check.Equals, "")
+
+	logger.Log(dynamodb.LogWarn, "retrying request", "target", "PutItem", "retry", 2)
+	c.Check(buf.String(), check.Equals, "dynamodb: [WARN] retrying request target=PutItem retry=2\n")
+
+	buf.Reset()
+	logger.Log(dynamodb.LogError, "odd", "key")
+	c.Check(buf.String(), check.Equals, "dynamodb: [ERROR] odd key=(missing)\n")
+}
@@ -0,0 +1,53 @@
+package dynamodb
+
+import "reflect"
+
+// Marshaler is implemented by types controlling their own Dynamodb
+// representation, such as UUIDs, money amounts or enums, in the place of
+// the default reflection based marshaling. The Name of the returned
+// attribute is replaced with the attribute name of the field; a nil
+// attribute omits the field.
+type Marshaler interface {
+	MarshalDynamoDB() (*Attribute, error)
+}
+
+// Unmarshaler is implemented by types decoding their own Dynamodb
+// representation, as produced by their Marshaler.
+type Unmarshaler interface {
+	UnmarshalDynamoDB(a *Attribute) error
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+)
+
+// marshalerOf returns the Marshaler of v, nil when v does not implement
+// it or is a nil pointer.
+func marshalerOf(v reflect.Value) Marshaler {
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil
+	}
+	if v.Type().Implements(marshalerType) {
+		return v.Interface().(Marshaler)
+	}
+	if v.CanAddr() && reflect.PtrTo(v.Type()).Implements(marshalerType) {
+		return v.Addr().Interface().(Marshaler)
+	}
+	return nil
+}
+
+// unmarshalerOf returns the Unmarshaler of v, allocating a nil pointer,
+// or nil when v does not implement it.
+func unmarshalerOf(v reflect.Value) Unmarshaler {
+	if v.Kind() == reflect.Ptr && v.Type().Implements(unmarshalerType) {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return v.Interface().(Unmarshaler)
+	}
+	if v.CanAddr() && reflect.PtrTo(v.Type()).Implements(unmarshalerType) {
+		return v.Addr().Interface().(Unmarshaler)
+	}
+	return nil
+}
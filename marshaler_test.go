@@ -0,0 +1,83 @@
+package dynamodb_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type MarshalerSuite struct{}
+
+var _ = check.Suite(&MarshalerSuite{})
+
+// money is stored as a number of cents.
+type money struct {
+	Cents int64
+}
+
+func (m money) MarshalDynamoDB() (*dynamodb.Attribute, error) {
+	return dynamodb.NewNumericAttribute("", fmt.Sprint(m.Cents)), nil
+}
+
+func (m *money) UnmarshalDynamoDB(a *dynamodb.Attribute) error {
+	if a.Type != dynamodb.TYPE_NUMBER {
+		return errors.New("money must be a number")
+	}
+	_, err := fmt.Sscan(a.Value, &m.Cents)
+	return err
+}
+
+// status is an enum stored by name, omitted when unknown.
+type status int
+
+func (s status) MarshalDynamoDB() (*dynamodb.Attribute, error) {
+	switch s {
+	case 1:
+		return dynamodb.NewStringAttribute("", "ACTIVE"), nil
+	case 2:
+		return dynamodb.NewStringAttribute("", "CLOSED"), nil
+	}
+	return nil, nil
+}
+
+func (s *status) UnmarshalDynamoDB(a *dynamodb.Attribute) error {
+	*s = map[string]status{"ACTIVE": 1, "CLOSED": 2}[strings.ToUpper(a.Value)]
+	return nil
+}
+
+type account struct {
+	ID      string `dynamodb:"id"`
+	Balance money  `dynamodb:"balance"`
+	Limit   *money `dynamodb:"limit"`
+	Status  status `dynamodb:"status"`
+}
+
+func (s *MarshalerSuite) TestRoundTrip(c *check.C) {
+	in := &account{ID: "a1", Balance: money{1250}, Limit: &money{0}, Status: 2}
+	attrs, err := dynamodb.MarshalAttributes(in)
+	c.Assert(err, check.IsNil)
+	c.Check(attrs, check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", "a1"),
+		*dynamodb.NewNumericAttribute("balance", "1250"),
+		*dynamodb.NewNumericAttribute("limit", "0"),
+		*dynamodb.NewStringAttribute("status", "CLOSED"),
+	})
+
+	item := make(map[string]*dynamodb.Attribute)
+	for i := range attrs {
+		item[attrs[i].Name] = &attrs[i]
+	}
+	out := &account{}
+	c.Assert(dynamodb.UnmarshalAttributes(&item, out), check.IsNil)
+	c.Check(out, check.DeepEquals, in)
+
+	// A nil attribute omits the field; errors are reported.
+	attrs, err = dynamodb.MarshalAttributes(&account{ID: "a2"})
+	c.Assert(err, check.IsNil)
+	c.Check(attrs, check.HasLen, 2)
+	item["balance"] = dynamodb.NewStringAttribute("balance", "x")
+	c.Check(dynamodb.UnmarshalAttributes(&item, out), check.ErrorMatches, "money must be a number")
+}
@@ -3,6 +3,7 @@ package dynamodb
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"reflect"
@@ -13,18 +14,66 @@ import (
 	"unicode"
 )
 
+// MarshalAttributes converts the exported fields of the struct m points
+// to into attributes. Fields are named by the dynamodb tag, or failing
+// that the json tag, which also take comma separated options:
+//
+//	`dynamodb:"-"`            skips the field
+//	`dynamodb:"n,omitempty"`  omits false, 0, "", nil and empty values
+//	`dynamodb:"n,string"`     stores a number or bool as a string
+//	`dynamodb:"n,set"`        stores a slice as a set, dropping duplicates
+//	`dynamodb:"n,list"`       stores a slice as a list
+//	`dynamodb:"n,unixtime"`   stores a time.Time as seconds since the epoch
+//	`dynamodb:"n,alias=old"`  also reads the attribute old
+//	`dynamodb:"n,write=old"`  writes the attribute old instead of n
+//	`dynamodb:",inline"`      flattens a struct into the item, or stores
+//	                          each member of a map as an attribute
+//
+// Without options, slices of strings and numbers are stored as sets and
+// other slices, arrays, maps and structs as JSON strings. The fields of
+// embedded structs without a name in their tag are flattened into the
+// item, following the rules of encoding/json. On unmarshal, a map tagged
+// inline receives the attributes no other field reads.
 func MarshalAttributes(m interface{}) ([]Attribute, error) {
+	return MarshalAttributesWithOptions(m, nil)
+}
+
+// MarshalAttributesWithOptions is MarshalAttributes applying opts, nil
+// for the defaults.
+func MarshalAttributesWithOptions(m interface{}, opts *MarshalOptions) ([]Attribute, error) {
+	if opts == nil {
+		opts = &MarshalOptions{}
+	}
+	if opts.EmptySets == EmptyKeep {
+		return nil, errors.New("EmptyKeep is not supported for sets, Dynamodb rejects empty sets.")
+	}
 	v := reflect.ValueOf(m).Elem()
 
 	builder := &attributeBuilder{}
 	builder.buffer = []Attribute{}
 	for _, f := range cachedTypeFields(v.Type()) { // loop on each field
 		fv := fieldByIndex(v, f.index)
-		if !fv.IsValid() || isEmptyValueToOmit(fv) {
+		if !fv.IsValid() || f.omitEmpty && f.isEmpty(fv) {
+			continue
+		}
+		if f.inlineMap {
+			if err := builder.marshalInline(fv); err != nil {
+				return builder.buffer, err
+			}
+			continue
+		}
+		if isEmptyValueToOmit(fv) && marshalerOf(fv) == nil {
+			a, err := opts.emptyAttribute(&f, fv)
+			if err != nil {
+				return builder.buffer, err
+			}
+			if a != nil {
+				builder.Push(a)
+			}
 			continue
 		}
 
-		err := builder.reflectToDynamoDBAttribute(f.name, fv)
+		err := builder.marshalField(&f, fv)
 		if err != nil {
 			return builder.buffer, err
 		}
@@ -42,13 +91,24 @@ func UnmarshalAttributes(attributesRef *map[string]*Attribute, m interface{}) er
 	v := reflect.ValueOf(m).Elem()
 
 	attributes := *attributesRef
-	for _, f := range cachedTypeFields(v.Type()) { // loop on each field
-		fv := fieldByIndex(v, f.index)
-		correlatedAttribute := attributes[f.name]
+	if native, ok := m.(*map[string]interface{}); ok {
+		*native = NativeItem(attributes)
+		return nil
+	}
+	fields := cachedTypeFields(v.Type())
+	for _, f := range fields { // loop on each field
+		if f.inlineMap {
+			if err := unmarshalInline(fields, attributes, allocFieldByIndex(v, f.index)); err != nil {
+				return err
+			}
+			continue
+		}
+		correlatedAttribute := f.lookup(attributes)
 		if correlatedAttribute == nil {
 			continue
 		}
-		err := unmarshallAttribute(correlatedAttribute, fv)
+		fv := allocFieldByIndex(v, f.index)
+		err := unmarshalField(&f, correlatedAttribute, fv)
 		if err != nil {
 			return err
 		}
@@ -66,6 +126,19 @@ func (builder *attributeBuilder) Push(attribute *Attribute) {
 }
 
 func unmarshallAttribute(a *Attribute, v reflect.Value) error {
+	if u := unmarshalerOf(v); u != nil {
+		return u.UnmarshalDynamoDB(a)
+	}
+	if a.Type == TYPE_NULL {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if a.Type == TYPE_LIST || a.Type == TYPE_MAP {
+		if handled, err := unmarshalDocument(a, v); handled {
+			return err
+		}
+	}
+
 	switch v.Kind() {
 	case reflect.Bool:
 		n, err := strconv.ParseInt(a.Value, 10, 64)
@@ -167,6 +240,21 @@ func unmarshallAttribute(a *Attribute, v reflect.Value) error {
 					arry.Index(i).SetString(aval)
 				}
 				v.Set(arry)
+
+			case reflect.Slice:
+				if a.Type != TYPE_BINARY_SET || v.Type().Elem().Elem().Kind() != reflect.Uint8 {
+					break
+				}
+				nativeSetCreated = true
+				arry := reflect.MakeSlice(v.Type(), len(a.SetValues), len(a.SetValues))
+				for i, aval := range a.SetValues {
+					b, err := base64.StdEncoding.DecodeString(aval)
+					if err != nil {
+						return fmt.Errorf("UnmarshalSetTypeError (byte) %#v: %#v", aval, err)
+					}
+					arry.Index(i).SetBytes(b)
+				}
+				v.Set(arry)
 			}
 
 			if nativeSetCreated {
@@ -178,6 +266,9 @@ func unmarshallAttribute(a *Attribute, v reflect.Value) error {
 		// as arrays.
 		fallthrough
 	case reflect.Array, reflect.Struct, reflect.Map, reflect.Interface, reflect.Ptr:
+		if a.SetType() && isSetType(v.Type()) {
+			return unmarshalSet(a, v)
+		}
 		unmarshalled := reflect.New(v.Type())
 		err := json.Unmarshal([]byte(a.Value), unmarshalled.Interface())
 		if err != nil {
@@ -192,6 +283,30 @@ func unmarshallAttribute(a *Attribute, v reflect.Value) error {
 	return nil
 }
 
+var emptyStructType = reflect.TypeOf(struct{}{})
+
+// isSetType reports whether t is a map[string]struct{}, such as StringSet,
+// which set attributes decode into.
+func isSetType(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem() == emptyStructType
+}
+
+func unmarshalSet(a *Attribute, v reflect.Value) error {
+	set := reflect.MakeMapWithSize(v.Type(), len(a.SetValues))
+	for _, aval := range a.SetValues {
+		if a.Type == TYPE_BINARY_SET {
+			b, err := base64.StdEncoding.DecodeString(aval)
+			if err != nil {
+				return fmt.Errorf("UnmarshalSetTypeError (byte) %#v: %#v", aval, err)
+			}
+			aval = string(b)
+		}
+		set.SetMapIndex(reflect.ValueOf(aval).Convert(v.Type().Key()), reflect.Zero(emptyStructType))
+	}
+	v.Set(set)
+	return nil
+}
+
 // reflectValueQuoted writes the value in v to the output.
 // If quoted is true, the serialization is wrapped in a JSON string.
 func (e *attributeBuilder) reflectToDynamoDBAttribute(name string, v reflect.Value) error {
@@ -199,6 +314,26 @@ func (e *attributeBuilder) reflectToDynamoDBAttribute(name string, v reflect.Val
 		return nil
 	} // don't build
 
+	if m := marshalerOf(v); m != nil {
+		a, err := m.MarshalDynamoDB()
+		if err != nil {
+			return err
+		}
+		if a != nil {
+			c := *a
+			c.Name = name
+			e.Push(&c)
+		}
+		return nil
+	}
+
+	if v.Kind() == reflect.Map {
+		if s, ok := v.Interface().(Set); ok {
+			e.Push(s.Attribute(name))
+			return nil
+		}
+	}
+
 	switch v.Kind() {
 	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64:
 		rv, err := numericReflectedValueString(v)
@@ -337,6 +472,21 @@ func fieldByIndex(v reflect.Value, index []int) reflect.Value {
 	return v
 }
 
+// allocFieldByIndex is fieldByIndex allocating the nil pointers to
+// embedded structs on the way.
+func allocFieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
 // A field represents a single field found in a struct.
 type field struct {
 	name      string
@@ -344,7 +494,34 @@ type field struct {
 	index     []int
 	typ       reflect.Type
 	omitEmpty bool
-	quoted    bool
+	quoted    bool     // numbers stored as strings
+	set       bool     // slices stored as sets, even of []byte
+	list      bool     // slices stored as lists
+	unixtime  bool     // times stored as seconds since the epoch
+	aliases   []string // other attribute names accepted on unmarshal
+	write     string   // attribute name used on marshal, if not name
+	inlineMap bool     // map holding the attributes of no other field
+}
+
+func (f *field) writeName() string {
+	if f.write != "" {
+		return f.write
+	}
+	return f.name
+}
+
+// lookup returns the attribute stored under the field's name or, failing
+// that, under the first of its aliases present in attributes.
+func (f *field) lookup(attributes map[string]*Attribute) *Attribute {
+	if a := attributes[f.name]; a != nil {
+		return a
+	}
+	for _, alias := range f.aliases {
+		if a := attributes[alias]; a != nil {
+			return a
+		}
+	}
+	return nil
 }
 
 // byName sorts field by name, breaking ties with depth,
@@ -433,6 +610,18 @@ func (o tagOptions) Contains(optionName string) bool {
 	return false
 }
 
+// Values returns the values of every "optionName=value" option, in order.
+func (o tagOptions) Values(optionName string) []string {
+	var values []string
+	prefix := optionName + "="
+	for _, opt := range strings.Split(string(o), ",") {
+		if strings.HasPrefix(opt, prefix) {
+			values = append(values, opt[len(prefix):])
+		}
+	}
+	return values
+}
+
 // parseTag splits a struct field's json tag into its name and
 // comma-separated options.
 func parseTag(tag string) (string, tagOptions) {
@@ -476,7 +665,11 @@ func typeFields(t reflect.Type) []field {
 				if sf.PkgPath != "" { // unexported
 					continue
 				}
-				tag := sf.Tag.Get("json")
+				// The dynamodb tag takes precedence over the json tag.
+				tag, ok := sf.Tag.Lookup("dynamodb")
+				if !ok {
+					tag = sf.Tag.Get("json")
+				}
 				if tag == "-" {
 					continue
 				}
@@ -494,14 +687,33 @@ func typeFields(t reflect.Type) []field {
 					ft = ft.Elem()
 				}
 
-				// Record found field and index sequence.
-				if name != "" || !sf.Anonymous || ft.Kind() != reflect.Struct {
+				// Record found field and index sequence. Structs
+				// tagged inline are explored like embedded ones.
+				inline := opts.Contains("inline")
+				explore := ft.Kind() == reflect.Struct && (inline || name == "" && sf.Anonymous)
+				if !explore {
 					tagged := name != ""
 					if name == "" {
 						name = sf.Name
 					}
-					fields = append(fields, field{name, tagged, index, ft,
-						opts.Contains("omitempty"), opts.Contains("string")})
+					var write string
+					if w := opts.Values("write"); len(w) > 0 {
+						write = w[len(w)-1]
+					}
+					fields = append(fields, field{
+						name:      name,
+						tag:       tagged,
+						index:     index,
+						typ:       ft,
+						omitEmpty: opts.Contains("omitempty"),
+						quoted:    opts.Contains("string"),
+						set:       opts.Contains("set"),
+						list:      opts.Contains("list"),
+						unixtime:  opts.Contains("unixtime"),
+						aliases:   opts.Values("alias"),
+						write:     write,
+						inlineMap: inline && ft.Kind() == reflect.Map,
+					})
 					if count[f.typ] > 1 {
 						// If there were multiple instances, add a second,
 						// so that the annihilation code will see a duplicate.
@@ -280,3 +280,43 @@ func (s *MarshallerSuite) TestUnmarshalEmptySets(c *check.C) {
 	expected := testObjectWithNilSets()
 	c.Check(testObj, check.DeepEquals, expected)
 }
+
+type TestStructAlias struct {
+	UserId string `dynamodb:"userId,alias=user_id,alias=uid"`
+	Legacy string `dynamodb:"newName,alias=oldName,write=oldName"`
+	Plain  string `json:"plain"`
+	Hidden string `dynamodb:"-" json:"hidden"`
+}
+
+func (s *MarshallerSuite) TestMarshalAlias(c *check.C) {
+	testObj := &TestStructAlias{UserId: "u1", Legacy: "l1", Plain: "p1", Hidden: "h1"}
+	attrs, err := dynamodb.MarshalAttributes(testObj)
+	if err != nil {
+		c.Errorf("Error from dynamodb.MarshalAttributes: %#v", err)
+	}
+
+	expected := []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("userId", "u1"),
+		*dynamodb.NewStringAttribute("oldName", "l1"),
+		*dynamodb.NewStringAttribute("plain", "p1"),
+	}
+	c.Check(attrs, check.DeepEquals, expected)
+}
+
+func (s *MarshallerSuite) TestUnmarshalAlias(c *check.C) {
+	attrMap := map[string]*dynamodb.Attribute{
+		"uid":     dynamodb.NewStringAttribute("uid", "u2"),
+		"user_id": dynamodb.NewStringAttribute("user_id", "u1"),
+		"newName": dynamodb.NewStringAttribute("newName", "n1"),
+		"oldName": dynamodb.NewStringAttribute("oldName", "o1"),
+		"hidden":  dynamodb.NewStringAttribute("hidden", "h1"),
+	}
+
+	testObj := &TestStructAlias{}
+	err := dynamodb.UnmarshalAttributes(&attrMap, testObj)
+	if err != nil {
+		c.Fatalf("Error from dynamodb.UnmarshalAttributes: %#v (Built: %#v)", err, testObj)
+	}
+
+	c.Check(testObj, check.DeepEquals, &TestStructAlias{UserId: "u1", Legacy: "n1"})
+}
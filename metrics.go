@@ -0,0 +1,88 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// OperationMetrics describes one completed Dynamodb call.
+type OperationMetrics struct {
+	Operation string // e.g. "Query"
+	TableName string // empty for operations not bound to a table
+	Duration  time.Duration
+	// Number of times the request was re-sent, and how many of the
+	// attempts were throttled.
+	Retries   int
+	Throttles int
+	// Capacity units reported by Dynamodb. Only non zero when the request
+	// asked for ReturnConsumedCapacity.
+	ConsumedCapacity float64
+	// The x-amzn-RequestId of the last response, if any.
+	RequestID string
+	Err       error
+}
+
+// MetricsCollector is invoked once per Dynamodb call, after retries.
+type MetricsCollector interface {
+	ObserveOperation(m OperationMetrics)
+}
+
+// MetricsCollectorFunc adapts a function to the MetricsCollector interface.
+type MetricsCollectorFunc func(m OperationMetrics)
+
+func (f MetricsCollectorFunc) ObserveOperation(m OperationMetrics) {
+	f(m)
+}
+
+// callStats accumulates per call counters while a request is retried.
+type callStats struct {
+	retries   int
+	throttles int
+	requestID string
+}
+
+type callStatsKey struct{}
+
+func withCallStats(ctx context.Context) (context.Context, *callStats) {
+	stats := &callStats{}
+	return context.WithValue(ctx, callStatsKey{}, stats), stats
+}
+
+func statsFromContext(ctx context.Context) *callStats {
+	if stats, ok := ctx.Value(callStatsKey{}).(*callStats); ok {
+		return stats
+	}
+	return &callStats{}
+}
+
+func (s *Server) observe(req *Request, started time.Time, stats *callStats, response []byte, err error) {
+	if s.Metrics == nil {
+		return
+	}
+	s.Metrics.ObserveOperation(OperationMetrics{
+		Operation:        req.Operation,
+		TableName:        requestTableName(req.Body),
+		Duration:         time.Since(started),
+		Retries:          stats.retries,
+		Throttles:        stats.throttles,
+		ConsumedCapacity: responseCapacityUnits(response),
+		RequestID:        stats.requestID,
+		Err:              err,
+	})
+}
+
+func requestTableName(body []byte) string {
+	var r struct{ TableName string }
+	json.Unmarshal(body, &r)
+	return r.TableName
+}
+
+// responseCapacityUnits sums the capacity consumed on all tables.
+func responseCapacityUnits(body []byte) float64 {
+	var total float64
+	for _, units := range consumedUnitsByTable(body) {
+		total += units
+	}
+	return total
+}
@@ -0,0 +1,143 @@
+package dynamodb
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the latency
+// histogram kept by PrometheusCollector.
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type promLabels struct {
+	operation string
+	table     string
+}
+
+type promSeries struct {
+	requests  uint64
+	errors    uint64
+	retries   uint64
+	throttles uint64
+	capacity  float64
+	buckets   []uint64 // cumulative counts per latency bucket
+	sum       float64
+	count     uint64
+}
+
+// PrometheusCollector is a MetricsCollector which keeps counters and a
+// latency histogram per operation and table, and serves them in the
+// Prometheus text exposition format. Register it as an http.Handler on
+// the process' metrics endpoint.
+type PrometheusCollector struct {
+	// Prefix of every metric name, "dynamodb" by default.
+	Namespace string
+	Buckets   []float64
+
+	mu     sync.Mutex
+	series map[promLabels]*promSeries
+}
+
+func NewPrometheusCollector() *PrometheusCollector {
+	return &PrometheusCollector{
+		Namespace: "dynamodb",
+		Buckets:   DefaultLatencyBuckets,
+		series:    make(map[promLabels]*promSeries),
+	}
+}
+
+func (p *PrometheusCollector) ObserveOperation(m OperationMetrics) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.series == nil {
+		p.series = make(map[promLabels]*promSeries)
+	}
+	labels := promLabels{m.Operation, m.TableName}
+	s, ok := p.series[labels]
+	if !ok {
+		s = &promSeries{buckets: make([]uint64, len(p.Buckets))}
+		p.series[labels] = s
+	}
+
+	s.requests++
+	if m.Err != nil {
+		s.errors++
+	}
+	s.retries += uint64(m.Retries)
+	s.throttles += uint64(m.Throttles)
+	s.capacity += m.ConsumedCapacity
+
+	seconds := m.Duration.Seconds()
+	for i, le := range p.Buckets {
+		if seconds <= le {
+			s.buckets[i]++
+		}
+	}
+	s.sum += seconds
+	s.count++
+}
+
+func (p *PrometheusCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	p.WriteTo(w)
+}
+
+// WriteTo writes every metric in the Prometheus text format.
+func (p *PrometheusCollector) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	keys := make([]promLabels, 0, len(p.series))
+	for k := range p.series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operation != keys[j].operation {
+			return keys[i].operation < keys[j].operation
+		}
+		return keys[i].table < keys[j].table
+	})
+
+	var b strings.Builder
+	counter := func(name, help string, value func(*promSeries) string) {
+		name = p.Namespace + "_" + name
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "%s{%s} %s\n", name, k.format(), value(p.series[k]))
+		}
+	}
+	counter("requests_total", "Dynamodb calls.", func(s *promSeries) string { return strconv.FormatUint(s.requests, 10) })
+	counter("errors_total", "Dynamodb calls which failed.", func(s *promSeries) string { return strconv.FormatUint(s.errors, 10) })
+	counter("retries_total", "Dynamodb requests re-sent.", func(s *promSeries) string { return strconv.FormatUint(s.retries, 10) })
+	counter("throttles_total", "Dynamodb requests throttled.", func(s *promSeries) string { return strconv.FormatUint(s.throttles, 10) })
+	counter("consumed_capacity_units_total", "Capacity units consumed.", func(s *promSeries) string { return formatFloat(s.capacity) })
+
+	name := p.Namespace + "_request_duration_seconds"
+	fmt.Fprintf(&b, "# HELP %s Dynamodb call latency, including retries.\n# TYPE %s histogram\n", name, name)
+	for _, k := range keys {
+		s := p.series[k]
+		for i, le := range p.Buckets {
+			fmt.Fprintf(&b, "%s_bucket{%s,le=\"%s\"} %d\n", name, k.format(), formatFloat(le), s.buckets[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, k.format(), s.count)
+		fmt.Fprintf(&b, "%s_sum{%s} %s\n", name, k.format(), formatFloat(s.sum))
+		fmt.Fprintf(&b, "%s_count{%s} %d\n", name, k.format(), s.count)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func (l promLabels) format() string {
+	return fmt.Sprintf("operation=%s,table=%s", strconv.Quote(l.operation), strconv.Quote(l.table))
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
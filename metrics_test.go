@@ -0,0 +1,66 @@
+package dynamodb_test
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type MetricsSuite struct {
+}
+
+var _ = check.Suite(&MetricsSuite{})
+
+func (s *MetricsSuite) TestCollectorObservesOperation(c *check.C) {
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"})
+	var observed []dynamodb.OperationMetrics
+	server.Metrics = dynamodb.MetricsCollectorFunc(func(m dynamodb.OperationMetrics) {
+		observed = append(observed, m)
+	})
+	server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			return []byte(`{"ConsumedCapacity":{"TableName":"users","CapacityUnits":1.5}}`), nil
+		}
+	})
+
+	pk := dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")}
+	table := server.NewTable("users", pk)
+	if _, err := table.DeleteItem(&dynamodb.Key{HashKey: "u1"}, false); err != nil {
+		c.Fatal(err)
+	}
+
+	c.Assert(observed, check.HasLen, 1)
+	c.Check(observed[0].Operation, check.Equals, "DeleteItem")
+	c.Check(observed[0].TableName, check.Equals, "users")
+	c.Check(observed[0].ConsumedCapacity, check.Equals, 1.5)
+	c.Check(observed[0].Err, check.IsNil)
+}
+
+func (s *MetricsSuite) TestPrometheusCollector(c *check.C) {
+	p := dynamodb.NewPrometheusCollector()
+	p.Buckets = []float64{0.1, 1}
+	p.ObserveOperation(dynamodb.OperationMetrics{Operation: "Query", TableName: "users", Duration: 50 * time.Millisecond, Retries: 2, Throttles: 1, ConsumedCapacity: 0.5})
+	p.ObserveOperation(dynamodb.OperationMetrics{Operation: "Query", TableName: "users", Duration: 500 * time.Millisecond, Err: dynamodb.ErrNotFound})
+
+	var buf bytes.Buffer
+	p.WriteTo(&buf)
+	out := buf.String()
+
+	for _, line := range []string{
+		`dynamodb_requests_total{operation="Query",table="users"} 2`,
+		`dynamodb_errors_total{operation="Query",table="users"} 1`,
+		`dynamodb_retries_total{operation="Query",table="users"} 2`,
+		`dynamodb_throttles_total{operation="Query",table="users"} 1`,
+		`dynamodb_consumed_capacity_units_total{operation="Query",table="users"} 0.5`,
+		`dynamodb_request_duration_seconds_bucket{operation="Query",table="users",le="0.1"} 1`,
+		`dynamodb_request_duration_seconds_bucket{operation="Query",table="users",le="1"} 2`,
+		`dynamodb_request_duration_seconds_bucket{operation="Query",table="users",le="+Inf"} 2`,
+		`dynamodb_request_duration_seconds_count{operation="Query",table="users"} 2`,
+	} {
+		c.Check(strings.Contains(out, line+"\n"), check.Equals, true, check.Commentf("missing %q in:\n%s", line, out))
+	}
+}
@@ -0,0 +1,49 @@
+package dynamodb
+
+import (
+	"context"
+)
+
+// Request is a single Dynamodb API call as seen by middleware.
+type Request struct {
+	Context context.Context
+	// Operation name, e.g. "GetItem".
+	Operation string
+	// Value of the X-Amz-Target header, e.g. "DynamoDB_20120810.GetItem".
+	Target   string
+	Endpoint string
+	// The JSON request body. Middleware may replace it.
+	Body []byte
+}
+
+// Handler sends a request and returns the raw JSON response body. Errors
+// returned by Dynamodb are *Error values.
+type Handler func(req *Request) ([]byte, error)
+
+// Middleware wraps a Handler to observe or alter requests and responses,
+// e.g. for metrics, tracing or custom retries. Calling next more than once
+// re-sends the request.
+type Middleware func(next Handler) Handler
+
+// Use appends middleware to the chain. It may be called while requests
+// are in flight; those already started keep the previous chain.
+func (s *Server) Use(middleware ...Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	chain := make([]Middleware, 0, len(s.Middleware)+len(middleware))
+	chain = append(chain, s.Middleware...)
+	s.Middleware = append(chain, middleware...)
+}
+
+// handler wraps the terminal handler with the Server's middleware.
+func (s *Server) handler(terminal Handler) Handler {
+	s.mu.Lock()
+	chain := s.Middleware
+	s.mu.Unlock()
+
+	h := terminal
+	for i := len(chain) - 1; i >= 0; i-- {
+		h = chain[i](h)
+	}
+	return h
+}
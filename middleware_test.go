@@ -0,0 +1,152 @@
+package dynamodb_test
+
+import (
+	"context"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type MiddlewareSuite struct {
+	server *dynamodb.Server
+	table  *dynamodb.Table
+}
+
+var _ = check.Suite(&MiddlewareSuite{})
+
+func (s *MiddlewareSuite) SetUpTest(c *check.C) {
+	s.server = dynamodb.New(aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"})
+	pk := dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")}
+	s.table = s.server.NewTable("users", pk)
+}
+
+func (s *MiddlewareSuite) TestChainOrderAndShortCircuit(c *check.C) {
+	var calls []string
+	trace := func(name string) dynamodb.Middleware {
+		return func(next dynamodb.Handler) dynamodb.Handler {
+			return func(req *dynamodb.Request) ([]byte, error) {
+				calls = append(calls, name+":"+req.Operation)
+				return next(req)
+			}
+		}
+	}
+	s.server.Use(trace("outer"), trace("inner"))
+	s.server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			c.Check(req.Target, check.Equals, "DynamoDB_20120810.GetItem")
+			c.Check(string(req.Body), check.Equals, `{"Key":{"id":{"S":"u1"}},"TableName":"users"}`)
+			return []byte(`{"Item":{"id":{"S":"u1"},"name":{"S":"Alice"}}}`), nil
+		}
+	})
+
+	item, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(item["name"], check.DeepEquals, dynamodb.NewStringAttribute("name", "Alice"))
+	c.Check(calls, check.DeepEquals, []string{"outer:GetItem", "inner:GetItem"})
+}
+
+func (s *MiddlewareSuite) TestErrorPropagation(c *check.C) {
+	s.server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			return nil, &dynamodb.Error{StatusCode: 400, Code: dynamodb.ConditionalCheckFailedException}
+		}
+	})
+
+	_, err := s.table.DeleteItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Check(dynamodb.IsConditionalCheckFailed(err), check.Equals, true)
+}
+
+func (s *MiddlewareSuite) TestDo(c *check.C) {
+	var requests []*dynamodb.Request
+	s.server.Region.DynamoDBEndpoint = "https://dynamodb.us-east-1.amazonaws.com"
+	s.server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			requests = append(requests, req)
+			return []byte(`{"ok":true}`), nil
+		}
+	})
+
+	response, err := s.server.Do(dynamodb.OPERATION_DESCRIBE_LIMITS, []byte(`{}`))
+	c.Assert(err, check.IsNil)
+	c.Check(string(response), check.Equals, `{"ok":true}`)
+	_, err = s.server.Do(dynamodb.OPERATION_LIST_STREAMS, []byte(`{"TableName":"users"}`))
+	c.Assert(err, check.IsNil)
+
+	c.Assert(requests, check.HasLen, 2)
+	c.Check(requests[0].Target, check.Equals, "DynamoDB_20120810.DescribeLimits")
+	c.Check(requests[0].Operation, check.Equals, "DescribeLimits")
+	c.Check(requests[0].Endpoint, check.Equals, "https://dynamodb.us-east-1.amazonaws.com")
+	c.Check(requests[1].Target, check.Equals, "DynamoDBStreams_20120810.ListStreams")
+	c.Check(requests[1].Endpoint, check.Equals, "https://streams.dynamodb.us-east-1.amazonaws.com")
+	c.Check(string(requests[1].Body), check.Equals, `{"TableName":"users"}`)
+}
+
+func (s *MiddlewareSuite) TestResponseDecoding(c *check.C) {
+	s.server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation == "GetItem" {
+				return []byte(`{"Item":{"id":{"S":"u1"},"n":{"N":"1.5"},"tags":{"SS":["a","b"]},"ok":{"BOOL":true},"m":{"M":{}}}}`), nil
+			}
+			return []byte(`{"Count":1,"ScannedCount":3,"Items":[{"id":{"S":"u2"}}],"LastEvaluatedKey":{"id":{"S":"u2"}},"ConsumedCapacity":{"TableName":"users","CapacityUnits":0.5}}`), nil
+		}
+	})
+
+	item, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(item, check.DeepEquals, map[string]*dynamodb.Attribute{
+		"id":   dynamodb.NewStringAttribute("id", "u1"),
+		"n":    dynamodb.NewNumericAttribute("n", "1.5"),
+		"tags": dynamodb.NewStringSetAttribute("tags", []string{"a", "b"}),
+		"m":    dynamodb.NewMapAttribute("m", nil),
+	})
+
+	items, last, consumed, err := s.table.ScanWithBudget(dynamodb.NewQuery(s.table), 0.5, false)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 1)
+	c.Check(last, check.DeepEquals, &dynamodb.Key{HashKey: "u2"})
+	c.Check(consumed, check.Equals, 0.5)
+}
+
+func (s *MiddlewareSuite) TestCountQueryAll(c *check.C) {
+	var bodies []string
+	s.server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			bodies = append(bodies, string(req.Body))
+			if len(bodies) == 1 {
+				return []byte(`{"Count":2,"ScannedCount":5,"LastEvaluatedKey":{"id":{"S":"u5"}}}`), nil
+			}
+			return []byte(`{"Count":1,"ScannedCount":2}`), nil
+		}
+	})
+
+	count, scanned, err := s.table.CountQueryAll([]dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("id", "u1")}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(count, check.Equals, int64(3))
+	c.Check(scanned, check.Equals, int64(7))
+	c.Assert(bodies, check.HasLen, 2)
+	c.Check(bodies[1], check.Matches, `.*"ExclusiveStartKey":\{"id":\{"S":"u5"\}\}.*`)
+}
+
+func (s *MiddlewareSuite) TestRenameAttributePassesContext(c *check.C) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "rename")
+	var operations []string
+	s.server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			c.Check(req.Context.Value(ctxKey{}), check.Equals, "rename")
+			operations = append(operations, req.Operation)
+			if req.Operation == "Scan" {
+				return []byte(`{"Count":1,"Items":[{"id":{"S":"u1"},"user_id":{"S":"a"}}]}`), nil
+			}
+			return []byte(`{}`), nil
+		}
+	})
+
+	progress, err := dynamodb.RenameAttribute(ctx, s.table, "user_id", "userId", &dynamodb.RenameAttributeOptions{TotalSegments: 1})
+	c.Assert(err, check.IsNil)
+	c.Check(progress.Updated, check.Equals, int64(1))
+	c.Check(operations, check.DeepEquals, []string{"Scan", "UpdateItem"})
+}
@@ -0,0 +1,276 @@
+// Package migrate applies schema changes to Dynamodb tables at deploy
+// time. Each Migration declares global secondary indexes to add or
+// remove, a Time To Live change and a data backfill; a Migrator runs the
+// ones not yet applied, in order, and records them in a migrations table:
+//
+//	migrator := migrate.New(server)
+//	applied, err := migrator.Run(ctx, []migrate.Migration{{
+//		ID:    "2024-05-01-users-by-email",
+//		Table: "users",
+//		AddGSIs: []dynamodb.GlobalSecondaryIndexSpec{{
+//			IndexName: "by-email",
+//			HashKey:   dynamodb.AttributeDefinitionT{Name: "email", Type: "S"},
+//		}},
+//		Backfill: backfillEmails,
+//	}})
+//
+// Every step is skipped when the table is already in the desired state,
+// so that a deploy interrupted before a migration is recorded can run it
+// again. Backfill functions must be idempotent for the same reason.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bluele/dynamodb"
+)
+
+const (
+	// Name of the migrations table when Migrator.TableName is empty.
+	DefaultTableName = "schema_migrations"
+	// Wait for tables and indexes when Migrator.Timeout is zero.
+	DefaultTimeout = 30 * time.Minute
+)
+
+// Migration is one change to a table. Its steps run in the order of the
+// fields: indexes are added, then removed, then TTL changes, then
+// Backfill runs.
+type Migration struct {
+	// Unique and stable identifier, under which the migration is
+	// recorded once applied.
+	ID          string
+	Description string
+	// Name of the table the migration changes.
+	Table string
+
+	// Indexes to create, waiting for each to be ACTIVE and done
+	// backfilling before going on, as Dynamodb creates one at a time.
+	AddGSIs []dynamodb.GlobalSecondaryIndexSpec
+	// Names of indexes to delete, waiting for each to be gone.
+	RemoveGSIs []string
+	// TTL, when set, enables or disables Time To Live.
+	TTL *TTL
+	// Backfill, when set, runs last, with table describing the indexes
+	// added.
+	Backfill func(ctx context.Context, table *dynamodb.Table) error
+}
+
+// TTL is the Time To Live setting of a Migration.
+type TTL struct {
+	AttributeName string
+	Enabled       bool
+}
+
+// Migrator runs migrations against the tables of Server.
+type Migrator struct {
+	Server *dynamodb.Server
+	// Table recording the applied migrations, created with on demand
+	// billing if missing. DefaultTableName when empty.
+	TableName string
+	// How long to wait for a table or an index to be ready,
+	// DefaultTimeout when zero.
+	Timeout time.Duration
+}
+
+func New(server *dynamodb.Server) *Migrator {
+	return &Migrator{Server: server}
+}
+
+// record is an item of the migrations table.
+type record struct {
+	ID          string `dynamodb:"id,hash"`
+	Table       string `dynamodb:"table"`
+	Description string `dynamodb:"description"`
+	AppliedAt   string `dynamodb:"applied_at"`
+}
+
+// Run applies the migrations which are not recorded yet, in order, and
+// returns the IDs of those it applied. It stops at the first failure,
+// leaving the failed migration unrecorded so that the next Run retries
+// it. Deploys running concurrently may both apply a migration; every
+// step is idempotent but Backfill must be too.
+func (m *Migrator) Run(ctx context.Context, migrations []Migration) ([]string, error) {
+	pending, err := m.Pending(ctx, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	for _, migration := range pending {
+		if err := ctx.Err(); err != nil {
+			return applied, err
+		}
+		if err := m.apply(ctx, migration); err != nil {
+			return applied, fmt.Errorf("Migration %s failed: %w", migration.ID, err)
+		}
+		if err := m.record(migration); err != nil {
+			return applied, fmt.Errorf("Recording migration %s failed: %w", migration.ID, err)
+		}
+		applied = append(applied, migration.ID)
+	}
+	return applied, nil
+}
+
+// Pending returns the migrations which are not recorded as applied,
+// creating the migrations table if needed.
+func (m *Migrator) Pending(ctx context.Context, migrations []Migration) ([]Migration, error) {
+	if err := validate(migrations); err != nil {
+		return nil, err
+	}
+	table, err := m.migrationsTable()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		_, err := table.GetItemConsistent(&dynamodb.Key{HashKey: migration.ID}, true, true)
+		if err == dynamodb.ErrNotFound {
+			pending = append(pending, migration)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return pending, nil
+}
+
+func validate(migrations []Migration) error {
+	ids := make(map[string]bool, len(migrations))
+	for _, migration := range migrations {
+		if migration.ID == "" {
+			return errors.New("Migration IDs cannot be empty.")
+		}
+		if ids[migration.ID] {
+			return fmt.Errorf("Duplicate migration ID %s.", migration.ID)
+		}
+		ids[migration.ID] = true
+		if migration.Table == "" {
+			return fmt.Errorf("Migration %s has no Table.", migration.ID)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	m.Server.ForgetTable(migration.Table)
+	table, err := m.Server.Table(migration.Table)
+	if err != nil {
+		return err
+	}
+
+	for _, spec := range migration.AddGSIs {
+		if _, err := table.CreateGSIContext(ctx, spec); err != nil {
+			return err
+		}
+		if err := table.WaitUntilIndexActiveContext(ctx, spec.IndexName, m.timeout()); err != nil {
+			return err
+		}
+	}
+	for _, name := range migration.RemoveGSIs {
+		if _, err := table.DeleteGSIContext(ctx, name); err != nil {
+			return err
+		}
+		if err := table.WaitUntilIndexDeletedContext(ctx, name, m.timeout()); err != nil {
+			return err
+		}
+	}
+	if migration.TTL != nil {
+		if err := updateTTL(table, *migration.TTL); err != nil {
+			return err
+		}
+	}
+
+	if migration.Backfill != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// Describe the table again for the indexes added above.
+		m.Server.ForgetTable(migration.Table)
+		table, err := m.Server.Table(migration.Table)
+		if err != nil {
+			return err
+		}
+		return migration.Backfill(ctx, table)
+	}
+	return nil
+}
+
+// updateTTL changes Time To Live unless it is already, or being, set as
+// ttl says.
+func updateTTL(table *dynamodb.Table, ttl TTL) error {
+	current, err := table.DescribeTimeToLive(true)
+	if err != nil {
+		return err
+	}
+	switch current.TimeToLiveStatus {
+	case dynamodb.TTL_STATUS_ENABLED, dynamodb.TTL_STATUS_ENABLING:
+		if ttl.Enabled && current.AttributeName == ttl.AttributeName {
+			return nil
+		}
+	case dynamodb.TTL_STATUS_DISABLED, dynamodb.TTL_STATUS_DISABLING:
+		if !ttl.Enabled {
+			return nil
+		}
+	}
+	return table.UpdateTimeToLive(ttl.AttributeName, ttl.Enabled, false)
+}
+
+func (m *Migrator) record(migration Migration) error {
+	table, err := m.migrationsTable()
+	if err != nil {
+		return err
+	}
+	item, err := dynamodb.MarshalAttributes(&record{
+		ID:          migration.ID,
+		Table:       migration.Table,
+		Description: migration.Description,
+		AppliedAt:   time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = table.PutItemWithOptions(context.Background(), item, &dynamodb.WriteOptions{IsRetry: true})
+	return err
+}
+
+// migrationsTable returns the migrations table, creating it if needed.
+func (m *Migrator) migrationsTable() (*dynamodb.Table, error) {
+	name := m.TableName
+	if name == "" {
+		name = DefaultTableName
+	}
+	table, err := m.Server.Table(name)
+	if !dynamodb.IsNotFound(err) {
+		return table, err
+	}
+
+	desc, err := dynamodb.TableSchemaFromStruct(record{}, &dynamodb.SchemaOptions{TableName: name})
+	if err != nil {
+		return nil, err
+	}
+	// Another deploy may be creating it too.
+	if _, err := m.Server.CreateTable(desc, false); err != nil && dynamodb.ErrorCode(err) != dynamodb.ResourceInUseException {
+		return nil, err
+	}
+	table, err = m.Server.Table(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := table.WaitUntilStatus("ACTIVE", m.timeout()); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+func (m *Migrator) timeout() time.Duration {
+	if m.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return m.Timeout
+}
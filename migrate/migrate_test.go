@@ -0,0 +1,182 @@
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"github.com/bluele/dynamodb/migrate"
+	"gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) {
+	check.TestingT(t)
+}
+
+type MigrateSuite struct {
+	server   *dynamodb.Server
+	fake     *dynamodbtest.Fake
+	migrator *migrate.Migrator
+}
+
+var _ = check.Suite(&MigrateSuite{})
+
+func (s *MigrateSuite) SetUpTest(c *check.C) {
+	s.server, s.fake = dynamodbtest.NewServer()
+	s.migrator = migrate.New(s.server)
+
+	d, err := dynamodb.TableSchemaFromStruct(struct {
+		ID string `dynamodb:"id,hash"`
+	}{}, &dynamodb.SchemaOptions{TableName: "users"})
+	c.Assert(err, check.IsNil)
+	_, err = s.server.CreateTable(d, false)
+	c.Assert(err, check.IsNil)
+
+	table, err := s.server.Table("users")
+	c.Assert(err, check.IsNil)
+	for _, id := range []string{"u1", "u2"} {
+		_, err := table.PutItemWithOptions(context.Background(), append(table.Key.Clone(id, ""), *dynamodb.NewStringAttribute("name", id)), nil)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func byEmail() dynamodb.GlobalSecondaryIndexSpec {
+	return dynamodb.GlobalSecondaryIndexSpec{
+		IndexName: "by-email",
+		HashKey:   dynamodb.AttributeDefinitionT{Name: "email", Type: "S"},
+	}
+}
+
+func (s *MigrateSuite) TestRun(c *check.C) {
+	backfills := 0
+	migrations := []migrate.Migration{{
+		ID:      "001-by-email",
+		Table:   "users",
+		AddGSIs: []dynamodb.GlobalSecondaryIndexSpec{byEmail()},
+		TTL:     &migrate.TTL{AttributeName: "expires", Enabled: true},
+		Backfill: func(ctx context.Context, table *dynamodb.Table) error {
+			backfills++
+			c.Check(table.Index("by-email"), check.NotNil)
+			for _, id := range []string{"u1", "u2"} {
+				_, err := table.UpdateAttributes(&dynamodb.Key{HashKey: id}, []dynamodb.Attribute{*dynamodb.NewStringAttribute("email", id+"@example.com")}, false)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}}
+
+	applied, err := s.migrator.Run(context.Background(), migrations)
+	c.Assert(err, check.IsNil)
+	c.Check(applied, check.DeepEquals, []string{"001-by-email"})
+	c.Check(backfills, check.Equals, 1)
+
+	users, err := s.server.Table("users")
+	c.Assert(err, check.IsNil)
+	items, _, err := users.Index("by-email").QueryWithOptions(context.Background(), []dynamodb.AttributeComparison{
+		*dynamodb.NewEqualStringAttributeComparison("email", "u2@example.com"),
+	}, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 1)
+	ttl, err := users.DescribeTimeToLive(false)
+	c.Assert(err, check.IsNil)
+	c.Check(*ttl, check.Equals, dynamodb.TimeToLiveDescriptionT{AttributeName: "expires", TimeToLiveStatus: dynamodb.TTL_STATUS_ENABLED})
+	c.Check(s.fake.Items(migrate.DefaultTableName), check.HasLen, 1)
+
+	// Applied migrations are not run again.
+	migrations = append(migrations, migrate.Migration{
+		ID:         "002-drop-by-email",
+		Table:      "users",
+		RemoveGSIs: []string{"by-email"},
+		TTL:        &migrate.TTL{AttributeName: "expires"},
+	})
+	applied, err = s.migrator.Run(context.Background(), migrations)
+	c.Assert(err, check.IsNil)
+	c.Check(applied, check.DeepEquals, []string{"002-drop-by-email"})
+	c.Check(backfills, check.Equals, 1)
+
+	desc, err := s.server.DescribeTable("users", false)
+	c.Assert(err, check.IsNil)
+	c.Check(desc.GlobalSecondaryIndexes, check.HasLen, 0)
+	ttl, err = users.DescribeTimeToLive(false)
+	c.Assert(err, check.IsNil)
+	c.Check(ttl.TimeToLiveStatus, check.Equals, dynamodb.TTL_STATUS_DISABLED)
+
+	applied, err = s.migrator.Run(context.Background(), migrations)
+	c.Assert(err, check.IsNil)
+	c.Check(applied, check.HasLen, 0)
+}
+
+func (s *MigrateSuite) TestFailedMigrationIsRetried(c *check.C) {
+	fail := errors.New("backfill failed")
+	migrations := []migrate.Migration{{
+		ID:      "001-by-email",
+		Table:   "users",
+		AddGSIs: []dynamodb.GlobalSecondaryIndexSpec{byEmail()},
+		TTL:     &migrate.TTL{AttributeName: "expires", Enabled: true},
+		Backfill: func(ctx context.Context, table *dynamodb.Table) error {
+			return fail
+		},
+	}, {
+		ID:    "002-never-reached",
+		Table: "users",
+	}}
+
+	applied, err := s.migrator.Run(context.Background(), migrations)
+	c.Check(errors.Is(err, fail), check.Equals, true)
+	c.Check(err, check.ErrorMatches, "Migration 001-by-email failed: backfill failed")
+	c.Check(applied, check.HasLen, 0)
+
+	pending, err := s.migrator.Pending(context.Background(), migrations)
+	c.Assert(err, check.IsNil)
+	c.Check(pending, check.HasLen, 2)
+
+	// The index and TTL already in place are left alone.
+	fail = nil
+	applied, err = s.migrator.Run(context.Background(), migrations)
+	c.Assert(err, check.IsNil)
+	c.Check(applied, check.DeepEquals, []string{"001-by-email", "002-never-reached"})
+}
+
+func (s *MigrateSuite) TestCancelledWhileTableIsBusy(c *check.C) {
+	server, _ := dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation == "UpdateTable" {
+				return nil, &dynamodb.Error{StatusCode: 400, Code: dynamodb.ResourceInUseException}
+			}
+			return next(req)
+		}
+	})
+	d, err := dynamodb.TableSchemaFromStruct(struct {
+		ID string `dynamodb:"id,hash"`
+	}{}, &dynamodb.SchemaOptions{TableName: "users"})
+	c.Assert(err, check.IsNil)
+	_, err = server.CreateTable(d, false)
+	c.Assert(err, check.IsNil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	applied, err := migrate.New(server).Run(ctx, []migrate.Migration{{
+		ID:      "001-by-email",
+		Table:   "users",
+		AddGSIs: []dynamodb.GlobalSecondaryIndexSpec{byEmail()},
+	}})
+	c.Check(errors.Is(err, context.DeadlineExceeded), check.Equals, true, check.Commentf("%v", err))
+	c.Check(applied, check.HasLen, 0)
+}
+
+func (s *MigrateSuite) TestInvalidMigrations(c *check.C) {
+	_, err := s.migrator.Run(context.Background(), []migrate.Migration{{ID: "1", Table: "users"}, {ID: "1", Table: "users"}})
+	c.Check(err, check.ErrorMatches, "Duplicate migration ID 1.")
+	_, err = s.migrator.Run(context.Background(), []migrate.Migration{{ID: "1"}})
+	c.Check(err, check.ErrorMatches, "Migration 1 has no Table.")
+	_, err = s.migrator.Run(context.Background(), []migrate.Migration{{Table: "users"}})
+	c.Check(err, check.ErrorMatches, "Migration IDs cannot be empty.")
+
+	_, err = s.migrator.Run(context.Background(), []migrate.Migration{{ID: "1", Table: "missing"}})
+	c.Check(dynamodb.IsNotFound(err), check.Equals, true)
+}
@@ -0,0 +1,186 @@
+package dynamodb
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Defaults of MultiRegionOptions.
+const (
+	DefaultFailureThreshold = 3
+	DefaultMinFailoverDelay = time.Second
+	DefaultMaxFailoverDelay = time.Minute
+)
+
+// MultiRegionOptions configures NewMultiRegionServer.
+type MultiRegionOptions struct {
+	// Consecutive failed attempts after which a region is taken out of
+	// rotation, DefaultFailureThreshold when zero.
+	FailureThreshold int
+	// How long a failed region stays out of rotation the first time,
+	// doubling each time it fails again right after coming back, up to
+	// MaxDelay. DefaultMinFailoverDelay and DefaultMaxFailoverDelay when
+	// zero.
+	MinDelay time.Duration
+	MaxDelay time.Duration
+}
+
+// A MultiRegionServer sends the requests of Server to the first healthy
+// region of a global table, in the order given to NewMultiRegionServer,
+// the first one being the primary. Make tables with Server.NewTable as
+// with any Server.
+//
+// A region fails when an attempt gets a server side error, times out or
+// cannot reach it; throttling and client errors do not count. After
+// FailureThreshold consecutive failures it is skipped for a while, after
+// which the next request tries it again: one success puts it back in
+// rotation, one failure takes it out for twice as long. When every region
+// is out, the one due back first is used.
+//
+// Failing over does not re-send the failed attempt, but a call made with
+// isRetry retries as usual, on the region then in use. Keep in mind that
+// global tables replicate asynchronously: after a failover, recent writes
+// made in the previous region may not be visible yet.
+type MultiRegionServer struct {
+	// The Server to make calls with. Its retry policy, timeouts and
+	// logger are copied from the primary's, and its middleware sees
+	// calls before they are routed; each region's own middleware and
+	// metrics apply to the attempts sent to it. Its TableNames, when set,
+	// replaces those of the regions; otherwise each region's applies.
+	Server *Server
+
+	threshold          int
+	minDelay, maxDelay time.Duration
+
+	mu      sync.Mutex
+	regions []*regionHealth
+}
+
+type regionHealth struct {
+	server   *Server
+	failures int           // consecutive failed attempts
+	delay    time.Duration // of the last time out of rotation
+	until    time.Time     // out of rotation until then
+}
+
+func NewMultiRegionServer(servers []*Server, opts *MultiRegionOptions) (*MultiRegionServer, error) {
+	if len(servers) == 0 {
+		return nil, errors.New("At least one region is required.")
+	}
+	if opts == nil {
+		opts = &MultiRegionOptions{}
+	}
+	m := &MultiRegionServer{
+		threshold: opts.FailureThreshold,
+		minDelay:  opts.MinDelay,
+		maxDelay:  opts.MaxDelay,
+	}
+	if m.threshold <= 0 {
+		m.threshold = DefaultFailureThreshold
+	}
+	if m.minDelay <= 0 {
+		m.minDelay = DefaultMinFailoverDelay
+	}
+	if m.maxDelay <= 0 {
+		m.maxDelay = DefaultMaxFailoverDelay
+	}
+	for _, s := range servers {
+		m.regions = append(m.regions, &regionHealth{server: s})
+	}
+
+	primary := servers[0]
+	m.Server = New(primary.Auth, primary.Region)
+	m.Server.Logger = primary.Logger
+	m.Server.RetryPolicy = primary.RetryPolicy
+	m.Server.Timeouts = primary.Timeouts
+	m.Server.Transport = TransportFunc(m.roundTrip)
+	return m, nil
+}
+
+// Active returns the Server of the region requests are currently sent to.
+func (m *MultiRegionServer) Active() *Server {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active(time.Now()).server
+}
+
+func (m *MultiRegionServer) roundTrip(req *Request) ([]byte, error) {
+	m.mu.Lock()
+	region := m.active(time.Now())
+	m.mu.Unlock()
+
+	s := region.server
+	// Table names were already mapped by m.Server when it has TableNames.
+	names := s.TableNames
+	if m.Server.TableNames != nil {
+		names = nil
+	}
+	response, err := s.rawQueryNames(req.Context, m.regionEndpoint(s, req), req.Target, string(req.Body), -1, names)
+	m.record(region, req, err)
+	return response, err
+}
+
+// regionEndpoint returns the endpoint of the region s for req, made for
+// the endpoints of m.Server.
+func (m *MultiRegionServer) regionEndpoint(s *Server, req *Request) string {
+	switch {
+	case Operation(req.Operation).IsStreams():
+		return s.streamsEndpoint()
+	case req.Endpoint == m.Server.Region.DynamoDBEndpoint:
+		return s.Region.DynamoDBEndpoint
+	}
+	return req.Endpoint
+}
+
+// active returns the first region in rotation, or the one due back first.
+func (m *MultiRegionServer) active(now time.Time) *regionHealth {
+	next := m.regions[0]
+	for _, r := range m.regions {
+		if !now.Before(r.until) {
+			return r
+		}
+		if r.until.Before(next.until) {
+			next = r
+		}
+	}
+	return next
+}
+
+func (m *MultiRegionServer) record(r *regionHealth, req *Request, err error) {
+	failed := err != nil && req.Context.Err() == nil && !IsThrottle(err)
+	if failed {
+		if e, ok := asError(err); ok && e.StatusCode < 500 && !IsRetryable(err) {
+			failed = false
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !failed {
+		if err == nil {
+			r.failures, r.delay = 0, 0
+		}
+		return
+	}
+	now := time.Now()
+	if now.Before(r.until) {
+		// Already out of rotation, by an attempt which failed meanwhile.
+		return
+	}
+	r.failures++
+	// A region back in rotation fails over again at its first failure.
+	if r.failures < m.threshold && r.delay == 0 {
+		return
+	}
+	r.delay *= 2
+	if r.delay < m.minDelay {
+		r.delay = m.minDelay
+	}
+	if r.delay > m.maxDelay {
+		r.delay = m.maxDelay
+	}
+	r.until = now.Add(r.delay)
+	r.failures = 0
+	m.Server.logger().Log(LogWarn, "region out of rotation", "region", r.server.Region.Name, "delay", r.delay, "error", err)
+}
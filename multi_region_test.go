@@ -0,0 +1,147 @@
+package dynamodb_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type MultiRegionSuite struct {
+	regions []*dynamodb.Server
+	multi   *dynamodb.MultiRegionServer
+	table   *dynamodb.Table
+
+	mu    sync.Mutex
+	down  map[string]bool
+	calls map[string]int
+}
+
+var _ = check.Suite(&MultiRegionSuite{})
+
+func (s *MultiRegionSuite) SetUpTest(c *check.C) {
+	s.down, s.calls, s.regions = map[string]bool{}, map[string]int{}, nil
+	for _, name := range []string{"us-east-1", "us-west-2"} {
+		name := name
+		server, _ := dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+			return func(req *dynamodb.Request) ([]byte, error) {
+				s.mu.Lock()
+				s.calls[name]++
+				down := s.down[name]
+				s.mu.Unlock()
+				if down {
+					return nil, &dynamodb.Error{StatusCode: 500, Code: dynamodb.InternalServerError}
+				}
+				return next(req)
+			}
+		})
+		server.Region.Name = name
+
+		createTable(c, server, tableSchema(c, "users", idKey{}))
+		s.regions = append(s.regions, server)
+	}
+
+	var err error
+	s.multi, err = dynamodb.NewMultiRegionServer(s.regions, &dynamodb.MultiRegionOptions{
+		FailureThreshold: 2,
+		MinDelay:         100 * time.Millisecond,
+	})
+	c.Assert(err, check.IsNil)
+	s.table = s.multi.Server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+	s.calls = map[string]int{}
+}
+
+// put writes with a single attempt, PutItem re-sending failed writes.
+func (s *MultiRegionSuite) put(id string) error {
+	_, err := s.table.UpdateAttributes(&dynamodb.Key{HashKey: id}, []dynamodb.Attribute{*dynamodb.NewStringAttribute("name", id)}, false)
+	return err
+}
+
+func (s *MultiRegionSuite) TestPrimary(c *check.C) {
+	c.Assert(s.put("u1"), check.IsNil)
+	c.Check(s.calls, check.DeepEquals, map[string]int{"us-east-1": 1})
+	c.Check(s.multi.Active(), check.Equals, s.regions[0])
+}
+
+func (s *MultiRegionSuite) TestFailover(c *check.C) {
+	s.down["us-east-1"] = true
+	c.Check(s.put("u1"), check.NotNil)
+	c.Check(s.multi.Active(), check.Equals, s.regions[0])
+	c.Check(s.put("u1"), check.NotNil)
+	c.Check(s.multi.Active(), check.Equals, s.regions[1])
+
+	c.Assert(s.put("u2"), check.IsNil)
+	c.Check(s.calls, check.DeepEquals, map[string]int{"us-east-1": 2, "us-west-2": 1})
+
+	// Back after the delay, the primary fails over again at once.
+	time.Sleep(120 * time.Millisecond)
+	c.Check(s.multi.Active(), check.Equals, s.regions[0])
+	c.Check(s.put("u3"), check.NotNil)
+	c.Check(s.multi.Active(), check.Equals, s.regions[1])
+
+	// Then twice as long.
+	time.Sleep(120 * time.Millisecond)
+	c.Check(s.multi.Active(), check.Equals, s.regions[1])
+	time.Sleep(120 * time.Millisecond)
+	c.Check(s.multi.Active(), check.Equals, s.regions[0])
+
+	// One success puts it back in rotation.
+	s.mu.Lock()
+	s.down["us-east-1"] = false
+	s.mu.Unlock()
+	c.Assert(s.put("u4"), check.IsNil)
+	c.Check(s.multi.Active(), check.Equals, s.regions[0])
+}
+
+func (s *MultiRegionSuite) TestRetryFailsOver(c *check.C) {
+	s.down["us-east-1"] = true
+	s.multi.Server.RetryPolicy = &dynamodb.RetryPolicy{MaxRetries: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	_, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("u1", ""), *dynamodb.NewStringAttribute("name", "u1")), &dynamodb.WriteOptions{IsRetry: true})
+	c.Assert(err, check.IsNil)
+	c.Check(s.calls, check.DeepEquals, map[string]int{"us-east-1": 2, "us-west-2": 1})
+
+	_, err = dynamodb.NewMultiRegionServer(nil, nil)
+	c.Check(err, check.ErrorMatches, "At least one region is required.")
+}
+
+func (s *MultiRegionSuite) TestRegionEndpoints(c *check.C) {
+	var endpoints, bodies []string
+	var regions []*dynamodb.Server
+	for _, name := range []string{"us-east-1", "us-west-2"} {
+		server := dynamodb.New(aws.Auth{}, aws.Region{Name: name, DynamoDBEndpoint: "https://dynamodb." + name + ".amazonaws.com"})
+		server.TableNames = dynamodb.TableNameAffix{Prefix: "staging-"}
+		server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+			return func(req *dynamodb.Request) ([]byte, error) {
+				endpoints = append(endpoints, req.Endpoint)
+				bodies = append(bodies, string(req.Body))
+				return []byte(`{"StreamDescription":{},"Item":{"id":{"S":"u1"}}}`), nil
+			}
+		})
+		regions = append(regions, server)
+	}
+	multi, err := dynamodb.NewMultiRegionServer(regions, nil)
+	c.Assert(err, check.IsNil)
+	table := multi.Server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+
+	_, err = multi.Server.DescribeStream("arn:stream", "", false)
+	c.Assert(err, check.IsNil)
+	_, err = table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Assert(err, check.IsNil)
+
+	// Names are mapped once, by the region or else by multi.Server.
+	multi.Server.TableNames = dynamodb.TableNameAffix{Prefix: "prod-"}
+	_, err = table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Assert(err, check.IsNil)
+
+	c.Check(endpoints, check.DeepEquals, []string{
+		"https://streams.dynamodb.us-east-1.amazonaws.com",
+		"https://dynamodb.us-east-1.amazonaws.com",
+		"https://dynamodb.us-east-1.amazonaws.com",
+	})
+	c.Check(bodies[1], check.Matches, `.*"TableName":"staging-users".*`)
+	c.Check(bodies[2], check.Matches, `.*"TableName":"prod-users".*`)
+}
@@ -0,0 +1,75 @@
+package dynamodb
+
+import (
+	"encoding/base64"
+	"strconv"
+)
+
+// NativeItem converts item to Go native values, for JSON encoders and
+// template engines: strings stay strings, numbers become int64 when they
+// are integers in range and float64 otherwise, binary values []byte,
+// NULL nil, and lists and sets []interface{} of the same conversions;
+// maps become map[string]interface{}. UnmarshalAttributes does the same
+// conversion into a *map[string]interface{}.
+func NativeItem(item map[string]*Attribute) map[string]interface{} {
+	out := make(map[string]interface{}, len(item))
+	for name, a := range item {
+		if a != nil {
+			out[name] = NativeValue(a)
+		}
+	}
+	return out
+}
+
+// NativeValue converts the value of a as NativeItem does.
+func NativeValue(a *Attribute) interface{} {
+	switch a.Type {
+	case TYPE_NUMBER:
+		return nativeNumber(a.Value)
+	case TYPE_BINARY:
+		return nativeBinary(a.Value)
+	case TYPE_NULL:
+		return nil
+	case TYPE_STRING_SET, TYPE_NUMBER_SET, TYPE_BINARY_SET:
+		values := make([]interface{}, len(a.SetValues))
+		for i, v := range a.SetValues {
+			switch a.Type {
+			case TYPE_NUMBER_SET:
+				values[i] = nativeNumber(v)
+			case TYPE_BINARY_SET:
+				values[i] = nativeBinary(v)
+			default:
+				values[i] = v
+			}
+		}
+		return values
+	case TYPE_LIST:
+		values := make([]interface{}, len(a.ListValues))
+		for i := range a.ListValues {
+			values[i] = NativeValue(&a.ListValues[i])
+		}
+		return values
+	case TYPE_MAP:
+		return NativeItem(a.MapValues)
+	}
+	return a.Value
+}
+
+// nativeNumber returns n as an int64 when it is an integer in range, as a
+// float64 otherwise, and as its string when it does not parse.
+func nativeNumber(n string) interface{} {
+	if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(n, 64); err == nil {
+		return f
+	}
+	return n
+}
+
+func nativeBinary(v string) interface{} {
+	if b, err := base64.StdEncoding.DecodeString(v); err == nil {
+		return b
+	}
+	return v
+}
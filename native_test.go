@@ -0,0 +1,70 @@
+package dynamodb_test
+
+import (
+	"encoding/json"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type NativeSuite struct{}
+
+var _ = check.Suite(&NativeSuite{})
+
+func nativeFixture() map[string]*dynamodb.Attribute {
+	return map[string]*dynamodb.Attribute{
+		"name":  dynamodb.NewStringAttribute("name", "Ann"),
+		"age":   dynamodb.NewNumericAttribute("age", "42"),
+		"score": dynamodb.NewNumericAttribute("score", "1.5"),
+		"big":   dynamodb.NewNumericAttribute("big", "123456789012345678901234567890"),
+		"blob":  dynamodb.NewBytesAttribute("blob", []byte{1, 2}),
+		"none":  dynamodb.NewNullAttribute("none"),
+		"tags":  dynamodb.NewStringSetAttribute("tags", []string{"a", "b"}),
+		"ns":    dynamodb.NewNumericSetAttribute("ns", []string{"1", "2.5"}),
+		"list":  dynamodb.NewListAttribute("list", []dynamodb.Attribute{*dynamodb.NewStringAttribute("", "x"), *dynamodb.NewNumericAttribute("", "7")}),
+		"profile": dynamodb.NewMapAttribute("profile", []dynamodb.Attribute{
+			*dynamodb.NewStringAttribute("theme", "dark"),
+		}),
+	}
+}
+
+func (s *NativeSuite) TestNativeItem(c *check.C) {
+	expected := map[string]interface{}{
+		"name":    "Ann",
+		"age":     int64(42),
+		"score":   1.5,
+		"big":     1.2345678901234568e+29,
+		"blob":    []byte{1, 2},
+		"none":    nil,
+		"tags":    []interface{}{"a", "b"},
+		"ns":      []interface{}{int64(1), 2.5},
+		"list":    []interface{}{"x", int64(7)},
+		"profile": map[string]interface{}{"theme": "dark"},
+	}
+	c.Check(dynamodb.NativeItem(nativeFixture()), check.DeepEquals, expected)
+
+	item := nativeFixture()
+	var m map[string]interface{}
+	c.Assert(dynamodb.UnmarshalAttributes(&item, &m), check.IsNil)
+	c.Check(m, check.DeepEquals, expected)
+
+	_, err := json.Marshal(m)
+	c.Check(err, check.IsNil)
+}
+
+func (s *NativeSuite) TestDocumentFields(c *check.C) {
+	var r struct {
+		List    []interface{}
+		Profile map[string]interface{}
+		Any     interface{}
+	}
+	item := map[string]*dynamodb.Attribute{
+		"List":    nativeFixture()["list"],
+		"Profile": nativeFixture()["profile"],
+		"Any":     nativeFixture()["profile"],
+	}
+	c.Assert(dynamodb.UnmarshalAttributes(&item, &r), check.IsNil)
+	c.Check(r.List, check.DeepEquals, []interface{}{"x", int64(7)})
+	c.Check(r.Profile, check.DeepEquals, map[string]interface{}{"theme": "dark"})
+	c.Check(r.Any, check.DeepEquals, map[string]interface{}{"theme": "dark"})
+}
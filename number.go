@@ -0,0 +1,173 @@
+package dynamodb
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Limits of the Dynamodb number type.
+const (
+	maxNumberDigits   = 38
+	maxNumberExponent = 125
+	minNumberExponent = -130
+)
+
+func NewInt64Attribute(name string, value int64) *Attribute {
+	return NewNumericAttribute(name, strconv.FormatInt(value, 10))
+}
+
+func NewUint64Attribute(name string, value uint64) *Attribute {
+	return NewNumericAttribute(name, strconv.FormatUint(value, 10))
+}
+
+// NewFloat64Attribute fails on NaN, infinities and values outside the range
+// Dynamodb can store.
+func NewFloat64Attribute(name string, value float64) (*Attribute, error) {
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return nil, fmt.Errorf("Attribute %s: %v is not a valid number.", name, value)
+	}
+	return newCheckedNumericAttribute(name, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// NewBigIntAttribute fails when value has more than 38 significant digits.
+func NewBigIntAttribute(name string, value *big.Int) (*Attribute, error) {
+	return newCheckedNumericAttribute(name, value.String())
+}
+
+// NewBigFloatAttribute stores the shortest decimal representation of value,
+// failing when it needs more than 38 significant digits.
+func NewBigFloatAttribute(name string, value *big.Float) (*Attribute, error) {
+	if value.IsInf() {
+		return nil, fmt.Errorf("Attribute %s: %v is not a valid number.", name, value)
+	}
+	return newCheckedNumericAttribute(name, value.Text('g', -1))
+}
+
+func newCheckedNumericAttribute(name string, value string) (*Attribute, error) {
+	if err := checkNumber(value); err != nil {
+		return nil, fmt.Errorf("Attribute %s: %s", name, err)
+	}
+	return NewNumericAttribute(name, value), nil
+}
+
+// checkNumber verifies that the decimal string s fits Dynamodb's number
+// type: at most 38 significant digits and a magnitude between 1E-130 and
+// 9.9999999999999999999999999999999999999E+125.
+func checkNumber(s string) error {
+	mantissa := strings.TrimLeft(s, "+-")
+	exponent := 0
+	if i := strings.IndexAny(mantissa, "eE"); i >= 0 {
+		e, err := strconv.Atoi(mantissa[i+1:])
+		if err != nil {
+			return fmt.Errorf("%q is not a valid number.", s)
+		}
+		mantissa, exponent = mantissa[:i], e
+	}
+
+	digits, point := mantissa, len(mantissa)
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		digits, point = mantissa[:i]+mantissa[i+1:], i
+	}
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return fmt.Errorf("%q is not a valid number.", s)
+	}
+
+	trimmed := strings.TrimLeft(digits, "0")
+	point -= len(digits) - len(trimmed)
+	trimmed = strings.TrimRight(trimmed, "0")
+	if trimmed == "" {
+		return nil
+	}
+
+	if len(trimmed) > maxNumberDigits {
+		return fmt.Errorf("%s has more than %d significant digits.", s, maxNumberDigits)
+	}
+	magnitude := point - 1 + exponent
+	if magnitude > maxNumberExponent || magnitude < minNumberExponent {
+		return fmt.Errorf("%s is out of the range of a number.", s)
+	}
+	return nil
+}
+
+func (a *Attribute) number() (string, error) {
+	if a.Type != TYPE_NUMBER {
+		return "", fmt.Errorf("Attribute %s is of type %s, not a number.", a.Name, a.Type)
+	}
+	return a.Value, nil
+}
+
+// Int64 fails when the value is not an integer or overflows an int64.
+func (a *Attribute) Int64() (int64, error) {
+	i, err := a.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsInt64() {
+		return 0, fmt.Errorf("Attribute %s: %s overflows int64.", a.Name, a.Value)
+	}
+	return i.Int64(), nil
+}
+
+// Uint64 fails when the value is not a non-negative integer that fits an
+// uint64.
+func (a *Attribute) Uint64() (uint64, error) {
+	i, err := a.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsUint64() {
+		return 0, fmt.Errorf("Attribute %s: %s overflows uint64.", a.Name, a.Value)
+	}
+	return i.Uint64(), nil
+}
+
+// Float64 returns the nearest float64, failing when the value is out of its
+// range. Values with more than 15 significant digits may be rounded; use
+// BigFloat to keep them.
+func (a *Attribute) Float64() (float64, error) {
+	s, err := a.number()
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Attribute %s: %s", a.Name, err)
+	}
+	return f, nil
+}
+
+// BigInt fails when the value has a fractional part.
+func (a *Attribute) BigInt() (*big.Int, error) {
+	s, err := a.number()
+	if err != nil {
+		return nil, err
+	}
+	if i, ok := new(big.Int).SetString(s, 10); ok {
+		return i, nil
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("Attribute %s: %q is not a valid number.", a.Name, s)
+	}
+	if !r.IsInt() {
+		return nil, fmt.Errorf("Attribute %s: %s is not an integer.", a.Name, s)
+	}
+	return r.Num(), nil
+}
+
+// BigFloat parses the value with enough precision for all 38 significant
+// digits Dynamodb allows.
+func (a *Attribute) BigFloat() (*big.Float, error) {
+	s, err := a.number()
+	if err != nil {
+		return nil, err
+	}
+	f, _, err := big.ParseFloat(s, 10, 128, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("Attribute %s: %s", a.Name, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,123 @@
+package dynamodb
+
+import (
+	"context"
+)
+
+// Operation names a Dynamodb or Dynamodb Streams API action, for Do.
+// Actions missing below can be called with Operation("Name").
+type Operation string
+
+const (
+	OPERATION_BATCH_EXECUTE_STATEMENT                Operation = "BatchExecuteStatement"
+	OPERATION_BATCH_GET_ITEM                         Operation = "BatchGetItem"
+	OPERATION_BATCH_WRITE_ITEM                       Operation = "BatchWriteItem"
+	OPERATION_CREATE_BACKUP                          Operation = "CreateBackup"
+	OPERATION_CREATE_GLOBAL_TABLE                    Operation = "CreateGlobalTable"
+	OPERATION_CREATE_TABLE                           Operation = "CreateTable"
+	OPERATION_DELETE_BACKUP                          Operation = "DeleteBackup"
+	OPERATION_DELETE_ITEM                            Operation = "DeleteItem"
+	OPERATION_DELETE_RESOURCE_POLICY                 Operation = "DeleteResourcePolicy"
+	OPERATION_DELETE_TABLE                           Operation = "DeleteTable"
+	OPERATION_DESCRIBE_BACKUP                        Operation = "DescribeBackup"
+	OPERATION_DESCRIBE_CONTINUOUS_BACKUPS            Operation = "DescribeContinuousBackups"
+	OPERATION_DESCRIBE_CONTRIBUTOR_INSIGHTS          Operation = "DescribeContributorInsights"
+	OPERATION_DESCRIBE_ENDPOINTS                     Operation = "DescribeEndpoints"
+	OPERATION_DESCRIBE_EXPORT                        Operation = "DescribeExport"
+	OPERATION_DESCRIBE_GLOBAL_TABLE                  Operation = "DescribeGlobalTable"
+	OPERATION_DESCRIBE_GLOBAL_TABLE_SETTINGS         Operation = "DescribeGlobalTableSettings"
+	OPERATION_DESCRIBE_IMPORT                        Operation = "DescribeImport"
+	OPERATION_DESCRIBE_KINESIS_STREAMING_DESTINATION Operation = "DescribeKinesisStreamingDestination"
+	OPERATION_DESCRIBE_LIMITS                        Operation = "DescribeLimits"
+	OPERATION_DESCRIBE_TABLE                         Operation = "DescribeTable"
+	OPERATION_DESCRIBE_TABLE_REPLICA_AUTO_SCALING    Operation = "DescribeTableReplicaAutoScaling"
+	OPERATION_DESCRIBE_TIME_TO_LIVE                  Operation = "DescribeTimeToLive"
+	OPERATION_DISABLE_KINESIS_STREAMING_DESTINATION  Operation = "DisableKinesisStreamingDestination"
+	OPERATION_ENABLE_KINESIS_STREAMING_DESTINATION   Operation = "EnableKinesisStreamingDestination"
+	OPERATION_EXECUTE_STATEMENT                      Operation = "ExecuteStatement"
+	OPERATION_EXECUTE_TRANSACTION                    Operation = "ExecuteTransaction"
+	OPERATION_EXPORT_TABLE_TO_POINT_IN_TIME          Operation = "ExportTableToPointInTime"
+	OPERATION_GET_ITEM                               Operation = "GetItem"
+	OPERATION_GET_RESOURCE_POLICY                    Operation = "GetResourcePolicy"
+	OPERATION_IMPORT_TABLE                           Operation = "ImportTable"
+	OPERATION_LIST_BACKUPS                           Operation = "ListBackups"
+	OPERATION_LIST_CONTRIBUTOR_INSIGHTS              Operation = "ListContributorInsights"
+	OPERATION_LIST_EXPORTS                           Operation = "ListExports"
+	OPERATION_LIST_GLOBAL_TABLES                     Operation = "ListGlobalTables"
+	OPERATION_LIST_IMPORTS                           Operation = "ListImports"
+	OPERATION_LIST_TABLES                            Operation = "ListTables"
+	OPERATION_LIST_TAGS_OF_RESOURCE                  Operation = "ListTagsOfResource"
+	OPERATION_PUT_ITEM                               Operation = "PutItem"
+	OPERATION_PUT_RESOURCE_POLICY                    Operation = "PutResourcePolicy"
+	OPERATION_QUERY                                  Operation = "Query"
+	OPERATION_RESTORE_TABLE_FROM_BACKUP              Operation = "RestoreTableFromBackup"
+	OPERATION_RESTORE_TABLE_TO_POINT_IN_TIME         Operation = "RestoreTableToPointInTime"
+	OPERATION_SCAN                                   Operation = "Scan"
+	OPERATION_TAG_RESOURCE                           Operation = "TagResource"
+	OPERATION_TRANSACT_GET_ITEMS                     Operation = "TransactGetItems"
+	OPERATION_TRANSACT_WRITE_ITEMS                   Operation = "TransactWriteItems"
+	OPERATION_UNTAG_RESOURCE                         Operation = "UntagResource"
+	OPERATION_UPDATE_CONTINUOUS_BACKUPS              Operation = "UpdateContinuousBackups"
+	OPERATION_UPDATE_CONTRIBUTOR_INSIGHTS            Operation = "UpdateContributorInsights"
+	OPERATION_UPDATE_GLOBAL_TABLE                    Operation = "UpdateGlobalTable"
+	OPERATION_UPDATE_GLOBAL_TABLE_SETTINGS           Operation = "UpdateGlobalTableSettings"
+	OPERATION_UPDATE_ITEM                            Operation = "UpdateItem"
+	OPERATION_UPDATE_KINESIS_STREAMING_DESTINATION   Operation = "UpdateKinesisStreamingDestination"
+	OPERATION_UPDATE_TABLE                           Operation = "UpdateTable"
+	OPERATION_UPDATE_TABLE_REPLICA_AUTO_SCALING      Operation = "UpdateTableReplicaAutoScaling"
+	OPERATION_UPDATE_TIME_TO_LIVE                    Operation = "UpdateTimeToLive"
+
+	// Dynamodb Streams actions.
+	OPERATION_DESCRIBE_STREAM    Operation = "DescribeStream"
+	OPERATION_GET_RECORDS        Operation = "GetRecords"
+	OPERATION_GET_SHARD_ITERATOR Operation = "GetShardIterator"
+	OPERATION_LIST_STREAMS       Operation = "ListStreams"
+)
+
+// IsStreams reports whether op belongs to the Dynamodb Streams API.
+func (op Operation) IsStreams() bool {
+	switch op {
+	case OPERATION_DESCRIBE_STREAM, OPERATION_GET_RECORDS, OPERATION_GET_SHARD_ITERATOR, OPERATION_LIST_STREAMS:
+		return true
+	}
+	return false
+}
+
+// Target returns the value of the X-Amz-Target header for op.
+func (op Operation) Target() string {
+	if op.IsStreams() {
+		return streamsTarget(string(op))
+	}
+	return target(string(op))
+}
+
+// Do sends payload, the JSON request body, to the op action and returns
+// the JSON response body. It lets callers use actions the rest of the
+// package does not wrap; errors returned by Dynamodb are *Error values.
+// Retryable errors are retried following the RetryPolicy.
+func (s *Server) Do(op Operation, payload []byte) ([]byte, error) {
+	return s.DoContext(context.Background(), op, payload, true)
+}
+
+// DoContext is Do with a context, and retries only when isRetry is true.
+//
+// Payloads of Idempotent operations without a ClientRequestToken get a
+// new one, sent again with every retry, so that Dynamodb applies a retried
+// transaction only once.
+func (s *Server) DoContext(ctx context.Context, op Operation, payload []byte, isRetry bool) ([]byte, error) {
+	var retryCount = 0
+	if !isRetry {
+		retryCount = -1
+	}
+	if op.Idempotent() {
+		var err error
+		if payload, err = withClientRequestToken(payload); err != nil {
+			return nil, err
+		}
+	}
+	endpoint := s.Region.DynamoDBEndpoint
+	if op.IsStreams() {
+		endpoint = s.streamsEndpoint()
+	}
+	return s.rawQueryEndpointContext(ctx, endpoint, op.Target(), string(payload), retryCount)
+}
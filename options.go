@@ -0,0 +1,186 @@
+package dynamodb
+
+import (
+	"context"
+)
+
+// QueryOptions configures QueryWithOptions. The zero value queries the
+// base table with the service defaults.
+type QueryOptions struct {
+	IndexName string
+	Limit     int64
+	// Strongly consistent read, reflecting every write acknowledged before
+	// the query at twice the capacity cost. Dynamodb only supports it on
+	// the base table and local secondary indexes.
+	ConsistentRead bool
+	// Sort descending on the range key when true.
+	Descending  bool
+	QueryFilter []AttributeComparison
+	// Combines the QueryFilter conditions, CONDITIONAL_OPERATOR_AND when
+	// empty.
+	ConditionalOperator string
+	ExclusiveStartKey   *Key
+	IsRetry             bool
+}
+
+// ScanOptions configures ScanWithOptions. The zero value scans the whole
+// base table with the service defaults.
+type ScanOptions struct {
+	IndexName string
+	Limit     int64
+	// Strongly consistent read; see QueryOptions.ConsistentRead.
+	ConsistentRead bool
+	// At most one condition per attribute, as for QueryFilter.
+	ScanFilter []AttributeComparison
+	// Combines the ScanFilter conditions, CONDITIONAL_OPERATOR_AND when
+	// empty.
+	ConditionalOperator string
+	// Segment of a parallel scan in TotalSegments, when TotalSegments is
+	// positive.
+	Segment           int
+	TotalSegments     int
+	ExclusiveStartKey *Key
+	IsRetry           bool
+}
+
+// QueryWithOptions runs a single Query request and returns the page of
+// items along with the key to resume from, nil when there are no more
+// results.
+func (t *Table) QueryWithOptions(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions) ([]map[string]*Attribute, *Key, error) {
+	page, err := t.QueryPage(ctx, keyConditions, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return page.Items, page.LastEvaluatedKey, nil
+}
+
+// QueryPage is QueryWithOptions returning the whole page, with its Count
+// and ScannedCount.
+func (t *Table) QueryPage(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions) (*PageResult, error) {
+	if opts == nil {
+		opts = &QueryOptions{}
+	}
+	return t.fetchPageContext(ctx, "Query", t.queryQuery(keyConditions, opts), opts.IsRetry)
+}
+
+func (t *Table) queryQuery(keyConditions []AttributeComparison, opts *QueryOptions) *Query {
+	q := NewQuery(t)
+	q.AddKeyConditions(keyConditions)
+	if opts.IndexName != "" {
+		q.AddIndex(opts.IndexName)
+	}
+	if opts.Limit > 0 {
+		q.AddLimit(opts.Limit)
+	}
+	q.ConsistentRead(opts.ConsistentRead)
+	if opts.Descending {
+		q.AddScanIndexForward(false)
+	}
+	if len(opts.QueryFilter) > 0 {
+		q.AddQueryFilter(opts.QueryFilter)
+		if opts.ConditionalOperator != "" {
+			q.AddConditionalOperator(opts.ConditionalOperator)
+		}
+	}
+	if opts.ExclusiveStartKey != nil {
+		q.AddExclusiveStartKey(t, opts.ExclusiveStartKey)
+	}
+	return q
+}
+
+// QueryAllPages runs Query requests until opts.Limit items have been
+// returned or there are no more results, following LastEvaluatedKey. With
+// a QueryFilter, a page often holds fewer items than its Limit, which
+// applies before filtering; QueryAllPages asks for the remaining number of
+// items on each request so it never reads past opts.Limit. A zero Limit
+// reads every page. The returned key resumes after the last item returned,
+// nil when the query is complete.
+func (t *Table) QueryAllPages(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions) ([]map[string]*Attribute, *Key, error) {
+	page := QueryOptions{}
+	if opts != nil {
+		page = *opts
+	}
+	limit := page.Limit
+
+	var items []map[string]*Attribute
+	for {
+		if limit > 0 {
+			page.Limit = limit - int64(len(items))
+		}
+		pageItems, last, err := t.QueryWithOptions(ctx, keyConditions, &page)
+		if err != nil {
+			return items, page.ExclusiveStartKey, err
+		}
+		items = append(items, pageItems...)
+		if last == nil || (limit > 0 && int64(len(items)) >= limit) {
+			return items, last, nil
+		}
+		page.ExclusiveStartKey = last
+	}
+}
+
+// ScanWithOptions runs a single Scan request and returns the page of items
+// along with the key to resume from, nil when there are no more results.
+func (t *Table) ScanWithOptions(ctx context.Context, opts *ScanOptions) ([]map[string]*Attribute, *Key, error) {
+	page, err := t.ScanPage(ctx, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return page.Items, page.LastEvaluatedKey, nil
+}
+
+// ScanPage is ScanWithOptions returning the whole page, with its Count and
+// ScannedCount.
+func (t *Table) ScanPage(ctx context.Context, opts *ScanOptions) (*PageResult, error) {
+	if opts == nil {
+		opts = &ScanOptions{}
+	}
+	return t.fetchPageContext(ctx, "Scan", t.scanQuery(opts), opts.IsRetry)
+}
+
+// ScanCountWithOptions counts the items matching opts.ScanFilter with
+// Select COUNT requests, following LastEvaluatedKey from
+// opts.ExclusiveStartKey over every page of the table, the index named by
+// opts.IndexName or the given segment. No item is transferred, but the
+// scan consumes the same read capacity as one returning them; Limit sets
+// the number of items evaluated per request. It returns the number of
+// items and the number of items evaluated, which only differ when a
+// filter is in play.
+func (t *Table) ScanCountWithOptions(ctx context.Context, opts *ScanOptions) (int64, int64, error) {
+	if opts == nil {
+		opts = &ScanOptions{}
+	}
+	return t.countPages(ctx, "Scan", t.scanQuery(opts), opts.IsRetry)
+}
+
+func (t *Table) scanQuery(opts *ScanOptions) *Query {
+	q := NewQuery(t)
+	if opts.IndexName != "" {
+		q.AddIndex(opts.IndexName)
+	}
+	if opts.Limit > 0 {
+		q.AddLimit(opts.Limit)
+	}
+	q.ConsistentRead(opts.ConsistentRead)
+	if len(opts.ScanFilter) > 0 {
+		q.AddScanFilter(opts.ScanFilter)
+		if opts.ConditionalOperator != "" {
+			q.AddConditionalOperator(opts.ConditionalOperator)
+		}
+	}
+	if opts.TotalSegments > 0 {
+		q.AddParallelScanConfiguration(opts.Segment, opts.TotalSegments)
+	}
+	if opts.ExclusiveStartKey != nil {
+		q.AddExclusiveStartKey(t, opts.ExclusiveStartKey)
+	}
+	return q
+}
+
+func (t *Table) fetchPageContext(ctx context.Context, operation string, query *Query, isRetry bool) (*PageResult, error) {
+	jsonResponse, err := t.Server.queryServerContext(ctx, target(operation), query, isRetry)
+	if err != nil {
+		return nil, err
+	}
+	return t.parsePage(jsonResponse)
+}
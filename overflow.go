@@ -0,0 +1,241 @@
+package dynamodb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DefaultOverflowThreshold is the size above which an OverflowTable moves
+// attributes out of the item when its Threshold is zero.
+const DefaultOverflowThreshold = 32 * 1024
+
+// Name of the string set attribute listing the blobs holding the
+// attributes moved out of an item by an OverflowTable.
+var OverflowAttribute = "*overflow*"
+
+// A BlobStore holds the attributes an OverflowTable moves out of its
+// items, typically an S3 bucket.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// An OverflowTable reads and writes the items of Table, storing their
+// large attributes in Store so that items can exceed MaxItemSize. Each
+// moved attribute gets a blob of its own, listed in the item's
+// OverflowAttribute, and is put back in place on reads.
+//
+// Blobs are written before the item, under new keys, and those of the
+// item it replaces are deleted after it: readers never see missing blobs,
+// but a failure can leave orphan ones behind. Moved attributes cannot be
+// used in keys, indexes or conditions, and projections return them only
+// when they include OverflowAttribute. Use Table directly for the other
+// operations.
+type OverflowTable struct {
+	Table *Table
+	Store BlobStore
+	// Attributes larger than Threshold bytes, as counted by
+	// EstimateItemSize, are moved to Store. DefaultOverflowThreshold when
+	// zero.
+	Threshold int
+	// Prepended to the blob keys, which are made of the table name and a
+	// random id.
+	KeyPrefix string
+}
+
+func (t *OverflowTable) threshold() int {
+	if t.Threshold > 0 {
+		return t.Threshold
+	}
+	return DefaultOverflowThreshold
+}
+
+// PutItemWithOptions writes item, moving its large attributes to Store.
+// The previous item is always requested to delete its blobs, and only
+// returned when opts asks for it.
+func (t *OverflowTable) PutItemWithOptions(ctx context.Context, item []Attribute, opts *WriteOptions) (*WriteResult, error) {
+	stored, blobs, err := t.moveOut(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := t.Table.PutItemWithOptions(ctx, stored, t.returningOld(opts))
+	if err != nil {
+		if writeRejected(err) {
+			t.deleteBlobs(ctx, blobs)
+		}
+		return nil, err
+	}
+	return t.replaced(ctx, result, opts)
+}
+
+// DeleteItemWithOptions deletes the item and then its blobs.
+func (t *OverflowTable) DeleteItemWithOptions(ctx context.Context, key *Key, opts *WriteOptions) (*WriteResult, error) {
+	result, err := t.Table.DeleteItemWithOptions(ctx, key, t.returningOld(opts))
+	if err != nil {
+		return nil, err
+	}
+	return t.replaced(ctx, result, opts)
+}
+
+func (t *OverflowTable) GetItem(ctx context.Context, key *Key, isRetry bool) (map[string]*Attribute, error) {
+	return t.GetItemConsistent(ctx, key, false, isRetry)
+}
+
+func (t *OverflowTable) GetItemConsistent(ctx context.Context, key *Key, consistentRead bool, isRetry bool) (map[string]*Attribute, error) {
+	item, err := t.Table.GetItemConsistent(key, consistentRead, isRetry)
+	if err != nil {
+		return nil, err
+	}
+	return item, t.moveIn(ctx, item)
+}
+
+func (t *OverflowTable) QueryWithOptions(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions) ([]map[string]*Attribute, *Key, error) {
+	items, last, err := t.Table.QueryWithOptions(ctx, keyConditions, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, last, t.moveInAll(ctx, items)
+}
+
+func (t *OverflowTable) ScanWithOptions(ctx context.Context, opts *ScanOptions) ([]map[string]*Attribute, *Key, error) {
+	items, last, err := t.Table.ScanWithOptions(ctx, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, last, t.moveInAll(ctx, items)
+}
+
+func (t *OverflowTable) returningOld(opts *WriteOptions) *WriteOptions {
+	o := WriteOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.ReturnValues = RETURN_VALUES_ALL_OLD
+	return &o
+}
+
+// replaced deletes the blobs of the item a write replaced, returning it
+// only if the caller asked for it.
+func (t *OverflowTable) replaced(ctx context.Context, result *WriteResult, opts *WriteOptions) (*WriteResult, error) {
+	old := result.Attributes
+	result.Attributes = nil
+	if old == nil {
+		return result, nil
+	}
+
+	var blobs []string
+	if pointer := old[OverflowAttribute]; pointer != nil {
+		blobs = pointer.SetValues
+	}
+	if opts != nil && opts.ReturnValues == RETURN_VALUES_ALL_OLD {
+		if err := t.moveIn(ctx, old); err != nil {
+			return nil, err
+		}
+		result.Attributes = old
+	}
+	t.deleteBlobs(ctx, blobs)
+	return result, nil
+}
+
+// moveOut writes the large attributes of item to Store and returns the
+// item to store in their place.
+func (t *OverflowTable) moveOut(ctx context.Context, item []Attribute) ([]Attribute, []string, error) {
+	stored := make([]Attribute, 0, len(item)+1)
+	var blobs []string
+	for i := range item {
+		a := &item[i]
+		if a.Name == OverflowAttribute {
+			return nil, nil, fmt.Errorf("Attribute name %s is reserved for overflow.", a.Name)
+		}
+		if attributeSize(a) <= t.threshold() || t.Table.Key.isKeyAttribute(a.Name) {
+			stored = append(stored, *a)
+			continue
+		}
+
+		data, err := json.Marshal(attributeList([]Attribute{*a}))
+		if err != nil {
+			return nil, nil, err
+		}
+		key, err := t.blobKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := t.Store.Put(ctx, key, data); err != nil {
+			t.deleteBlobs(ctx, blobs)
+			return nil, nil, err
+		}
+		blobs = append(blobs, key)
+	}
+	if len(blobs) > 0 {
+		stored = append(stored, *NewStringSetAttribute(OverflowAttribute, blobs))
+	}
+	return stored, blobs, nil
+}
+
+// moveIn puts the attributes of an item stored by moveOut back in place.
+func (t *OverflowTable) moveIn(ctx context.Context, item map[string]*Attribute) error {
+	pointer := item[OverflowAttribute]
+	if pointer == nil {
+		return nil
+	}
+	for _, key := range pointer.SetValues {
+		data, err := t.Store.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("Overflow blob %s: %s", key, err)
+		}
+		var m attributeMap
+		if err := json.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("Overflow blob %s: %s", key, err)
+		}
+		for name, a := range m.attributes() {
+			item[name] = a
+		}
+	}
+	delete(item, OverflowAttribute)
+	return nil
+}
+
+func (t *OverflowTable) moveInAll(ctx context.Context, items []map[string]*Attribute) error {
+	for _, item := range items {
+		if err := t.moveIn(ctx, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *OverflowTable) blobKey() (string, error) {
+	id := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, id); err != nil {
+		return "", err
+	}
+	return t.KeyPrefix + t.Table.Name + "/" + hex.EncodeToString(id), nil
+}
+
+// writeRejected reports whether err means a write was not applied: its
+// condition failed or Dynamodb refused the request. After a timeout or a
+// server error the write may have been applied, so its blobs are left
+// alone, orphans at worst.
+func writeRejected(err error) bool {
+	if IsConditionalCheckFailed(err) || IsValidationError(err) {
+		return true
+	}
+	e, ok := asError(err)
+	return ok && e.StatusCode >= 400 && e.StatusCode < 500 && !IsRetryable(err)
+}
+
+// deleteBlobs deletes blobs no item points to anymore. Failures only leave
+// orphans behind, so they are logged rather than returned.
+func (t *OverflowTable) deleteBlobs(ctx context.Context, blobs []string) {
+	for _, key := range blobs {
+		if err := t.Store.Delete(ctx, key); err != nil {
+			t.Table.Server.logger().Log(LogWarn, "could not delete overflow blob", "table", t.Table.Name, "blob", key, "error", err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package dynamodb_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type OverflowSuite struct {
+	table    *dynamodb.Table
+	blobs    *dynamodbtest.BlobStore
+	overflow *dynamodb.OverflowTable
+}
+
+var _ = check.Suite(&OverflowSuite{})
+
+func (s *OverflowSuite) SetUpTest(c *check.C) {
+	s.table = newFakeTable(c, "documents", idKey{})
+	s.blobs = dynamodbtest.NewBlobStore()
+	s.overflow = &dynamodb.OverflowTable{Table: s.table, Store: s.blobs, KeyPrefix: "ddb/"}
+}
+
+func (s *OverflowSuite) put(c *check.C, id string, body string, opts *dynamodb.WriteOptions) *dynamodb.WriteResult {
+	result, err := s.overflow.PutItemWithOptions(context.Background(), []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", id),
+		*dynamodb.NewStringAttribute("title", "small"),
+		*dynamodb.NewStringAttribute("body", body),
+		*dynamodb.NewBytesAttribute("scan", make([]byte, 300*1024)),
+	}, opts)
+	c.Assert(err, check.IsNil)
+	return result
+}
+
+func (s *OverflowSuite) TestRoundTrip(c *check.C) {
+	body := strings.Repeat("x", 200*1024)
+	s.put(c, "d1", body, nil)
+	c.Check(s.blobs.Keys(), check.HasLen, 2)
+	c.Check(strings.HasPrefix(s.blobs.Keys()[0], "ddb/documents/"), check.Equals, true)
+
+	raw, err := s.table.GetItem(&dynamodb.Key{HashKey: "d1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(raw["body"], check.IsNil)
+	c.Check(raw[dynamodb.OverflowAttribute].SetValues, check.HasLen, 2)
+
+	item, err := s.overflow.GetItem(context.Background(), &dynamodb.Key{HashKey: "d1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(item["title"].Value, check.Equals, "small")
+	c.Check(item["body"].Value, check.Equals, body)
+	scan, err := item["scan"].Bytes()
+	c.Assert(err, check.IsNil)
+	c.Check(scan, check.HasLen, 300*1024)
+	c.Check(item[dynamodb.OverflowAttribute], check.IsNil)
+
+	items, _, err := s.overflow.ScanWithOptions(context.Background(), nil)
+	c.Assert(err, check.IsNil)
+	c.Assert(items, check.HasLen, 1)
+	c.Check(items[0]["body"].Value, check.Equals, body)
+}
+
+func (s *OverflowSuite) TestReplaceAndDelete(c *check.C) {
+	s.put(c, "d1", strings.Repeat("a", 100*1024), nil)
+	first := s.blobs.Keys()
+
+	result := s.put(c, "d1", strings.Repeat("b", 100*1024), &dynamodb.WriteOptions{ReturnValues: dynamodb.RETURN_VALUES_ALL_OLD})
+	c.Check(result.Attributes["body"].Value, check.Equals, strings.Repeat("a", 100*1024))
+	second := s.blobs.Keys()
+	c.Assert(second, check.HasLen, 2)
+	for _, key := range first {
+		c.Check(strings.Contains(strings.Join(second, " "), key), check.Equals, false, check.Commentf("%s was not deleted", key))
+	}
+
+	result = s.put(c, "d1", "short", nil)
+	c.Check(result.Attributes, check.IsNil)
+	c.Check(s.blobs.Keys(), check.HasLen, 1)
+
+	_, err := s.overflow.DeleteItemWithOptions(context.Background(), &dynamodb.Key{HashKey: "d1"}, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(s.blobs.Keys(), check.HasLen, 0)
+}
+
+func (s *OverflowSuite) TestFailedPut(c *check.C) {
+	s.put(c, "d1", strings.Repeat("a", 100*1024), nil)
+	before := s.blobs.Keys()
+
+	// A rejected write deletes the blobs it wrote.
+	_, err := s.overflow.PutItemWithOptions(context.Background(), []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", "d1"),
+		*dynamodb.NewStringAttribute("body", strings.Repeat("b", 100*1024)),
+	}, &dynamodb.WriteOptions{ConditionExpression: "attribute_not_exists(id)"})
+	c.Check(dynamodb.IsConditionalCheckFailed(err), check.Equals, true)
+	c.Check(s.blobs.Keys(), check.DeepEquals, before)
+
+	// One which may have been applied keeps them.
+	server, _ := dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			response, err := next(req)
+			if err == nil && req.Operation == "PutItem" {
+				return nil, errors.New("connection reset by peer")
+			}
+			return response, err
+		}
+	})
+	d, err := s.table.DescribeTable(false)
+	c.Assert(err, check.IsNil)
+	_, err = server.CreateTable(*d, false)
+	c.Assert(err, check.IsNil)
+	blobs := dynamodbtest.NewBlobStore()
+	overflow := &dynamodb.OverflowTable{Table: server.NewTable("documents", s.table.Key), Store: blobs}
+	_, err = overflow.PutItemWithOptions(context.Background(), []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", "d1"),
+		*dynamodb.NewStringAttribute("body", strings.Repeat("c", 100*1024)),
+	}, nil)
+	c.Check(err, check.ErrorMatches, "connection reset by peer")
+	c.Check(blobs.Keys(), check.HasLen, 1)
+	item, err := overflow.GetItem(context.Background(), &dynamodb.Key{HashKey: "d1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(item["body"].Value, check.Equals, strings.Repeat("c", 100*1024))
+}
@@ -0,0 +1,183 @@
+package dynamodb
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Placeholders generates ExpressionAttributeNames ("#n0", "#n1", ...) and
+// ExpressionAttributeValues (":v0", ":v1", ...) for expressions, so that
+// attribute names never clash with reserved words such as name or status:
+//
+//	p := NewPlaceholders()
+//	expr := fmt.Sprintf("%s = %s", p.Name("status"), p.Value("active"))
+//	if err := p.Err(); err != nil { ... }
+//	q.AddFilterExpression(expr)
+//	p.Apply(q)
+type Placeholders struct {
+	names      map[string]string // attribute name -> placeholder
+	namesOrder []string
+	values     []Attribute
+	err        error
+}
+
+func NewPlaceholders() *Placeholders {
+	return &Placeholders{names: make(map[string]string)}
+}
+
+// Name returns the placeholder for an attribute name, the same one for
+// every use of that name.
+func (p *Placeholders) Name(name string) string {
+	if ph, ok := p.names[name]; ok {
+		return ph
+	}
+	ph := "#n" + strconv.Itoa(len(p.namesOrder))
+	p.names[name] = ph
+	p.namesOrder = append(p.namesOrder, name)
+	return ph
+}
+
+// Path returns the placeholders for a document path, each map key aliased
+// like Name and list indexes kept: "profile.tags[0]" gives "#n0.#n1[0]".
+// Invalid paths are reported by Err; use Name for an attribute whose name
+// contains a dot.
+func (p *Placeholders) Path(path string) string {
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		name, indexes := segment, ""
+		if j := strings.IndexByte(segment, '['); j >= 0 {
+			name, indexes = segment[:j], segment[j:]
+		}
+		if name == "" || !validIndexes(indexes) {
+			if p.err == nil {
+				p.err = fmt.Errorf("Invalid document path %q.", path)
+			}
+			return p.Name(path)
+		}
+		segments[i] = p.Name(name) + indexes
+	}
+	return strings.Join(segments, ".")
+}
+
+// validIndexes reports whether s is a sequence of list indexes, e.g.
+// "[0][2]".
+func validIndexes(s string) bool {
+	for s != "" {
+		end := strings.IndexByte(s, ']')
+		if s[0] != '[' || end < 2 {
+			return false
+		}
+		if _, err := strconv.ParseUint(s[1:end], 10, 32); err != nil {
+			return false
+		}
+		s = s[end+1:]
+	}
+	return true
+}
+
+// Value returns a new placeholder bound to v, which may be an Attribute,
+// a string, a number, a bool (stored as 1 or 0, like the marshaler does),
+// a []byte, a Set, or a slice of strings or numbers (stored as a set).
+// Conversion errors are reported by Err.
+func (p *Placeholders) Value(v interface{}) string {
+	ph := ":v" + strconv.Itoa(len(p.values))
+	a, err := valueAttribute(ph, v)
+	if err != nil {
+		if p.err == nil {
+			p.err = err
+		}
+		a = NewStringAttribute(ph, "")
+	}
+	p.values = append(p.values, *a)
+	return ph
+}
+
+// Err returns the first error met converting a value.
+func (p *Placeholders) Err() error {
+	return p.err
+}
+
+// Names returns ExpressionAttributeNames, placeholder to attribute name.
+func (p *Placeholders) Names() map[string]string {
+	if len(p.names) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(p.names))
+	for name, ph := range p.names {
+		out[ph] = name
+	}
+	return out
+}
+
+// Values returns ExpressionAttributeValues, each attribute named after
+// its placeholder.
+func (p *Placeholders) Values() []Attribute {
+	return p.values
+}
+
+// Apply adds the names and values to q.
+func (p *Placeholders) Apply(q *Query) {
+	q.AddExpressionAttributeNames(p.Names())
+	q.AddExpressionAttributeValues(p.values)
+}
+
+// ApplyWrite sets the names and values on opts, merging with any names
+// already present.
+func (p *Placeholders) ApplyWrite(opts *WriteOptions) {
+	if names := p.Names(); names != nil {
+		if opts.ExpressionAttributeNames == nil {
+			opts.ExpressionAttributeNames = make(map[string]string, len(names))
+		}
+		for ph, name := range names {
+			opts.ExpressionAttributeNames[ph] = name
+		}
+	}
+	opts.ExpressionAttributeValues = append(opts.ExpressionAttributeValues, p.values...)
+}
+
+func valueAttribute(name string, v interface{}) (*Attribute, error) {
+	switch x := v.(type) {
+	case Attribute:
+		x.Name = name
+		return &x, nil
+	case *Attribute:
+		a := *x
+		a.Name = name
+		return &a, nil
+	case []byte:
+		return NewBytesAttribute(name, x), nil
+	case Set:
+		return x.Attribute(name), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return NewStringAttribute(name, rv.String()), nil
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64:
+		s, err := numericReflectedValueString(rv)
+		if err != nil {
+			return nil, err
+		}
+		return NewNumericAttribute(name, s), nil
+	case reflect.Slice, reflect.Array:
+		values := make([]string, rv.Len())
+		if rv.Type().Elem().Kind() == reflect.String {
+			for i := range values {
+				values[i] = rv.Index(i).String()
+			}
+			return NewStringSetAttribute(name, values), nil
+		}
+		for i := range values {
+			s, err := numericReflectedValueString(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			values[i] = s
+		}
+		return NewNumericSetAttribute(name, values), nil
+	}
+	return nil, fmt.Errorf("UnsupportedTypeError %T", v)
+}
@@ -0,0 +1,61 @@
+package dynamodb_test
+
+import (
+	"fmt"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type PlaceholdersSuite struct {
+}
+
+var _ = check.Suite(&PlaceholdersSuite{})
+
+func (s *PlaceholdersSuite) TestNamesAndValues(c *check.C) {
+	p := dynamodb.NewPlaceholders()
+	expr := fmt.Sprintf("%s = %s AND %s > %s AND %s <> %s",
+		p.Name("status"), p.Value("active"),
+		p.Name("count"), p.Value(5),
+		p.Name("status"), p.Value(true))
+	c.Assert(p.Err(), check.IsNil)
+
+	c.Check(expr, check.Equals, "#n0 = :v0 AND #n1 > :v1 AND #n0 <> :v2")
+	c.Check(p.Names(), check.DeepEquals, map[string]string{"#n0": "status", "#n1": "count"})
+	c.Check(p.Values(), check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute(":v0", "active"),
+		*dynamodb.NewNumericAttribute(":v1", "5"),
+		*dynamodb.NewNumericAttribute(":v2", "1"),
+	})
+}
+
+func (s *PlaceholdersSuite) TestSetsAndErrors(c *check.C) {
+	p := dynamodb.NewPlaceholders()
+	p.Value([]string{"a", "b"})
+	p.Value([]int{1, 2})
+	c.Check(p.Values(), check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringSetAttribute(":v0", []string{"a", "b"}),
+		*dynamodb.NewNumericSetAttribute(":v1", []string{"1", "2"}),
+	})
+	c.Check(p.Err(), check.IsNil)
+
+	p.Value(map[string]int{})
+	c.Check(p.Err(), check.ErrorMatches, "UnsupportedTypeError.*")
+}
+
+func (s *PlaceholdersSuite) TestPaths(c *check.C) {
+	p := dynamodb.NewPlaceholders()
+	c.Check(p.Path("profile.settings.theme"), check.Equals, "#n0.#n1.#n2")
+	c.Check(p.Path("profile.addresses[1][0].city"), check.Equals, "#n0.#n3[1][0].#n4")
+	c.Check(p.Path("status"), check.Equals, "#n5")
+	c.Check(p.Names(), check.DeepEquals, map[string]string{
+		"#n0": "profile", "#n1": "settings", "#n2": "theme", "#n3": "addresses", "#n4": "city", "#n5": "status",
+	})
+	c.Check(p.Err(), check.IsNil)
+
+	for _, path := range []string{"", "a..b", "a.", "a[x]", "a[1", "[0]", "a[-1]"} {
+		p := dynamodb.NewPlaceholders()
+		p.Path(path)
+		c.Check(p.Err(), check.ErrorMatches, "Invalid document path .*", check.Commentf(path))
+	}
+}
@@ -1,59 +1,90 @@
 package dynamodb
 
 import (
-	"errors"
-	"fmt"
-
-	simplejson "github.com/bitly/go-simplejson"
+	"context"
 )
 
+// Deprecated: use QueryWithOptions.
 func (t *Table) Query(attributeComparisons []AttributeComparison, isRetry bool) ([]map[string]*Attribute, error) {
-	q := NewQuery(t)
-	q.AddKeyConditions(attributeComparisons)
-	return RunQuery(q, t, isRetry)
+	deprecated(t.Server, "Query", "QueryWithOptions")
+	items, _, err := t.QueryWithOptions(context.Background(), attributeComparisons, &QueryOptions{IsRetry: isRetry})
+	return items, err
 }
 
+// Deprecated: use QueryWithOptions with IndexName.
 func (t *Table) QueryOnIndex(attributeComparisons []AttributeComparison, indexName string, isRetry bool) ([]map[string]*Attribute, error) {
-	q := NewQuery(t)
-	q.AddKeyConditions(attributeComparisons)
-	q.AddIndex(indexName)
-	return RunQuery(q, t, isRetry)
+	deprecated(t.Server, "QueryOnIndex", "QueryWithOptions")
+	items, _, err := t.QueryWithOptions(context.Background(), attributeComparisons, &QueryOptions{IndexName: indexName, IsRetry: isRetry})
+	return items, err
 }
 
+// Deprecated: use QueryWithOptions with Limit.
 func (t *Table) LimitedQuery(attributeComparisons []AttributeComparison, limit int64, isRetry bool) ([]map[string]*Attribute, error) {
-	q := NewQuery(t)
-	q.AddKeyConditions(attributeComparisons)
-	q.AddLimit(limit)
-	return RunQuery(q, t, isRetry)
+	deprecated(t.Server, "LimitedQuery", "QueryWithOptions")
+	items, _, err := t.QueryWithOptions(context.Background(), attributeComparisons, &QueryOptions{Limit: limit, IsRetry: isRetry})
+	return items, err
 }
 
+// Deprecated: use QueryWithOptions with IndexName and Limit.
 func (t *Table) LimitedQueryOnIndex(attributeComparisons []AttributeComparison, indexName string, limit int64, isRetry bool) ([]map[string]*Attribute, error) {
+	deprecated(t.Server, "LimitedQueryOnIndex", "QueryWithOptions")
+	items, _, err := t.QueryWithOptions(context.Background(), attributeComparisons, &QueryOptions{IndexName: indexName, Limit: limit, IsRetry: isRetry})
+	return items, err
+}
+
+func (t *Table) CountQuery(attributeComparisons []AttributeComparison, isRetry bool) (int64, error) {
+	return t.countQuery(attributeComparisons, false, isRetry)
+}
+
+// CountQueryConsistent is CountQuery with a strongly consistent read when
+// consistentRead is true, so that items just written are counted.
+func (t *Table) CountQueryConsistent(attributeComparisons []AttributeComparison, consistentRead bool, isRetry bool) (int64, error) {
+	return t.countQuery(attributeComparisons, consistentRead, isRetry)
+}
+
+// CountQueryAll counts the items matching the key conditions over every
+// page of the query, where CountQuery only counts the first page of at
+// most 1MB of data. It returns the number of items and the number of items
+// evaluated, which only differ when a filter is in play.
+func (t *Table) CountQueryAll(attributeComparisons []AttributeComparison, isRetry bool) (int64, int64, error) {
 	q := NewQuery(t)
 	q.AddKeyConditions(attributeComparisons)
-	q.AddIndex(indexName)
-	q.AddLimit(limit)
-	return RunQuery(q, t, isRetry)
+	return t.countPages(context.Background(), "Query", q, isRetry)
 }
 
-func (t *Table) CountQuery(attributeComparisons []AttributeComparison, isRetry bool) (int64, error) {
+// countPages runs q with Select COUNT over every page and sums the counts.
+func (t *Table) countPages(ctx context.Context, operation string, q *Query, isRetry bool) (int64, int64, error) {
+	q.AddSelect("COUNT")
+	var count, scannedCount int64
+	for {
+		page, err := t.fetchPageContext(ctx, operation, q, isRetry)
+		if err != nil {
+			return count, scannedCount, err
+		}
+		count += page.Count
+		scannedCount += page.ScannedCount
+		if page.LastEvaluatedKey == nil {
+			return count, scannedCount, nil
+		}
+		q.AddExclusiveStartKey(t, page.LastEvaluatedKey)
+	}
+}
+
+func (t *Table) countQuery(attributeComparisons []AttributeComparison, consistentRead bool, isRetry bool) (int64, error) {
 	q := NewQuery(t)
 	q.AddKeyConditions(attributeComparisons)
 	q.AddSelect("COUNT")
+	q.ConsistentRead(consistentRead)
 	jsonResponse, err := t.Server.queryServer("DynamoDB_20120810.Query", q, isRetry)
 	if err != nil {
 		return 0, err
 	}
-	json, err := simplejson.NewJson(jsonResponse)
-	if err != nil {
-		return 0, err
-	}
-
-	itemCount, err := json.Get("Count").Int64()
+	page, err := t.parsePage(jsonResponse)
 	if err != nil {
 		return 0, err
 	}
 
-	return itemCount, nil
+	return page.Count, nil
 }
 
 func (t *Table) RawQueryTable(query string, target string, isRetry bool) ([]map[string]*Attribute, *Key, error) {
@@ -66,38 +97,25 @@ func (t *Table) RawQueryTable(query string, target string, isRetry bool) ([]map[
 		return nil, nil, err
 	}
 
-	json, err := simplejson.NewJson(jsonResponse)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	itemCount, err := json.Get("Count").Int()
-	if err != nil {
-		if target == "UpdateItem" {
+	if target == "UpdateItem" {
+		var r struct{ Count *int64 }
+		if err := decodeResponse(jsonResponse, &r); err != nil {
+			return nil, nil, err
+		}
+		if r.Count == nil {
 			return make([]map[string]*Attribute, 0), nil, nil
 		}
-
-		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-		return nil, nil, errors.New(message)
 	}
 
-	results := make([]map[string]*Attribute, itemCount)
-
-	for i, _ := range results {
-		item, err := json.Get("Items").GetIndex(i).Map()
-		if err != nil {
-			message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-			return nil, nil, errors.New(message)
-		}
-		results[i] = parseAttributes(item)
+	page, err := t.parsePage(jsonResponse)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	var lastEvaluatedKey *Key
-	if lastKeyMap := json.Get("LastEvaluatedKey").MustMap(); lastKeyMap != nil {
-		lastEvaluatedKey = parseKey(t, lastKeyMap)
+	if page.Items == nil {
+		page.Items = make([]map[string]*Attribute, 0)
 	}
 
-	return results, lastEvaluatedKey, nil
+	return page.Items, page.LastEvaluatedKey, nil
 }
 
 func (t *Table) QueryTable(q *Query, isRetry bool) ([]map[string]*Attribute, *Key, error) {
@@ -0,0 +1,99 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// QueryAll runs Query requests as QueryAllPages does, appending the items
+// to the slice out points to as each page arrives, decoded with
+// UnmarshalAttributes. out is a pointer to a slice of structs or of
+// pointers to structs:
+//
+//	var orders []Order
+//	last, err := t.QueryAll(ctx, conditions, nil, &orders)
+//
+// It returns the key resuming after the last item decoded, nil when the
+// query is complete. On error, out holds the items of the pages decoded so
+// far.
+func (t *Table) QueryAll(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions, out interface{}) (*Key, error) {
+	page := QueryOptions{}
+	if opts != nil {
+		page = *opts
+	}
+	return decodeAll(out, page.Limit, page.ExclusiveStartKey, func(limit int64, start *Key) ([]map[string]*Attribute, *Key, error) {
+		page := page
+		page.Limit, page.ExclusiveStartKey = limit, start
+		return t.QueryWithOptions(ctx, keyConditions, &page)
+	})
+}
+
+// QueryAll is Table.QueryAll on the index.
+func (i *Index) QueryAll(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions, out interface{}) (*Key, error) {
+	opts, err := i.options(keyConditions, opts)
+	if err != nil {
+		return nil, err
+	}
+	return i.Table.QueryAll(ctx, keyConditions, opts, out)
+}
+
+// ScanAll is QueryAll for Scan.
+func (t *Table) ScanAll(ctx context.Context, opts *ScanOptions, out interface{}) (*Key, error) {
+	page := ScanOptions{}
+	if opts != nil {
+		page = *opts
+	}
+	return decodeAll(out, page.Limit, page.ExclusiveStartKey, func(limit int64, start *Key) ([]map[string]*Attribute, *Key, error) {
+		page := page
+		page.Limit, page.ExclusiveStartKey = limit, start
+		return t.ScanWithOptions(ctx, &page)
+	})
+}
+
+// decodeAll appends to out the items of the pages returned by fetch, which
+// is passed the number of items still wanted, zero for all, and the key to
+// resume from.
+func decodeAll(out interface{}, limit int64, start *Key, fetch func(limit int64, start *Key) ([]map[string]*Attribute, *Key, error)) (*Key, error) {
+	slice := reflect.ValueOf(out)
+	if slice.Kind() != reflect.Ptr || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("Cannot decode items into %T, a pointer to a slice is required.", out)
+	}
+	slice = slice.Elem()
+	elemType := slice.Type().Elem()
+	structType, pointers := elemType, elemType.Kind() == reflect.Ptr
+	if pointers {
+		structType = elemType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Cannot decode items into %T, a slice of structs is required.", out)
+	}
+
+	var decoded int64
+	last := start
+	for {
+		var pageLimit int64
+		if limit > 0 {
+			pageLimit = limit - decoded
+		}
+		items, next, err := fetch(pageLimit, last)
+		if err != nil {
+			return last, err
+		}
+		for _, item := range items {
+			v := reflect.New(structType)
+			if err := UnmarshalAttributes(&item, v.Interface()); err != nil {
+				return last, err
+			}
+			if !pointers {
+				v = v.Elem()
+			}
+			slice.Set(reflect.Append(slice, v))
+		}
+		decoded += int64(len(items))
+		if next == nil || (limit > 0 && decoded >= limit) {
+			return next, nil
+		}
+		last = next
+	}
+}
@@ -0,0 +1,106 @@
+package dynamodb_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type QueryAllSuite struct {
+	table    *dynamodb.Table
+	requests int
+	fail     error
+	// canned returns the response to a Query or Scan, nil to run it.
+	canned func(request int) []byte
+}
+
+var _ = check.Suite(&QueryAllSuite{})
+
+type event struct {
+	User string `dynamodb:"user"`
+	Seq  int64  `dynamodb:"seq"`
+	Kind string `dynamodb:"kind"`
+}
+
+func (s *QueryAllSuite) SetUpTest(c *check.C) {
+	s.requests, s.fail, s.canned = 0, nil, nil
+	server, _ := dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation == "Query" || req.Operation == "Scan" {
+				s.requests++
+				if s.canned != nil {
+					if response := s.canned(s.requests); response != nil {
+						return response, nil
+					}
+				}
+				if s.fail != nil {
+					return nil, s.fail
+				}
+			}
+			return next(req)
+		}
+	})
+
+	s.table = createTable(c, server, tableSchema(c, "events", userSeqKey{}))
+
+	for i := 0; i < 5; i++ {
+		_, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("u1", strconv.Itoa(i)), *dynamodb.NewStringAttribute("kind", "click")), nil)
+		c.Assert(err, check.IsNil)
+	}
+}
+
+func (s *QueryAllSuite) conditions() []dynamodb.AttributeComparison {
+	return []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("user", "u1")}
+}
+
+func (s *QueryAllSuite) TestQueryAll(c *check.C) {
+	var events []event
+	last, err := s.table.QueryAll(context.Background(), s.conditions(), &dynamodb.QueryOptions{Limit: 3}, &events)
+	c.Assert(err, check.IsNil)
+	c.Check(events, check.DeepEquals, []event{{"u1", 0, "click"}, {"u1", 1, "click"}, {"u1", 2, "click"}})
+	c.Assert(last, check.NotNil)
+	c.Check(last.RangeKey, check.Equals, "2")
+
+	// Resuming appends the rest.
+	last, err = s.table.QueryAll(context.Background(), s.conditions(), &dynamodb.QueryOptions{ExclusiveStartKey: last}, &events)
+	c.Assert(err, check.IsNil)
+	c.Check(last, check.IsNil)
+	c.Check(events, check.HasLen, 5)
+	c.Check(events[4].Seq, check.Equals, int64(4))
+}
+
+func (s *QueryAllSuite) TestScanAllPages(c *check.C) {
+	var events []*event
+	last, err := s.table.ScanAll(context.Background(), &dynamodb.ScanOptions{Limit: 2}, &events)
+	c.Assert(err, check.IsNil)
+	c.Check(last, check.NotNil)
+	c.Check(events, check.HasLen, 2)
+
+	// Without a Limit every page is read; a failed page keeps the items
+	// decoded before it.
+	events = nil
+	s.requests, s.fail = 0, errors.New("boom")
+	s.canned = func(request int) []byte {
+		if request > 1 {
+			return nil
+		}
+		return []byte(`{"Count":1,"Items":[{"user":{"S":"u1"},"seq":{"N":"9"}}],"LastEvaluatedKey":{"user":{"S":"u1"},"seq":{"N":"9"}}}`)
+	}
+	_, err = s.table.ScanAll(context.Background(), nil, &events)
+	c.Check(err, check.ErrorMatches, "boom")
+	c.Check(events, check.DeepEquals, []*event{{User: "u1", Seq: 9}})
+}
+
+func (s *QueryAllSuite) TestInvalidOutput(c *check.C) {
+	var events []event
+	_, err := s.table.ScanAll(context.Background(), nil, events)
+	c.Check(err, check.ErrorMatches, "Cannot decode items into .*, a pointer to a slice is required.")
+	var names []string
+	_, err = s.table.ScanAll(context.Background(), nil, &names)
+	c.Check(err, check.ErrorMatches, "Cannot decode items into .*, a slice of structs is required.")
+	c.Check(s.requests, check.Equals, 0)
+}
@@ -3,6 +3,7 @@ package dynamodb
 import (
 	"encoding/json"
 	"sort"
+	"time"
 )
 
 type msi map[string]interface{}
@@ -36,6 +37,10 @@ func (q *Query) AddKey(t *Table, key *Key) {
 }
 
 func (q *Query) AddExclusiveStartKey(t *Table, key *Key) {
+	if key.exclusiveStart != "" {
+		q.buffer["ExclusiveStartKey"] = json.RawMessage(key.exclusiveStart)
+		return
+	}
 	q.buffer["ExclusiveStartKey"] = keyAttributes(t, key)
 }
 
@@ -127,9 +132,20 @@ func (q *Query) AddCreateRequestTable(description TableDescriptionT) {
 	b["AttributeDefinitions"] = attDefs
 	b["KeySchema"] = description.KeySchema
 	b["TableName"] = description.TableName
-	b["ProvisionedThroughput"] = msi{
-		"ReadCapacityUnits":  int(description.ProvisionedThroughput.ReadCapacityUnits),
-		"WriteCapacityUnits": int(description.ProvisionedThroughput.WriteCapacityUnits),
+	onDemand := description.BillingModeSummary.BillingMode == BILLING_MODE_PAY_PER_REQUEST
+	if onDemand {
+		b["BillingMode"] = BILLING_MODE_PAY_PER_REQUEST
+	} else {
+		b["ProvisionedThroughput"] = msi{
+			"ReadCapacityUnits":  int(description.ProvisionedThroughput.ReadCapacityUnits),
+			"WriteCapacityUnits": int(description.ProvisionedThroughput.WriteCapacityUnits),
+		}
+	}
+	if description.StreamSpecification.StreamEnabled {
+		b["StreamSpecification"] = description.StreamSpecification
+	}
+	if description.SSESpecification.Enabled {
+		b["SSESpecification"] = description.SSESpecification
 	}
 
 	localSecondaryIndexes := []interface{}{}
@@ -149,15 +165,18 @@ func (q *Query) AddCreateRequestTable(description TableDescriptionT) {
 	globalSecondaryIndexes := []interface{}{}
 
 	for _, ind := range description.GlobalSecondaryIndexes {
-		globalSecondaryIndexes = append(globalSecondaryIndexes, msi{
+		index := msi{
 			"IndexName":  ind.IndexName,
 			"KeySchema":  ind.KeySchema,
 			"Projection": ind.Projection,
-			"ProvisionedThroughput": msi{
+		}
+		if !onDemand {
+			index["ProvisionedThroughput"] = msi{
 				"ReadCapacityUnits":  int(ind.ProvisionedThroughput.ReadCapacityUnits),
 				"WriteCapacityUnits": int(ind.ProvisionedThroughput.WriteCapacityUnits),
-			},
-		})
+			}
+		}
+		globalSecondaryIndexes = append(globalSecondaryIndexes, index)
 	}
 
 	if len(globalSecondaryIndexes) > 0 {
@@ -170,6 +189,99 @@ func (q *Query) AddDeleteRequestTable(description TableDescriptionT) {
 	b["TableName"] = description.TableName
 }
 
+func (q *Query) AddTimeToLiveSpecification(attributeName string, enabled bool) {
+	q.buffer["TimeToLiveSpecification"] = msi{
+		"AttributeName": attributeName,
+		"Enabled":       enabled,
+	}
+}
+
+func (q *Query) AddPointInTimeRecoverySpecification(enabled bool) {
+	q.buffer["PointInTimeRecoverySpecification"] = msi{
+		"PointInTimeRecoveryEnabled": enabled,
+	}
+}
+
+// A zero restoreDateTime restores to the latest restorable time.
+func (q *Query) AddRestoreToPointInTime(sourceTableName, targetTableName string, restoreDateTime time.Time) {
+	q.buffer["SourceTableName"] = sourceTableName
+	q.buffer["TargetTableName"] = targetTableName
+	if restoreDateTime.IsZero() {
+		q.buffer["UseLatestRestorableTime"] = true
+	} else {
+		q.buffer["RestoreDateTime"] = float64(restoreDateTime.UnixNano()) / float64(time.Second)
+	}
+}
+
+// action is "Create" or "Delete". Dynamodb accepts a single replica
+// update per UpdateTable request.
+func (q *Query) AddReplicaUpdate(action string, regionName string) {
+	q.buffer["ReplicaUpdates"] = []interface{}{
+		msi{action: msi{"RegionName": regionName}},
+	}
+}
+
+func (q *Query) AddAttributeDefinitions(definitions []AttributeDefinitionT) {
+	q.buffer["AttributeDefinitions"] = definitions
+}
+
+// provisioned is false for tables with on demand billing, whose indexes
+// have no throughput of their own.
+func (q *Query) AddGlobalSecondaryIndexCreate(index GlobalSecondaryIndexT, provisioned bool) {
+	create := msi{
+		"IndexName":  index.IndexName,
+		"KeySchema":  index.KeySchema,
+		"Projection": index.Projection,
+	}
+	if provisioned {
+		create["ProvisionedThroughput"] = msi{
+			"ReadCapacityUnits":  index.ProvisionedThroughput.ReadCapacityUnits,
+			"WriteCapacityUnits": index.ProvisionedThroughput.WriteCapacityUnits,
+		}
+	}
+	q.buffer["GlobalSecondaryIndexUpdates"] = []interface{}{msi{"Create": create}}
+}
+
+func (q *Query) AddGlobalSecondaryIndexDelete(indexName string) {
+	q.buffer["GlobalSecondaryIndexUpdates"] = []interface{}{
+		msi{"Delete": msi{"IndexName": indexName}},
+	}
+}
+
+func (q *Query) AddResourceArn(resourceArn string) {
+	q.buffer["ResourceArn"] = resourceArn
+}
+
+func (q *Query) AddTags(tags []TagT) {
+	q.buffer["Tags"] = tags
+}
+
+func (q *Query) AddTagKeys(keys []string) {
+	q.buffer["TagKeys"] = keys
+}
+
+func (q *Query) AddNextToken(token string) {
+	if token != "" {
+		q.buffer["NextToken"] = token
+	}
+}
+
+func (q *Query) AddProvisionedThroughput(read, write int64) {
+	q.buffer["ProvisionedThroughput"] = msi{
+		"ReadCapacityUnits":  read,
+		"WriteCapacityUnits": write,
+	}
+}
+
+// mode is BILLING_MODE_PROVISIONED or BILLING_MODE_PAY_PER_REQUEST.
+func (q *Query) AddBillingMode(mode string) {
+	q.buffer["BillingMode"] = mode
+}
+
+func (q *Query) AddSSESpecification(spec SSESpecificationT) {
+	q.buffer["SSESpecification"] = spec
+}
+
 func (q *Query) AddKeyConditions(comparisons []AttributeComparison) {
 	q.buffer["KeyConditions"] = buildComparisons(comparisons)
 }
@@ -185,6 +297,11 @@ func (q *Query) AddSelect(value string) {
 	q.buffer["Select"] = value
 }
 
+// value is one of the RETURN_CONSUMED_CAPACITY_* constants.
+func (q *Query) AddReturnConsumedCapacity(value string) {
+	q.buffer["ReturnConsumedCapacity"] = value
+}
+
 func (q *Query) AddIndex(value string) {
 	q.buffer["IndexName"] = value
 }
@@ -206,11 +323,91 @@ func (q *Query) AddScanFilter(comparisons []AttributeComparison) {
 	q.buffer["ScanFilter"] = buildComparisons(comparisons)
 }
 
+// operator is CONDITIONAL_OPERATOR_AND, the default, or
+// CONDITIONAL_OPERATOR_OR. It combines the conditions of a QueryFilter,
+// ScanFilter or Expected.
+func (q *Query) AddConditionalOperator(operator string) {
+	q.buffer["ConditionalOperator"] = operator
+}
+
 func (q *Query) AddParallelScanConfiguration(segment int, totalSegments int) {
 	q.buffer["Segment"] = segment
 	q.buffer["TotalSegments"] = totalSegments
 }
 
+func (q *Query) AddKeyConditionExpression(expression string) {
+	q.buffer["KeyConditionExpression"] = expression
+}
+
+func (q *Query) AddFilterExpression(expression string) {
+	q.buffer["FilterExpression"] = expression
+}
+
+func (q *Query) AddProjectionExpression(expression string) {
+	q.buffer["ProjectionExpression"] = expression
+}
+
+func (q *Query) AddUpdateExpression(expression string) {
+	q.buffer["UpdateExpression"] = expression
+}
+
+func (q *Query) AddConditionExpression(expression string) {
+	q.buffer["ConditionExpression"] = expression
+}
+
+// Names are merged into any placeholders already added to the query.
+func (q *Query) AddExpressionAttributeNames(names map[string]string) {
+	if len(names) == 0 {
+		return
+	}
+	out, ok := q.buffer["ExpressionAttributeNames"].(msi)
+	if !ok {
+		out = msi{}
+	}
+	for placeholder, name := range names {
+		out[placeholder] = name
+	}
+	q.buffer["ExpressionAttributeNames"] = out
+}
+
+// The Name of each attribute is used as its placeholder (e.g. ":v0").
+func (q *Query) AddExpressionAttributeValues(attributes []Attribute) {
+	if len(attributes) == 0 {
+		return
+	}
+	out, ok := q.buffer["ExpressionAttributeValues"].(msi)
+	if !ok {
+		out = msi{}
+	}
+	for placeholder, value := range attributeList(attributes) {
+		out[placeholder] = value
+	}
+	q.buffer["ExpressionAttributeValues"] = out
+}
+
+func (q *Query) AddStreamArn(streamArn string) {
+	q.buffer["StreamArn"] = streamArn
+}
+
+func (q *Query) AddExclusiveStartShardId(shardId string) {
+	if shardId != "" {
+		q.buffer["ExclusiveStartShardId"] = shardId
+	}
+}
+
+// An empty sequenceNumber is omitted, as required for TRIM_HORIZON and LATEST.
+func (q *Query) AddShardIteratorRequest(shardId, iteratorType, sequenceNumber string) {
+	q.buffer["ShardId"] = shardId
+	q.buffer["ShardIteratorType"] = iteratorType
+	if sequenceNumber != "" {
+		q.buffer["SequenceNumber"] = sequenceNumber
+	}
+}
+
+func (q *Query) AddShardIterator(shardIterator string) {
+	q.buffer["ShardIterator"] = shardIterator
+}
+
 func buildComparisons(comparisons []AttributeComparison) msi {
 	out := msi{}
 
@@ -237,9 +434,7 @@ func (q *Query) AddUpdates(attributes []Attribute, action string) {
 	updates := msi{}
 	for _, a := range attributes {
 		au := msi{
-			"Value": msi{
-				a.Type: map[bool]interface{}{true: a.SetValues, false: a.Value}[a.SetType()],
-			},
+			"Value":  a.wireValue(),
 			"Action": action,
 		}
 		// Delete 'Value' from AttributeUpdates if Type is not Set
@@ -252,6 +447,21 @@ func (q *Query) AddUpdates(attributes []Attribute, action string) {
 	q.buffer["AttributeUpdates"] = updates
 }
 
+// value is one of the RETURN_VALUES_* constants.
+func (q *Query) AddReturnValues(value string) {
+	q.buffer["ReturnValues"] = value
+}
+
+// value is RETURN_VALUES_ALL_OLD or RETURN_VALUES_NONE.
+func (q *Query) AddReturnValuesOnConditionCheckFailure(value string) {
+	q.buffer["ReturnValuesOnConditionCheckFailure"] = value
+}
+
+// value is one of the RETURN_ITEM_COLLECTION_METRICS_* constants.
+func (q *Query) AddReturnItemCollectionMetrics(value string) {
+	q.buffer["ReturnItemCollectionMetrics"] = value
+}
+
 func (q *Query) AddExpected(attributes []Attribute) {
 	expected := msi{}
 	for _, a := range attributes {
@@ -261,7 +471,7 @@ func (q *Query) AddExpected(attributes []Attribute) {
 		}
 		// If set Exists to false, we must remove Value
 		if value["Exists"] != "false" {
-			value["Value"] = msi{a.Type: map[bool]interface{}{true: a.SetValues, false: a.Value}[a.SetType()]}
+			value["Value"] = a.wireValue()
 		}
 		expected[a.Name] = value
 	}
@@ -271,12 +481,29 @@ func (q *Query) AddExpected(attributes []Attribute) {
 func attributeList(attributes []Attribute) msi {
 	b := msi{}
 	for _, a := range attributes {
-		//UGH!!  (I miss the query operator)
-		b[a.Name] = msi{a.Type: map[bool]interface{}{true: a.SetValues, false: a.Value}[a.SetType()]}
+		b[a.Name] = a.wireValue()
 	}
 	return b
 }
 
+// copy returns a query with the parameters of q, which the Add methods of
+// either can then set without affecting the other. The expression names
+// and values, which they add to, are copied too.
+func (q *Query) copy() *Query {
+	buffer := make(msi, len(q.buffer))
+	for k, v := range q.buffer {
+		if m, ok := v.(msi); ok && (k == "ExpressionAttributeNames" || k == "ExpressionAttributeValues") {
+			c := make(msi, len(m))
+			for name, value := range m {
+				c[name] = value
+			}
+			v = c
+		}
+		buffer[k] = v
+	}
+	return &Query{buffer}
+}
+
 func (q *Query) addTable(t *Table) {
 	q.addTableByName(t.Name)
 }
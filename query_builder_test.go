@@ -1,6 +1,8 @@
 package dynamodb_test
 
 import (
+	"time"
+
 	simplejson "github.com/bitly/go-simplejson"
 	"github.com/bluele/dynamodb"
 	"github.com/goamz/goamz/aws"
@@ -427,3 +429,98 @@ func (s *QueryBuilderSuite) TestAddQueryFilterConditions(c *check.C) {
 	}
 	c.Check(queryJson, check.DeepEquals, expectedJson)
 }
+
+func (s *QueryBuilderSuite) TestAddExpressions(c *check.C) {
+	primary := dynamodb.NewStringAttribute("domain", "")
+	key := dynamodb.PrimaryKey{primary, nil}
+	table := s.server.NewTable("sites", key)
+
+	q := dynamodb.NewQuery(table)
+	q.AddFilterExpression("attribute_exists(#a) AND #c > :min")
+	q.AddProjectionExpression("#a, #c")
+	q.AddExpressionAttributeNames(map[string]string{"#a": "owner"})
+	q.AddExpressionAttributeNames(map[string]string{"#c": "count"})
+	q.AddExpressionAttributeValues([]dynamodb.Attribute{
+		*dynamodb.NewNumericAttribute(":min", "5"),
+	})
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+{
+  "FilterExpression": "attribute_exists(#a) AND #c > :min",
+  "ProjectionExpression": "#a, #c",
+  "ExpressionAttributeNames": {
+    "#a": "owner",
+    "#c": "count"
+  },
+  "ExpressionAttributeValues": {
+    ":min": { "N": "5" }
+  },
+  "TableName": "sites"
+}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, check.DeepEquals, expectedJson)
+}
+
+func (s *QueryBuilderSuite) TestAddRestoreToPointInTime(c *check.C) {
+	q := dynamodb.NewEmptyQuery()
+	q.AddRestoreToPointInTime("src", "dst", time.Unix(1500000000, 500000000))
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+{
+  "SourceTableName": "src",
+  "TargetTableName": "dst",
+  "RestoreDateTime": 1500000000.5
+}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, check.DeepEquals, expectedJson)
+
+	q = dynamodb.NewEmptyQuery()
+	q.AddRestoreToPointInTime("src", "dst", time.Time{})
+	c.Check(q.String(), check.Equals, `{"SourceTableName":"src","TargetTableName":"dst","UseLatestRestorableTime":true}`)
+}
+
+func (s *QueryBuilderSuite) TestAddCreateRequestTableOnDemand(c *check.C) {
+	q := dynamodb.NewEmptyQuery()
+	q.AddCreateRequestTable(dynamodb.TableDescriptionT{
+		TableName:            "events",
+		AttributeDefinitions: []dynamodb.AttributeDefinitionT{{Name: "id", Type: "S"}},
+		KeySchema:            []dynamodb.KeySchemaT{{AttributeName: "id", KeyType: "HASH"}},
+		BillingModeSummary:   dynamodb.BillingModeSummaryT{BillingMode: dynamodb.BILLING_MODE_PAY_PER_REQUEST},
+		StreamSpecification: dynamodb.StreamSpecificationT{
+			StreamEnabled:  true,
+			StreamViewType: dynamodb.STREAM_VIEW_TYPE_NEW_AND_OLD_IMAGES,
+		},
+	})
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+	if err != nil {
+		c.Fatal(err)
+	}
+
+	expectedJson, err := simplejson.NewJson([]byte(`
+{
+  "AttributeDefinitions": [{"AttributeName": "id", "AttributeType": "S"}],
+  "KeySchema": [{"AttributeName": "id", "KeyType": "HASH"}],
+  "TableName": "events",
+  "BillingMode": "PAY_PER_REQUEST",
+  "StreamSpecification": {"StreamEnabled": true, "StreamViewType": "NEW_AND_OLD_IMAGES"}
+}
+	`))
+	if err != nil {
+		c.Fatal(err)
+	}
+	c.Check(queryJson, check.DeepEquals, expectedJson)
+}
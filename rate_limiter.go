@@ -0,0 +1,204 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+var (
+	readOperations = map[string]bool{
+		"GetItem": true, "BatchGetItem": true, "Query": true, "Scan": true,
+	}
+	writeOperations = map[string]bool{
+		"PutItem": true, "UpdateItem": true, "DeleteItem": true, "BatchWriteItem": true,
+	}
+)
+
+// RateLimiter caps the read and write capacity units consumed per second
+// on each table, so that bulk jobs leave capacity to other clients. It
+// asks Dynamodb for the consumed capacity of every read and write and,
+// once a table has used up its budget, delays the next requests to it
+// until the budget has refilled. Install it with Server.Use(l.Middleware()).
+type RateLimiter struct {
+	// Units per second allowed on each table, unlimited when zero.
+	ReadUnitsPerSecond  float64
+	WriteUnitsPerSecond float64
+
+	// Now returns the current time, time.Now when nil.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func NewRateLimiter(readUnitsPerSecond, writeUnitsPerSecond float64) *RateLimiter {
+	return &RateLimiter{
+		ReadUnitsPerSecond:  readUnitsPerSecond,
+		WriteUnitsPerSecond: writeUnitsPerSecond,
+	}
+}
+
+func (l *RateLimiter) now() time.Time {
+	if l.Now != nil {
+		return l.Now()
+	}
+	return time.Now()
+}
+
+// tokenBucket holds up to one second worth of units. Consumption is only
+// known once a response arrives, so the balance may go negative; requests
+// wait until it is positive again.
+type tokenBucket struct {
+	rate    float64
+	balance float64
+	updated time.Time
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.balance += now.Sub(b.updated).Seconds() * b.rate
+	if b.balance > b.rate {
+		b.balance = b.rate
+	}
+	b.updated = now
+}
+
+// bucket returns the bucket of the table for reads or writes, nil when
+// unlimited.
+func (l *RateLimiter) bucket(tableName string, write bool) *tokenBucket {
+	rate, kind := l.ReadUnitsPerSecond, "read:"
+	if write {
+		rate, kind = l.WriteUnitsPerSecond, "write:"
+	}
+	if rate <= 0 {
+		return nil
+	}
+	if l.buckets == nil {
+		l.buckets = make(map[string]*tokenBucket)
+	}
+	b, ok := l.buckets[kind+tableName]
+	if !ok {
+		b = &tokenBucket{rate: rate, balance: rate, updated: l.now()}
+		l.buckets[kind+tableName] = b
+	}
+	return b
+}
+
+// delay returns how long to wait before the tables may be used again.
+func (l *RateLimiter) delay(tables []string, write bool) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var longest time.Duration
+	for _, table := range tables {
+		b := l.bucket(table, write)
+		if b == nil {
+			continue
+		}
+		b.refill(now)
+		if b.balance < 0 {
+			if d := time.Duration(-b.balance / b.rate * float64(time.Second)); d > longest {
+				longest = d
+			}
+		}
+	}
+	return longest
+}
+
+func (l *RateLimiter) consume(units map[string]float64, write bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for table, n := range units {
+		if b := l.bucket(table, write); b != nil {
+			b.refill(now)
+			b.balance -= n
+		}
+	}
+}
+
+// Middleware returns the middleware applying the limits.
+func (l *RateLimiter) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) ([]byte, error) {
+			write := writeOperations[req.Operation]
+			if !write && !readOperations[req.Operation] {
+				return next(req)
+			}
+
+			body, tables := requestForCapacity(req.Body)
+			if d := l.delay(tables, write); d > 0 {
+				if !sleepContext(req.Context, d) {
+					return nil, req.Context.Err()
+				}
+			}
+
+			req.Body = body
+			response, err := next(req)
+			if err == nil {
+				l.consume(consumedUnitsByTable(response), write)
+			}
+			return response, err
+		}
+	}
+}
+
+// requestForCapacity returns body asking for the total consumed capacity,
+// and the tables the request touches.
+func requestForCapacity(body []byte) ([]byte, []string) {
+	var r map[string]json.RawMessage
+	if json.Unmarshal(body, &r) != nil {
+		return body, nil
+	}
+
+	var tables []string
+	var name string
+	if json.Unmarshal(r["TableName"], &name) == nil && name != "" {
+		tables = append(tables, name)
+	}
+	var items map[string]json.RawMessage
+	if json.Unmarshal(r["RequestItems"], &items) == nil {
+		for table := range items {
+			tables = append(tables, table)
+		}
+	}
+
+	var current string
+	if json.Unmarshal(r["ReturnConsumedCapacity"], &current) == nil && current != "" && current != RETURN_CONSUMED_CAPACITY_NONE {
+		return body, tables
+	}
+	r["ReturnConsumedCapacity"] = json.RawMessage(`"` + RETURN_CONSUMED_CAPACITY_TOTAL + `"`)
+	if rewritten, err := json.Marshal(r); err == nil {
+		body = rewritten
+	}
+	return body, tables
+}
+
+// consumedUnitsByTable reads ConsumedCapacity, an object for single table
+// operations and a list for batch operations.
+func consumedUnitsByTable(body []byte) map[string]float64 {
+	var r struct{ ConsumedCapacity json.RawMessage }
+	if len(body) == 0 || json.Unmarshal(body, &r) != nil || len(r.ConsumedCapacity) == 0 {
+		return nil
+	}
+
+	type units struct {
+		TableName     string
+		CapacityUnits float64
+	}
+	var many []units
+	var one units
+	if json.Unmarshal(r.ConsumedCapacity, &one) == nil {
+		many = []units{one}
+	} else if json.Unmarshal(r.ConsumedCapacity, &many) != nil {
+		return nil
+	}
+
+	out := make(map[string]float64, len(many))
+	for _, u := range many {
+		out[u.TableName] += u.CapacityUnits
+	}
+	return out
+}
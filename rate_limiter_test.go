@@ -0,0 +1,50 @@
+package dynamodb_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type RateLimiterSuite struct {
+}
+
+var _ = check.Suite(&RateLimiterSuite{})
+
+func (s *RateLimiterSuite) TestWritesAreDelayed(c *check.C) {
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"})
+	table := server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	limiter := dynamodb.NewRateLimiter(0, 100)
+	limiter.Now = func() time.Time { return now }
+	server.Use(limiter.Middleware())
+	server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			c.Check(strings.Contains(string(req.Body), `"ReturnConsumedCapacity":"TOTAL"`), check.Equals, true)
+			return []byte(`{"ConsumedCapacity":{"TableName":"users","CapacityUnits":110}}`), nil
+		}
+	})
+
+	// A cancelled context fails only the writes that have to wait.
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	item := []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", "u1")}
+	_, err := table.PutItemWithOptions(cancelled, item, nil)
+	c.Assert(err, check.IsNil)
+
+	// The first write overdrew the bucket by 10 units, a tenth of a second.
+	_, err = table.PutItemWithOptions(cancelled, item, nil)
+	c.Check(err, check.Equals, context.Canceled)
+	now = now.Add(99 * time.Millisecond)
+	_, err = table.PutItemWithOptions(cancelled, item, nil)
+	c.Check(err, check.Equals, context.Canceled)
+	now = now.Add(time.Millisecond)
+	_, err = table.PutItemWithOptions(cancelled, item, nil)
+	c.Check(err, check.IsNil)
+}
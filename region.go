@@ -0,0 +1,88 @@
+package dynamodb
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/goamz/goamz/aws"
+)
+
+// DNS suffixes of the AWS partitions, by region name prefix. Regions
+// matching none are in the commercial partition, amazonaws.com.
+var partitionSuffixes = []struct {
+	prefix, suffix string
+}{
+	{"cn-", "amazonaws.com.cn"},
+	{"us-iso-", "c2s.ic.gov"},
+	{"us-isob-", "sc2s.sgov.gov"},
+}
+
+func partitionSuffix(regionName string) string {
+	for _, p := range partitionSuffixes {
+		if strings.HasPrefix(regionName, p.prefix) {
+			return p.suffix
+		}
+	}
+	return "amazonaws.com"
+}
+
+// NewRegion returns the region named name, for regions missing from
+// aws.Regions such as recent ones, GovCloud (us-gov-*), China (cn-*) or
+// custom deployments. Requests are signed for name, which must be the
+// region the endpoint serves; endpoint defaults to the Dynamodb endpoint of
+// name in its partition, and the STS endpoint is derived the same way.
+// The scheme of endpoint defaults to https.
+//
+//	region, err := dynamodb.NewRegion("cn-north-1", "")
+//	region, err := dynamodb.NewRegion("us-gov-west-1", "https://dynamodb.us-gov-west-1.amazonaws.com")
+//	server := dynamodb.New(auth, region)
+func NewRegion(name, endpoint string) (aws.Region, error) {
+	if name == "" {
+		return aws.Region{}, errors.New("Region name cannot be empty.")
+	}
+	suffix := partitionSuffix(name)
+	if endpoint == "" {
+		endpoint = "https://dynamodb." + name + "." + suffix
+	} else if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return aws.Region{}, err
+	}
+	if u.Host == "" {
+		return aws.Region{}, errors.New("Invalid endpoint " + endpoint + ".")
+	}
+
+	region := aws.Regions[name]
+	region.Name = name
+	region.DynamoDBEndpoint = strings.TrimSuffix(endpoint, "/")
+	if region.STSEndpoint == "" {
+		region.STSEndpoint = "https://sts." + name + "." + suffix
+	}
+	return region, nil
+}
+
+// EndpointT is an endpoint returned by DescribeEndpoints.
+type EndpointT struct {
+	Address              string
+	CachePeriodInMinutes int64
+}
+
+// DescribeEndpoints returns the endpoints serving the Server's region,
+// which may be cached for CachePeriodInMinutes. Make a Server using one
+// with NewRegion(s.Region.Name, endpoint.Address).
+func (s *Server) DescribeEndpoints(isRetry bool) ([]EndpointT, error) {
+	jsonResponse, err := s.queryServer(target("DescribeEndpoints"), NewEmptyQuery(), isRetry)
+	if err != nil {
+		return nil, err
+	}
+	var r struct {
+		Endpoints []EndpointT
+	}
+	if err := decodeResponse(jsonResponse, &r); err != nil {
+		return nil, err
+	}
+	return r.Endpoints, nil
+}
@@ -0,0 +1,75 @@
+package dynamodb_test
+
+import (
+	"strings"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type RegionSuite struct{}
+
+var _ = check.Suite(&RegionSuite{})
+
+func (s *RegionSuite) TestPartitions(c *check.C) {
+	for name, endpoint := range map[string]string{
+		"ap-southeast-5": "https://dynamodb.ap-southeast-5.amazonaws.com",
+		"us-gov-west-1":  "https://dynamodb.us-gov-west-1.amazonaws.com",
+		"cn-north-1":     "https://dynamodb.cn-north-1.amazonaws.com.cn",
+		"us-iso-east-1":  "https://dynamodb.us-iso-east-1.c2s.ic.gov",
+		"us-isob-east-1": "https://dynamodb.us-isob-east-1.sc2s.sgov.gov",
+	} {
+		region, err := dynamodb.NewRegion(name, "")
+		c.Assert(err, check.IsNil)
+		c.Check(region.Name, check.Equals, name)
+		c.Check(region.DynamoDBEndpoint, check.Equals, endpoint)
+	}
+
+	region, err := dynamodb.NewRegion("cn-northwest-1", "")
+	c.Assert(err, check.IsNil)
+	c.Check(region.STSEndpoint, check.Equals, "https://sts.cn-northwest-1.amazonaws.com.cn")
+}
+
+func (s *RegionSuite) TestCustomEndpoint(c *check.C) {
+	region, err := dynamodb.NewRegion("us-gov-west-1", "dynamodb.us-gov-west-1.amazonaws.com/")
+	c.Assert(err, check.IsNil)
+	c.Check(region.DynamoDBEndpoint, check.Equals, "https://dynamodb.us-gov-west-1.amazonaws.com")
+
+	region, err = dynamodb.NewRegion("local", "http://localhost:8000")
+	c.Assert(err, check.IsNil)
+	c.Check(region.DynamoDBEndpoint, check.Equals, "http://localhost:8000")
+
+	_, err = dynamodb.NewRegion("", "")
+	c.Check(err, check.ErrorMatches, "Region name cannot be empty.")
+	_, err = dynamodb.NewRegion("local", "http://")
+	c.Check(err, check.ErrorMatches, "Invalid endpoint http://.")
+}
+
+func (s *RegionSuite) TestSigningScope(c *check.C) {
+	region, err := dynamodb.NewRegion("cn-north-1", "")
+	c.Assert(err, check.IsNil)
+	server := dynamodb.New(aws.Auth{AccessKey: "DUMMY_KEY", SecretKey: "DUMMY_SECRET"}, region)
+	var request *dynamodb.SignedRequest
+	server.DryRun = func(req *dynamodb.SignedRequest) { request = req }
+
+	_, err = server.ListTables(false)
+	c.Check(err, check.Equals, dynamodb.ErrDryRun)
+	c.Assert(request, check.NotNil)
+	c.Check(request.URL, check.Equals, "https://dynamodb.cn-north-1.amazonaws.com.cn/")
+	c.Check(strings.Contains(request.Header.Get("Authorization"), "/cn-north-1/dynamodb"), check.Equals, true)
+}
+
+func (s *RegionSuite) TestDescribeEndpoints(c *check.C) {
+	server := dynamodb.New(aws.Auth{}, aws.Region{Name: "us-east-1", DynamoDBEndpoint: "http://127.0.0.1:1"})
+	server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			c.Check(req.Operation, check.Equals, "DescribeEndpoints")
+			c.Check(string(req.Body), check.Equals, "{}")
+			return []byte(`{"Endpoints":[{"Address":"dynamodb.us-east-1.amazonaws.com","CachePeriodInMinutes":1440}]}`), nil
+		}
+	})
+	endpoints, err := server.DescribeEndpoints(false)
+	c.Assert(err, check.IsNil)
+	c.Check(endpoints, check.DeepEquals, []dynamodb.EndpointT{{Address: "dynamodb.us-east-1.amazonaws.com", CachePeriodInMinutes: 1440}})
+}
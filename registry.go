@@ -0,0 +1,55 @@
+package dynamodb
+
+// tableEntry is a Table of the registry of a Server, ready once done is
+// closed.
+type tableEntry struct {
+	done  chan struct{}
+	table *Table
+	err   error
+}
+
+// Table returns a Table with the key and secondary indexes of the named
+// table, described with DescribeTable on the first call and cached by the
+// Server afterwards. Concurrent first calls share one DescribeTable; a
+// failed one is not cached. Use ForgetTable after changing the schema of
+// the table.
+func (s *Server) Table(name string) (*Table, error) {
+	s.tablesMu.Lock()
+	e, ok := s.tables[name]
+	if !ok {
+		e = &tableEntry{done: make(chan struct{})}
+		if s.tables == nil {
+			s.tables = make(map[string]*tableEntry)
+		}
+		s.tables[name] = e
+	}
+	s.tablesMu.Unlock()
+
+	if ok {
+		<-e.done
+		return e.table, e.err
+	}
+
+	desc, err := s.DescribeTable(name, true)
+	if err == nil {
+		e.table, err = s.NewTableFromDescription(desc)
+	}
+	e.err = err
+	if err != nil {
+		s.tablesMu.Lock()
+		if s.tables[name] == e {
+			delete(s.tables, name)
+		}
+		s.tablesMu.Unlock()
+	}
+	close(e.done)
+	return e.table, e.err
+}
+
+// ForgetTable drops the named table from the registry of Table, so that
+// the next call describes it again.
+func (s *Server) ForgetTable(name string) {
+	s.tablesMu.Lock()
+	defer s.tablesMu.Unlock()
+	delete(s.tables, name)
+}
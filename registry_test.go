@@ -0,0 +1,82 @@
+package dynamodb_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type RegistrySuite struct {
+	server *dynamodb.Server
+
+	mu        sync.Mutex
+	describes int
+}
+
+var _ = check.Suite(&RegistrySuite{})
+
+func (s *RegistrySuite) SetUpTest(c *check.C) {
+	s.describes = 0
+	s.server, _ = dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation == "DescribeTable" {
+				s.mu.Lock()
+				s.describes++
+				s.mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+			}
+			return next(req)
+		}
+	})
+
+	createTable(c, s.server, tableSchema(c, "events", struct {
+		ID   string `dynamodb:"id,hash"`
+		Seq  int64  `dynamodb:"seq,range"`
+		Kind string `dynamodb:"kind"`
+	}{}))
+}
+
+func (s *RegistrySuite) TestTable(c *check.C) {
+	var wg sync.WaitGroup
+	tables := make([]*dynamodb.Table, 5)
+	for i := range tables {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			t, err := s.server.Table("events")
+			c.Check(err, check.IsNil)
+			tables[i] = t
+		}(i)
+	}
+	wg.Wait()
+
+	c.Check(s.describes, check.Equals, 1)
+	for _, t := range tables {
+		c.Check(t == tables[0], check.Equals, true)
+	}
+	t := tables[0]
+	c.Check(t.Name, check.Equals, "events")
+	c.Check(t.Key.KeyAttribute, check.DeepEquals, &dynamodb.Attribute{Type: dynamodb.TYPE_STRING, Name: "id"})
+	c.Check(t.Key.RangeAttribute, check.DeepEquals, &dynamodb.Attribute{Type: dynamodb.TYPE_NUMBER, Name: "seq"})
+
+	_, err := t.PutItemWithOptions(context.Background(), append(t.Key.Clone("u1", "1"), *dynamodb.NewStringAttribute("kind", "click")), nil)
+	c.Check(err, check.IsNil)
+
+	s.server.ForgetTable("events")
+	again, err := s.server.Table("events")
+	c.Assert(err, check.IsNil)
+	c.Check(again == t, check.Equals, false)
+	c.Check(s.describes, check.Equals, 2)
+}
+
+func (s *RegistrySuite) TestErrorsAreNotCached(c *check.C) {
+	_, err := s.server.Table("missing")
+	c.Check(dynamodb.IsNotFound(err), check.Equals, true)
+	_, err = s.server.Table("missing")
+	c.Check(dynamodb.IsNotFound(err), check.Equals, true)
+	c.Check(s.describes, check.Equals, 2)
+}
@@ -0,0 +1,121 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// RenameAttributeOptions tunes RenameAttribute. The zero value is usable.
+type RenameAttributeOptions struct {
+	// Number of parallel scan segments, 4 when zero.
+	TotalSegments int
+	// Remove the old attribute once it has been copied. Leave it false
+	// while readers still use the old name (or an alias of it) so both
+	// names resolve during the transition.
+	RemoveOld bool
+	// Called after every scanned page with the running totals.
+	Progress func(RenameProgress)
+	IsRetry  bool
+}
+
+// RenameProgress reports how far a RenameAttribute run has got.
+type RenameProgress struct {
+	Scanned int64 // items carrying the old attribute seen so far
+	Updated int64 // items backfilled by this run
+	Skipped int64 // items already migrated or changed concurrently
+}
+
+// RenameAttribute copies oldName into newName on every item of t which
+// has oldName but not yet newName. Each item is updated with a
+// conditional UpdateItem, so running it again, or concurrently with
+// writers that already set newName, is safe.
+func RenameAttribute(ctx context.Context, t *Table, oldName, newName string, opts *RenameAttributeOptions) (RenameProgress, error) {
+	if oldName == "" || newName == "" || oldName == newName {
+		return RenameProgress{}, errors.New("Old and new attribute names must differ and be non empty.")
+	}
+	if opts == nil {
+		opts = &RenameAttributeOptions{}
+	}
+	segments := opts.TotalSegments
+	if segments <= 0 {
+		segments = 4
+	}
+
+	var (
+		mu       sync.Mutex
+		progress RenameProgress
+	)
+	err := runSegments(ctx, segments, func(ctx context.Context, segment int) error {
+		return t.renameSegment(ctx, oldName, newName, segment, segments, opts, func(scanned, updated, skipped int64) {
+			mu.Lock()
+			progress.Scanned += scanned
+			progress.Updated += updated
+			progress.Skipped += skipped
+			current := progress
+			if opts.Progress != nil {
+				opts.Progress(current)
+			}
+			mu.Unlock()
+		})
+	})
+	return progress, err
+}
+
+func (t *Table) renameSegment(ctx context.Context, oldName, newName string, segment, totalSegments int, opts *RenameAttributeOptions, report func(scanned, updated, skipped int64)) error {
+	names := map[string]string{"#old": oldName, "#new": newName}
+
+	q := NewQuery(t)
+	q.AddFilterExpression("attribute_exists(#old) AND attribute_not_exists(#new)")
+	q.AddExpressionAttributeNames(names)
+	q.AddParallelScanConfiguration(segment, totalSegments)
+
+	updateExpression := "SET #new = #old"
+	if opts.RemoveOld {
+		updateExpression += " REMOVE #old"
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page, err := t.fetchPageContext(ctx, "Scan", q, opts.IsRetry)
+		if err != nil {
+			return err
+		}
+		items := page.Items
+
+		var updated, skipped int64
+		for _, item := range items {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			key, err := t.KeyFromItem(item)
+			if err != nil {
+				return err
+			}
+
+			u := NewQuery(t)
+			u.AddKey(t, key)
+			u.AddUpdateExpression(updateExpression)
+			u.AddConditionExpression("attribute_exists(#old) AND attribute_not_exists(#new)")
+			u.AddExpressionAttributeNames(names)
+
+			_, err = t.Server.queryServerContext(ctx, target("UpdateItem"), u, opts.IsRetry)
+			switch {
+			case err == nil:
+				updated++
+			case IsConditionalCheckFailed(err):
+				skipped++
+			default:
+				return err
+			}
+		}
+		report(int64(len(items)), updated, skipped)
+
+		if page.LastEvaluatedKey == nil {
+			return nil
+		}
+		q.AddExclusiveStartKey(t, page.LastEvaluatedKey)
+	}
+}
@@ -0,0 +1,22 @@
+package dynamodb
+
+import (
+	"context"
+)
+
+const requestIDHeader = "X-Amzn-Requestid"
+
+// ResponseMetadata describes the response to a call.
+type ResponseMetadata struct {
+	// The x-amzn-RequestId Dynamodb gave the response, empty when no
+	// response was received. Errors returned by Dynamodb carry it too.
+	RequestID string
+}
+
+type responseMetadataKey struct{}
+
+// WithResponseMetadata returns a context for which md is filled in once
+// each call made with it returns, successful or not.
+func WithResponseMetadata(ctx context.Context, md *ResponseMetadata) context.Context {
+	return context.WithValue(ctx, responseMetadataKey{}, md)
+}
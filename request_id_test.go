@@ -0,0 +1,50 @@
+package dynamodb_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type RequestIDSuite struct{}
+
+var _ = check.Suite(&RequestIDSuite{})
+
+func (s *RequestIDSuite) TestRequestIDs(c *check.C) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") == "DynamoDB_20120810.DeleteItem" {
+			w.Header().Set("x-amzn-RequestId", "FAILED0001")
+			w.WriteHeader(400)
+			w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`))
+			return
+		}
+		w.Header().Set("x-amzn-RequestId", "OK0001")
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	var metrics []dynamodb.OperationMetrics
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: ts.URL})
+	server.Logger = dynamodb.NopLogger
+	server.Metrics = dynamodb.MetricsCollectorFunc(func(m dynamodb.OperationMetrics) { metrics = append(metrics, m) })
+	table := server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+
+	result, err := table.PutItemWithOptions(context.Background(), []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", "u1")}, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(result.RequestID, check.Equals, "OK0001")
+
+	var md dynamodb.ResponseMetadata
+	_, err = table.DeleteItemWithOptions(dynamodb.WithResponseMetadata(context.Background(), &md), &dynamodb.Key{HashKey: "u1"}, nil)
+	c.Check(dynamodb.IsConditionalCheckFailed(err), check.Equals, true)
+	c.Check(err, check.ErrorMatches, `ConditionalCheckFailedException: The conditional request failed \(request id FAILED0001\)`)
+	c.Check(err.(*dynamodb.Error).RequestID, check.Equals, "FAILED0001")
+	c.Check(md.RequestID, check.Equals, "FAILED0001")
+
+	c.Assert(metrics, check.HasLen, 2)
+	c.Check(metrics[0].RequestID, check.Equals, "OK0001")
+	c.Check(metrics[1].RequestID, check.Equals, "FAILED0001")
+}
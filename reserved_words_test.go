@@ -0,0 +1,59 @@
+package dynamodb_test
+
+import (
+	"context"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type ReservedWordsSuite struct {
+}
+
+var _ = check.Suite(&ReservedWordsSuite{})
+
+func (s *ReservedWordsSuite) TestIsReservedWord(c *check.C) {
+	c.Check(dynamodb.IsReservedWord("status"), check.Equals, true)
+	c.Check(dynamodb.IsReservedWord("Name"), check.Equals, true)
+	c.Check(dynamodb.IsReservedWord("userId"), check.Equals, false)
+}
+
+func (s *ReservedWordsSuite) TestEscapeReservedWords(c *check.C) {
+	names := map[string]string{"#a": "address"}
+	expr := dynamodb.EscapeReservedWords(
+		"attribute_exists(name) AND size(comment) > :n AND #a.status = :s AND userId IN (:u)", names)
+	c.Check(expr, check.Equals,
+		"attribute_exists(#name) AND size(#comment) > :n AND #a.#status = :s AND userId IN (:u)")
+	c.Check(names, check.DeepEquals, map[string]string{
+		"#a": "address", "#name": "name", "#comment": "comment", "#status": "status",
+	})
+
+	// A placeholder already taken by another attribute is not reused.
+	names = map[string]string{"#name": "fullName"}
+	expr = dynamodb.EscapeReservedWords("SET name = :v, #name = :w", names)
+	c.Check(expr, check.Equals, "SET #name_ = :v, #name = :w")
+	c.Check(names["#name_"], check.Equals, "name")
+}
+
+func (s *ReservedWordsSuite) TestEscapedOnSend(c *check.C) {
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"})
+	pk := dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")}
+	table := server.NewTable("users", pk)
+	server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			c.Check(string(req.Body), check.Equals,
+				`{"ConditionExpression":"attribute_exists(id)","ExpressionAttributeNames":{"#status":"status"},`+
+					`"ExpressionAttributeValues":{":s":{"S":"active"}},"Key":{"id":{"S":"u1"}},"TableName":"users",`+
+					`"UpdateExpression":"SET #status = :s"}`)
+			return []byte(`{}`), nil
+		}
+	})
+
+	_, err := table.UpdateItemWithOptions(context.Background(), &dynamodb.Key{HashKey: "u1"}, nil, "", &dynamodb.WriteOptions{
+		UpdateExpression:          "SET status = :s",
+		ConditionExpression:       "attribute_exists(id)",
+		ExpressionAttributeValues: []dynamodb.Attribute{*dynamodb.NewStringAttribute(":s", "active")},
+	})
+	c.Assert(err, check.IsNil)
+}
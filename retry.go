@@ -0,0 +1,89 @@
+package dynamodb
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how requests made with isRetry are re-sent. Delays
+// grow exponentially from BaseDelay up to MaxDelay, and each actual delay
+// is drawn at random below that bound ("full jitter") so that clients
+// throttled together do not retry together.
+type RetryPolicy struct {
+	// Maximum number of re-sends of a request, unlimited when zero.
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	// No retry is attempted once this much time has passed since the
+	// first attempt, unlimited when zero.
+	MaxElapsed time.Duration
+}
+
+// DefaultRetryPolicy is used by Servers without a RetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 10,
+	BaseDelay:  50 * time.Millisecond,
+	MaxDelay:   20 * time.Second,
+	MaxElapsed: time.Minute,
+}
+
+func (s *Server) retryPolicy() *RetryPolicy {
+	if s.RetryPolicy != nil {
+		return s.RetryPolicy
+	}
+	return &DefaultRetryPolicy
+}
+
+type retryPolicyKey struct{}
+
+// withRetryPolicy returns a context whose calls follow p instead of the
+// Server's retry policy.
+func withRetryPolicy(ctx context.Context, p *RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, p)
+}
+
+// retryPolicyFor returns the retry policy of a call made with ctx.
+func (s *Server) retryPolicyFor(ctx context.Context) *RetryPolicy {
+	if p, ok := ctx.Value(retryPolicyKey{}).(*RetryPolicy); ok {
+		return p
+	}
+	return s.retryPolicy()
+}
+
+// Backoff returns the delay before retry number attempt (starting at 0).
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	bound := p.MaxDelay
+	if attempt < 62 && p.BaseDelay > 0 {
+		if d := p.BaseDelay << uint(attempt); d > 0 && (bound <= 0 || d < bound) {
+			bound = d
+		}
+	}
+	if bound <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(bound) + 1))
+}
+
+// allows reports whether retry number attempt may be made after elapsed
+// time, with the given delay before it.
+func (p *RetryPolicy) allows(attempt int, elapsed, delay time.Duration) bool {
+	if p.MaxRetries > 0 && attempt >= p.MaxRetries {
+		return false
+	}
+	if p.MaxElapsed > 0 && elapsed+delay > p.MaxElapsed {
+		return false
+	}
+	return true
+}
+
+// retryAfter parses a Retry-After header given in seconds.
+func retryAfter(h http.Header) time.Duration {
+	seconds, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,148 @@
+package dynamodb_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type RetrySuite struct {
+	calls   int32
+	failing int
+	status  int
+	body    string
+	slow    int // number of calls answered late
+	server  *dynamodb.Server
+	http    *httptest.Server
+	table   *dynamodb.Table
+}
+
+var _ = check.Suite(&RetrySuite{})
+
+func (s *RetrySuite) SetUpTest(c *check.C) {
+	s.calls = 0
+	s.failing = 0
+	s.slow = 0
+	s.status = 400
+	s.body = `{"__type":"com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException","message":"slow down"}`
+	s.http = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(atomic.AddInt32(&s.calls, 1))
+		if call <= s.slow {
+			time.Sleep(100 * time.Millisecond)
+		}
+		if call <= s.failing {
+			w.WriteHeader(s.status)
+			w.Write([]byte(s.body))
+			return
+		}
+		w.Write([]byte(`{"Item":{"id":{"S":"u1"}}}`))
+	}))
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: s.http.URL})
+	s.server = server
+	server.Logger = dynamodb.NopLogger
+	server.RetryPolicy = &dynamodb.RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+	s.table = server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+}
+
+func (s *RetrySuite) TearDownTest(c *check.C) {
+	s.http.Close()
+}
+
+func (s *RetrySuite) TestThrottledRequestsAreRetried(c *check.C) {
+	s.failing = 3
+	_, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, true)
+	c.Assert(err, check.IsNil)
+	c.Check(atomic.LoadInt32(&s.calls), check.Equals, int32(4))
+}
+
+func (s *RetrySuite) TestRetriesAreBounded(c *check.C) {
+	s.failing = 10
+	_, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, true)
+	c.Check(dynamodb.IsThrottle(err), check.Equals, true)
+	c.Check(atomic.LoadInt32(&s.calls), check.Equals, int32(4))
+}
+
+func (s *RetrySuite) TestNoRetryWithoutIsRetry(c *check.C) {
+	s.failing = 1
+	_, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Check(dynamodb.IsThrottle(err), check.Equals, true)
+	c.Check(atomic.LoadInt32(&s.calls), check.Equals, int32(1))
+}
+
+func (s *RetrySuite) TestPutItemRetriesWithoutIsRetry(c *check.C) {
+	// PutItem has always re-sent failed writes, at most 4 times.
+	s.failing = 2
+	//lint:ignore SA1019 the legacy retries of PutItem are under test.
+	_, err := s.table.PutItem("u1", "", []dynamodb.Attribute{*dynamodb.NewStringAttribute("name", "a")}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(atomic.LoadInt32(&s.calls), check.Equals, int32(3))
+
+	s.calls, s.failing = 0, 10
+	_, err = s.table.PutItemWithOptions(context.Background(), []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", "u1")}, nil)
+	c.Check(dynamodb.IsThrottle(err), check.Equals, true)
+	c.Check(atomic.LoadInt32(&s.calls), check.Equals, int32(5))
+
+	// With IsRetry, the Server's policy applies.
+	s.calls = 0
+	_, err = s.table.PutItemWithOptions(context.Background(), []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", "u1")}, &dynamodb.WriteOptions{IsRetry: true})
+	c.Check(dynamodb.IsThrottle(err), check.Equals, true)
+	c.Check(atomic.LoadInt32(&s.calls), check.Equals, int32(4))
+}
+
+func (s *RetrySuite) TestServerErrorsAreRetried(c *check.C) {
+	s.failing = 1
+	s.status = 500
+	s.body = `{"__type":"com.amazonaws.dynamodb.v20120810#InternalServerError","message":"oops"}`
+	_, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, true)
+	c.Check(err, check.IsNil)
+	c.Check(atomic.LoadInt32(&s.calls), check.Equals, int32(2))
+}
+
+func (s *RetrySuite) TestClientErrorsAreNotRetried(c *check.C) {
+	s.failing = 1
+	s.body = `{"__type":"com.amazon.coral.validate#ValidationException","message":"bad"}`
+	_, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, true)
+	c.Check(dynamodb.ErrorCode(err), check.Equals, dynamodb.ValidationException)
+	c.Check(atomic.LoadInt32(&s.calls), check.Equals, int32(1))
+}
+
+func (s *RetrySuite) TestBackoffIsCapped(c *check.C) {
+	p := &dynamodb.RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 100 * time.Millisecond}
+	for attempt := 0; attempt < 100; attempt++ {
+		d := p.Backoff(attempt)
+		c.Check(d >= 0 && d <= 100*time.Millisecond, check.Equals, true)
+		if attempt == 0 {
+			c.Check(d <= 10*time.Millisecond, check.Equals, true)
+		}
+	}
+}
+
+func (s *RetrySuite) TestAttemptTimeoutIsRetried(c *check.C) {
+	s.slow = 1
+	s.server.Timeouts.Attempt = 30 * time.Millisecond
+	_, err := s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, true)
+	c.Check(err, check.IsNil)
+	c.Check(atomic.LoadInt32(&s.calls), check.Equals, int32(2))
+
+	atomic.StoreInt32(&s.calls, 0)
+	_, err = s.table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Check(errors.Is(err, dynamodb.ErrAttemptTimeout), check.Equals, true)
+}
+
+func (s *RetrySuite) TestOperationTimeout(c *check.C) {
+	s.slow = 10
+	s.server.Timeouts.Attempt = 30 * time.Millisecond
+	ctx := dynamodb.WithTimeouts(context.Background(), dynamodb.Timeouts{Operation: 50 * time.Millisecond})
+
+	started := time.Now()
+	_, _, err := s.table.QueryWithOptions(ctx, nil, &dynamodb.QueryOptions{IsRetry: true})
+	c.Check(err, check.NotNil)
+	c.Check(time.Since(started) < 90*time.Millisecond, check.Equals, true)
+}
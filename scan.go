@@ -1,46 +1,87 @@
 package dynamodb
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
-
-	simplejson "github.com/bitly/go-simplejson"
 )
 
 func (t *Table) FetchPartialResults(query *Query, isRetry bool) ([]map[string]*Attribute, *Key, error) {
-	jsonResponse, err := t.Server.queryServer(target("Scan"), query, isRetry)
+	page, err := t.fetchPage("Scan", query, isRetry)
 	if err != nil {
 		return nil, nil, err
 	}
+	return page.Items, page.LastEvaluatedKey, nil
+}
 
-	json, err := simplejson.NewJson(jsonResponse)
-	if err != nil {
-		return nil, nil, err
-	}
+// PageResult is one page of Query or Scan results. Count is the number of
+// items in the page and ScannedCount the number of items evaluated before
+// filters applied, so ScannedCount - Count is the capacity spent on items
+// filtered out.
+type PageResult struct {
+	Items            []map[string]*Attribute
+	LastEvaluatedKey *Key
+	Count            int64
+	ScannedCount     int64
+	ConsumedCapacity *ConsumedCapacityT
+}
 
-	itemCount, err := json.Get("Count").Int()
-	if err != nil {
+func (t *Table) fetchPage(operation string, query *Query, isRetry bool) (*PageResult, error) {
+	return t.fetchPageContext(context.Background(), operation, query, isRetry)
+}
+
+// pageResponse is the body of a Query or Scan response.
+type pageResponse struct {
+	Count            *int64
+	ScannedCount     int64
+	Items            []attributeMap
+	LastEvaluatedKey attributeMap
+	ConsumedCapacity *ConsumedCapacityT
+}
+
+func (t *Table) parsePage(jsonResponse []byte) (*PageResult, error) {
+	r := getPageResponse()
+	defer putPageResponse(r)
+	if err := decodeResponse(jsonResponse, r); err != nil {
+		return nil, err
+	}
+	if r.Count == nil || (len(r.Items) > 0 && int64(len(r.Items)) < *r.Count) {
 		message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-		return nil, nil, errors.New(message)
+		return nil, errors.New(message)
+	}
+
+	page := &PageResult{
+		Count:            *r.Count,
+		ScannedCount:     r.ScannedCount,
+		ConsumedCapacity: r.ConsumedCapacity,
 	}
 
-	results := make([]map[string]*Attribute, itemCount)
-	for i, _ := range results {
-		item, err := json.Get("Items").GetIndex(i).Map()
-		if err != nil {
-			message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-			return nil, nil, errors.New(message)
+	// Select COUNT responses carry a Count but no Items.
+	if len(r.Items) > 0 || *r.Count == 0 {
+		page.Items = make([]map[string]*Attribute, len(r.Items))
+		for i, item := range r.Items {
+			page.Items[i] = item.attributes()
 		}
-		results[i] = parseAttributes(item)
 	}
 
-	var lastEvaluatedKey *Key
-	if lastKeyMap := json.Get("LastEvaluatedKey").MustMap(); lastKeyMap != nil {
-		lastEvaluatedKey = parseKey(t, lastKeyMap)
+	if r.LastEvaluatedKey != nil {
+		page.LastEvaluatedKey = parseKey(t, r.LastEvaluatedKey)
+		if page.LastEvaluatedKey != nil && len(r.LastEvaluatedKey) > t.Key.attributeCount() {
+			// An index key: keep it whole for ExclusiveStartKey.
+			attributes := make([]Attribute, 0, len(r.LastEvaluatedKey))
+			for _, a := range r.LastEvaluatedKey.attributes() {
+				attributes = append(attributes, *a)
+			}
+			start, err := json.Marshal(attributeList(attributes))
+			if err != nil {
+				return nil, err
+			}
+			page.LastEvaluatedKey.exclusiveStart = string(start)
+		}
 	}
 
-	return results, lastEvaluatedKey, nil
+	return page, nil
 }
 
 func (t *Table) ScanPartial(attributeComparisons []AttributeComparison, exclusiveStartKey *Key, isRetry bool) ([]map[string]*Attribute, *Key, error) {
@@ -81,6 +122,19 @@ func (t *Table) Scan(attributeComparisons []AttributeComparison, isRetry bool) (
 	return t.FetchResults(q, isRetry)
 }
 
+// ScanCount counts the items of the table matching the filter over every
+// page of the scan, without transferring them. See ScanCountWithOptions.
+func (t *Table) ScanCount(attributeComparisons []AttributeComparison, isRetry bool) (int64, error) {
+	count, _, err := t.ScanCountWithOptions(context.Background(), &ScanOptions{ScanFilter: attributeComparisons, IsRetry: isRetry})
+	return count, err
+}
+
+// ScanCountOnIndex is ScanCount over the index named indexName.
+func (t *Table) ScanCountOnIndex(attributeComparisons []AttributeComparison, indexName string, isRetry bool) (int64, error) {
+	count, _, err := t.ScanCountWithOptions(context.Background(), &ScanOptions{ScanFilter: attributeComparisons, IndexName: indexName, IsRetry: isRetry})
+	return count, err
+}
+
 func (t *Table) ParallelScan(attributeComparisons []AttributeComparison, segment int, totalSegments int, isRetry bool) ([]map[string]*Attribute, error) {
 	q := NewQuery(t)
 	q.AddScanFilter(attributeComparisons)
@@ -88,45 +142,46 @@ func (t *Table) ParallelScan(attributeComparisons []AttributeComparison, segment
 	return t.FetchResults(q, isRetry)
 }
 
-func parseKey(t *Table, s map[string]interface{}) *Key {
+func parseKey(t *Table, s attributeMap) *Key {
 	k := &Key{}
+	logger := t.Server.logger()
 
 	hk := t.Key.KeyAttribute
-	if v, ok := s[hk.Name].(map[string]interface{}); ok {
+	if v, ok := s[hk.Name]; ok {
 		switch hk.Type {
 		case TYPE_NUMBER, TYPE_STRING, TYPE_BINARY:
-			if key, ok := v[hk.Type].(string); ok {
+			if key, ok := v.scalar(hk.Type); ok {
 				k.HashKey = key
 			} else {
-				log.Printf("type assertion to string failed for : %s\n", hk.Type)
+				logger.Log(LogWarn, "type assertion to string failed", "type", hk.Type)
 				return nil
 			}
 		default:
-			log.Printf("invalid primary key hash type : %s\n", hk.Type)
+			logger.Log(LogWarn, "invalid primary key hash type", "type", hk.Type)
 			return nil
 		}
 	} else {
-		log.Printf("type assertion to map[string]interface{} failed for : %s\n", hk.Name)
+		logger.Log(LogWarn, "key attribute missing", "attribute", hk.Name)
 		return nil
 	}
 
 	if t.Key.HasRange() {
 		rk := t.Key.RangeAttribute
-		if v, ok := s[rk.Name].(map[string]interface{}); ok {
+		if v, ok := s[rk.Name]; ok {
 			switch rk.Type {
 			case TYPE_NUMBER, TYPE_STRING, TYPE_BINARY:
-				if key, ok := v[rk.Type].(string); ok {
+				if key, ok := v.scalar(rk.Type); ok {
 					k.RangeKey = key
 				} else {
-					log.Printf("type assertion to string failed for : %s\n", rk.Type)
+					logger.Log(LogWarn, "type assertion to string failed", "type", rk.Type)
 					return nil
 				}
 			default:
-				log.Printf("invalid primary key range type : %s\n", rk.Type)
+				logger.Log(LogWarn, "invalid primary key range type", "type", rk.Type)
 				return nil
 			}
 		} else {
-			log.Printf("type assertion to map[string]interface{} failed for : %s\n", rk.Name)
+			logger.Log(LogWarn, "key attribute missing", "attribute", rk.Name)
 			return nil
 		}
 	}
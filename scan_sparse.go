@@ -0,0 +1,57 @@
+package dynamodb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScanAttributeExistsCallbackIterator scans only the items that carry
+// attributeName, which is cheap on sparse attributes since the filter is
+// evaluated server side. When projection is not empty only those
+// attributes are returned. Items are handed to cb one at a time as pages
+// arrive; returning false from cb stops the scan.
+func (t *Table) ScanAttributeExistsCallbackIterator(attributeName string, projection []string, cb func(map[string]*Attribute) bool, isRetry bool) error {
+	names := map[string]string{"#attr": attributeName}
+
+	q := NewQuery(t)
+	q.AddFilterExpression("attribute_exists(#attr)")
+	if len(projection) > 0 {
+		placeholders := make([]string, len(projection))
+		for i, p := range projection {
+			placeholders[i] = fmt.Sprintf("#p%d", i)
+			names[placeholders[i]] = p
+		}
+		q.AddProjectionExpression(strings.Join(placeholders, ", "))
+	}
+	q.AddExpressionAttributeNames(names)
+
+	for {
+		items, lastEvaluatedKey, err := t.FetchPartialResults(q, isRetry)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if !cb(item) {
+				return nil
+			}
+		}
+		if lastEvaluatedKey == nil {
+			return nil
+		}
+		q.AddExclusiveStartKey(t, lastEvaluatedKey)
+	}
+}
+
+// ScanAttributeExists is like ScanAttributeExistsCallbackIterator but
+// collects every matching item.
+func (t *Table) ScanAttributeExists(attributeName string, projection []string, isRetry bool) ([]map[string]*Attribute, error) {
+	var items []map[string]*Attribute
+	err := t.ScanAttributeExistsCallbackIterator(attributeName, projection,
+		func(item map[string]*Attribute) bool {
+			items = append(items, item)
+			return true
+		},
+		isRetry,
+	)
+	return items, err
+}
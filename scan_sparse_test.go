@@ -0,0 +1,85 @@
+package dynamodb_test
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type ScanSparseSuite struct {
+	table *dynamodb.Table
+	scans int
+}
+
+var _ = check.Suite(&ScanSparseSuite{})
+
+func (s *ScanSparseSuite) SetUpTest(c *check.C) {
+	// Scans read two items per page, before the filter is applied.
+	server, _ := dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation != "Scan" {
+				return next(req)
+			}
+			s.scans++
+			var body map[string]json.RawMessage
+			c.Assert(json.Unmarshal(req.Body, &body), check.IsNil)
+			body["Limit"] = json.RawMessage("2")
+			var err error
+			req.Body, err = json.Marshal(body)
+			c.Assert(err, check.IsNil)
+			return next(req)
+		}
+	})
+	s.table = createTable(c, server, tableSchema(c, "users", idKey{}))
+
+	for _, id := range []string{"u1", "u2", "u3", "u4", "u5"} {
+		item := append(s.table.Key.Clone(id, ""), *dynamodb.NewStringAttribute("name", "name of "+id))
+		if id == "u1" || id == "u3" || id == "u4" {
+			item = append(item, *dynamodb.NewStringAttribute("email", id+"@example.com"))
+		}
+		_, err := s.table.PutItemWithOptions(context.Background(), item, nil)
+		c.Assert(err, check.IsNil)
+	}
+	s.scans = 0
+}
+
+func (s *ScanSparseSuite) TestSkipsItemsWithoutAttribute(c *check.C) {
+	items, err := s.table.ScanAttributeExists("email", nil, false)
+	c.Assert(err, check.IsNil)
+
+	var ids []string
+	for _, item := range items {
+		c.Check(item["email"].Value, check.Equals, item["id"].Value+"@example.com")
+		c.Check(item["name"], check.NotNil)
+		ids = append(ids, item["id"].Value)
+	}
+	sort.Strings(ids)
+	c.Check(ids, check.DeepEquals, []string{"u1", "u3", "u4"})
+	// Five items, two per page.
+	c.Check(s.scans, check.Equals, 3)
+}
+
+func (s *ScanSparseSuite) TestProjection(c *check.C) {
+	items, err := s.table.ScanAttributeExists("email", []string{"email"}, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(items, check.HasLen, 3)
+	for _, item := range items {
+		c.Check(item, check.HasLen, 1)
+		c.Check(item["email"], check.NotNil)
+	}
+}
+
+func (s *ScanSparseSuite) TestCallbackStopsTheScan(c *check.C) {
+	var ids []string
+	err := s.table.ScanAttributeExistsCallbackIterator("email", nil, func(item map[string]*dynamodb.Attribute) bool {
+		ids = append(ids, item["id"].Value)
+		return false
+	}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(ids, check.HasLen, 1)
+	c.Check(s.scans, check.Equals, 1)
+}
@@ -0,0 +1,196 @@
+package dynamodb
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// SchemaOptions configures TableSchemaFromStruct.
+type SchemaOptions struct {
+	// Name of the table, the struct's type name when empty.
+	TableName string
+	// The table and its global secondary indexes are created with on
+	// demand billing when both capacities are zero.
+	ReadCapacityUnits  int64
+	WriteCapacityUnits int64
+	// Projection of every secondary index, ALL when zero.
+	Projection ProjectionT
+}
+
+// TableSchemaFromStruct returns the description to pass to CreateTable for
+// a table holding items of the struct type of v, which may be a pointer.
+// The key attributes are declared with options of the dynamodb tag:
+//
+//	type Event struct {
+//		User  string `dynamodb:"user,hash"`
+//		Seq   int64  `dynamodb:"seq,range,lsi=by-seq"`
+//		Kind  string `dynamodb:"kind,gsi=by-kind"`
+//		Score int    `dynamodb:"score,gsirange=by-kind"`
+//	}
+//
+// hash and range make up the table's primary key. gsi=name and
+// gsirange=name declare the hash and range keys of a global secondary
+// index, and lsi=name the range key of a local secondary index. Key
+// attributes must be strings, numbers or byte slices.
+func TableSchemaFromStruct(v interface{}, opts *SchemaOptions) (TableDescriptionT, error) {
+	if opts == nil {
+		opts = &SchemaOptions{}
+	}
+
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return TableDescriptionT{}, fmt.Errorf("Cannot infer a table schema from %T.", v)
+	}
+
+	d := TableDescriptionT{TableName: opts.TableName}
+	if d.TableName == "" {
+		d.TableName = t.Name()
+	}
+	onDemand := opts.ReadCapacityUnits == 0 && opts.WriteCapacityUnits == 0
+	throughput := ProvisionedThroughputT{
+		ReadCapacityUnits:  opts.ReadCapacityUnits,
+		WriteCapacityUnits: opts.WriteCapacityUnits,
+	}
+	if onDemand {
+		d.BillingModeSummary.BillingMode = BILLING_MODE_PAY_PER_REQUEST
+	} else {
+		d.ProvisionedThroughput = throughput
+	}
+	projection := opts.Projection
+	if projection.ProjectionType == "" {
+		projection.ProjectionType = "ALL"
+	}
+
+	var hash, rangeKey string
+	var indexes []string // secondary index names in declaration order
+	indexHash := map[string]string{}
+	indexRange := map[string]string{}
+	local := map[string]bool{}
+
+	for _, f := range cachedTypeFields(t) {
+		sf := t.FieldByIndex(f.index)
+		tag, ok := sf.Tag.Lookup("dynamodb")
+		if !ok {
+			tag = sf.Tag.Get("json")
+		}
+		_, tagOpts := parseTag(tag)
+		name := f.writeName()
+		isKey := false
+
+		set := func(keys map[string]string, index, what string) error {
+			if other, ok := keys[index]; ok {
+				return fmt.Errorf("Both %s and %s are tagged as %s.", other, name, what)
+			}
+			if _, ok := indexHash[index]; !ok {
+				if _, ok := indexRange[index]; !ok {
+					indexes = append(indexes, index)
+				}
+			}
+			keys[index] = name
+			isKey = true
+			return nil
+		}
+		if tagOpts.Contains("hash") {
+			if hash != "" {
+				return TableDescriptionT{}, fmt.Errorf("Both %s and %s are tagged as hash key.", hash, name)
+			}
+			hash, isKey = name, true
+		}
+		if tagOpts.Contains("range") {
+			if rangeKey != "" {
+				return TableDescriptionT{}, fmt.Errorf("Both %s and %s are tagged as range key.", rangeKey, name)
+			}
+			rangeKey, isKey = name, true
+		}
+		for _, index := range tagOpts.Values("gsi") {
+			if err := set(indexHash, index, "hash key of "+index); err != nil {
+				return TableDescriptionT{}, err
+			}
+		}
+		for _, index := range tagOpts.Values("gsirange") {
+			if err := set(indexRange, index, "range key of "+index); err != nil {
+				return TableDescriptionT{}, err
+			}
+		}
+		for _, index := range tagOpts.Values("lsi") {
+			if err := set(indexRange, index, "range key of "+index); err != nil {
+				return TableDescriptionT{}, err
+			}
+			local[index] = true
+		}
+		if !isKey {
+			continue
+		}
+
+		typ, err := keyAttributeType(f)
+		if err != nil {
+			return TableDescriptionT{}, err
+		}
+		d.AttributeDefinitions = append(d.AttributeDefinitions, AttributeDefinitionT{name, typ})
+	}
+
+	if hash == "" {
+		return TableDescriptionT{}, fmt.Errorf("No field of %s is tagged as hash key.", t)
+	}
+	d.KeySchema = []KeySchemaT{{hash, "HASH"}}
+	if rangeKey != "" {
+		d.KeySchema = append(d.KeySchema, KeySchemaT{rangeKey, "RANGE"})
+	}
+
+	for _, name := range indexes {
+		if local[name] {
+			if _, ok := indexHash[name]; ok {
+				return TableDescriptionT{}, fmt.Errorf("Index %s is tagged as both local and global.", name)
+			}
+			if rangeKey == "" {
+				return TableDescriptionT{}, fmt.Errorf("Local secondary index %s requires a table with a range key.", name)
+			}
+			d.LocalSecondaryIndexes = append(d.LocalSecondaryIndexes, LocalSecondaryIndexT{
+				IndexName:  name,
+				KeySchema:  []KeySchemaT{{hash, "HASH"}, {indexRange[name], "RANGE"}},
+				Projection: projection,
+			})
+			continue
+		}
+
+		indexHashKey, ok := indexHash[name]
+		if !ok {
+			return TableDescriptionT{}, fmt.Errorf("No field is tagged as hash key of %s.", name)
+		}
+		index := GlobalSecondaryIndexT{
+			IndexName:  name,
+			KeySchema:  []KeySchemaT{{indexHashKey, "HASH"}},
+			Projection: projection,
+		}
+		if r, ok := indexRange[name]; ok {
+			index.KeySchema = append(index.KeySchema, KeySchemaT{r, "RANGE"})
+		}
+		if !onDemand {
+			index.ProvisionedThroughput = throughput
+		}
+		d.GlobalSecondaryIndexes = append(d.GlobalSecondaryIndexes, index)
+	}
+
+	return d, nil
+}
+
+// keyAttributeType returns the attribute type a key field is stored as.
+// Byte slices are stored base64 encoded in strings by MarshalAttributes.
+func keyAttributeType(f field) (string, error) {
+	switch f.typ.Kind() {
+	case reflect.String:
+		return TYPE_STRING, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return TYPE_NUMBER, nil
+	case reflect.Slice:
+		if f.typ.Elem().Kind() == reflect.Uint8 {
+			return TYPE_STRING, nil
+		}
+	}
+	return "", fmt.Errorf("Field %s of type %s cannot be a key attribute.", f.name, f.typ)
+}
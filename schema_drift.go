@@ -0,0 +1,106 @@
+package dynamodb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SchemaDifference is a difference between the key schema configured on a
+// Table and the one of the live table. Local and Live are of the form
+// "name (type)", or "local" and "global" for the kind of an index, and
+// are empty when the field does not exist on that side.
+type SchemaDifference struct {
+	Field string // "HashKey", "RangeKey" or "Index <name>[ HashKey|RangeKey|Kind]"
+	Local string
+	Live  string
+}
+
+func (d SchemaDifference) String() string {
+	return fmt.Sprintf("%s: local %q, live %q", d.Field, d.Local, d.Live)
+}
+
+// SchemaDiff lists the differences found by ValidateSchema, empty when
+// the schemas match.
+type SchemaDiff []SchemaDifference
+
+func (d SchemaDiff) String() string {
+	lines := make([]string, len(d))
+	for i, difference := range d {
+		lines[i] = difference.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidateSchema describes the table and compares its key schema and
+// indexes to t.Key and t.Indexes, catching a mistyped or missing key
+// before it fails requests at runtime. See DiffSchema.
+func (t *Table) ValidateSchema() (SchemaDiff, error) {
+	desc, err := t.DescribeTable(true)
+	if err != nil {
+		return nil, err
+	}
+	return t.DiffSchema(desc)
+}
+
+// DiffSchema compares t.Key and t.Indexes to desc. Indexes of desc which
+// are not in t.Indexes are not reported, as a Table needs not know of
+// every index of its table.
+func (t *Table) DiffSchema(desc *TableDescriptionT) (SchemaDiff, error) {
+	key, err := desc.BuildPrimaryKey()
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := desc.BuildIndexes()
+	if err != nil {
+		return nil, err
+	}
+
+	var diff SchemaDiff
+	diff = diffKey(diff, "", t.Key, key)
+	for _, local := range t.Indexes {
+		field := "Index " + local.IndexName
+		live := findIndex(indexes, local.IndexName)
+		if live == nil {
+			diff = append(diff, SchemaDifference{Field: field, Local: indexKind(local.Local)})
+			continue
+		}
+		if local.Local != live.Local {
+			diff = append(diff, SchemaDifference{Field: field + " Kind", Local: indexKind(local.Local), Live: indexKind(live.Local)})
+		}
+		diff = diffKey(diff, field+" ", local.Key, live.Key)
+	}
+	return diff, nil
+}
+
+func diffKey(diff SchemaDiff, prefix string, local, live PrimaryKey) SchemaDiff {
+	if l, r := keyAttributeString(local.KeyAttribute), keyAttributeString(live.KeyAttribute); l != r {
+		diff = append(diff, SchemaDifference{Field: prefix + "HashKey", Local: l, Live: r})
+	}
+	if l, r := keyAttributeString(local.RangeAttribute), keyAttributeString(live.RangeAttribute); l != r {
+		diff = append(diff, SchemaDifference{Field: prefix + "RangeKey", Local: l, Live: r})
+	}
+	return diff
+}
+
+func keyAttributeString(a *Attribute) string {
+	if a == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", a.Name, a.Type)
+}
+
+func findIndex(indexes []IndexT, name string) *IndexT {
+	for i := range indexes {
+		if indexes[i].IndexName == name {
+			return &indexes[i]
+		}
+	}
+	return nil
+}
+
+func indexKind(local bool) string {
+	if local {
+		return "local"
+	}
+	return "global"
+}
@@ -0,0 +1,85 @@
+package dynamodb_test
+
+import (
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type SchemaDriftSuite struct {
+	server *dynamodb.Server
+	desc   dynamodb.TableDescriptionT
+}
+
+var _ = check.Suite(&SchemaDriftSuite{})
+
+func (s *SchemaDriftSuite) SetUpTest(c *check.C) {
+	s.server, _ = dynamodbtest.NewServer()
+	s.desc = dynamodb.TableDescriptionT{
+		TableName: "events",
+		AttributeDefinitions: []dynamodb.AttributeDefinitionT{
+			{Name: "user", Type: "S"},
+			{Name: "seq", Type: "N"},
+			{Name: "kind", Type: "S"},
+		},
+		KeySchema: []dynamodb.KeySchemaT{
+			{AttributeName: "user", KeyType: "HASH"},
+			{AttributeName: "seq", KeyType: "RANGE"},
+		},
+		GlobalSecondaryIndexes: []dynamodb.GlobalSecondaryIndexT{{
+			IndexName:  "kind-index",
+			KeySchema:  []dynamodb.KeySchemaT{{AttributeName: "kind", KeyType: "HASH"}},
+			Projection: dynamodb.ProjectionT{ProjectionType: "ALL"},
+		}},
+	}
+	_, err := s.server.CreateTable(s.desc, false)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *SchemaDriftSuite) TestMatchingSchema(c *check.C) {
+	t, err := s.server.NewTableFromDescription(&s.desc)
+	c.Assert(err, check.IsNil)
+	diff, err := t.ValidateSchema()
+	c.Assert(err, check.IsNil)
+	c.Check(diff, check.HasLen, 0)
+
+	// Indexes the Table does not know of are not reported.
+	t.Indexes = nil
+	diff, err = t.ValidateSchema()
+	c.Assert(err, check.IsNil)
+	c.Check(diff, check.HasLen, 0)
+}
+
+func (s *SchemaDriftSuite) TestDrift(c *check.C) {
+	t := s.server.NewTable("events", dynamodb.PrimaryKey{
+		KeyAttribute:   dynamodb.NewStringAttribute("user", ""),
+		RangeAttribute: dynamodb.NewStringAttribute("seq", ""),
+	})
+	t.Indexes = []dynamodb.IndexT{
+		{IndexName: "kind-index", Local: true, Key: dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("kind", "")}},
+		{IndexName: "status-index", Key: dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("status", "")}},
+	}
+
+	diff, err := t.ValidateSchema()
+	c.Assert(err, check.IsNil)
+	c.Check(diff, check.DeepEquals, dynamodb.SchemaDiff{
+		{Field: "RangeKey", Local: "seq (S)", Live: "seq (N)"},
+		{Field: "Index kind-index Kind", Local: "local", Live: "global"},
+		{Field: "Index status-index", Local: "global"},
+	})
+	c.Check(diff[0].String(), check.Equals, `RangeKey: local "seq (S)", live "seq (N)"`)
+
+	t.Key.RangeAttribute = nil
+	t.Indexes = nil
+	diff, err = t.ValidateSchema()
+	c.Assert(err, check.IsNil)
+	c.Check(diff, check.DeepEquals, dynamodb.SchemaDiff{{Field: "RangeKey", Live: "seq (N)"}})
+}
+
+func (s *SchemaDriftSuite) TestMissingTable(c *check.C) {
+	t, err := s.server.NewTableFromDescription(&s.desc)
+	c.Assert(err, check.IsNil)
+	t.Name = "missing"
+	_, err = t.ValidateSchema()
+	c.Check(dynamodb.IsNotFound(err), check.Equals, true)
+}
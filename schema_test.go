@@ -0,0 +1,105 @@
+package dynamodb_test
+
+import (
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type SchemaSuite struct{}
+
+var _ = check.Suite(&SchemaSuite{})
+
+type schemaEvent struct {
+	User    string `dynamodb:"user,hash"`
+	Seq     int64  `dynamodb:"seq,range"`
+	Kind    string `dynamodb:"kind,gsi=by-kind"`
+	Score   int    `dynamodb:"score,gsirange=by-kind,lsi=by-score"`
+	Payload []byte `json:"payload"`
+}
+
+func (s *SchemaSuite) TestTableSchemaFromStruct(c *check.C) {
+	d, err := dynamodb.TableSchemaFromStruct(&schemaEvent{}, &dynamodb.SchemaOptions{
+		TableName:          "events",
+		ReadCapacityUnits:  5,
+		WriteCapacityUnits: 2,
+	})
+	c.Assert(err, check.IsNil)
+
+	all := dynamodb.ProjectionT{ProjectionType: "ALL"}
+	throughput := dynamodb.ProvisionedThroughputT{ReadCapacityUnits: 5, WriteCapacityUnits: 2}
+	c.Check(d, check.DeepEquals, dynamodb.TableDescriptionT{
+		TableName: "events",
+		AttributeDefinitions: []dynamodb.AttributeDefinitionT{
+			{Name: "user", Type: "S"},
+			{Name: "seq", Type: "N"},
+			{Name: "kind", Type: "S"},
+			{Name: "score", Type: "N"},
+		},
+		KeySchema: []dynamodb.KeySchemaT{
+			{AttributeName: "user", KeyType: "HASH"},
+			{AttributeName: "seq", KeyType: "RANGE"},
+		},
+		GlobalSecondaryIndexes: []dynamodb.GlobalSecondaryIndexT{{
+			IndexName: "by-kind",
+			KeySchema: []dynamodb.KeySchemaT{
+				{AttributeName: "kind", KeyType: "HASH"},
+				{AttributeName: "score", KeyType: "RANGE"},
+			},
+			Projection:            all,
+			ProvisionedThroughput: throughput,
+		}},
+		LocalSecondaryIndexes: []dynamodb.LocalSecondaryIndexT{{
+			IndexName: "by-score",
+			KeySchema: []dynamodb.KeySchemaT{
+				{AttributeName: "user", KeyType: "HASH"},
+				{AttributeName: "score", KeyType: "RANGE"},
+			},
+			Projection: all,
+		}},
+		ProvisionedThroughput: throughput,
+	})
+
+	server, _ := dynamodbtest.NewServer()
+	_, err = server.CreateTable(d, false)
+	c.Assert(err, check.IsNil)
+	created, err := server.DescribeTable("events", false)
+	c.Assert(err, check.IsNil)
+	c.Check(created.GlobalSecondaryIndex("by-kind"), check.NotNil)
+}
+
+func (s *SchemaSuite) TestTableSchemaFromStructDefaults(c *check.C) {
+	type Session struct {
+		ID []byte `dynamodb:"id,hash"`
+	}
+	d, err := dynamodb.TableSchemaFromStruct(Session{}, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(d.TableName, check.Equals, "Session")
+	c.Check(d.BillingMode(), check.Equals, dynamodb.BILLING_MODE_PAY_PER_REQUEST)
+	c.Check(d.AttributeDefinitions, check.DeepEquals, []dynamodb.AttributeDefinitionT{{Name: "id", Type: "S"}})
+}
+
+func (s *SchemaSuite) TestTableSchemaFromStructErrors(c *check.C) {
+	type NoHash struct {
+		ID string `dynamodb:"id"`
+	}
+	_, err := dynamodb.TableSchemaFromStruct(NoHash{}, nil)
+	c.Check(err, check.ErrorMatches, "No field of .*NoHash is tagged as hash key.")
+
+	type BadKey struct {
+		ID   string            `dynamodb:"id,hash"`
+		Tags map[string]string `dynamodb:"tags,gsi=by-tags"`
+	}
+	_, err = dynamodb.TableSchemaFromStruct(BadKey{}, nil)
+	c.Check(err, check.ErrorMatches, "Field tags of type map.* cannot be a key attribute.")
+
+	type NoRange struct {
+		ID string `dynamodb:"id,hash"`
+		At int64  `dynamodb:"at,lsi=by-at"`
+	}
+	_, err = dynamodb.TableSchemaFromStruct(NoRange{}, nil)
+	c.Check(err, check.ErrorMatches, "Local secondary index by-at requires a table with a range key.")
+
+	_, err = dynamodb.TableSchemaFromStruct("events", nil)
+	c.Check(err, check.ErrorMatches, "Cannot infer a table schema from string.")
+}
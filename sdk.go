@@ -0,0 +1,109 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/goamz/goamz/aws"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// NewSDKCredentialsProvider adapts a credentials provider of aws-sdk-go-v2
+// (aws.CredentialsProvider, e.g. the Credentials of the aws.Config loaded
+// by config.LoadDefaultConfig) to a CredentialsProvider, without this
+// package depending on the SDK. Any value with a method
+//
+//	Retrieve(context.Context) (T, error)
+//
+// where T is a struct with the fields of Credentials is accepted. The
+// SDK's providers cache credentials on their own.
+func NewSDKCredentialsProvider(provider interface{}) (CredentialsProvider, error) {
+	if provider == nil {
+		return nil, errors.New("Credentials provider cannot be nil.")
+	}
+	v := reflect.ValueOf(provider)
+	retrieve := v.MethodByName("Retrieve")
+	if !retrieve.IsValid() {
+		return nil, fmt.Errorf("%s has no Retrieve method.", v.Type())
+	}
+	t := retrieve.Type()
+	if t.NumIn() != 1 || t.In(0) != contextType || t.NumOut() != 2 || t.Out(1) != errorType || t.Out(0).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s.Retrieve does not return credentials.", v.Type())
+	}
+	fields := []struct {
+		name string
+		t    reflect.Type
+	}{
+		{"AccessKeyID", reflect.TypeOf("")},
+		{"SecretAccessKey", reflect.TypeOf("")},
+		{"SessionToken", reflect.TypeOf("")},
+		{"CanExpire", reflect.TypeOf(false)},
+		{"Expires", timeType},
+	}
+	for _, f := range fields {
+		if field, ok := t.Out(0).FieldByName(f.name); !ok || field.Type != f.t {
+			return nil, fmt.Errorf("%s has no %s field of type %s.", t.Out(0), f.name, f.t)
+		}
+	}
+
+	return CredentialsFunc(func(ctx context.Context) (Credentials, error) {
+		out := retrieve.Call([]reflect.Value{reflect.ValueOf(ctx)})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return Credentials{}, err
+		}
+		c := out[0]
+		return Credentials{
+			AccessKeyID:     c.FieldByName("AccessKeyID").String(),
+			SecretAccessKey: c.FieldByName("SecretAccessKey").String(),
+			SessionToken:    c.FieldByName("SessionToken").String(),
+			CanExpire:       c.FieldByName("CanExpire").Bool(),
+			Expires:         c.FieldByName("Expires").Interface().(time.Time),
+		}, nil
+	}), nil
+}
+
+// NewFromSDKConfig returns a Server using the region, credentials and, when
+// set, base endpoint of an aws-sdk-go-v2 aws.Config, or a pointer to one:
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	...
+//	server, err := dynamodb.NewFromSDKConfig(cfg)
+func NewFromSDKConfig(cfg interface{}) (*Server, error) {
+	v := reflect.Indirect(reflect.ValueOf(cfg))
+	if v.Kind() != reflect.Struct {
+		return nil, errors.New("SDK config must be a struct.")
+	}
+	regionName := v.FieldByName("Region")
+	if !regionName.IsValid() || regionName.Kind() != reflect.String {
+		return nil, fmt.Errorf("%s has no Region field.", v.Type())
+	}
+	var endpoint string
+	if base := v.FieldByName("BaseEndpoint"); base.IsValid() && base.Kind() == reflect.Ptr && !base.IsNil() && base.Elem().Kind() == reflect.String {
+		endpoint = base.Elem().String()
+	}
+	region, err := NewRegion(regionName.String(), endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := v.FieldByName("Credentials")
+	if !credentials.IsValid() || credentials.Kind() != reflect.Interface || credentials.IsNil() {
+		return nil, fmt.Errorf("%s has no Credentials.", v.Type())
+	}
+	provider, err := NewSDKCredentialsProvider(credentials.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	s := New(aws.Auth{}, region)
+	s.Credentials = provider
+	return s, nil
+}
@@ -0,0 +1,38 @@
+package dynamodb
+
+import (
+	"context"
+	"sync"
+)
+
+// runSegments calls fn for each of the segments of a parallel scan in its
+// own goroutine. The first error cancels the context given to the other
+// calls and is returned once all of them have returned.
+func runSegments(ctx context.Context, segments int, fn func(ctx context.Context, segment int) error) error {
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for segment := 0; segment < segments; segment++ {
+		wg.Add(1)
+		go func(segment int) {
+			defer wg.Done()
+			if err := fn(ctx, segment); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(segment)
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
@@ -0,0 +1,124 @@
+package dynamodb
+
+import (
+	"encoding/base64"
+	"sort"
+)
+
+// Set is implemented by StringSet, NumberSet and BinarySet, the values
+// AddToSet and RemoveFromSet take.
+type Set interface {
+	// Attribute returns the set as an attribute, members sorted.
+	Attribute(name string) *Attribute
+	Len() int
+}
+
+// StringSet is a Dynamodb string set. Struct fields of this type are
+// marshaled as SS attributes, and omitted when empty since Dynamodb
+// rejects empty sets.
+type StringSet map[string]struct{}
+
+func NewStringSet(values ...string) StringSet {
+	s := make(StringSet, len(values))
+	for _, v := range values {
+		s[v] = struct{}{}
+	}
+	return s
+}
+
+func (s StringSet) Has(v string) bool {
+	_, ok := s[v]
+	return ok
+}
+
+func (s StringSet) Len() int {
+	return len(s)
+}
+
+func (s StringSet) Attribute(name string) *Attribute {
+	return NewStringSetAttribute(name, sortedKeys(s))
+}
+
+// NumberSet is a Dynamodb number set, whose members are decimal strings
+// as Dynamodb returns them, e.g. "1.5". Struct fields of this type are
+// marshaled as NS attributes, and omitted when empty.
+type NumberSet map[string]struct{}
+
+func NewNumberSet(values ...string) NumberSet {
+	s := make(NumberSet, len(values))
+	for _, v := range values {
+		s[v] = struct{}{}
+	}
+	return s
+}
+
+func (s NumberSet) Has(v string) bool {
+	_, ok := s[v]
+	return ok
+}
+
+func (s NumberSet) Len() int {
+	return len(s)
+}
+
+func (s NumberSet) Attribute(name string) *Attribute {
+	return NewNumericSetAttribute(name, sortedKeys(s))
+}
+
+// BinarySet is a Dynamodb binary set, keyed by the raw bytes of its
+// members. Struct fields of this type are marshaled as BS attributes, and
+// omitted when empty.
+type BinarySet map[string]struct{}
+
+func NewBinarySet(values ...[]byte) BinarySet {
+	s := make(BinarySet, len(values))
+	for _, v := range values {
+		s[string(v)] = struct{}{}
+	}
+	return s
+}
+
+func (s BinarySet) Has(v []byte) bool {
+	_, ok := s[string(v)]
+	return ok
+}
+
+func (s BinarySet) Len() int {
+	return len(s)
+}
+
+func (s BinarySet) Attribute(name string) *Attribute {
+	values := sortedKeys(s)
+	for i, v := range values {
+		values[i] = base64.StdEncoding.EncodeToString([]byte(v))
+	}
+	return NewBinarySetAttribute(name, values)
+}
+
+func sortedKeys(s map[string]struct{}) []string {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AddToSet adds the members of values to the set at path in the item,
+// creating either when missing. Members already present are left alone,
+// so a retried call has the same effect.
+func (t *Table) AddToSet(key *Key, path string, values Set, isRetry bool) error {
+	if values.Len() == 0 {
+		return validationErrorf("Cannot add an empty set to %s.", path)
+	}
+	return t.updateExpression(key, NewUpdateExpression().Add(path, values), isRetry)
+}
+
+// RemoveFromSet removes the members of values from the set at path in the
+// item. Dynamodb removes the attribute once its set is empty.
+func (t *Table) RemoveFromSet(key *Key, path string, values Set, isRetry bool) error {
+	if values.Len() == 0 {
+		return validationErrorf("Cannot remove an empty set from %s.", path)
+	}
+	return t.updateExpression(key, NewUpdateExpression().Delete(path, values), isRetry)
+}
@@ -0,0 +1,90 @@
+package dynamodb_test
+
+import (
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type SetSuite struct {
+	table *dynamodb.Table
+	key   *dynamodb.Key
+}
+
+var _ = check.Suite(&SetSuite{})
+
+func (s *SetSuite) SetUpTest(c *check.C) {
+	s.table = newFakeTable(c, "users", idKey{})
+	s.key = &dynamodb.Key{HashKey: "u1"}
+}
+
+type setRecord struct {
+	ID     string `dynamodb:"id"`
+	Tags   dynamodb.StringSet
+	Scores dynamodb.NumberSet
+	Keys   dynamodb.BinarySet
+}
+
+func (s *SetSuite) TestMarshal(c *check.C) {
+	attrs, err := dynamodb.MarshalAttributes(&setRecord{
+		ID:     "u1",
+		Tags:   dynamodb.NewStringSet("b", "a", "b"),
+		Scores: dynamodb.NewNumberSet("2", "10"),
+	})
+	c.Assert(err, check.IsNil)
+	// Empty sets are omitted, members sorted.
+	c.Check(attrs, check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", "u1"),
+		*dynamodb.NewStringSetAttribute("Tags", []string{"a", "b"}),
+		*dynamodb.NewNumericSetAttribute("Scores", []string{"10", "2"}),
+	})
+	c.Check(dynamodb.NewBinarySet([]byte{1}).Attribute("k"), check.DeepEquals, dynamodb.NewBinarySetAttribute("k", []string{"AQ=="}))
+}
+
+func (s *SetSuite) TestUnmarshal(c *check.C) {
+	item := map[string]*dynamodb.Attribute{
+		"Tags":   dynamodb.NewStringSetAttribute("Tags", []string{"a", "b"}),
+		"Scores": dynamodb.NewNumericSetAttribute("Scores", []string{"1"}),
+		"Keys":   dynamodb.NewBinarySetAttribute("Keys", []string{"AQ==", "AgM="}),
+	}
+	var r setRecord
+	c.Assert(dynamodb.UnmarshalAttributes(&item, &r), check.IsNil)
+	c.Check(r.Tags, check.DeepEquals, dynamodb.NewStringSet("a", "b"))
+	c.Check(r.Scores.Has("1"), check.Equals, true)
+	c.Check(r.Keys, check.DeepEquals, dynamodb.NewBinarySet([]byte{1}, []byte{2, 3}))
+
+	// Plain Go sets and slices work too.
+	var plain struct {
+		Tags map[string]struct{}
+		Keys [][]byte
+	}
+	c.Assert(dynamodb.UnmarshalAttributes(&item, &plain), check.IsNil)
+	c.Check(plain.Tags, check.DeepEquals, map[string]struct{}{"a": {}, "b": {}})
+	c.Check(plain.Keys, check.DeepEquals, [][]byte{{1}, {2, 3}})
+}
+
+func (s *SetSuite) TestAddAndRemove(c *check.C) {
+	tags := func() dynamodb.StringSet {
+		item, err := s.table.GetItem(s.key, false)
+		c.Assert(err, check.IsNil)
+		var r setRecord
+		c.Assert(dynamodb.UnmarshalAttributes(&item, &r), check.IsNil)
+		return r.Tags
+	}
+
+	c.Assert(s.table.AddToSet(s.key, "Tags", dynamodb.NewStringSet("a", "b"), false), check.IsNil)
+	c.Assert(s.table.AddToSet(s.key, "Tags", dynamodb.NewStringSet("b", "c"), true), check.IsNil)
+	c.Check(tags(), check.DeepEquals, dynamodb.NewStringSet("a", "b", "c"))
+
+	c.Assert(s.table.RemoveFromSet(s.key, "Tags", dynamodb.NewStringSet("a", "z"), false), check.IsNil)
+	c.Check(tags(), check.DeepEquals, dynamodb.NewStringSet("b", "c"))
+
+	// The attribute goes away with its last member.
+	c.Assert(s.table.RemoveFromSet(s.key, "Tags", dynamodb.NewStringSet("b", "c"), false), check.IsNil)
+	c.Check(tags(), check.IsNil)
+
+	err := s.table.AddToSet(s.key, "Tags", dynamodb.NewStringSet(), false)
+	c.Check(dynamodb.IsValidationError(err), check.Equals, true)
+
+	u := dynamodb.NewUpdateExpression().Add("n", 1).Delete("Tags", dynamodb.NewStringSet("x"))
+	c.Check(u.String(), check.Equals, "ADD #n0 :v0 DELETE #n1 :v1")
+}
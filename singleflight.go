@@ -0,0 +1,71 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Singleflight collapses identical GetItem calls in flight at the same time
+// into a single request, whose response all of them share, so that bursts
+// of reads of a hot key cost the capacity of one. Calls are identical when
+// they read the same key of the same table with the same options. Install
+// it with Server.Use(NewSingleflight().Middleware()); middleware installed
+// after it only sees the calls actually sent.
+//
+// A call waiting for another one returns when its own context is done. If
+// the call it waits for is cancelled instead, it sends its own request.
+type Singleflight struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+type flight struct {
+	done     chan struct{}
+	response []byte
+	err      error
+}
+
+func NewSingleflight() *Singleflight {
+	return &Singleflight{flights: make(map[string]*flight)}
+}
+
+// Middleware returns the middleware collapsing the calls.
+func (s *Singleflight) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request) ([]byte, error) {
+			if req.Operation != "GetItem" {
+				return next(req)
+			}
+			id := req.Endpoint + "\x00" + string(req.Body)
+
+			s.mu.Lock()
+			if f, ok := s.flights[id]; ok {
+				s.mu.Unlock()
+				select {
+				case <-f.done:
+				case <-req.Context.Done():
+					return nil, req.Context.Err()
+				}
+				if isContextError(f.err) {
+					return next(req)
+				}
+				return f.response, f.err
+			}
+			f := &flight{done: make(chan struct{})}
+			s.flights[id] = f
+			s.mu.Unlock()
+
+			f.response, f.err = next(req)
+			s.mu.Lock()
+			delete(s.flights, id)
+			s.mu.Unlock()
+			close(f.done)
+			return f.response, f.err
+		}
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
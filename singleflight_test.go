@@ -0,0 +1,92 @@
+package dynamodb_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type SingleflightSuite struct {
+	table *dynamodb.Table
+
+	mu   sync.Mutex
+	sent int
+}
+
+var _ = check.Suite(&SingleflightSuite{})
+
+func (s *SingleflightSuite) SetUpTest(c *check.C) {
+	s.sent = 0
+	server, _ := dynamodbtest.NewServer(dynamodb.NewSingleflight().Middleware(), func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation == "GetItem" {
+				s.mu.Lock()
+				s.sent++
+				s.mu.Unlock()
+				// Long enough for the concurrent calls to pile up.
+				time.Sleep(50 * time.Millisecond)
+			}
+			return next(req)
+		}
+	})
+
+	s.table = createTable(c, server, tableSchema(c, "users", idKey{}))
+
+	_, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("hot", ""), *dynamodb.NewStringAttribute("name", "Hot")), nil)
+	c.Assert(err, check.IsNil)
+}
+
+func (s *SingleflightSuite) get(c *check.C, keys ...string) []map[string]*dynamodb.Attribute {
+	items := make([]map[string]*dynamodb.Attribute, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			item, err := s.table.GetItem(&dynamodb.Key{HashKey: key}, false)
+			if err != dynamodb.ErrNotFound {
+				c.Check(err, check.IsNil)
+			}
+			items[i] = item
+		}(i, key)
+	}
+	wg.Wait()
+	return items
+}
+
+func (s *SingleflightSuite) TestCollapsesSameKey(c *check.C) {
+	items := s.get(c, "hot", "hot", "hot", "hot", "hot")
+	c.Check(s.sent, check.Equals, 1)
+	for _, item := range items {
+		c.Check(item["name"].Value, check.Equals, "Hot")
+	}
+	// Callers do not share the decoded items.
+	items[0]["name"].Value = "changed"
+	c.Check(items[1]["name"].Value, check.Equals, "Hot")
+
+	// Once done, the next call is sent.
+	s.get(c, "hot")
+	c.Check(s.sent, check.Equals, 2)
+}
+
+func (s *SingleflightSuite) TestDistinctCalls(c *check.C) {
+	s.get(c, "hot", "cold")
+	c.Check(s.sent, check.Equals, 2)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.table.GetItemConsistent(&dynamodb.Key{HashKey: "hot"}, true, false)
+	}()
+	go func() {
+		defer wg.Done()
+		s.table.GetItem(&dynamodb.Key{HashKey: "hot"}, false)
+	}()
+	wg.Wait()
+	c.Check(s.sent, check.Equals, 4)
+}
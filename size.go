@@ -0,0 +1,87 @@
+package dynamodb
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// MaxItemSize is the largest item Dynamodb accepts, in bytes.
+const MaxItemSize = 400 * 1024
+
+// EstimateItemSize returns the size of an item as Dynamodb computes it for
+// the 400KB item limit and capacity units: the UTF-8 length of each
+// attribute name plus the size of its value. Strings count their UTF-8
+// bytes, binary values their decoded bytes, numbers one byte per two
+// significant digits plus one, and sets the sum of their elements. Writes
+// consume one capacity unit per started 1KB and strongly consistent reads
+// one per started 4KB.
+func EstimateItemSize(attributes []Attribute) int {
+	size := 0
+	for i := range attributes {
+		size += attributeSize(&attributes[i])
+	}
+	return size
+}
+
+func attributeSize(a *Attribute) int {
+	size := len(a.Name)
+	switch a.Type {
+	case TYPE_STRING:
+		size += len(a.Value)
+	case TYPE_NUMBER:
+		size += numberSize(a.Value)
+	case TYPE_BINARY:
+		size += binarySize(a.Value)
+	case TYPE_STRING_SET:
+		for _, v := range a.SetValues {
+			size += len(v)
+		}
+	case TYPE_NUMBER_SET:
+		for _, v := range a.SetValues {
+			size += numberSize(v)
+		}
+	case TYPE_BINARY_SET:
+		for _, v := range a.SetValues {
+			size += binarySize(v)
+		}
+	case TYPE_LIST:
+		// 3 bytes for the list and 1 per element.
+		size += 3
+		for i := range a.ListValues {
+			size += 1 + attributeSize(&a.ListValues[i])
+		}
+	case TYPE_MAP:
+		// 3 bytes for the map and 1 per member, plus the member names.
+		size += 3
+		for key, member := range a.MapValues {
+			m := *member
+			m.Name = key
+			size += 1 + attributeSize(&m)
+		}
+	case TYPE_NULL:
+		size++
+	default:
+		size += len(a.Value)
+	}
+	return size
+}
+
+// numberSize is the stored size of a decimal number: leading and trailing
+// zeros, the sign, the decimal point and the exponent are not stored.
+func numberSize(n string) int {
+	if i := strings.IndexAny(n, "eE"); i >= 0 {
+		n = n[:i]
+	}
+	n = strings.TrimLeft(n, "+-")
+	n = strings.Replace(n, ".", "", 1)
+	n = strings.Trim(n, "0")
+	return (len(n)+1)/2 + 1
+}
+
+func binarySize(b64 string) int {
+	b, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return len(b64)
+	}
+	return len(b)
+}
@@ -0,0 +1,80 @@
+package dynamodb
+
+import "context"
+
+const (
+	SSE_TYPE_AES256 = "AES256"
+	SSE_TYPE_KMS    = "KMS"
+
+	SSE_STATUS_ENABLING  = "ENABLING"
+	SSE_STATUS_ENABLED   = "ENABLED"
+	SSE_STATUS_DISABLING = "DISABLING"
+	SSE_STATUS_DISABLED  = "DISABLED"
+	SSE_STATUS_UPDATING  = "UPDATING"
+)
+
+// SSESpecificationT selects the key a table is encrypted at rest with.
+// The zero value is the AWS owned key, free and not visible in the
+// account. Enabled with SSE_TYPE_KMS uses the AWS managed key
+// aws/dynamodb, or the customer managed key KMSMasterKeyId when set.
+type SSESpecificationT struct {
+	Enabled bool
+	SSEType string `json:",omitempty"`
+	// Id, ARN, alias name or alias ARN of the KMS key.
+	KMSMasterKeyId string `json:",omitempty"`
+}
+
+// SSEDescriptionT is the encryption at rest status reported by
+// DescribeTable. It is empty for tables using the AWS owned key.
+type SSEDescriptionT struct {
+	Status          string // one of the SSE_STATUS_* constants
+	SSEType         string
+	KMSMasterKeyArn string
+	// Set when the table's KMS key has been inaccessible, the table being
+	// archived seven days later.
+	InaccessibleEncryptionDateTime float64
+}
+
+// AWSOwnedKeySSE, AWSManagedKeySSE and CustomerManagedKeySSE return the
+// specifications of the three kinds of encryption at rest.
+func AWSOwnedKeySSE() SSESpecificationT {
+	return SSESpecificationT{}
+}
+
+func AWSManagedKeySSE() SSESpecificationT {
+	return SSESpecificationT{Enabled: true, SSEType: SSE_TYPE_KMS}
+}
+
+func CustomerManagedKeySSE(kmsMasterKeyId string) SSESpecificationT {
+	return SSESpecificationT{Enabled: true, SSEType: SSE_TYPE_KMS, KMSMasterKeyId: kmsMasterKeyId}
+}
+
+// KMSEncrypted reports whether the table is encrypted with a KMS key,
+// either AWS or customer managed, rather than the AWS owned key.
+func (t *TableDescriptionT) KMSEncrypted() bool {
+	switch t.SSEDescription.Status {
+	case SSE_STATUS_ENABLED, SSE_STATUS_ENABLING, SSE_STATUS_UPDATING:
+		return true
+	}
+	return false
+}
+
+// UpdateSSE changes the key the table is encrypted at rest with. Like
+// UpdateThroughput, it waits for the table to be ACTIVE first. It is a
+// no-op when the table already uses the AWS owned key and that is what is
+// requested, or the customer managed key requested by ARN.
+func (t *Table) UpdateSSE(spec SSESpecificationT) (*TableDescriptionT, error) {
+	return t.updateWhenActive(context.Background(), func(desc *TableDescriptionT) *Query {
+		if !spec.Enabled && !desc.KMSEncrypted() {
+			return nil
+		}
+		if spec.Enabled && desc.SSEDescription.Status == SSE_STATUS_ENABLED &&
+			spec.KMSMasterKeyId != "" && spec.KMSMasterKeyId == desc.SSEDescription.KMSMasterKeyArn {
+			return nil
+		}
+
+		q := NewQuery(t)
+		q.AddSSESpecification(spec)
+		return q
+	})
+}
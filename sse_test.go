@@ -0,0 +1,72 @@
+package dynamodb_test
+
+import (
+	simplejson "github.com/bitly/go-simplejson"
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type SSESuite struct {
+	server *dynamodb.Server
+}
+
+var _ = check.Suite(&SSESuite{})
+
+func (s *SSESuite) SetUpTest(c *check.C) {
+	s.server, _ = dynamodbtest.NewServer()
+}
+
+func (s *SSESuite) create(c *check.C, name string, spec dynamodb.SSESpecificationT) *dynamodb.Table {
+	d := tableSchema(c, name, idKey{})
+	d.SSESpecification = spec
+	return createTable(c, s.server, d)
+}
+
+func (s *SSESuite) TestCreateTable(c *check.C) {
+	const key = "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	for name, spec := range map[string]dynamodb.SSESpecificationT{
+		"owned":    dynamodb.AWSOwnedKeySSE(),
+		"managed":  dynamodb.AWSManagedKeySSE(),
+		"customer": dynamodb.CustomerManagedKeySSE(key),
+	} {
+		desc, err := s.create(c, name, spec).DescribeTable(false)
+		c.Assert(err, check.IsNil)
+		c.Check(desc.KMSEncrypted(), check.Equals, spec.Enabled, check.Commentf(name))
+		if spec.Enabled {
+			c.Check(desc.SSEDescription.SSEType, check.Equals, dynamodb.SSE_TYPE_KMS)
+		}
+	}
+
+	desc, err := s.server.DescribeTable("customer", false)
+	c.Assert(err, check.IsNil)
+	c.Check(desc.SSEDescription.KMSMasterKeyArn, check.Equals, key)
+}
+
+func (s *SSESuite) TestUpdateSSE(c *check.C) {
+	const key = "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	table := s.create(c, "events", dynamodb.AWSOwnedKeySSE())
+
+	desc, err := table.UpdateSSE(dynamodb.CustomerManagedKeySSE(key))
+	c.Assert(err, check.IsNil)
+	c.Check(desc.SSEDescription.Status, check.Equals, dynamodb.SSE_STATUS_ENABLED)
+	c.Check(desc.SSEDescription.KMSMasterKeyArn, check.Equals, key)
+
+	desc, err = table.UpdateSSE(dynamodb.AWSOwnedKeySSE())
+	c.Assert(err, check.IsNil)
+	c.Check(desc.KMSEncrypted(), check.Equals, false)
+}
+
+func (s *SSESuite) TestAddSSESpecification(c *check.C) {
+	q := dynamodb.NewEmptyQuery()
+	q.AddSSESpecification(dynamodb.AWSManagedKeySSE())
+	queryJson, err := simplejson.NewJson([]byte(q.String()))
+	c.Assert(err, check.IsNil)
+	expectedJson, err := simplejson.NewJson([]byte(`{"SSESpecification": {"Enabled": true, "SSEType": "KMS"}}`))
+	c.Assert(err, check.IsNil)
+	c.Check(queryJson, check.DeepEquals, expectedJson)
+
+	q = dynamodb.NewEmptyQuery()
+	q.AddSSESpecification(dynamodb.AWSOwnedKeySSE())
+	c.Check(q.String(), check.Equals, `{"SSESpecification":{"Enabled":false}}`)
+}
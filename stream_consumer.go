@@ -0,0 +1,316 @@
+package dynamodb
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Checkpointer persists, per shard, the sequence number of the last
+// record the consumer's handler processed successfully.
+type Checkpointer interface {
+	// GetCheckpoint returns "" when the shard has never been checkpointed.
+	GetCheckpoint(shardId string) (string, error)
+	SetCheckpoint(shardId string, sequenceNumber string) error
+}
+
+// MemoryCheckpointer keeps checkpoints in process memory. It is useful for
+// tests and consumers that can replay the stream after a restart.
+type MemoryCheckpointer struct {
+	mu          sync.Mutex
+	checkpoints map[string]string
+}
+
+func NewMemoryCheckpointer() *MemoryCheckpointer {
+	return &MemoryCheckpointer{checkpoints: make(map[string]string)}
+}
+
+func (m *MemoryCheckpointer) GetCheckpoint(shardId string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkpoints[shardId], nil
+}
+
+func (m *MemoryCheckpointer) SetCheckpoint(shardId string, sequenceNumber string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoints[shardId] = sequenceNumber
+	return nil
+}
+
+// StreamRecordHandler is invoked with each non empty batch of records read
+// from a shard. A shard is checkpointed only after its handler returns nil;
+// an error stops the consumer.
+type StreamRecordHandler func(shardId string, records []StreamRecordT) error
+
+// StreamConsumer reads every shard of a Dynamodb stream, processing child
+// shards only once their parent has been fully consumed so that records
+// for a given key are delivered in order.
+type StreamConsumer struct {
+	Server       *Server
+	StreamArn    string
+	Checkpointer Checkpointer
+
+	// Where to start the shards which have no checkpoint, among those open
+	// when Run starts, TRIM_HORIZON when empty. The shards which appear
+	// later, such as the children of a split, are always read from
+	// TRIM_HORIZON so that none of their records are skipped.
+	IteratorType string
+	// Maximum records per GetRecords call, 0 for the server default.
+	Limit int64
+	// Polling delay bounds; the delay doubles while shards are idle or
+	// throttled and resets when records arrive.
+	MinPollInterval time.Duration
+	MaxPollInterval time.Duration
+	// How often the shard list is refreshed to discover new shards, 0 for
+	// DefaultShardRefreshInterval.
+	ShardRefreshInterval time.Duration
+}
+
+// DefaultShardRefreshInterval is how often a StreamConsumer refreshes its
+// shard list by default.
+const DefaultShardRefreshInterval = 30 * time.Second
+
+func NewStreamConsumer(server *Server, streamArn string, checkpointer Checkpointer) *StreamConsumer {
+	if checkpointer == nil {
+		checkpointer = NewMemoryCheckpointer()
+	}
+	return &StreamConsumer{
+		Server:               server,
+		StreamArn:            streamArn,
+		Checkpointer:         checkpointer,
+		IteratorType:         SHARD_ITERATOR_TRIM_HORIZON,
+		MinPollInterval:      250 * time.Millisecond,
+		MaxPollInterval:      10 * time.Second,
+		ShardRefreshInterval: DefaultShardRefreshInterval,
+	}
+}
+
+// Run consumes the stream until ctx is cancelled or the handler (or the
+// service) returns an error. Cancellation is not reported as an error.
+func (c *StreamConsumer) Run(ctx context.Context, handler StreamRecordHandler) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		running  = make(map[string]bool)
+		finished = make(map[string]bool)
+		changed  = make(chan struct{}, 1)
+		// The iterator type of the shards with no checkpoint, set for
+		// those listed first.
+		startAt map[string]string
+	)
+
+	refresh := c.ShardRefreshInterval
+	if refresh <= 0 {
+		refresh = DefaultShardRefreshInterval
+	}
+
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	for {
+		// DescribeStream only reads, so it is safely retried.
+		shards, err := c.Server.ListShardsContext(ctx, c.StreamArn, true)
+		if err != nil {
+			if ctx.Err() == nil {
+				return err
+			}
+			// Cancelled, possibly by a failed shard.
+			wg.Wait()
+			mu.Lock()
+			defer mu.Unlock()
+			return firstErr
+		}
+		if startAt == nil {
+			startAt = c.startingIterators(shards)
+		}
+
+		mu.Lock()
+		if firstErr != nil {
+			mu.Unlock()
+			return firstErr
+		}
+		for _, shard := range readyShards(shards, running, finished) {
+			running[shard.ShardId] = true
+			wg.Add(1)
+			iteratorType, ok := startAt[shard.ShardId]
+			if !ok {
+				iteratorType = SHARD_ITERATOR_TRIM_HORIZON
+			}
+			go func(shardId string) {
+				defer wg.Done()
+				err := c.consumeShard(ctx, shardId, iteratorType, handler)
+
+				mu.Lock()
+				delete(running, shardId)
+				if err == nil {
+					finished[shardId] = true
+				} else if ctx.Err() == nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+				notify()
+			}(shard.ShardId)
+		}
+		mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			mu.Lock()
+			defer mu.Unlock()
+			return firstErr
+		case <-changed:
+		case <-time.After(refresh):
+		}
+	}
+}
+
+// startingIterators returns the iterator type of the shards listed when
+// Run starts: IteratorType for those whose parent is gone, TRIM_HORIZON
+// for the children of listed shards, which are read once their parent is.
+func (c *StreamConsumer) startingIterators(shards []ShardT) map[string]string {
+	iteratorType := c.IteratorType
+	if iteratorType == "" {
+		iteratorType = SHARD_ITERATOR_TRIM_HORIZON
+	}
+	known := make(map[string]bool, len(shards))
+	for _, s := range shards {
+		known[s.ShardId] = true
+	}
+	startAt := make(map[string]string, len(shards))
+	for _, s := range shards {
+		if s.ParentShardId != "" && known[s.ParentShardId] {
+			startAt[s.ShardId] = SHARD_ITERATOR_TRIM_HORIZON
+		} else {
+			startAt[s.ShardId] = iteratorType
+		}
+	}
+	return startAt
+}
+
+// readyShards returns the shards which are neither running nor finished and
+// whose parent, if still part of the stream, has been finished.
+func readyShards(shards []ShardT, running, finished map[string]bool) []ShardT {
+	known := make(map[string]bool, len(shards))
+	for _, s := range shards {
+		known[s.ShardId] = true
+	}
+
+	var ready []ShardT
+	for _, s := range shards {
+		if running[s.ShardId] || finished[s.ShardId] {
+			continue
+		}
+		if s.ParentShardId != "" && known[s.ParentShardId] && !finished[s.ParentShardId] {
+			continue
+		}
+		ready = append(ready, s)
+	}
+	return ready
+}
+
+// consumeShard returns nil once a closed shard has been read to its end.
+func (c *StreamConsumer) consumeShard(ctx context.Context, shardId, iteratorType string, handler StreamRecordHandler) error {
+	iterator, err := c.shardIterator(shardId, iteratorType)
+	if err != nil {
+		return err
+	}
+
+	delay := c.MinPollInterval
+	for iterator != "" {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		records, next, err := c.Server.GetRecords(iterator, c.Limit, false)
+		switch {
+		case err == nil:
+		case ErrorCode(err) == ExpiredIteratorException:
+			if iterator, err = c.shardIterator(shardId, iteratorType); err != nil {
+				return err
+			}
+			continue
+		case IsRetryable(err):
+			delay = nextPollDelay(delay, c.MaxPollInterval)
+			if !sleepContext(ctx, delay) {
+				return ctx.Err()
+			}
+			continue
+		default:
+			return err
+		}
+
+		if len(records) > 0 {
+			if err := handler(shardId, records); err != nil {
+				return err
+			}
+			if err := c.Checkpointer.SetCheckpoint(shardId, records[len(records)-1].SequenceNumber); err != nil {
+				return err
+			}
+			delay = c.MinPollInterval
+		} else {
+			delay = nextPollDelay(delay, c.MaxPollInterval)
+		}
+
+		iterator = next
+		if iterator != "" && len(records) == 0 {
+			if !sleepContext(ctx, delay) {
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// shardIterator returns an iterator after the checkpoint of the shard, or
+// of iteratorType when it has none.
+func (c *StreamConsumer) shardIterator(shardId, iteratorType string) (string, error) {
+	checkpoint, err := c.Checkpointer.GetCheckpoint(shardId)
+	if err != nil {
+		return "", err
+	}
+	if checkpoint != "" {
+		iterator, err := c.Server.GetShardIterator(c.StreamArn, shardId, SHARD_ITERATOR_AFTER_SEQUENCE_NUMBER, checkpoint, false)
+		if ErrorCode(err) != TrimmedDataAccessException {
+			return iterator, err
+		}
+		// The checkpoint fell out of the 24h retention window; resume
+		// from the oldest record still available.
+		return c.Server.GetShardIterator(c.StreamArn, shardId, SHARD_ITERATOR_TRIM_HORIZON, "", false)
+	}
+	return c.Server.GetShardIterator(c.StreamArn, shardId, iteratorType, "", false)
+}
+
+func nextPollDelay(current, max time.Duration) time.Duration {
+	if current <= 0 {
+		current = 100 * time.Millisecond
+	}
+	current *= 2
+	if max > 0 && current > max {
+		current = max
+	}
+	return current
+}
+
+// sleepContext waits for d, returning false if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
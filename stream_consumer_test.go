@@ -0,0 +1,245 @@
+package dynamodb_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+// fakeStream serves the Dynamodb Streams API for a closed shard "s1",
+// holding records 1 and 2, and its open child "s2", holding record 3.
+// Iterators are "<shard>:<index of the next record>".
+type fakeStream struct {
+	mu              sync.Mutex
+	describeCalls   int
+	failDescribe    int // number of DescribeStream calls throttled
+	shards          []dynamodb.ShardT
+	records         map[string][]string
+	afterSequence   map[string]string // shard: AFTER_SEQUENCE_NUMBER requested
+	iteratorsByType map[string]int
+}
+
+func newFakeStream() *fakeStream {
+	return &fakeStream{
+		shards: []dynamodb.ShardT{
+			{ShardId: "s1", SequenceNumberRange: dynamodb.SequenceNumberRangeT{StartingSequenceNumber: "1", EndingSequenceNumber: "2"}},
+			{ShardId: "s2", ParentShardId: "s1", SequenceNumberRange: dynamodb.SequenceNumberRangeT{StartingSequenceNumber: "3"}},
+		},
+		records:         map[string][]string{"s1": {"1", "2"}, "s2": {"3"}},
+		afterSequence:   make(map[string]string),
+		iteratorsByType: make(map[string]int),
+	}
+}
+
+func (f *fakeStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body struct {
+		ShardId           string
+		ShardIteratorType string
+		SequenceNumber    string
+		ShardIterator     string
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	target := r.Header.Get("X-Amz-Target")
+	var response interface{}
+	switch target[strings.LastIndex(target, ".")+1:] {
+	case "DescribeStream":
+		f.describeCalls++
+		if f.describeCalls <= f.failDescribe {
+			w.WriteHeader(400)
+			w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ThrottlingException","message":"slow down"}`))
+			return
+		}
+		response = map[string]interface{}{"StreamDescription": map[string]interface{}{"Shards": f.shards}}
+	case "GetShardIterator":
+		f.iteratorsByType[body.ShardIteratorType]++
+		next := 0
+		if body.ShardIteratorType == dynamodb.SHARD_ITERATOR_LATEST {
+			next = len(f.records[body.ShardId])
+		}
+		if body.ShardIteratorType == dynamodb.SHARD_ITERATOR_AFTER_SEQUENCE_NUMBER {
+			f.afterSequence[body.ShardId] = body.SequenceNumber
+			for i, seq := range f.records[body.ShardId] {
+				if seq == body.SequenceNumber {
+					next = i + 1
+				}
+			}
+		}
+		response = map[string]string{"ShardIterator": fmt.Sprintf("%s:%d", body.ShardId, next)}
+	case "GetRecords":
+		i := strings.Index(body.ShardIterator, ":")
+		shard := body.ShardIterator[:i]
+		next, _ := strconv.Atoi(body.ShardIterator[i+1:])
+		var records []interface{}
+		for _, seq := range f.records[shard][next:] {
+			records = append(records, map[string]interface{}{
+				"eventName": "INSERT",
+				"dynamodb":  map[string]interface{}{"SequenceNumber": seq, "Keys": map[string]interface{}{"id": map[string]string{"S": seq}}},
+			})
+		}
+		iterator := fmt.Sprintf("%s:%d", shard, len(f.records[shard]))
+		if shard == "s1" && len(records) == 0 {
+			iterator = "" // s1 is closed and read to its end.
+		}
+		response = map[string]interface{}{"Records": records, "NextShardIterator": iterator}
+	default:
+		w.WriteHeader(400)
+		w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#UnknownOperationException"}`))
+		return
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+type StreamConsumerSuite struct {
+	stream   *fakeStream
+	http     *httptest.Server
+	consumer *dynamodb.StreamConsumer
+}
+
+var _ = check.Suite(&StreamConsumerSuite{})
+
+func (s *StreamConsumerSuite) SetUpTest(c *check.C) {
+	s.stream = newFakeStream()
+	s.http = httptest.NewServer(s.stream)
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: s.http.URL})
+	server.Logger = dynamodb.NopLogger
+	server.RetryPolicy = &dynamodb.RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	s.consumer = dynamodb.NewStreamConsumer(server, "arn:stream", nil)
+	s.consumer.MinPollInterval = time.Millisecond
+	s.consumer.MaxPollInterval = time.Millisecond
+}
+
+func (s *StreamConsumerSuite) TearDownTest(c *check.C) {
+	s.http.Close()
+}
+
+// consume runs the consumer until it has received the record with
+// sequence number last, returning the sequence numbers received.
+func (s *StreamConsumerSuite) consume(c *check.C, last string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	var received []string
+	err := s.consumer.Run(ctx, func(shardId string, records []dynamodb.StreamRecordT) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, r := range records {
+			c.Check(r.Keys["id"].Value, check.Equals, r.SequenceNumber)
+			received = append(received, r.SequenceNumber)
+			if r.SequenceNumber == last {
+				cancel()
+			}
+		}
+		return nil
+	})
+	c.Assert(err, check.IsNil)
+	c.Assert(ctx.Err(), check.Equals, context.Canceled)
+
+	mu.Lock()
+	defer mu.Unlock()
+	return received
+}
+
+func (s *StreamConsumerSuite) TestShardDiscovery(c *check.C) {
+	s.consumer.ShardRefreshInterval = 0
+	s.stream.failDescribe = 1
+
+	c.Check(s.consume(c, "3"), check.DeepEquals, []string{"1", "2", "3"})
+
+	s.stream.mu.Lock()
+	defer s.stream.mu.Unlock()
+	// The throttled call, the first listing, and the one made when s1 was
+	// finished: a zero ShardRefreshInterval does not poll continuously.
+	c.Check(s.stream.describeCalls <= 4, check.Equals, true, check.Commentf("%d DescribeStream calls", s.stream.describeCalls))
+	c.Check(s.stream.iteratorsByType, check.DeepEquals, map[string]int{dynamodb.SHARD_ITERATOR_TRIM_HORIZON: 2})
+}
+
+func (s *StreamConsumerSuite) TestLatestSkipsOnlyTheOpenShards(c *check.C) {
+	s.consumer.IteratorType = dynamodb.SHARD_ITERATOR_LATEST
+
+	// s2 is the child of s1, so all of it is read once s1 is.
+	c.Check(s.consume(c, "3"), check.DeepEquals, []string{"3"})
+
+	s.stream.mu.Lock()
+	defer s.stream.mu.Unlock()
+	c.Check(s.stream.iteratorsByType, check.DeepEquals, map[string]int{
+		dynamodb.SHARD_ITERATOR_LATEST:       1,
+		dynamodb.SHARD_ITERATOR_TRIM_HORIZON: 1,
+	})
+}
+
+func (s *StreamConsumerSuite) TestLaterShardsAreReadFromTheStart(c *check.C) {
+	s.consumer.IteratorType = dynamodb.SHARD_ITERATOR_LATEST
+	s.consumer.ShardRefreshInterval = 10 * time.Millisecond
+	s.stream.shards = []dynamodb.ShardT{{ShardId: "s2"}}
+	s.stream.records["s2"] = nil
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		s.stream.mu.Lock()
+		defer s.stream.mu.Unlock()
+		s.stream.shards = append(s.stream.shards, dynamodb.ShardT{ShardId: "s3"})
+		s.stream.records["s3"] = []string{"5"}
+	}()
+
+	// s2 is open when the consumer starts, s3 is found later.
+	c.Check(s.consume(c, "5"), check.DeepEquals, []string{"5"})
+
+	s.stream.mu.Lock()
+	defer s.stream.mu.Unlock()
+	c.Check(s.stream.iteratorsByType, check.DeepEquals, map[string]int{
+		dynamodb.SHARD_ITERATOR_LATEST:       1,
+		dynamodb.SHARD_ITERATOR_TRIM_HORIZON: 1,
+	})
+}
+
+func (s *StreamConsumerSuite) TestCancelledWhileListing(c *check.C) {
+	s.stream.failDescribe = 1000
+	s.consumer.Server.RetryPolicy = &dynamodb.RetryPolicy{MaxRetries: 1000, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.consumer.Run(ctx, func(string, []dynamodb.StreamRecordT) error { return nil })
+	}()
+	select {
+	case err := <-done:
+		c.Check(err, check.IsNil)
+	case <-time.After(5 * time.Second):
+		c.Fatal("Run did not stop when its context was done")
+	}
+}
+
+func (s *StreamConsumerSuite) TestCheckpoints(c *check.C) {
+	c.Check(s.consume(c, "3"), check.DeepEquals, []string{"1", "2", "3"})
+
+	for shard, want := range map[string]string{"s1": "2", "s2": "3"} {
+		checkpoint, err := s.consumer.Checkpointer.GetCheckpoint(shard)
+		c.Assert(err, check.IsNil)
+		c.Check(checkpoint, check.Equals, want)
+	}
+}
+
+func (s *StreamConsumerSuite) TestResumeFromCheckpoint(c *check.C) {
+	c.Assert(s.consumer.Checkpointer.SetCheckpoint("s1", "1"), check.IsNil)
+
+	c.Check(s.consume(c, "3"), check.DeepEquals, []string{"2", "3"})
+
+	s.stream.mu.Lock()
+	defer s.stream.mu.Unlock()
+	c.Check(s.stream.afterSequence, check.DeepEquals, map[string]string{"s1": "1"})
+}
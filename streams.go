@@ -0,0 +1,220 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	SHARD_ITERATOR_TRIM_HORIZON          = "TRIM_HORIZON"
+	SHARD_ITERATOR_LATEST                = "LATEST"
+	SHARD_ITERATOR_AT_SEQUENCE_NUMBER    = "AT_SEQUENCE_NUMBER"
+	SHARD_ITERATOR_AFTER_SEQUENCE_NUMBER = "AFTER_SEQUENCE_NUMBER"
+
+	ExpiredIteratorException   = "ExpiredIteratorException"
+	TrimmedDataAccessException = "TrimmedDataAccessException"
+)
+
+type SequenceNumberRangeT struct {
+	StartingSequenceNumber string
+	EndingSequenceNumber   string
+}
+
+type ShardT struct {
+	ShardId             string
+	ParentShardId       string
+	SequenceNumberRange SequenceNumberRangeT
+}
+
+// Closed reports whether the shard no longer receives new records.
+func (s *ShardT) Closed() bool {
+	return s.SequenceNumberRange.EndingSequenceNumber != ""
+}
+
+type StreamDescriptionT struct {
+	StreamArn               string
+	StreamLabel             string
+	StreamStatus            string
+	StreamViewType          string
+	TableName               string
+	KeySchema               []KeySchemaT
+	Shards                  []ShardT
+	LastEvaluatedShardId    string
+	CreationRequestDateTime float64
+}
+
+type StreamRecordT struct {
+	EventID      string
+	EventName    string // INSERT, MODIFY or REMOVE
+	EventSource  string
+	EventVersion string
+	AwsRegion    string
+
+	ApproximateCreationDateTime float64
+	SequenceNumber              string
+	SizeBytes                   int64
+	StreamViewType              string
+	Keys                        map[string]*Attribute
+	NewImage                    map[string]*Attribute
+	OldImage                    map[string]*Attribute
+}
+
+type describeStreamResponse struct {
+	StreamDescription StreamDescriptionT
+}
+
+type getShardIteratorResponse struct {
+	ShardIterator string
+}
+
+type rawStreamRecord struct {
+	EventID      string `json:"eventID"`
+	EventName    string `json:"eventName"`
+	EventSource  string `json:"eventSource"`
+	EventVersion string `json:"eventVersion"`
+	AwsRegion    string `json:"awsRegion"`
+	Dynamodb     struct {
+		ApproximateCreationDateTime float64
+		SequenceNumber              string
+		SizeBytes                   int64
+		StreamViewType              string
+		Keys                        attributeMap
+		NewImage                    attributeMap
+		OldImage                    attributeMap
+	} `json:"dynamodb"`
+}
+
+type getRecordsResponse struct {
+	NextShardIterator string
+	Records           []rawStreamRecord
+}
+
+// streamsEndpoint derives the Dynamodb Streams endpoint from the Dynamodb
+// one. Endpoints which do not follow the AWS naming (e.g. DynamoDB local)
+// serve both APIs and are used as is.
+func (s *Server) streamsEndpoint() string {
+	endpoint := s.Region.DynamoDBEndpoint
+	if i := strings.Index(endpoint, "://dynamodb."); i >= 0 {
+		return endpoint[:i] + "://streams.dynamodb." + endpoint[i+len("://dynamodb."):]
+	}
+	return endpoint
+}
+
+func (s *Server) queryStreams(target string, query *Query, isRetry bool) ([]byte, error) {
+	return s.queryStreamsContext(context.Background(), target, query, isRetry)
+}
+
+func (s *Server) queryStreamsContext(ctx context.Context, target string, query *Query, isRetry bool) ([]byte, error) {
+	var retryCount = 0
+	if !isRetry {
+		retryCount = -1
+	}
+	return s.rawQueryEndpointContext(ctx, s.streamsEndpoint(), target, query.String(), retryCount)
+}
+
+func streamsTarget(name string) string {
+	return "DynamoDBStreams_20120810." + name
+}
+
+// DescribeStream returns one page of the stream's shards, starting after
+// exclusiveStartShardId when it is not empty.
+func (s *Server) DescribeStream(streamArn string, exclusiveStartShardId string, isRetry bool) (*StreamDescriptionT, error) {
+	return s.describeStreamContext(context.Background(), streamArn, exclusiveStartShardId, isRetry)
+}
+
+func (s *Server) describeStreamContext(ctx context.Context, streamArn string, exclusiveStartShardId string, isRetry bool) (*StreamDescriptionT, error) {
+	q := NewEmptyQuery()
+	q.AddStreamArn(streamArn)
+	q.AddExclusiveStartShardId(exclusiveStartShardId)
+
+	jsonResponse, err := s.queryStreamsContext(ctx, streamsTarget("DescribeStream"), q, isRetry)
+	if err != nil {
+		return nil, err
+	}
+
+	var r describeStreamResponse
+	if err := json.Unmarshal(jsonResponse, &r); err != nil {
+		return nil, err
+	}
+	return &r.StreamDescription, nil
+}
+
+// ListShards follows DescribeStream pagination and returns every shard.
+func (s *Server) ListShards(streamArn string, isRetry bool) ([]ShardT, error) {
+	return s.ListShardsContext(context.Background(), streamArn, isRetry)
+}
+
+// ListShardsContext is ListShards with a context, which stops the
+// requests and their retries when done.
+func (s *Server) ListShardsContext(ctx context.Context, streamArn string, isRetry bool) ([]ShardT, error) {
+	var shards []ShardT
+	var lastEvaluatedShardId string
+	for {
+		desc, err := s.describeStreamContext(ctx, streamArn, lastEvaluatedShardId, isRetry)
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, desc.Shards...)
+		lastEvaluatedShardId = desc.LastEvaluatedShardId
+		if lastEvaluatedShardId == "" {
+			return shards, nil
+		}
+	}
+}
+
+func (s *Server) GetShardIterator(streamArn, shardId, iteratorType, sequenceNumber string, isRetry bool) (string, error) {
+	q := NewEmptyQuery()
+	q.AddStreamArn(streamArn)
+	q.AddShardIteratorRequest(shardId, iteratorType, sequenceNumber)
+
+	jsonResponse, err := s.queryStreams(streamsTarget("GetShardIterator"), q, isRetry)
+	if err != nil {
+		return "", err
+	}
+
+	var r getShardIteratorResponse
+	if err := json.Unmarshal(jsonResponse, &r); err != nil {
+		return "", err
+	}
+	return r.ShardIterator, nil
+}
+
+// GetRecords reads from shardIterator. The returned iterator is empty once
+// a closed shard has been read completely.
+func (s *Server) GetRecords(shardIterator string, limit int64, isRetry bool) ([]StreamRecordT, string, error) {
+	q := NewEmptyQuery()
+	q.AddShardIterator(shardIterator)
+	if limit > 0 {
+		q.AddLimit(limit)
+	}
+
+	jsonResponse, err := s.queryStreams(streamsTarget("GetRecords"), q, isRetry)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var r getRecordsResponse
+	if err := json.Unmarshal(jsonResponse, &r); err != nil {
+		return nil, "", err
+	}
+
+	records := make([]StreamRecordT, len(r.Records))
+	for i, raw := range r.Records {
+		records[i] = StreamRecordT{
+			EventID:                     raw.EventID,
+			EventName:                   raw.EventName,
+			EventSource:                 raw.EventSource,
+			EventVersion:                raw.EventVersion,
+			AwsRegion:                   raw.AwsRegion,
+			ApproximateCreationDateTime: raw.Dynamodb.ApproximateCreationDateTime,
+			SequenceNumber:              raw.Dynamodb.SequenceNumber,
+			SizeBytes:                   raw.Dynamodb.SizeBytes,
+			StreamViewType:              raw.Dynamodb.StreamViewType,
+			Keys:                        raw.Dynamodb.Keys.attributes(),
+			NewImage:                    raw.Dynamodb.NewImage.attributes(),
+			OldImage:                    raw.Dynamodb.OldImage.attributes(),
+		}
+	}
+	return records, r.NextShardIterator, nil
+}
@@ -1,17 +1,28 @@
 package dynamodb
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
+)
 
-	simplejson "github.com/bitly/go-simplejson"
+const (
+	STREAM_VIEW_TYPE_KEYS_ONLY          = "KEYS_ONLY"
+	STREAM_VIEW_TYPE_NEW_IMAGE          = "NEW_IMAGE"
+	STREAM_VIEW_TYPE_OLD_IMAGE          = "OLD_IMAGE"
+	STREAM_VIEW_TYPE_NEW_AND_OLD_IMAGES = "NEW_AND_OLD_IMAGES"
 )
 
+// A Table is a lightweight handle on a table of its Server, safe for
+// concurrent use as long as its fields are not modified.
 type Table struct {
 	Server *Server
 	Name   string
 	Key    PrimaryKey
+	// Secondary indexes, for Index. Set by NewTableFromDescription.
+	Indexes []IndexT
 }
 
 type AttributeDefinitionT struct {
@@ -31,6 +42,9 @@ type ProjectionT struct {
 
 type GlobalSecondaryIndexT struct {
 	IndexName             string
+	IndexArn              string
+	IndexStatus           string // CREATING, UPDATING, DELETING or ACTIVE
+	Backfilling           bool
 	IndexSizeBytes        int64
 	ItemCount             int64
 	KeySchema             []KeySchemaT
@@ -40,6 +54,7 @@ type GlobalSecondaryIndexT struct {
 
 type LocalSecondaryIndexT struct {
 	IndexName      string
+	IndexArn       string
 	IndexSizeBytes int64
 	ItemCount      int64
 	KeySchema      []KeySchemaT
@@ -47,11 +62,18 @@ type LocalSecondaryIndexT struct {
 }
 
 type ProvisionedThroughputT struct {
+	LastDecreaseDateTime   float64
+	LastIncreaseDateTime   float64
 	NumberOfDecreasesToday int64
 	ReadCapacityUnits      int64
 	WriteCapacityUnits     int64
 }
 
+type StreamSpecificationT struct {
+	StreamEnabled  bool
+	StreamViewType string // one of the STREAM_VIEW_TYPE_* constants
+}
+
 type TableDescriptionT struct {
 	AttributeDefinitions   []AttributeDefinitionT
 	CreationDateTime       float64
@@ -60,15 +82,75 @@ type TableDescriptionT struct {
 	LocalSecondaryIndexes  []LocalSecondaryIndexT
 	GlobalSecondaryIndexes []GlobalSecondaryIndexT
 	ProvisionedThroughput  ProvisionedThroughputT
+	TableArn               string
+	TableId                string
 	TableName              string
 	TableSizeBytes         int64
 	TableStatus            string
+	LatestStreamArn        string
+	LatestStreamLabel      string
+	GlobalTableVersion     string
+	Replicas               []ReplicaDescriptionT
+	BillingModeSummary     BillingModeSummaryT
+	StreamSpecification    StreamSpecificationT
+	SSEDescription         SSEDescriptionT
+	// Encryption at rest of new tables, only read by CreateTable. Existing
+	// tables report theirs in SSEDescription.
+	SSESpecification SSESpecificationT
+}
+
+type BillingModeSummaryT struct {
+	BillingMode                       string
+	LastUpdateToPayPerRequestDateTime float64
+}
+
+type ReplicaDescriptionT struct {
+	RegionName               string
+	ReplicaStatus            string
+	ReplicaStatusDescription string
+	KMSMasterKeyId           string
 }
 
 type describeTableResponse struct {
 	Table TableDescriptionT
 }
 
+// BillingMode returns the billing mode of the table. Tables created before
+// on-demand billing existed have no BillingModeSummary and are provisioned.
+func (t *TableDescriptionT) BillingMode() string {
+	if t.BillingModeSummary.BillingMode == "" {
+		return BILLING_MODE_PROVISIONED
+	}
+	return t.BillingModeSummary.BillingMode
+}
+
+// CreationTime returns CreationDateTime as a time.Time.
+func (t *TableDescriptionT) CreationTime() time.Time {
+	return epochTime(t.CreationDateTime)
+}
+
+// GlobalSecondaryIndex returns the description of the named global
+// secondary index, or nil.
+func (t *TableDescriptionT) GlobalSecondaryIndex(name string) *GlobalSecondaryIndexT {
+	for i := range t.GlobalSecondaryIndexes {
+		if t.GlobalSecondaryIndexes[i].IndexName == name {
+			return &t.GlobalSecondaryIndexes[i]
+		}
+	}
+	return nil
+}
+
+// LocalSecondaryIndex returns the description of the named local secondary
+// index, or nil.
+func (t *TableDescriptionT) LocalSecondaryIndex(name string) *LocalSecondaryIndexT {
+	for i := range t.LocalSecondaryIndexes {
+		if t.LocalSecondaryIndexes[i].IndexName == name {
+			return &t.LocalSecondaryIndexes[i]
+		}
+	}
+	return nil
+}
+
 func findAttributeDefinitionByName(ads []AttributeDefinitionT, name string) *AttributeDefinitionT {
 	for _, a := range ads {
 		if a.Name == name {
@@ -92,7 +174,32 @@ func (a *AttributeDefinitionT) GetEmptyAttribute() *Attribute {
 }
 
 func (t *TableDescriptionT) BuildPrimaryKey() (pk PrimaryKey, err error) {
-	for _, k := range t.KeySchema {
+	return t.buildKey(t.KeySchema)
+}
+
+// BuildIndexes returns the local then global secondary indexes of the
+// table, with their keys typed from AttributeDefinitions.
+func (t *TableDescriptionT) BuildIndexes() ([]IndexT, error) {
+	var indexes []IndexT
+	for _, index := range t.LocalSecondaryIndexes {
+		key, err := t.buildKey(index.KeySchema)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, IndexT{IndexName: index.IndexName, Local: true, Key: key, Projection: index.Projection})
+	}
+	for _, index := range t.GlobalSecondaryIndexes {
+		key, err := t.buildKey(index.KeySchema)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, IndexT{IndexName: index.IndexName, Key: key, Projection: index.Projection})
+	}
+	return indexes, nil
+}
+
+func (t *TableDescriptionT) buildKey(schema []KeySchemaT) (pk PrimaryKey, err error) {
+	for _, k := range schema {
 		var attr *Attribute
 		ad := findAttributeDefinitionByName(t.AttributeDefinitions, k.AttributeName)
 		if ad == nil {
@@ -114,7 +221,21 @@ func (t *TableDescriptionT) BuildPrimaryKey() (pk PrimaryKey, err error) {
 }
 
 func (s *Server) NewTable(name string, key PrimaryKey) *Table {
-	return &Table{s, name, key}
+	return &Table{Server: s, Name: name, Key: key}
+}
+
+// NewTableFromDescription returns a Table with the key and secondary
+// indexes of desc, as returned by DescribeTable.
+func (s *Server) NewTableFromDescription(desc *TableDescriptionT) (*Table, error) {
+	key, err := desc.BuildPrimaryKey()
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := desc.BuildIndexes()
+	if err != nil {
+		return nil, err
+	}
+	return &Table{Server: s, Name: desc.TableName, Key: key, Indexes: indexes}, nil
 }
 
 func (s *Server) ListTables(isRetry bool) ([]string, error) {
@@ -130,6 +251,8 @@ func (s *Server) ListTables(isRetry bool) ([]string, error) {
 	return tables, err
 }
 
+// ListTablesCallbackIterator calls cb with the name of every table. With
+// Server.TableNames set, only the tables of the namespace are listed.
 func (s *Server) ListTablesCallbackIterator(cb func(string), isRetry bool) error {
 	var lastEvaluatedTableName string
 
@@ -142,31 +265,29 @@ func (s *Server) ListTablesCallbackIterator(cb func(string), isRetry bool) error
 			return err
 		}
 
-		json, err := simplejson.NewJson(jsonResponse)
-		if err != nil {
-			return err
+		var r struct {
+			LastEvaluatedTableName string
+			TableNames             []string
 		}
-
-		lastEvaluatedTableName = ""
-		if json, ok := json.CheckGet("LastEvaluatedTableName"); ok {
-			lastEvaluatedTableName, err = json.String()
-			if err != nil {
-				message := fmt.Sprintf("Unexpected response %s", jsonResponse)
-				return errors.New(message)
-			}
+		if err := decodeResponse(jsonResponse, &r); err != nil {
+			return err
 		}
-
-		response, err := json.Get("TableNames").Array()
-		if err != nil {
+		if r.TableNames == nil {
 			message := fmt.Sprintf("Unexpected response %s", jsonResponse)
 			return errors.New(message)
 		}
 
-		for _, value := range response {
-			if t, ok := (value).(string); ok {
-				cb(t)
+		for _, t := range r.TableNames {
+			if s.TableNames != nil {
+				name, ok := s.TableNames.Logical(t)
+				if !ok {
+					continue
+				}
+				t = name
 			}
+			cb(t)
 		}
+		lastEvaluatedTableName = r.LastEvaluatedTableName
 		if lastEvaluatedTableName == "" {
 			break
 		}
@@ -186,13 +307,7 @@ func (s *Server) CreateTable(tableDescription TableDescriptionT, isRetry bool) (
 		return "unknown", err
 	}
 
-	json, err := simplejson.NewJson(jsonResponse)
-
-	if err != nil {
-		return "unknown", err
-	}
-
-	return json.Get("TableDescription").Get("TableStatus").MustString(), nil
+	return parseTableStatus(jsonResponse)
 }
 
 func (s *Server) DeleteTable(tableDescription TableDescriptionT, isRetry bool) (string, error) {
@@ -205,13 +320,21 @@ func (s *Server) DeleteTable(tableDescription TableDescriptionT, isRetry bool) (
 		return "unknown", err
 	}
 
-	json, err := simplejson.NewJson(jsonResponse)
+	return parseTableStatus(jsonResponse)
+}
 
-	if err != nil {
+// parseTableStatus returns the status of a CreateTable or DeleteTable
+// response.
+func parseTableStatus(jsonResponse []byte) (string, error) {
+	var r struct {
+		TableDescription struct {
+			TableStatus string
+		}
+	}
+	if err := decodeResponse(jsonResponse, &r); err != nil {
 		return "unknown", err
 	}
-
-	return json.Get("TableDescription").Get("TableStatus").MustString(), nil
+	return r.TableDescription.TableStatus, nil
 }
 
 func (t *Table) DescribeTable(isRetry bool) (*TableDescriptionT, error) {
@@ -219,10 +342,14 @@ func (t *Table) DescribeTable(isRetry bool) (*TableDescriptionT, error) {
 }
 
 func (s *Server) DescribeTable(name string, isRetry bool) (*TableDescriptionT, error) {
+	return s.describeTableContext(context.Background(), name, isRetry)
+}
+
+func (s *Server) describeTableContext(ctx context.Context, name string, isRetry bool) (*TableDescriptionT, error) {
 	q := NewEmptyQuery()
 	q.addTableByName(name)
 
-	jsonResponse, err := s.queryServer(target("DescribeTable"), q, isRetry)
+	jsonResponse, err := s.queryServerContext(ctx, target("DescribeTable"), q, isRetry)
 	if err != nil {
 		return nil, err
 	}
@@ -236,6 +363,31 @@ func (s *Server) DescribeTable(name string, isRetry bool) (*TableDescriptionT, e
 	return &r.Table, nil
 }
 
+type updateTableResponse struct {
+	TableDescription TableDescriptionT
+}
+
+// updateTable sends an UpdateTable request built by the caller and returns
+// the resulting table description.
+func (s *Server) updateTable(q *Query, isRetry bool) (*TableDescriptionT, error) {
+	return s.updateTableContext(context.Background(), q, isRetry)
+}
+
+func (s *Server) updateTableContext(ctx context.Context, q *Query, isRetry bool) (*TableDescriptionT, error) {
+	jsonResponse, err := s.queryServerContext(ctx, target("UpdateTable"), q, isRetry)
+	if err != nil {
+		return nil, err
+	}
+
+	var r updateTableResponse
+	err = json.Unmarshal(jsonResponse, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r.TableDescription, nil
+}
+
 func keyParam(k *PrimaryKey, hashKey string, rangeKey string) string {
 	value := fmt.Sprintf("{\"HashKeyElement\":{%s}", keyValue(k.KeyAttribute.Type, hashKey))
 
@@ -0,0 +1,157 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// TableNameTransformer maps the table names used by the code to the names
+// of the tables in Dynamodb, and back, e.g. to run one codebase against
+// per-environment tables. See Server.TableNames.
+type TableNameTransformer interface {
+	// Physical returns the name of the table in Dynamodb.
+	Physical(name string) string
+	// Logical reverses Physical. It returns false for the tables outside
+	// the namespace.
+	Logical(physical string) (string, bool)
+}
+
+// TableNameAffix namespaces tables with a prefix and a suffix:
+// TableNameAffix{Prefix: "staging-"} maps "orders" to "staging-orders".
+type TableNameAffix struct {
+	Prefix string
+	Suffix string
+}
+
+func (a TableNameAffix) Physical(name string) string {
+	return a.Prefix + name + a.Suffix
+}
+
+func (a TableNameAffix) Logical(physical string) (string, bool) {
+	if len(physical) <= len(a.Prefix)+len(a.Suffix) ||
+		!strings.HasPrefix(physical, a.Prefix) || !strings.HasSuffix(physical, a.Suffix) {
+		return "", false
+	}
+	return physical[len(a.Prefix) : len(physical)-len(a.Suffix)], true
+}
+
+// tableNames rewrites the table names of request and response bodies.
+// Bodies which cannot be parsed are left as they are, for Dynamodb to
+// report.
+type tableNames struct {
+	t TableNameTransformer
+}
+
+func (n tableNames) physical(name string) string {
+	return n.t.Physical(name)
+}
+
+func (n tableNames) logical(name string) string {
+	if logical, ok := n.t.Logical(name); ok {
+		return logical
+	}
+	return name
+}
+
+// request rewrites the table names of a request body to their physical
+// names. ListTables is left alone: ListTablesCallbackIterator filters and
+// maps its names itself.
+func (n tableNames) request(operation string, body []byte) []byte {
+	if operation == "ListTables" {
+		return body
+	}
+	return n.rewrite(body, func(fields map[string]json.RawMessage) {
+		for _, field := range []string{"TableName", "SourceTableName", "TargetTableName"} {
+			renameField(fields, field, n.physical)
+		}
+		renameKeys(fields, "RequestItems", n.physical)
+		if items, ok := objects(fields["TransactItems"]); ok {
+			for _, item := range items {
+				for action := range item {
+					renameNested(item, action, "TableName", n.physical)
+				}
+			}
+			fields["TransactItems"], _ = json.Marshal(items)
+		}
+	})
+}
+
+// response rewrites the table names of a response body to their logical
+// names.
+func (n tableNames) response(operation string, body []byte) []byte {
+	if operation == "ListTables" || len(body) == 0 {
+		return body
+	}
+	return n.rewrite(body, func(fields map[string]json.RawMessage) {
+		for _, field := range []string{"Table", "TableDescription", "StreamDescription"} {
+			renameNested(fields, field, "TableName", n.logical)
+		}
+		if operation == "BatchGetItem" || operation == "BatchWriteItem" {
+			for _, field := range []string{"Responses", "UnprocessedKeys", "UnprocessedItems", "ItemCollectionMetrics"} {
+				renameKeys(fields, field, n.logical)
+			}
+		}
+		if capacities, ok := objects(fields["ConsumedCapacity"]); ok {
+			for _, c := range capacities {
+				renameField(c, "TableName", n.logical)
+			}
+			fields["ConsumedCapacity"], _ = json.Marshal(capacities)
+		} else {
+			renameNested(fields, "ConsumedCapacity", "TableName", n.logical)
+		}
+	})
+}
+
+func (n tableNames) rewrite(body []byte, fn func(fields map[string]json.RawMessage)) []byte {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil || fields == nil {
+		return body
+	}
+	fn(fields)
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// renameField renames the string value of fields[field].
+func renameField(fields map[string]json.RawMessage, field string, rename func(string) string) {
+	var name string
+	if raw, ok := fields[field]; !ok || json.Unmarshal(raw, &name) != nil {
+		return
+	}
+	fields[field], _ = json.Marshal(rename(name))
+}
+
+// renameNested renames the string value of fields[object][field].
+func renameNested(fields map[string]json.RawMessage, object, field string, rename func(string) string) {
+	var nested map[string]json.RawMessage
+	if raw, ok := fields[object]; !ok || json.Unmarshal(raw, &nested) != nil || nested == nil {
+		return
+	}
+	renameField(nested, field, rename)
+	fields[object], _ = json.Marshal(nested)
+}
+
+// renameKeys renames the keys of the object fields[field].
+func renameKeys(fields map[string]json.RawMessage, field string, rename func(string) string) {
+	var byTable map[string]json.RawMessage
+	if raw, ok := fields[field]; !ok || json.Unmarshal(raw, &byTable) != nil || byTable == nil {
+		return
+	}
+	renamed := make(map[string]json.RawMessage, len(byTable))
+	for name, v := range byTable {
+		renamed[rename(name)] = v
+	}
+	fields[field], _ = json.Marshal(renamed)
+}
+
+// objects decodes raw as an array of objects.
+func objects(raw json.RawMessage) ([]map[string]json.RawMessage, bool) {
+	var list []map[string]json.RawMessage
+	if raw == nil || json.Unmarshal(raw, &list) != nil {
+		return nil, false
+	}
+	return list, true
+}
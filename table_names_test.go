@@ -0,0 +1,81 @@
+package dynamodb_test
+
+import (
+	"context"
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type TableNamesSuite struct {
+	staging *dynamodb.Server
+	plain   *dynamodb.Server
+}
+
+var _ = check.Suite(&TableNamesSuite{})
+
+func (s *TableNamesSuite) SetUpTest(c *check.C) {
+	f := dynamodbtest.New()
+	s.staging = dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"})
+	s.staging.TableNames = dynamodb.TableNameAffix{Prefix: "staging-"}
+	s.staging.Use(f.Middleware())
+	s.plain = dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"})
+	s.plain.Use(f.Middleware())
+
+	d := tableSchema(c, "orders", idKey{})
+	createTable(c, s.staging, d)
+	d.TableName = "production-orders"
+	createTable(c, s.plain, d)
+}
+
+func (s *TableNamesSuite) TestAffix(c *check.C) {
+	a := dynamodb.TableNameAffix{Prefix: "app-", Suffix: "-dev"}
+	c.Check(a.Physical("orders"), check.Equals, "app-orders-dev")
+	name, ok := a.Logical("app-orders-dev")
+	c.Check(name, check.Equals, "orders")
+	c.Check(ok, check.Equals, true)
+	for _, physical := range []string{"orders", "app-orders", "orders-dev", "app--dev"} {
+		_, ok := a.Logical(physical)
+		c.Check(ok, check.Equals, false, check.Commentf(physical))
+	}
+}
+
+func (s *TableNamesSuite) TestTables(c *check.C) {
+	tables, err := s.plain.ListTables(false)
+	c.Assert(err, check.IsNil)
+	c.Check(tables, check.DeepEquals, []string{"production-orders", "staging-orders"})
+	tables, err = s.staging.ListTables(false)
+	c.Assert(err, check.IsNil)
+	c.Check(tables, check.DeepEquals, []string{"orders"})
+
+	desc, err := s.staging.DescribeTable("orders", false)
+	c.Assert(err, check.IsNil)
+	c.Check(desc.TableName, check.Equals, "orders")
+	_, err = s.staging.DescribeTable("production-orders", false)
+	c.Check(dynamodb.IsNotFound(err), check.Equals, true)
+}
+
+func (s *TableNamesSuite) TestItems(c *check.C) {
+	table, err := s.staging.Table("orders")
+	c.Assert(err, check.IsNil)
+	c.Check(table.Name, check.Equals, "orders")
+	_, err = table.PutItemWithOptions(context.Background(), append(table.Key.Clone("o1", ""), *dynamodb.NewStringAttribute("status", "new")), nil)
+	c.Assert(err, check.IsNil)
+
+	// The item is in the physical table only.
+	physical, err := s.plain.Table("staging-orders")
+	c.Assert(err, check.IsNil)
+	item, err := physical.GetItem(&dynamodb.Key{HashKey: "o1"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(item["status"].Value, check.Equals, "new")
+	production, err := s.plain.Table("production-orders")
+	c.Assert(err, check.IsNil)
+	_, err = production.GetItem(&dynamodb.Key{HashKey: "o1"}, false)
+	c.Check(err, check.Equals, dynamodb.ErrNotFound)
+
+	results, err := table.BatchGetItems([]dynamodb.Key{{HashKey: "o1"}}).Execute(false)
+	c.Assert(err, check.IsNil)
+	c.Check(results["orders"], check.HasLen, 1)
+	c.Check(results["staging-orders"], check.HasLen, 0)
+}
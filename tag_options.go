@@ -0,0 +1,363 @@
+package dynamodb
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// isEmpty reports whether v, the value of f, is empty for the omitempty
+// option: false, 0, a nil pointer or interface, an empty string, slice or
+// map, or the zero time of a unixtime field.
+func (f *field) isEmpty(v reflect.Value) bool {
+	if f.unixtime {
+		if t, ok := timeOf(v); ok {
+			return t.IsZero()
+		}
+	}
+	return isEmptyValue(v)
+}
+
+// marshalField marshals v, the value of f, honoring the string, set, list
+// and unixtime options of its tag. Marshalers ignore them.
+func (e *attributeBuilder) marshalField(f *field, v reflect.Value) error {
+	name := f.writeName()
+	if marshalerOf(v) != nil {
+		return e.reflectToDynamoDBAttribute(name, v)
+	}
+
+	switch {
+	case f.unixtime:
+		t, ok := timeOf(v)
+		if !ok {
+			return fmt.Errorf("UnsupportedTypeError %#v: unixtime applies to time.Time", v.Type())
+		}
+		e.Push(NewNumericAttribute(name, strconv.FormatInt(t.Unix(), 10)))
+		return nil
+
+	case f.quoted && isNumericKind(v.Kind()):
+		s, err := numericReflectedValueString(v)
+		if err != nil {
+			return err
+		}
+		e.Push(NewStringAttribute(name, s))
+		return nil
+
+	case f.list && isListKind(v):
+		a, err := listAttribute(name, v)
+		if err != nil {
+			return err
+		}
+		e.Push(a)
+		return nil
+
+	case f.set && isListKind(v):
+		a, err := setAttribute(name, v)
+		if err != nil {
+			return err
+		}
+		if a != nil {
+			e.Push(a)
+		}
+		return nil
+	}
+	return e.reflectToDynamoDBAttribute(name, v)
+}
+
+// unmarshalField decodes a into v, the value of f. Numbers stored as
+// strings, sets and lists are all understood without options; unixtime
+// fields need theirs.
+func unmarshalField(f *field, a *Attribute, v reflect.Value) error {
+	if f.unixtime && a.Type != TYPE_NULL && unmarshalerOf(v) == nil {
+		n, err := strconv.ParseInt(a.Value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("UnmarshalTypeError (unixtime) %#v: %#v", a.Value, err)
+		}
+		t := reflect.ValueOf(time.Unix(n, 0).UTC())
+		switch {
+		case v.Type() == timeType:
+			v.Set(t)
+		case v.Kind() == reflect.Ptr && v.Type().Elem() == timeType:
+			v.Set(reflect.New(timeType))
+			v.Elem().Set(t)
+		default:
+			return fmt.Errorf("UnsupportedTypeError %#v: unixtime applies to time.Time", v.Type())
+		}
+		return nil
+	}
+	return unmarshallAttribute(a, v)
+}
+
+func timeOf(v reflect.Value) (time.Time, bool) {
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Type() != timeType {
+		return time.Time{}, false
+	}
+	return v.Interface().(time.Time), true
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isListKind reports whether v is a slice or array other than []byte.
+func isListKind(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice:
+		return v.Type().Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return true
+	}
+	return false
+}
+
+// setAttribute returns the slice v as a set, dropping duplicates, which
+// Dynamodb rejects. It returns nil for an empty slice, Dynamodb rejecting
+// empty sets too.
+func setAttribute(name string, v reflect.Value) (*Attribute, error) {
+	elem := v.Type().Elem()
+	typ := TYPE_NUMBER_SET
+	switch {
+	case elem.Kind() == reflect.String:
+		typ = TYPE_STRING_SET
+	case elem.Kind() == reflect.Slice && elem.Elem().Kind() == reflect.Uint8:
+		typ = TYPE_BINARY_SET
+	case !isNumericKind(elem.Kind()):
+		return nil, fmt.Errorf("UnsupportedTypeError %#v: sets hold strings, numbers or []byte", v.Type())
+	}
+
+	if v.Len() == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, v.Len())
+	values := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		var s string
+		switch typ {
+		case TYPE_STRING_SET:
+			s = v.Index(i).String()
+		case TYPE_BINARY_SET:
+			s = base64.StdEncoding.EncodeToString(v.Index(i).Bytes())
+		default:
+			var err error
+			if s, err = numericReflectedValueString(v.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		if !seen[s] {
+			seen[s] = true
+			values = append(values, s)
+		}
+	}
+	return &Attribute{Type: typ, Name: name, SetValues: values}, nil
+}
+
+// listAttribute returns the slice or array v as a list.
+func listAttribute(name string, v reflect.Value) (*Attribute, error) {
+	values := make([]Attribute, v.Len())
+	for i := range values {
+		a, err := documentAttribute("", v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		values[i] = *a
+	}
+	return NewListAttribute(name, values), nil
+}
+
+// documentAttribute converts v to a list element or map member: strings,
+// numbers and []byte to S, N and B, slices and arrays to lists, and
+// structs and maps keyed by strings to maps.
+func documentAttribute(name string, v reflect.Value) (*Attribute, error) {
+	if m := marshalerOf(v); m != nil {
+		a, err := m.MarshalDynamoDB()
+		if err != nil || a == nil {
+			return NewNullAttribute(name), err
+		}
+		c := *a
+		c.Name = name
+		return &c, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return NewNullAttribute(name), nil
+		}
+		return documentAttribute(name, v.Elem())
+	case reflect.String:
+		return NewStringAttribute(name, v.String()), nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return NewBytesAttribute(name, v.Bytes()), nil
+		}
+		return listAttribute(name, v)
+	case reflect.Struct:
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		members, err := MarshalAttributes(p.Interface())
+		if err != nil {
+			return nil, err
+		}
+		return NewMapAttribute(name, members), nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		members := make([]Attribute, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			a, err := documentAttribute(key.String(), v.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, *a)
+		}
+		return NewMapAttribute(name, members), nil
+	}
+	if isNumericKind(v.Kind()) {
+		s, err := numericReflectedValueString(v)
+		if err != nil {
+			return nil, err
+		}
+		return NewNumericAttribute(name, s), nil
+	}
+	return nil, fmt.Errorf("UnsupportedTypeError %#v", v.Type())
+}
+
+// unmarshalDocument decodes the list or map a into slices, arrays,
+// structs and maps keyed by strings, reporting false for other targets.
+// Elements decode into interface{} as NativeValue converts them.
+func unmarshalDocument(a *Attribute, v reflect.Value) (bool, error) {
+	switch {
+	case v.Kind() == reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return true, unmarshallAttribute(a, v.Elem())
+
+	case v.Kind() == reflect.Interface && v.NumMethod() == 0:
+		v.Set(reflect.ValueOf(NativeValue(a)))
+		return true, nil
+
+	case a.Type == TYPE_LIST && v.Kind() == reflect.Slice:
+		list := reflect.MakeSlice(v.Type(), len(a.ListValues), len(a.ListValues))
+		for i := range a.ListValues {
+			if err := unmarshalElement(&a.ListValues[i], list.Index(i)); err != nil {
+				return true, err
+			}
+		}
+		v.Set(list)
+		return true, nil
+
+	case a.Type == TYPE_LIST && v.Kind() == reflect.Array:
+		for i := 0; i < v.Len() && i < len(a.ListValues); i++ {
+			if err := unmarshalElement(&a.ListValues[i], v.Index(i)); err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+
+	case a.Type == TYPE_MAP && v.Kind() == reflect.Struct && v.CanAddr():
+		return true, UnmarshalAttributes(&a.MapValues, v.Addr().Interface())
+
+	case a.Type == TYPE_MAP && v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		m := reflect.MakeMapWithSize(v.Type(), len(a.MapValues))
+		for key, member := range a.MapValues {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := unmarshalElement(member, elem); err != nil {
+				return true, err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+		v.Set(m)
+		return true, nil
+	}
+	return false, nil
+}
+
+// unmarshalElement decodes a list element or map member. Unlike top level
+// attributes, these are never JSON encoded by the marshaler, so that
+// interface{} receives their native value.
+func unmarshalElement(a *Attribute, v reflect.Value) error {
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		if native := NativeValue(a); native != nil {
+			v.Set(reflect.ValueOf(native))
+		}
+		return nil
+	}
+	return unmarshallAttribute(a, v)
+}
+
+// marshalInline adds the members of v, a map tagged inline, as attributes
+// of their own, in key order.
+func (e *attributeBuilder) marshalInline(v reflect.Value) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("UnsupportedTypeError %#v: inline maps are keyed by strings", v.Type())
+	}
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, key := range keys {
+		member := v.MapIndex(key)
+		if a, ok := member.Interface().(*Attribute); ok {
+			if a != nil {
+				c := *a
+				c.Name = key.String()
+				e.Push(&c)
+			}
+			continue
+		}
+		a, err := documentAttribute(key.String(), member)
+		if err != nil {
+			return err
+		}
+		e.Push(a)
+	}
+	return nil
+}
+
+// unmarshalInline stores into v, a map tagged inline, the attributes not
+// read by any of fields. A map[string]*Attribute receives them as is.
+func unmarshalInline(fields []field, attributes map[string]*Attribute, v reflect.Value) error {
+	t := v.Type()
+	if t.Key().Kind() != reflect.String {
+		return fmt.Errorf("UnsupportedTypeError %#v: inline maps are keyed by strings", t)
+	}
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if f.inlineMap {
+			continue
+		}
+		known[f.name], known[f.writeName()] = true, true
+		for _, alias := range f.aliases {
+			known[alias] = true
+		}
+	}
+
+	m := reflect.MakeMap(t)
+	for name, a := range attributes {
+		if known[name] || a == nil {
+			continue
+		}
+		elem := reflect.New(t.Elem()).Elem()
+		if t.Elem() == reflect.TypeOf(a) {
+			elem.Set(reflect.ValueOf(a))
+		} else if err := unmarshalElement(a, elem); err != nil {
+			return err
+		}
+		m.SetMapIndex(reflect.ValueOf(name).Convert(t.Key()), elem)
+	}
+	if m.Len() > 0 {
+		v.Set(m)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package dynamodb_test
+
+import (
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type TagOptionsSuite struct{}
+
+var _ = check.Suite(&TagOptionsSuite{})
+
+type line struct {
+	SKU string `dynamodb:"sku"`
+	Qty int    `dynamodb:"qty"`
+}
+
+type tagged struct {
+	ID       string     `dynamodb:"id"`
+	Count    int        `dynamodb:"count,omitempty"`
+	Flag     bool       `dynamodb:"flag,omitempty"`
+	Version  int64      `dynamodb:"version,string"`
+	Tags     []string   `dynamodb:"tags,set"`
+	Keys     [][]byte   `dynamodb:"keys,set"`
+	Names    []string   `dynamodb:"names,list"`
+	Lines    []line     `dynamodb:"lines,list"`
+	Created  time.Time  `dynamodb:"created,unixtime"`
+	Expires  *time.Time `dynamodb:"expires,unixtime,omitempty"`
+	Internal string     `dynamodb:"-"`
+}
+
+func (s *TagOptionsSuite) TestMarshal(c *check.C) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	in := &tagged{
+		ID:       "t1",
+		Version:  7,
+		Tags:     []string{"a", "b", "a"},
+		Keys:     [][]byte{{1}, {1}},
+		Names:    []string{"x", "x"},
+		Lines:    []line{{"s1", 2}},
+		Created:  created,
+		Internal: "secret",
+	}
+	attrs, err := dynamodb.MarshalAttributes(in)
+	c.Assert(err, check.IsNil)
+	c.Check(attrs, check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", "t1"),
+		*dynamodb.NewStringAttribute("version", "7"),
+		*dynamodb.NewStringSetAttribute("tags", []string{"a", "b"}),
+		*dynamodb.NewBinarySetAttribute("keys", []string{"AQ=="}),
+		*dynamodb.NewListAttribute("names", stringList("x", "x")),
+		*dynamodb.NewListAttribute("lines", []dynamodb.Attribute{
+			*dynamodb.NewMapAttribute("", []dynamodb.Attribute{
+				*dynamodb.NewStringAttribute("sku", "s1"),
+				*dynamodb.NewNumericAttribute("qty", "2"),
+			}),
+		}),
+		*dynamodb.NewNumericAttribute("created", "1577934245"),
+	})
+
+	item := make(map[string]*dynamodb.Attribute)
+	for i := range attrs {
+		item[attrs[i].Name] = &attrs[i]
+	}
+	out := &tagged{}
+	c.Assert(dynamodb.UnmarshalAttributes(&item, out), check.IsNil)
+	in.Tags, in.Keys, in.Internal = []string{"a", "b"}, [][]byte{{1}}, ""
+	c.Check(out, check.DeepEquals, in)
+
+	in.Count, in.Flag, in.Expires = 1, true, &created
+	attrs, err = dynamodb.MarshalAttributes(in)
+	c.Assert(err, check.IsNil)
+	c.Check(attrs, check.HasLen, 10)
+}
+
+func (s *TagOptionsSuite) TestInvalidOptions(c *check.C) {
+	_, err := dynamodb.MarshalAttributes(&struct {
+		T string `dynamodb:"t,unixtime"`
+	}{"now"})
+	c.Check(err, check.ErrorMatches, "UnsupportedTypeError.*unixtime.*")
+
+	_, err = dynamodb.MarshalAttributes(&struct {
+		S []line `dynamodb:"s,set"`
+	}{[]line{{}}})
+	c.Check(err, check.ErrorMatches, "UnsupportedTypeError.*sets hold.*")
+}
+
+func (s *TagOptionsSuite) TestEmptySet(c *check.C) {
+	type record struct {
+		ID     string   `dynamodb:"id"`
+		Tags   []string `dynamodb:"tags,set"`
+		Scores [0]int   `dynamodb:"scores,set"`
+	}
+	in := &record{ID: "r1", Tags: []string{}}
+	attrs, err := dynamodb.MarshalAttributes(in)
+	c.Assert(err, check.IsNil)
+	c.Check(attrs, check.DeepEquals, []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", "r1")})
+
+	attrs, err = dynamodb.MarshalAttributesWithOptions(in, &dynamodb.MarshalOptions{EmptySets: dynamodb.EmptyNull})
+	c.Assert(err, check.IsNil)
+	c.Check(attrs, check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", "r1"),
+		*dynamodb.NewNullAttribute("tags"),
+		*dynamodb.NewNullAttribute("scores"),
+	})
+
+	_, err = dynamodb.MarshalAttributesWithOptions(in, &dynamodb.MarshalOptions{EmptySets: dynamodb.EmptyError})
+	c.Check(dynamodb.IsValidationError(err), check.Equals, true)
+	c.Check(err, check.ErrorMatches, ".*Attribute tags is empty.")
+}
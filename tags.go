@@ -0,0 +1,97 @@
+package dynamodb
+
+import (
+	"encoding/json"
+)
+
+type TagT struct {
+	Key   string
+	Value string
+}
+
+type listTagsOfResourceResponse struct {
+	Tags      []TagT
+	NextToken string
+}
+
+// TagResource adds or overwrites tags on a table (or other resource) ARN,
+// as found in TableDescriptionT.TableArn.
+func (s *Server) TagResource(resourceArn string, tags []TagT, isRetry bool) error {
+	q := NewEmptyQuery()
+	q.AddResourceArn(resourceArn)
+	q.AddTags(tags)
+
+	_, err := s.queryServer(target("TagResource"), q, isRetry)
+	return err
+}
+
+func (s *Server) UntagResource(resourceArn string, tagKeys []string, isRetry bool) error {
+	q := NewEmptyQuery()
+	q.AddResourceArn(resourceArn)
+	q.AddTagKeys(tagKeys)
+
+	_, err := s.queryServer(target("UntagResource"), q, isRetry)
+	return err
+}
+
+// ListTagsOfResource returns every tag of the resource, following
+// pagination.
+func (s *Server) ListTagsOfResource(resourceArn string, isRetry bool) ([]TagT, error) {
+	var tags []TagT
+	var nextToken string
+	for {
+		q := NewEmptyQuery()
+		q.AddResourceArn(resourceArn)
+		q.AddNextToken(nextToken)
+
+		jsonResponse, err := s.queryServer(target("ListTagsOfResource"), q, isRetry)
+		if err != nil {
+			return nil, err
+		}
+
+		var r listTagsOfResourceResponse
+		err = json.Unmarshal(jsonResponse, &r)
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, r.Tags...)
+		nextToken = r.NextToken
+		if nextToken == "" {
+			return tags, nil
+		}
+	}
+}
+
+// TagTable tags the table, describing it first to learn its ARN.
+func (t *Table) TagTable(tags []TagT, isRetry bool) error {
+	arn, err := t.arn(isRetry)
+	if err != nil {
+		return err
+	}
+	return t.Server.TagResource(arn, tags, isRetry)
+}
+
+func (t *Table) UntagTable(tagKeys []string, isRetry bool) error {
+	arn, err := t.arn(isRetry)
+	if err != nil {
+		return err
+	}
+	return t.Server.UntagResource(arn, tagKeys, isRetry)
+}
+
+func (t *Table) ListTags(isRetry bool) ([]TagT, error) {
+	arn, err := t.arn(isRetry)
+	if err != nil {
+		return nil, err
+	}
+	return t.Server.ListTagsOfResource(arn, isRetry)
+}
+
+func (t *Table) arn(isRetry bool) (string, error) {
+	desc, err := t.DescribeTable(isRetry)
+	if err != nil {
+		return "", err
+	}
+	return desc.TableArn, nil
+}
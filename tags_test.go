@@ -0,0 +1,59 @@
+package dynamodb_test
+
+import (
+	"fmt"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type TagsSuite struct {
+	table *dynamodb.Table
+}
+
+var _ = check.Suite(&TagsSuite{})
+
+func (s *TagsSuite) SetUpTest(c *check.C) {
+	s.table = newFakeTable(c, "users", idKey{})
+}
+
+func (s *TagsSuite) TestRoundTrip(c *check.C) {
+	tags, err := s.table.ListTags(false)
+	c.Assert(err, check.IsNil)
+	c.Check(tags, check.HasLen, 0)
+
+	c.Assert(s.table.TagTable([]dynamodb.TagT{{Key: "env", Value: "dev"}, {Key: "team", Value: "core"}}, false), check.IsNil)
+	c.Assert(s.table.TagTable([]dynamodb.TagT{{Key: "env", Value: "prod"}}, false), check.IsNil)
+	tags, err = s.table.ListTags(false)
+	c.Assert(err, check.IsNil)
+	c.Check(tags, check.DeepEquals, []dynamodb.TagT{{Key: "env", Value: "prod"}, {Key: "team", Value: "core"}})
+
+	c.Assert(s.table.UntagTable([]string{"team", "missing"}, false), check.IsNil)
+	tags, err = s.table.ListTags(false)
+	c.Assert(err, check.IsNil)
+	c.Check(tags, check.DeepEquals, []dynamodb.TagT{{Key: "env", Value: "prod"}})
+}
+
+func (s *TagsSuite) TestPagination(c *check.C) {
+	var want []dynamodb.TagT
+	for i := 0; i < 25; i++ {
+		want = append(want, dynamodb.TagT{Key: fmt.Sprintf("k%02d", i), Value: fmt.Sprint(i)})
+	}
+	c.Assert(s.table.TagTable(want, false), check.IsNil)
+
+	tags, err := s.table.ListTags(false)
+	c.Assert(err, check.IsNil)
+	c.Check(tags, check.DeepEquals, want)
+}
+
+func (s *TagsSuite) TestErrors(c *check.C) {
+	err := s.table.TagTable(nil, false)
+	c.Check(dynamodb.IsValidationError(err), check.Equals, true)
+
+	err = s.table.Server.TagResource("arn:aws:dynamodb:fake:000000000000:table/missing", []dynamodb.TagT{{Key: "env", Value: "dev"}}, false)
+	c.Check(dynamodb.ErrorCode(err), check.Equals, dynamodb.ResourceNotFoundException)
+
+	missing := s.table.Server.NewTable("missing", s.table.Key)
+	_, err = missing.ListTags(false)
+	c.Check(dynamodb.ErrorCode(err), check.Equals, dynamodb.ResourceNotFoundException)
+}
@@ -0,0 +1,109 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	BILLING_MODE_PROVISIONED     = "PROVISIONED"
+	BILLING_MODE_PAY_PER_REQUEST = "PAY_PER_REQUEST"
+)
+
+// How long UpdateThroughput and SwitchToOnDemand wait for a table which is
+// being updated to become ACTIVE again.
+var UpdateTableTimeout = 10 * time.Minute
+
+// UpdateThroughput sets the table's provisioned capacity, switching it to
+// PROVISIONED billing if needed. Since Dynamodb rejects UpdateTable while a
+// previous update is in progress, it first waits for the table to be
+// ACTIVE and retries with backoff on ResourceInUseException. It is a no-op
+// when the table already has the requested throughput.
+func (t *Table) UpdateThroughput(read, write int64) (*TableDescriptionT, error) {
+	return t.UpdateThroughputContext(context.Background(), read, write)
+}
+
+// UpdateThroughputContext is UpdateThroughput with a context, which stops
+// the waits and requests when done.
+func (t *Table) UpdateThroughputContext(ctx context.Context, read, write int64) (*TableDescriptionT, error) {
+	if read <= 0 || write <= 0 {
+		return nil, errors.New("Read and write capacity units must be positive.")
+	}
+
+	return t.updateWhenActive(ctx, func(desc *TableDescriptionT) *Query {
+		provisioned := desc.BillingModeSummary.BillingMode != BILLING_MODE_PAY_PER_REQUEST
+		if provisioned &&
+			desc.ProvisionedThroughput.ReadCapacityUnits == read &&
+			desc.ProvisionedThroughput.WriteCapacityUnits == write {
+			return nil
+		}
+
+		q := NewQuery(t)
+		if !provisioned {
+			q.AddBillingMode(BILLING_MODE_PROVISIONED)
+		}
+		q.AddProvisionedThroughput(read, write)
+		return q
+	})
+}
+
+// SwitchToOnDemand moves the table to PAY_PER_REQUEST billing. It is a
+// no-op when the table is already on demand.
+func (t *Table) SwitchToOnDemand() (*TableDescriptionT, error) {
+	return t.SwitchToOnDemandContext(context.Background())
+}
+
+// SwitchToOnDemandContext is SwitchToOnDemand with a context, which stops
+// the waits and requests when done.
+func (t *Table) SwitchToOnDemandContext(ctx context.Context) (*TableDescriptionT, error) {
+	return t.updateWhenActive(ctx, func(desc *TableDescriptionT) *Query {
+		if desc.BillingModeSummary.BillingMode == BILLING_MODE_PAY_PER_REQUEST {
+			return nil
+		}
+
+		q := NewQuery(t)
+		q.AddBillingMode(BILLING_MODE_PAY_PER_REQUEST)
+		return q
+	})
+}
+
+// updateWhenActive waits for the table to be ACTIVE, then sends the
+// UpdateTable request built from its current description. A nil request
+// means there is nothing to change. The context's error is returned when
+// it is done first.
+func (t *Table) updateWhenActive(ctx context.Context, build func(*TableDescriptionT) *Query) (*TableDescriptionT, error) {
+	deadline := time.Now().Add(UpdateTableTimeout)
+	backoff := time.Second
+	for {
+		var desc *TableDescriptionT
+		err := t.WaitUntilContext(ctx, deadline.Sub(time.Now()), func(d *TableDescriptionT) (bool, error) {
+			desc = d
+			return d.TableStatus == "ACTIVE", nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		q := build(desc)
+		if q == nil {
+			return desc, nil
+		}
+
+		updated, err := t.Server.updateTableContext(ctx, q, false)
+		if ErrorCode(err) != ResourceInUseException {
+			return updated, err
+		}
+
+		// Someone else started an update in between, try again later.
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, ErrWaitTimeout
+		}
+		if !sleepContext(ctx, backoff) {
+			return nil, ctx.Err()
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package dynamodb_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/bluele/dynamodb/dynamodbtest"
+	"gopkg.in/check.v1"
+)
+
+type ThroughputSuite struct {
+	table   *dynamodb.Table
+	updates int
+	inUse   bool // whether UpdateTable fails with ResourceInUseException
+}
+
+var _ = check.Suite(&ThroughputSuite{})
+
+func (s *ThroughputSuite) SetUpTest(c *check.C) {
+	s.updates, s.inUse = 0, false
+	server, _ := dynamodbtest.NewServer(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			if req.Operation == "UpdateTable" {
+				s.updates++
+				if s.inUse {
+					return nil, &dynamodb.Error{StatusCode: 400, Code: dynamodb.ResourceInUseException}
+				}
+			}
+			return next(req)
+		}
+	})
+	s.table = createTable(c, server, tableSchema(c, "users", idKey{}))
+}
+
+func (s *ThroughputSuite) TestUpdateThroughput(c *check.C) {
+	desc, err := s.table.UpdateThroughput(5, 2)
+	c.Assert(err, check.IsNil)
+	c.Check(desc.BillingMode(), check.Equals, dynamodb.BILLING_MODE_PROVISIONED)
+	c.Check(desc.ProvisionedThroughput.ReadCapacityUnits, check.Equals, int64(5))
+	c.Check(desc.ProvisionedThroughput.WriteCapacityUnits, check.Equals, int64(2))
+
+	desc, err = s.table.DescribeTable(false)
+	c.Assert(err, check.IsNil)
+	c.Check(desc.BillingMode(), check.Equals, dynamodb.BILLING_MODE_PROVISIONED)
+	c.Check(desc.ProvisionedThroughput.ReadCapacityUnits, check.Equals, int64(5))
+
+	// Unchanged throughput is not sent again.
+	_, err = s.table.UpdateThroughput(5, 2)
+	c.Assert(err, check.IsNil)
+	c.Check(s.updates, check.Equals, 1)
+
+	desc, err = s.table.UpdateThroughput(10, 2)
+	c.Assert(err, check.IsNil)
+	c.Check(desc.ProvisionedThroughput.ReadCapacityUnits, check.Equals, int64(10))
+	c.Check(s.updates, check.Equals, 2)
+
+	_, err = s.table.UpdateThroughput(0, 2)
+	c.Check(err, check.ErrorMatches, "Read and write capacity units must be positive.")
+	c.Check(s.updates, check.Equals, 2)
+}
+
+func (s *ThroughputSuite) TestSwitchToOnDemand(c *check.C) {
+	// Tables made by TableSchemaFromStruct are on demand already.
+	desc, err := s.table.SwitchToOnDemand()
+	c.Assert(err, check.IsNil)
+	c.Check(desc.BillingMode(), check.Equals, dynamodb.BILLING_MODE_PAY_PER_REQUEST)
+	c.Check(s.updates, check.Equals, 0)
+
+	_, err = s.table.UpdateThroughput(5, 5)
+	c.Assert(err, check.IsNil)
+	desc, err = s.table.SwitchToOnDemand()
+	c.Assert(err, check.IsNil)
+	c.Check(desc.BillingMode(), check.Equals, dynamodb.BILLING_MODE_PAY_PER_REQUEST)
+	c.Check(s.updates, check.Equals, 2)
+}
+
+func (s *ThroughputSuite) TestCancelledWhileInUse(c *check.C) {
+	s.inUse = true
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The backoff after ResourceInUseException, a second, is cut short.
+	_, err := s.table.UpdateThroughputContext(ctx, 5, 2)
+	c.Check(err, check.Equals, context.DeadlineExceeded)
+	c.Check(s.updates, check.Equals, 1)
+}
@@ -0,0 +1,91 @@
+package dynamodb
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// String in UTC with second precision, e.g. "2006-01-02T15:04:05Z".
+	TIME_FORMAT_RFC3339 = "RFC3339"
+	// String in UTC with a fixed nine digit fraction, so values still sort
+	// lexically.
+	TIME_FORMAT_RFC3339_NANO = "RFC3339_NANO"
+	// Number of seconds since the Unix epoch, as Time To Live expects.
+	TIME_FORMAT_EPOCH_SECONDS = "EPOCH_SECONDS"
+	// Number of milliseconds since the Unix epoch.
+	TIME_FORMAT_EPOCH_MILLIS = "EPOCH_MILLIS"
+)
+
+const rfc3339FixedNano = "2006-01-02T15:04:05.000000000Z07:00"
+
+// Format used by NewTimeAttribute and to interpret numbers in
+// Attribute.Time.
+var DefaultTimeFormat = TIME_FORMAT_RFC3339
+
+// NewTimeAttribute stores t in DefaultTimeFormat.
+func NewTimeAttribute(name string, t time.Time) *Attribute {
+	return NewTimeAttributeFormat(name, t, DefaultTimeFormat)
+}
+
+// NewTimeAttributeFormat stores t in the given TIME_FORMAT_*. Unknown
+// formats fall back to TIME_FORMAT_RFC3339.
+func NewTimeAttributeFormat(name string, t time.Time, format string) *Attribute {
+	switch format {
+	case TIME_FORMAT_RFC3339_NANO:
+		return NewStringAttribute(name, t.UTC().Format(rfc3339FixedNano))
+	case TIME_FORMAT_EPOCH_SECONDS:
+		return NewInt64Attribute(name, t.Unix())
+	case TIME_FORMAT_EPOCH_MILLIS:
+		return NewInt64Attribute(name, t.UnixNano()/int64(time.Millisecond))
+	}
+	return NewStringAttribute(name, t.UTC().Format(time.RFC3339))
+}
+
+// Time parses a time stored by NewTimeAttribute. Strings are read as
+// RFC 3339 with or without a fraction; numbers as epoch milliseconds when
+// DefaultTimeFormat is TIME_FORMAT_EPOCH_MILLIS and epoch seconds
+// otherwise.
+func (a *Attribute) Time() (time.Time, error) {
+	format := DefaultTimeFormat
+	if a.Type == TYPE_STRING {
+		format = TIME_FORMAT_RFC3339
+	} else if format != TIME_FORMAT_EPOCH_MILLIS {
+		format = TIME_FORMAT_EPOCH_SECONDS
+	}
+	return a.TimeFormat(format)
+}
+
+// TimeFormat parses a time stored in the given TIME_FORMAT_*.
+func (a *Attribute) TimeFormat(format string) (time.Time, error) {
+	switch format {
+	case TIME_FORMAT_EPOCH_SECONDS, TIME_FORMAT_EPOCH_MILLIS:
+		n, err := a.Int64()
+		if err != nil {
+			return time.Time{}, err
+		}
+		if format == TIME_FORMAT_EPOCH_MILLIS {
+			return time.Unix(n/1000, (n%1000)*int64(time.Millisecond)).UTC(), nil
+		}
+		return time.Unix(n, 0).UTC(), nil
+	}
+
+	if a.Type != TYPE_STRING {
+		return time.Time{}, fmt.Errorf("Attribute %s is of type %s, not a string.", a.Name, a.Type)
+	}
+	// RFC3339Nano also accepts values without a fraction.
+	t, err := time.Parse(time.RFC3339Nano, a.Value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Attribute %s: %s", a.Name, err)
+	}
+	return t, nil
+}
+
+// epochTime converts the fractional epoch seconds Dynamodb uses for
+// timestamps such as CreationDateTime.
+func epochTime(seconds float64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))).UTC()
+}
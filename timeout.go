@@ -0,0 +1,65 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrAttemptTimeout is returned (wrapped) when a single HTTP attempt takes
+// longer than Timeouts.Attempt. Such attempts are retried like throttled
+// requests.
+var ErrAttemptTimeout = errors.New("Request attempt timed out")
+
+// Timeouts bound the time spent on calls. Zero values mean no limit.
+type Timeouts struct {
+	// Establishing a TCP connection to the endpoint, 10 seconds when
+	// zero. It does not apply to Server.HTTPClient.
+	Connect time.Duration
+	// One HTTP attempt, from sending the request to reading the whole
+	// response.
+	Attempt time.Duration
+	// A whole call, retries and backoff included. A deadline on the
+	// context passed to the call applies as well.
+	Operation time.Duration
+}
+
+type timeoutsKey struct{}
+
+// WithTimeouts returns a context overriding the Server's Attempt and
+// Operation timeouts for the calls made with it. Zero values keep the
+// Server's settings.
+func WithTimeouts(ctx context.Context, timeouts Timeouts) context.Context {
+	return context.WithValue(ctx, timeoutsKey{}, timeouts)
+}
+
+// timeouts returns the timeouts in effect for a call made with ctx.
+func (s *Server) timeouts(ctx context.Context) Timeouts {
+	t := s.Timeouts
+	if override, ok := ctx.Value(timeoutsKey{}).(Timeouts); ok {
+		if override.Attempt > 0 {
+			t.Attempt = override.Attempt
+		}
+		if override.Operation > 0 {
+			t.Operation = override.Operation
+		}
+	}
+	return t
+}
+
+// withAttemptTimeout bounds one attempt; the returned function maps a
+// failure caused by the attempt deadline to ErrAttemptTimeout.
+func withAttemptTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc, func(error) error) {
+	if d <= 0 {
+		return ctx, func() {}, func(err error) error { return err }
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, d)
+	classify := func(err error) error {
+		if err != nil && ctx.Err() == nil && attemptCtx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%w after %s: %v", ErrAttemptTimeout, d, err)
+		}
+		return err
+	}
+	return attemptCtx, cancel, classify
+}
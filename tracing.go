@@ -0,0 +1,67 @@
+package dynamodb
+
+import (
+	"context"
+)
+
+// Tracer starts spans around Dynamodb calls. It is deliberately small so
+// that an OpenTelemetry (or any other) tracer can be adapted in a few
+// lines, e.g. by wrapping otel's trace.Tracer.Start and span.SetAttributes.
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced Dynamodb call.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	// End finishes the span; err is nil when the call succeeded.
+	End(err error)
+}
+
+// Span attribute keys, following the OpenTelemetry semantic conventions
+// for Dynamodb.
+const (
+	SpanAttrDBSystem         = "db.system"
+	SpanAttrDBOperation      = "db.operation"
+	SpanAttrRPCService       = "rpc.service"
+	SpanAttrTableNames       = "aws.dynamodb.table_names"
+	SpanAttrConsumedCapacity = "aws.dynamodb.consumed_capacity"
+	SpanAttrErrorCode        = "aws.dynamodb.error_code"
+	SpanAttrRequestID        = "aws.request_id"
+)
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) End(error)                        {}
+
+func (s *Server) startSpan(ctx context.Context, req *Request) (context.Context, Span) {
+	if s.Tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	ctx, span := s.Tracer.StartSpan(ctx, "DynamoDB."+req.Operation)
+	span.SetAttribute(SpanAttrDBSystem, "dynamodb")
+	span.SetAttribute(SpanAttrRPCService, "DynamoDB")
+	span.SetAttribute(SpanAttrDBOperation, req.Operation)
+	if table := requestTableName(req.Body); table != "" {
+		span.SetAttribute(SpanAttrTableNames, []string{table})
+	}
+	return ctx, span
+}
+
+func endSpan(span Span, req *Request, stats *callStats, response []byte, err error) {
+	if _, ok := span.(noopSpan); ok {
+		return
+	}
+	if stats.requestID != "" {
+		span.SetAttribute(SpanAttrRequestID, stats.requestID)
+	}
+	if units := responseCapacityUnits(response); units > 0 {
+		span.SetAttribute(SpanAttrConsumedCapacity, units)
+	}
+	if code := ErrorCode(err); code != "" {
+		span.SetAttribute(SpanAttrErrorCode, code)
+	}
+	span.End(err)
+}
@@ -0,0 +1,59 @@
+package dynamodb_test
+
+import (
+	"context"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type TracingSuite struct {
+}
+
+var _ = check.Suite(&TracingSuite{})
+
+type testSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) { s.attributes[key] = value }
+func (s *testSpan) End(err error)                              { s.err, s.ended = err, true }
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) StartSpan(ctx context.Context, name string) (context.Context, dynamodb.Span) {
+	span := &testSpan{name: name, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func (s *TracingSuite) TestSpanAttributes(c *check.C) {
+	tracer := &testTracer{}
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"})
+	server.Tracer = tracer
+	server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			return nil, &dynamodb.Error{StatusCode: 400, Code: dynamodb.ConditionalCheckFailedException}
+		}
+	})
+
+	pk := dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")}
+	table := server.NewTable("users", pk)
+	_, err := table.DeleteItem(&dynamodb.Key{HashKey: "u1"}, false)
+	c.Check(err, check.NotNil)
+
+	c.Assert(tracer.spans, check.HasLen, 1)
+	span := tracer.spans[0]
+	c.Check(span.name, check.Equals, "DynamoDB.DeleteItem")
+	c.Check(span.ended, check.Equals, true)
+	c.Check(span.err, check.Equals, err)
+	c.Check(span.attributes[dynamodb.SpanAttrDBSystem], check.Equals, "dynamodb")
+	c.Check(span.attributes[dynamodb.SpanAttrTableNames], check.DeepEquals, []string{"users"})
+	c.Check(span.attributes[dynamodb.SpanAttrErrorCode], check.Equals, dynamodb.ConditionalCheckFailedException)
+}
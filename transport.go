@@ -0,0 +1,112 @@
+package dynamodb
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Settings of the transports made by NewTransport.
+const (
+	defaultConnectTimeout      = 10 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	defaultMaxIdleConnsPerHost = 256
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+)
+
+// NewTransport returns a transport tuned for Dynamodb: HTTP/2 when the
+// endpoint offers it, and enough idle keep-alive connections that
+// concurrent calls do not keep opening new ones, unlike
+// http.DefaultTransport which keeps 2 per host. A zero connectTimeout
+// means 10 seconds.
+//
+// Every Server uses its own such transport unless HTTPClient is set;
+// NewTransport is a starting point for custom ones.
+func NewTransport(connectTimeout time.Duration) *http.Transport {
+	if connectTimeout <= 0 {
+		connectTimeout = defaultConnectTimeout
+	}
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   connectTimeout,
+			KeepAlive: defaultKeepAlive,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          defaultMaxIdleConnsPerHost,
+		MaxIdleConnsPerHost:   defaultMaxIdleConnsPerHost,
+		IdleConnTimeout:       defaultIdleConnTimeout,
+		TLSHandshakeTimeout:   defaultTLSHandshakeTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// httpClient returns the client used to reach Dynamodb: HTTPClient if
+// set, else one with a transport of the Server's own, made again when
+// the connect timeout changes.
+func (s *Server) httpClient() *http.Client {
+	if s.HTTPClient != nil {
+		return s.HTTPClient
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == nil || s.clientConnectTimeout != s.Timeouts.Connect {
+		if s.client != nil {
+			s.client.Transport.(*http.Transport).CloseIdleConnections()
+		}
+		s.client = &http.Client{Transport: NewTransport(s.Timeouts.Connect)}
+		s.clientConnectTimeout = s.Timeouts.Connect
+	}
+	return s.client
+}
+
+// A Transport sends requests in place of HTTP, to Dynamodb or to anything
+// serving its API, such as a DAX cluster through its client, so that
+// switching to it does not change call sites. RoundTrip receives the JSON
+// request of one attempt and returns the JSON response; errors of the
+// service should be *Error values so that they are classified and retried
+// as usual. Middleware, retries and timeouts apply as with HTTP.
+type Transport interface {
+	RoundTrip(req *Request) ([]byte, error)
+}
+
+// TransportFunc adapts a function to a Transport.
+type TransportFunc func(req *Request) ([]byte, error)
+
+func (f TransportFunc) RoundTrip(req *Request) ([]byte, error) {
+	return f(req)
+}
+
+// roundTrip sends one attempt through the Server's Transport. In dry-run
+// mode the unsigned request is reported instead.
+func (s *Server) roundTrip(ctx context.Context, endpoint string, target string, query string) ([]byte, error) {
+	if dryRun := s.dryRun(ctx); dryRun != nil {
+		dryRun(&SignedRequest{
+			Method: "POST",
+			URL:    endpoint + "/",
+			Header: http.Header{"X-Amz-Target": {target}},
+			Body:   []byte(query),
+		})
+		return nil, ErrDryRun
+	}
+
+	logger := s.logger()
+	logger.Log(LogDebug, "request", "target", target, "body", query)
+	body, err := s.Transport.RoundTrip(&Request{
+		Context:   ctx,
+		Operation: target[strings.LastIndex(target, ".")+1:],
+		Target:    target,
+		Endpoint:  endpoint,
+		Body:      []byte(query),
+	})
+	if err != nil {
+		logger.Log(LogError, "error calling transport", "target", target, "error", err)
+		return nil, err
+	}
+	logger.Log(LogDebug, "response", "target", target, "body", string(body))
+	return body, nil
+}
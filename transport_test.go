@@ -0,0 +1,85 @@
+package dynamodb_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type TransportSuite struct{}
+
+var _ = check.Suite(&TransportSuite{})
+
+func (s *TransportSuite) TestConnectionsAreKeptAlive(c *check.C) {
+	var connections int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Item":{"id":{"S":"u1"}}}`))
+	}))
+	ts.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: ts.URL})
+	table := server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+
+	const concurrency = 16
+	for round := 0; round < 3; round++ {
+		var wg sync.WaitGroup
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := table.GetItem(&dynamodb.Key{HashKey: "u1"}, false)
+				c.Check(err, check.IsNil)
+			}()
+		}
+		wg.Wait()
+	}
+	// http.DefaultTransport would keep only 2 of them between rounds.
+	c.Check(atomic.LoadInt32(&connections) <= concurrency, check.Equals, true)
+}
+
+func (s *TransportSuite) TestNewTransport(c *check.C) {
+	transport := dynamodb.NewTransport(0)
+	c.Check(transport.ForceAttemptHTTP2, check.Equals, true)
+	c.Check(transport.MaxIdleConnsPerHost > http.DefaultMaxIdleConnsPerHost, check.Equals, true)
+}
+
+func (s *TransportSuite) TestCustomTransport(c *check.C) {
+	var operations []string
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"})
+	server.RetryPolicy = &dynamodb.RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond}
+	server.Transport = dynamodb.TransportFunc(func(req *dynamodb.Request) ([]byte, error) {
+		operations = append(operations, req.Operation)
+		if len(operations) == 1 {
+			return nil, &dynamodb.Error{StatusCode: 400, Code: dynamodb.ThrottlingException}
+		}
+		return []byte(`{"Item":{"id":{"S":"u1"},"name":{"S":"Ann"}}}`), nil
+	})
+	table := server.NewTable("users", dynamodb.PrimaryKey{KeyAttribute: dynamodb.NewStringAttribute("id", "")})
+
+	item, err := table.GetItem(&dynamodb.Key{HashKey: "u1"}, true)
+	c.Assert(err, check.IsNil)
+	c.Check(item["name"].Value, check.Equals, "Ann")
+	c.Check(operations, check.DeepEquals, []string{"GetItem", "GetItem"})
+
+	var dryRun *dynamodb.SignedRequest
+	ctx := dynamodb.WithDryRun(context.Background(), func(req *dynamodb.SignedRequest) { dryRun = req })
+	_, err = table.PutItemWithOptions(ctx, []dynamodb.Attribute{*dynamodb.NewStringAttribute("id", "u2")}, nil)
+	c.Check(err, check.Equals, dynamodb.ErrDryRun)
+	c.Assert(dryRun, check.NotNil)
+	c.Check(dryRun.Header.Get("X-Amz-Target"), check.Equals, "DynamoDB_20120810.PutItem")
+	c.Check(operations, check.HasLen, 2)
+}
@@ -0,0 +1,65 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	TTL_STATUS_ENABLING  = "ENABLING"
+	TTL_STATUS_DISABLING = "DISABLING"
+	TTL_STATUS_ENABLED   = "ENABLED"
+	TTL_STATUS_DISABLED  = "DISABLED"
+)
+
+type TimeToLiveDescriptionT struct {
+	AttributeName    string
+	TimeToLiveStatus string
+}
+
+type describeTimeToLiveResponse struct {
+	TimeToLiveDescription TimeToLiveDescriptionT
+}
+
+// NewTTLAttribute returns a numeric attribute holding expiresAt as epoch
+// seconds, the format Dynamodb's Time To Live expects.
+func NewTTLAttribute(name string, expiresAt time.Time) *Attribute {
+	return NewTimeAttributeFormat(name, expiresAt, TIME_FORMAT_EPOCH_SECONDS)
+}
+
+func (t *Table) UpdateTimeToLive(attributeName string, enabled bool, isRetry bool) error {
+	return t.Server.UpdateTimeToLive(t.Name, attributeName, enabled, isRetry)
+}
+
+// UpdateTimeToLive enables or disables expiry of items on the given
+// attribute. Dynamodb only allows one change per table per hour.
+func (s *Server) UpdateTimeToLive(tableName string, attributeName string, enabled bool, isRetry bool) error {
+	q := NewEmptyQuery()
+	q.addTableByName(tableName)
+	q.AddTimeToLiveSpecification(attributeName, enabled)
+
+	_, err := s.queryServer(target("UpdateTimeToLive"), q, isRetry)
+	return err
+}
+
+func (t *Table) DescribeTimeToLive(isRetry bool) (*TimeToLiveDescriptionT, error) {
+	return t.Server.DescribeTimeToLive(t.Name, isRetry)
+}
+
+func (s *Server) DescribeTimeToLive(tableName string, isRetry bool) (*TimeToLiveDescriptionT, error) {
+	q := NewEmptyQuery()
+	q.addTableByName(tableName)
+
+	jsonResponse, err := s.queryServer(target("DescribeTimeToLive"), q, isRetry)
+	if err != nil {
+		return nil, err
+	}
+
+	var r describeTimeToLiveResponse
+	err = json.Unmarshal(jsonResponse, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r.TimeToLiveDescription, nil
+}
@@ -0,0 +1,64 @@
+//go:build go1.18
+
+package dynamodb
+
+import "context"
+
+// TypedTable gives typed access to the items of a table, T being the
+// struct type they are marshaled from with MarshalAttributes and
+// unmarshaled into with UnmarshalAttributes:
+//
+//	orders := NewTypedTable[Order](table)
+//	order, err := orders.Get(ctx, &Key{HashKey: "o1"})
+type TypedTable[T any] struct {
+	Table *Table
+	// Consistent makes Get use strongly consistent reads.
+	Consistent bool
+}
+
+func NewTypedTable[T any](t *Table) *TypedTable[T] {
+	return &TypedTable[T]{Table: t}
+}
+
+// Get returns the item with the given key, ErrNotFound when there is none.
+func (t *TypedTable[T]) Get(ctx context.Context, key *Key) (T, error) {
+	var item T
+	attributes, err := t.Table.getItemContext(ctx, key, t.Consistent, true)
+	if err != nil {
+		return item, err
+	}
+	err = UnmarshalAttributes(&attributes, &item)
+	return item, err
+}
+
+// Put stores item, replacing any item with the same key.
+func (t *TypedTable[T]) Put(ctx context.Context, item T) error {
+	attributes, err := MarshalAttributes(&item)
+	if err != nil {
+		return err
+	}
+	_, err = t.Table.PutItemWithOptions(ctx, attributes, &WriteOptions{IsRetry: true})
+	return err
+}
+
+// Delete deletes the item with the given key, if any.
+func (t *TypedTable[T]) Delete(ctx context.Context, key *Key) error {
+	_, err := t.Table.DeleteItemWithOptions(ctx, key, &WriteOptions{IsRetry: true})
+	return err
+}
+
+// Query returns the items matching keyConditions, reading every page as
+// Table.QueryAll does.
+func (t *TypedTable[T]) Query(ctx context.Context, keyConditions []AttributeComparison, opts *QueryOptions) ([]T, error) {
+	var items []T
+	_, err := t.Table.QueryAll(ctx, keyConditions, opts, &items)
+	return items, err
+}
+
+// Scan returns the items of the table, reading every page as
+// Table.ScanAll does.
+func (t *TypedTable[T]) Scan(ctx context.Context, opts *ScanOptions) ([]T, error) {
+	var items []T
+	_, err := t.Table.ScanAll(ctx, opts, &items)
+	return items, err
+}
@@ -0,0 +1,44 @@
+//go:build go1.18
+
+package dynamodb_test
+
+import (
+	"context"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type TypedTableSuite struct {
+	events *dynamodb.TypedTable[event]
+}
+
+var _ = check.Suite(&TypedTableSuite{})
+
+func (s *TypedTableSuite) SetUpTest(c *check.C) {
+	table := newFakeTable(c, "events", userSeqKey{})
+	s.events = dynamodb.NewTypedTable[event](table)
+}
+
+func (s *TypedTableSuite) TestCRUD(c *check.C) {
+	ctx := context.Background()
+	for _, e := range []event{{"u1", 1, "click"}, {"u1", 2, "view"}, {"u2", 1, "click"}} {
+		c.Assert(s.events.Put(ctx, e), check.IsNil)
+	}
+
+	got, err := s.events.Get(ctx, &dynamodb.Key{HashKey: "u1", RangeKey: "2"})
+	c.Assert(err, check.IsNil)
+	c.Check(got, check.DeepEquals, event{"u1", 2, "view"})
+
+	_, err = s.events.Get(ctx, &dynamodb.Key{HashKey: "u1", RangeKey: "9"})
+	c.Check(err, check.Equals, dynamodb.ErrNotFound)
+
+	items, err := s.events.Query(ctx, []dynamodb.AttributeComparison{*dynamodb.NewEqualStringAttributeComparison("user", "u1")}, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.DeepEquals, []event{{"u1", 1, "click"}, {"u1", 2, "view"}})
+
+	c.Assert(s.events.Delete(ctx, &dynamodb.Key{HashKey: "u1", RangeKey: "1"}), check.IsNil)
+	items, err = s.events.Scan(ctx, nil)
+	c.Assert(err, check.IsNil)
+	c.Check(items, check.HasLen, 2)
+}
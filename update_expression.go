@@ -0,0 +1,183 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// UpdateExpression builds an update expression along with its
+// placeholders, for UpdateItemWithOptions. Attributes are designated by
+// document paths, see Placeholders.Path, so that nested documents can be
+// updated in part:
+//
+//	u := NewUpdateExpression()
+//	u.Set("status", "shipped")
+//	u.Set("profile.settings.theme", "dark")
+//	u.ListAppend("events", *NewStringAttribute("", "shipped"))
+//	opts := &WriteOptions{ConditionExpression: "attribute_exists(" + u.Path("profile.settings") + ")"}
+//	if err := u.ApplyWrite(opts); err != nil { ... }
+//	t.UpdateItemWithOptions(ctx, key, nil, "", opts)
+type UpdateExpression struct {
+	p      *Placeholders
+	set    []string
+	remove []string
+	add    []string
+	delete []string
+}
+
+func NewUpdateExpression() *UpdateExpression {
+	return &UpdateExpression{p: NewPlaceholders()}
+}
+
+// Set sets the attribute at path to v, any value Placeholders.Value
+// accepts. The map or list holding it must exist.
+func (u *UpdateExpression) Set(path string, v interface{}) *UpdateExpression {
+	u.set = append(u.set, u.p.Path(path)+" = "+u.p.Value(v))
+	return u
+}
+
+// Remove removes the attribute at path.
+func (u *UpdateExpression) Remove(path string) *UpdateExpression {
+	u.remove = append(u.remove, u.p.Path(path))
+	return u
+}
+
+// Add adds v to the number at path, or the members of the set v to the
+// set at path, creating the attribute when missing.
+func (u *UpdateExpression) Add(path string, v interface{}) *UpdateExpression {
+	u.add = append(u.add, u.p.Path(path)+" "+u.p.Value(v))
+	return u
+}
+
+// Delete removes the members of the set v from the set at path.
+func (u *UpdateExpression) Delete(path string, v interface{}) *UpdateExpression {
+	u.delete = append(u.delete, u.p.Path(path)+" "+u.p.Value(v))
+	return u
+}
+
+// ListAppend adds values at the end of the list attribute, which is
+// created when missing.
+func (u *UpdateExpression) ListAppend(path string, values ...Attribute) *UpdateExpression {
+	list, valuesList := u.listOperands(path, values)
+	u.set = append(u.set, u.p.Path(path)+" = list_append("+list+", "+valuesList+")")
+	return u
+}
+
+// ListPrepend adds values at the start of the list attribute, which is
+// created when missing.
+func (u *UpdateExpression) ListPrepend(path string, values ...Attribute) *UpdateExpression {
+	list, valuesList := u.listOperands(path, values)
+	u.set = append(u.set, u.p.Path(path)+" = list_append("+valuesList+", "+list+")")
+	return u
+}
+
+// listOperands returns the operands of list_append: the list attribute,
+// or an empty list when missing, and the list of values.
+func (u *UpdateExpression) listOperands(path string, values []Attribute) (string, string) {
+	list := "if_not_exists(" + u.p.Path(path) + ", " + u.p.Value(*NewListAttribute("", []Attribute{})) + ")"
+	return list, u.p.Value(*NewListAttribute("", values))
+}
+
+// RemoveListElements removes the elements of the list attribute at the
+// given indexes, as of before the update. Indexes past the end of the
+// list are ignored.
+func (u *UpdateExpression) RemoveListElements(path string, indexes ...int) *UpdateExpression {
+	ph := u.p.Path(path)
+	for _, i := range indexes {
+		u.remove = append(u.remove, ph+"["+strconv.Itoa(i)+"]")
+	}
+	return u
+}
+
+// Path returns the placeholders for a document path, for use in a
+// condition expression sharing the names and values of u.
+func (u *UpdateExpression) Path(path string) string {
+	return u.p.Path(path)
+}
+
+// Value returns a new placeholder bound to v, for use in a condition
+// expression sharing the names and values of u.
+func (u *UpdateExpression) Value(v interface{}) string {
+	return u.p.Value(v)
+}
+
+// Empty reports whether no action was added.
+func (u *UpdateExpression) Empty() bool {
+	return len(u.set) == 0 && len(u.remove) == 0 && len(u.add) == 0 && len(u.delete) == 0
+}
+
+// String returns the expression, e.g. "SET #n0 = :v0 REMOVE #n1".
+func (u *UpdateExpression) String() string {
+	var clauses []string
+	if len(u.set) > 0 {
+		clauses = append(clauses, "SET "+strings.Join(u.set, ", "))
+	}
+	if len(u.remove) > 0 {
+		clauses = append(clauses, "REMOVE "+strings.Join(u.remove, ", "))
+	}
+	if len(u.add) > 0 {
+		clauses = append(clauses, "ADD "+strings.Join(u.add, ", "))
+	}
+	if len(u.delete) > 0 {
+		clauses = append(clauses, "DELETE "+strings.Join(u.delete, ", "))
+	}
+	return strings.Join(clauses, " ")
+}
+
+// Names returns ExpressionAttributeNames; see Placeholders.Names.
+func (u *UpdateExpression) Names() map[string]string {
+	return u.p.Names()
+}
+
+// Values returns ExpressionAttributeValues; see Placeholders.Values.
+func (u *UpdateExpression) Values() []Attribute {
+	return u.p.Values()
+}
+
+// Err returns the first error met converting a value.
+func (u *UpdateExpression) Err() error {
+	return u.p.Err()
+}
+
+// ApplyWrite sets the expression, its names and its values on opts,
+// merging with any names and values already present.
+func (u *UpdateExpression) ApplyWrite(opts *WriteOptions) error {
+	if err := u.Err(); err != nil {
+		return err
+	}
+	if u.Empty() {
+		return errors.New("The update expression is empty.")
+	}
+	opts.UpdateExpression = u.String()
+	u.p.ApplyWrite(opts)
+	return nil
+}
+
+// AppendToList appends values to the list at path in the item, creating
+// either when missing. A retried append may add the values twice.
+func (t *Table) AppendToList(key *Key, path string, values []Attribute, isRetry bool) error {
+	return t.updateExpression(key, NewUpdateExpression().ListAppend(path, values...), isRetry)
+}
+
+// PrependToList is AppendToList adding values at the start of the list.
+func (t *Table) PrependToList(key *Key, path string, values []Attribute, isRetry bool) error {
+	return t.updateExpression(key, NewUpdateExpression().ListPrepend(path, values...), isRetry)
+}
+
+// RemoveFromList removes the elements of the list at path in the item at
+// the given indexes, as of before the removal. A retried removal may
+// remove the elements which followed them.
+func (t *Table) RemoveFromList(key *Key, path string, indexes []int, isRetry bool) error {
+	return t.updateExpression(key, NewUpdateExpression().RemoveListElements(path, indexes...), isRetry)
+}
+
+func (t *Table) updateExpression(key *Key, u *UpdateExpression, isRetry bool) error {
+	opts := &WriteOptions{IsRetry: isRetry}
+	if err := u.ApplyWrite(opts); err != nil {
+		return err
+	}
+	_, err := t.UpdateItemWithOptions(context.Background(), key, nil, "", opts)
+	return err
+}
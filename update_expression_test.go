@@ -0,0 +1,140 @@
+package dynamodb_test
+
+import (
+	"context"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type UpdateExpressionSuite struct {
+	table *dynamodb.Table
+	key   *dynamodb.Key
+}
+
+var _ = check.Suite(&UpdateExpressionSuite{})
+
+func (s *UpdateExpressionSuite) SetUpTest(c *check.C) {
+	s.table = newFakeTable(c, "orders", idKey{})
+	s.key = &dynamodb.Key{HashKey: "o1"}
+	_, err := s.table.PutItemWithOptions(context.Background(), append(s.table.Key.Clone("o1", ""), *dynamodb.NewStringAttribute("status", "new")), nil)
+	c.Assert(err, check.IsNil)
+}
+
+func stringList(values ...string) []dynamodb.Attribute {
+	list := make([]dynamodb.Attribute, len(values))
+	for i, v := range values {
+		list[i] = *dynamodb.NewStringAttribute("", v)
+	}
+	return list
+}
+
+func (s *UpdateExpressionSuite) events(c *check.C) []dynamodb.Attribute {
+	item, err := s.table.GetItem(s.key, false)
+	c.Assert(err, check.IsNil)
+	c.Assert(item["events"], check.NotNil)
+	c.Check(item["events"].Type, check.Equals, dynamodb.TYPE_LIST)
+	return item["events"].ListValues
+}
+
+func (s *UpdateExpressionSuite) TestExpression(c *check.C) {
+	u := dynamodb.NewUpdateExpression().
+		Set("status", "shipped").
+		ListAppend("events", stringList("shipped")...).
+		RemoveListElements("events", 0, 2).
+		Remove("note")
+	c.Check(u.String(), check.Equals, "SET #n0 = :v0, #n1 = list_append(if_not_exists(#n1, :v1), :v2) REMOVE #n1[0], #n1[2], #n2")
+	c.Check(u.Names(), check.DeepEquals, map[string]string{"#n0": "status", "#n1": "events", "#n2": "note"})
+	c.Check(u.Values(), check.DeepEquals, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute(":v0", "shipped"),
+		*dynamodb.NewListAttribute(":v1", []dynamodb.Attribute{}),
+		*dynamodb.NewListAttribute(":v2", stringList("shipped")),
+	})
+
+	opts := &dynamodb.WriteOptions{ExpressionAttributeNames: map[string]string{"#k": "id"}}
+	c.Assert(u.ApplyWrite(opts), check.IsNil)
+	c.Check(opts.UpdateExpression, check.Equals, u.String())
+	c.Check(opts.ExpressionAttributeNames, check.HasLen, 4)
+	c.Check(opts.ExpressionAttributeValues, check.HasLen, 3)
+
+	c.Check(dynamodb.NewUpdateExpression().ApplyWrite(opts), check.ErrorMatches, "The update expression is empty.")
+	c.Check(dynamodb.NewUpdateExpression().Set("a", struct{}{}).ApplyWrite(opts), check.NotNil)
+}
+
+func (s *UpdateExpressionSuite) TestLists(c *check.C) {
+	// The list is created by the first append.
+	c.Assert(s.table.AppendToList(s.key, "events", stringList("a", "b"), false), check.IsNil)
+	c.Assert(s.table.AppendToList(s.key, "events", stringList("c"), false), check.IsNil)
+	c.Assert(s.table.PrependToList(s.key, "events", stringList("z"), false), check.IsNil)
+	c.Check(s.events(c), check.DeepEquals, stringList("z", "a", "b", "c"))
+
+	// Indexes are those before the removal.
+	c.Assert(s.table.RemoveFromList(s.key, "events", []int{1, 3, 9}, false), check.IsNil)
+	c.Check(s.events(c), check.DeepEquals, stringList("z", "b"))
+
+	// Lists are written and read back by the other operations too.
+	item := []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("id", "o2"),
+		*dynamodb.NewListAttribute("lines", []dynamodb.Attribute{
+			*dynamodb.NewNumericAttribute("", "2"),
+			*dynamodb.NewListAttribute("", stringList("nested")),
+		}),
+	}
+	_, err := s.table.PutItemWithOptions(context.Background(), item, nil)
+	c.Assert(err, check.IsNil)
+	got, err := s.table.GetItem(&dynamodb.Key{HashKey: "o2"}, false)
+	c.Assert(err, check.IsNil)
+	c.Check(got["lines"], check.DeepEquals, &item[1])
+}
+
+func (s *UpdateExpressionSuite) TestDocumentPaths(c *check.C) {
+	profile := dynamodb.NewMapAttribute("profile", []dynamodb.Attribute{
+		*dynamodb.NewMapAttribute("settings", []dynamodb.Attribute{
+			*dynamodb.NewStringAttribute("theme", "light"),
+			*dynamodb.NewStringAttribute("lang", "en"),
+		}),
+		*dynamodb.NewListAttribute("addresses", []dynamodb.Attribute{
+			*dynamodb.NewMapAttribute("", []dynamodb.Attribute{*dynamodb.NewStringAttribute("city", "Paris")}),
+		}),
+	})
+	_, err := s.table.UpdateItemWithOptions(context.Background(), s.key, []dynamodb.Attribute{*profile}, "", nil)
+	c.Assert(err, check.IsNil)
+
+	u := dynamodb.NewUpdateExpression().
+		Set("profile.settings.theme", "dark").
+		Set("profile.addresses[0].zip", "75001").
+		Remove("profile.settings.lang").
+		ListAppend("profile.tags", stringList("vip")...)
+	opts := &dynamodb.WriteOptions{ConditionExpression: "attribute_exists(" + u.Path("profile.settings") + ")"}
+	c.Assert(u.ApplyWrite(opts), check.IsNil)
+	c.Check(opts.ExpressionAttributeNames, check.HasLen, 7)
+	_, err = s.table.UpdateItemWithOptions(context.Background(), s.key, nil, "", opts)
+	c.Assert(err, check.IsNil)
+
+	item, err := s.table.GetItem(s.key, false)
+	c.Assert(err, check.IsNil)
+	c.Check(item["status"].Value, check.Equals, "new")
+	got := item["profile"]
+	c.Assert(got.Type, check.Equals, dynamodb.TYPE_MAP)
+	settings := got.MapValues["settings"]
+	c.Check(settings.MapValues, check.DeepEquals, map[string]*dynamodb.Attribute{
+		"theme": dynamodb.NewStringAttribute("theme", "dark"),
+	})
+	c.Check(got.MapValues["addresses"].ListValues[0].MapValues["zip"].Value, check.Equals, "75001")
+	c.Check(got.MapValues["addresses"].ListValues[0].MapValues["city"].Value, check.Equals, "Paris")
+	c.Check(got.MapValues["tags"].ListValues, check.DeepEquals, stringList("vip"))
+
+	// The condition sees the document as stored.
+	u = dynamodb.NewUpdateExpression().Set("profile.settings.theme", "light")
+	opts = &dynamodb.WriteOptions{ConditionExpression: u.Path("profile.settings.theme") + " = " + u.Value("blue")}
+	c.Assert(u.ApplyWrite(opts), check.IsNil)
+	_, err = s.table.UpdateItemWithOptions(context.Background(), s.key, nil, "", opts)
+	c.Check(dynamodb.IsConditionalCheckFailed(err), check.Equals, true)
+
+	// Intermediate documents must exist.
+	u = dynamodb.NewUpdateExpression().Set("profile.missing.theme", "dark")
+	opts = &dynamodb.WriteOptions{}
+	c.Assert(u.ApplyWrite(opts), check.IsNil)
+	_, err = s.table.UpdateItemWithOptions(context.Background(), s.key, nil, "", opts)
+	c.Check(dynamodb.IsValidationError(err), check.Equals, true)
+}
@@ -0,0 +1,171 @@
+package dynamodb
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// Maximum total size of the items in one BatchWriteItem call.
+const maxBatchWriteSize = 16 * 1024 * 1024
+
+// ValidationError is returned, before anything is sent, for requests
+// Dynamodb would reject with a ValidationException: missing or mistyped
+// key attributes, items over MaxItemSize, empty attribute names and
+// batches over their limits.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func validationErrorf(format string, args ...interface{}) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
+
+// validateKey checks that key has a value, of the right type, for every
+// key attribute of the table.
+func (t *Table) validateKey(key *Key) error {
+	if t.Key.KeyAttribute == nil {
+		return nil // schema unknown
+	}
+	if key == nil {
+		return validationErrorf("A key is required for table %s.", t.Name)
+	}
+	if err := validateKeyValue(t.Key.KeyAttribute, key.HashKey); err != nil {
+		return err
+	}
+	if t.Key.HasRange() {
+		return validateKeyValue(t.Key.RangeAttribute, key.RangeKey)
+	}
+	return nil
+}
+
+func validateKeyValue(attribute *Attribute, value string) error {
+	if value == "" {
+		return validationErrorf("Key attribute %s cannot be empty.", attribute.Name)
+	}
+	switch attribute.Type {
+	case TYPE_NUMBER:
+		if err := checkNumber(value); err != nil {
+			return validationErrorf("Key attribute %s: %s", attribute.Name, err)
+		}
+	case TYPE_BINARY:
+		if _, err := base64.StdEncoding.DecodeString(value); err != nil {
+			return validationErrorf("Key attribute %s is not valid base64: %s", attribute.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateItem checks that item holds the table's key attributes with the
+// right types, has no attribute with an empty name and fits in
+// MaxItemSize.
+func (t *Table) validateItem(item []Attribute) error {
+	var key []*Attribute
+	if t.Key.KeyAttribute != nil {
+		key = append(key, t.Key.KeyAttribute)
+	}
+	if t.Key.HasRange() {
+		key = append(key, t.Key.RangeAttribute)
+	}
+	for _, k := range key {
+		a := findAttribute(item, k.Name)
+		if a == nil {
+			return validationErrorf("Missing key attribute %s in item for table %s.", k.Name, t.Name)
+		}
+		if a.Type != k.Type {
+			return validationErrorf("Key attribute %s is of type %s, got %s.", k.Name, k.Type, a.Type)
+		}
+		if err := validateKeyValue(k, a.Value); err != nil {
+			return err
+		}
+	}
+	if err := validateAttributeNames(item); err != nil {
+		return err
+	}
+	if size := EstimateItemSize(item); size > MaxItemSize {
+		return validationErrorf("Item size of %d bytes exceeds the maximum of %d bytes.", size, MaxItemSize)
+	}
+	return nil
+}
+
+func validateAttributeNames(attributes []Attribute) error {
+	for _, a := range attributes {
+		if a.Name == "" {
+			return validationErrorf("Attribute names cannot be empty.")
+		}
+	}
+	return nil
+}
+
+func findAttribute(attributes []Attribute, name string) *Attribute {
+	for i := range attributes {
+		if attributes[i].Name == name {
+			return &attributes[i]
+		}
+	}
+	return nil
+}
+
+// validateBatchGet checks the keys of a BatchGetItem call, which Execute
+// splits into requests of at most 100 keys.
+func validateBatchGet(keys map[*Table][]Key) error {
+	for t, tableKeys := range keys {
+		for i := range tableKeys {
+			if err := t.validateKey(&tableKeys[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateBatchWrite checks the requests of a BatchWriteItem call. Put
+// requests carry whole items and Delete requests key attributes.
+func validateBatchWrite(actions map[*Table]map[string][][]Attribute) error {
+	n, size := 0, 0
+	for t, tableActions := range actions {
+		for action, items := range tableActions {
+			for _, item := range items {
+				var err error
+				if action == "Put" {
+					err = t.validateItem(item)
+				} else {
+					err = t.validateKeyAttributes(item)
+				}
+				if err != nil {
+					return err
+				}
+				size += EstimateItemSize(item)
+			}
+			n += len(items)
+		}
+	}
+	if n > maxBatchWriteItems {
+		return validationErrorf("Too many requests in BatchWriteItem: %d, the maximum is %d.", n, maxBatchWriteItems)
+	}
+	if size > maxBatchWriteSize {
+		return validationErrorf("BatchWriteItem size of %d bytes exceeds the maximum of %d bytes.", size, maxBatchWriteSize)
+	}
+	return nil
+}
+
+// validateKeyAttributes checks a key given as attributes, as in Delete
+// requests of BatchWriteItem.
+func (t *Table) validateKeyAttributes(attributes []Attribute) error {
+	if t.Key.KeyAttribute == nil {
+		return nil
+	}
+	key := &Key{}
+	if a := findAttribute(attributes, t.Key.KeyAttribute.Name); a != nil {
+		key.HashKey = a.Value
+	}
+	if t.Key.HasRange() {
+		if a := findAttribute(attributes, t.Key.RangeAttribute.Name); a != nil {
+			key.RangeKey = a.Value
+		}
+	}
+	return t.validateKey(key)
+}
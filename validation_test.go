@@ -0,0 +1,98 @@
+package dynamodb_test
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bluele/dynamodb"
+	"github.com/goamz/goamz/aws"
+	"gopkg.in/check.v1"
+)
+
+type ValidationSuite struct {
+	table *dynamodb.Table
+	sent  int
+}
+
+var _ = check.Suite(&ValidationSuite{})
+
+func (s *ValidationSuite) SetUpTest(c *check.C) {
+	server := dynamodb.New(aws.Auth{}, aws.Region{DynamoDBEndpoint: "http://127.0.0.1:1"})
+	s.sent = 0
+	server.Use(func(next dynamodb.Handler) dynamodb.Handler {
+		return func(req *dynamodb.Request) ([]byte, error) {
+			s.sent++
+			return []byte(`{}`), nil
+		}
+	})
+	s.table = server.NewTable("events", dynamodb.PrimaryKey{
+		KeyAttribute:   dynamodb.NewStringAttribute("user", ""),
+		RangeAttribute: dynamodb.NewNumericAttribute("seq", ""),
+	})
+}
+
+func (s *ValidationSuite) check(c *check.C, err error, message string) {
+	c.Check(err, check.ErrorMatches, message)
+	c.Check(dynamodb.IsValidationError(err), check.Equals, true)
+	_, ok := err.(*dynamodb.ValidationError)
+	c.Check(ok, check.Equals, true)
+}
+
+func (s *ValidationSuite) TestItems(c *check.C) {
+	ctx := context.Background()
+	_, err := s.table.PutItemWithOptions(ctx, []dynamodb.Attribute{*dynamodb.NewStringAttribute("user", "alice")}, nil)
+	s.check(c, err, "Missing key attribute seq in item for table events.")
+
+	_, err = s.table.PutItemWithOptions(ctx, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("user", "alice"),
+		*dynamodb.NewStringAttribute("seq", "1"),
+	}, nil)
+	s.check(c, err, "Key attribute seq is of type N, got S.")
+
+	_, err = s.table.PutItemWithOptions(ctx, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("user", "alice"),
+		*dynamodb.NewNumericAttribute("seq", "one"),
+	}, nil)
+	s.check(c, err, "Key attribute seq: .*")
+
+	_, err = s.table.PutItemWithOptions(ctx, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("user", "alice"),
+		*dynamodb.NewNumericAttribute("seq", "1"),
+		*dynamodb.NewStringAttribute("", "x"),
+	}, nil)
+	s.check(c, err, "Attribute names cannot be empty.")
+
+	_, err = s.table.PutItemWithOptions(ctx, []dynamodb.Attribute{
+		*dynamodb.NewStringAttribute("user", "alice"),
+		*dynamodb.NewNumericAttribute("seq", "1"),
+		*dynamodb.NewStringAttribute("blob", strings.Repeat("x", dynamodb.MaxItemSize)),
+	}, nil)
+	s.check(c, err, "Item size of 409618 bytes exceeds the maximum of 409600 bytes.")
+
+	_, err = s.table.DeleteItemWithOptions(ctx, &dynamodb.Key{HashKey: "alice"}, nil)
+	s.check(c, err, "Key attribute seq cannot be empty.")
+
+	c.Check(s.sent, check.Equals, 0)
+}
+
+func (s *ValidationSuite) TestBatches(c *check.C) {
+	keys := make([]dynamodb.Key, 101)
+	for i := range keys {
+		keys[i] = dynamodb.Key{HashKey: "alice", RangeKey: "1"}
+	}
+	keys[100].RangeKey = ""
+	_, err := s.table.BatchGetItems(keys).Execute(false)
+	s.check(c, err, "Key attribute seq cannot be empty.")
+
+	var puts [][]dynamodb.Attribute
+	for i := 0; i < 26; i++ {
+		puts = append(puts, []dynamodb.Attribute{*dynamodb.NewStringAttribute("user", "alice"), *dynamodb.NewNumericAttribute("seq", "1")})
+	}
+	_, err = s.table.BatchWriteItems(map[string][][]dynamodb.Attribute{"Put": puts}).Execute(false)
+	s.check(c, err, "Too many requests in BatchWriteItem: 26, the maximum is 25.")
+
+	_, err = s.table.BatchWriteItems(map[string][][]dynamodb.Attribute{"Delete": {{*dynamodb.NewNumericAttribute("seq", "1")}}}).Execute(false)
+	s.check(c, err, "Key attribute user cannot be empty.")
+
+	c.Check(s.sent, check.Equals, 0)
+}
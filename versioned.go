@@ -0,0 +1,60 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// ErrVersionConflict is returned by the versioned writes when the item was
+// modified (or created) concurrently since the caller read it.
+var ErrVersionConflict = errors.New("Item version conflict")
+
+// Name of the numeric attribute maintained by VersionedPut and
+// VersionedUpdate.
+var VersionAttribute = "version"
+
+func expectedVersion(version int64) []Attribute {
+	if version == 0 {
+		return []Attribute{*NewNumericAttribute(VersionAttribute, "").SetExists(false)}
+	}
+	return []Attribute{*NewNumericAttribute(VersionAttribute, strconv.FormatInt(version, 10)).SetExists(true)}
+}
+
+// VersionedPut writes the item only if its stored version still equals
+// version (0 meaning the item must not exist yet) and returns the new
+// version stored along with it. The put is retried after server errors,
+// as PutItemWithOptions does with or without isRetry, so a put whose
+// response was lost may report ErrVersionConflict for its own write.
+func (t *Table) VersionedPut(hashKey, rangeKey string, attributes []Attribute, version int64, isRetry bool) (int64, error) {
+	next := version + 1
+	item := append([]Attribute{}, attributes...)
+	item = append(item, *NewNumericAttribute(VersionAttribute, strconv.FormatInt(next, 10)))
+	item = append(item, t.Key.Clone(hashKey, rangeKey)...)
+
+	_, err := t.PutItemWithOptions(context.Background(), item, &WriteOptions{Expected: expectedVersion(version), IsRetry: isRetry})
+	if IsConditionalCheckFailed(err) {
+		return version, ErrVersionConflict
+	}
+	if err != nil {
+		return version, err
+	}
+	return next, nil
+}
+
+// VersionedUpdate sets attributes on the item only if its stored version
+// still equals version and returns the new version.
+func (t *Table) VersionedUpdate(key *Key, attributes []Attribute, version int64, isRetry bool) (int64, error) {
+	next := version + 1
+	updates := append([]Attribute{}, attributes...)
+	updates = append(updates, *NewNumericAttribute(VersionAttribute, strconv.FormatInt(next, 10)))
+
+	_, err := t.modifyAttributes(key, updates, expectedVersion(version), "PUT", isRetry)
+	if IsConditionalCheckFailed(err) {
+		return version, ErrVersionConflict
+	}
+	if err != nil {
+		return version, err
+	}
+	return next, nil
+}
@@ -0,0 +1,50 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrWaitTimeout = errors.New("Timed out waiting for the table")
+
+// How often waiters poll DescribeTable.
+var WaitPollInterval = 5 * time.Second
+
+// WaitUntil polls DescribeTable until done reports true, done or
+// DescribeTable fail, or timeout elapses.
+func (t *Table) WaitUntil(timeout time.Duration, done func(*TableDescriptionT) (bool, error)) error {
+	return t.WaitUntilContext(context.Background(), timeout, done)
+}
+
+// WaitUntilContext is WaitUntil with a context, whose error is returned
+// when it is done first.
+func (t *Table) WaitUntilContext(ctx context.Context, timeout time.Duration, done func(*TableDescriptionT) (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		desc, err := t.Server.describeTableContext(ctx, t.Name, false)
+		if err != nil {
+			return err
+		}
+		ok, err := done(desc)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if time.Now().Add(WaitPollInterval).After(deadline) {
+			return ErrWaitTimeout
+		}
+		if !sleepContext(ctx, WaitPollInterval) {
+			return ctx.Err()
+		}
+	}
+}
+
+// WaitUntilStatus waits for the table to reach status, e.g. "ACTIVE".
+func (t *Table) WaitUntilStatus(status string, timeout time.Duration) error {
+	return t.WaitUntil(timeout, func(desc *TableDescriptionT) (bool, error) {
+		return desc.TableStatus == status, nil
+	})
+}
@@ -0,0 +1,63 @@
+package dynamodb_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bluele/dynamodb"
+	"gopkg.in/check.v1"
+)
+
+type WaiterSuite struct {
+	table    *dynamodb.Table
+	interval time.Duration
+}
+
+var _ = check.Suite(&WaiterSuite{})
+
+func (s *WaiterSuite) SetUpTest(c *check.C) {
+	s.table = newFakeTable(c, "users", idKey{})
+	s.interval = dynamodb.WaitPollInterval
+	dynamodb.WaitPollInterval = 10 * time.Millisecond
+}
+
+func (s *WaiterSuite) TearDownTest(c *check.C) {
+	dynamodb.WaitPollInterval = s.interval
+}
+
+func (s *WaiterSuite) TestWaitUntilStatus(c *check.C) {
+	c.Check(s.table.WaitUntilStatus("ACTIVE", time.Second), check.IsNil)
+}
+
+func (s *WaiterSuite) TestTimeout(c *check.C) {
+	polls := 0
+	err := s.table.WaitUntil(55*time.Millisecond, func(desc *dynamodb.TableDescriptionT) (bool, error) {
+		polls++
+		c.Check(desc.TableName, check.Equals, "users")
+		return false, nil
+	})
+	c.Check(err, check.Equals, dynamodb.ErrWaitTimeout)
+	c.Check(polls >= 2 && polls <= 6, check.Equals, true, check.Commentf("%d polls", polls))
+
+	c.Check(s.table.WaitUntilStatus("DELETING", 0), check.Equals, dynamodb.ErrWaitTimeout)
+
+	failed := errors.New("no such index")
+	err = s.table.WaitUntil(time.Second, func(*dynamodb.TableDescriptionT) (bool, error) {
+		return false, failed
+	})
+	c.Check(err, check.Equals, failed)
+}
+
+func (s *WaiterSuite) TestCancel(c *check.C) {
+	dynamodb.WaitPollInterval = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	started := time.Now()
+	err := s.table.WaitUntilContext(ctx, 2*time.Hour, func(*dynamodb.TableDescriptionT) (bool, error) {
+		return false, nil
+	})
+	c.Check(err, check.Equals, context.Canceled)
+	c.Check(time.Since(started) < time.Second, check.Equals, true)
+}
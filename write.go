@@ -0,0 +1,212 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	RETURN_ITEM_COLLECTION_METRICS_SIZE = "SIZE"
+	RETURN_ITEM_COLLECTION_METRICS_NONE = "NONE"
+)
+
+// WriteOptions configures PutItemWithOptions, DeleteItemWithOptions and
+// UpdateItemWithOptions. The zero value performs an unconditional write
+// returning nothing but the error.
+type WriteOptions struct {
+	// Legacy style conditions, see Attribute.SetExists.
+	Expected []Attribute
+	// Expression style condition. Cannot be combined with Expected.
+	ConditionExpression       string
+	ExpressionAttributeNames  map[string]string
+	ExpressionAttributeValues []Attribute
+	// Used by UpdateItemWithOptions instead of attributes and action.
+	UpdateExpression string
+
+	// What the WriteResult should carry, see the RETURN_VALUES_*,
+	// RETURN_CONSUMED_CAPACITY_* and RETURN_ITEM_COLLECTION_METRICS_*
+	// constants.
+	ReturnValues                string
+	ReturnConsumedCapacity      string
+	ReturnItemCollectionMetrics string
+	// RETURN_VALUES_ALL_OLD to get the item failing the condition in the
+	// Item of the returned *Error, saving a read to resolve the conflict;
+	// see ConditionFailedItem.
+	ReturnValuesOnConditionCheckFailure string
+
+	IsRetry bool
+}
+
+// WriteResult holds what Dynamodb returned for a write, as selected by the
+// Return* fields of WriteOptions.
+type WriteResult struct {
+	Attributes            map[string]*Attribute
+	ConsumedCapacity      *ConsumedCapacityT
+	ItemCollectionMetrics *ItemCollectionMetricsT
+	// The x-amzn-RequestId of the response.
+	RequestID string
+}
+
+type ItemCollectionMetricsT struct {
+	ItemCollectionKey   map[string]*Attribute
+	SizeEstimateRangeGB []float64
+}
+
+func (opts *WriteOptions) apply(q *Query) error {
+	if opts.Expected != nil && opts.ConditionExpression != "" {
+		return errors.New("Expected and ConditionExpression cannot be used together.")
+	}
+	if opts.Expected != nil {
+		q.AddExpected(opts.Expected)
+	}
+	if opts.ConditionExpression != "" {
+		q.AddConditionExpression(opts.ConditionExpression)
+	}
+	if opts.UpdateExpression != "" {
+		q.AddUpdateExpression(opts.UpdateExpression)
+	}
+	q.AddExpressionAttributeNames(opts.ExpressionAttributeNames)
+	q.AddExpressionAttributeValues(opts.ExpressionAttributeValues)
+	if opts.ReturnValues != "" {
+		q.AddReturnValues(opts.ReturnValues)
+	}
+	if opts.ReturnConsumedCapacity != "" {
+		q.AddReturnConsumedCapacity(opts.ReturnConsumedCapacity)
+	}
+	if opts.ReturnItemCollectionMetrics != "" {
+		q.AddReturnItemCollectionMetrics(opts.ReturnItemCollectionMetrics)
+	}
+	if opts.ReturnValuesOnConditionCheckFailure != "" {
+		q.AddReturnValuesOnConditionCheckFailure(opts.ReturnValuesOnConditionCheckFailure)
+	}
+	return nil
+}
+
+// PutItemWithOptions writes item, which must include the primary key
+// attributes. Throttling and server errors are retried with backoff, at
+// most 4 times without opts.IsRetry and following the Server's retry
+// policy with it.
+func (t *Table) PutItemWithOptions(ctx context.Context, item []Attribute, opts *WriteOptions) (*WriteResult, error) {
+	if len(item) == 0 {
+		return nil, errors.New("At least one attribute is required.")
+	}
+	if opts == nil {
+		opts = &WriteOptions{}
+	}
+	if err := t.validateItem(item); err != nil {
+		return nil, err
+	}
+
+	q := NewQuery(t)
+	q.AddItem(item)
+	if err := opts.apply(q); err != nil {
+		return nil, err
+	}
+
+	if !opts.IsRetry {
+		policy := *t.Server.retryPolicy()
+		policy.MaxRetries = maxNumberOfRetry
+		ctx = withRetryPolicy(ctx, &policy)
+	}
+	return t.write(ctx, "PutItem", q, true)
+}
+
+func (t *Table) DeleteItemWithOptions(ctx context.Context, key *Key, opts *WriteOptions) (*WriteResult, error) {
+	if opts == nil {
+		opts = &WriteOptions{}
+	}
+	if err := t.validateKey(key); err != nil {
+		return nil, err
+	}
+
+	q := NewQuery(t)
+	q.AddKey(t, key)
+	if err := opts.apply(q); err != nil {
+		return nil, err
+	}
+
+	return t.write(ctx, "DeleteItem", q, opts.IsRetry)
+}
+
+// UpdateItemWithOptions applies action ("PUT", "ADD" or "DELETE") to
+// attributes of the item, or runs opts.UpdateExpression when attributes
+// is empty.
+func (t *Table) UpdateItemWithOptions(ctx context.Context, key *Key, attributes []Attribute, action string, opts *WriteOptions) (*WriteResult, error) {
+	if opts == nil {
+		opts = &WriteOptions{}
+	}
+	if len(attributes) == 0 && opts.UpdateExpression == "" {
+		return nil, errors.New("At least one attribute is required.")
+	}
+	if len(attributes) > 0 && opts.UpdateExpression != "" {
+		return nil, errors.New("Attributes and UpdateExpression cannot be used together.")
+	}
+	if err := t.validateKey(key); err != nil {
+		return nil, err
+	}
+	if err := validateAttributeNames(attributes); err != nil {
+		return nil, err
+	}
+
+	q := NewQuery(t)
+	q.AddKey(t, key)
+	if len(attributes) > 0 {
+		q.AddUpdates(attributes, action)
+	}
+	if err := opts.apply(q); err != nil {
+		return nil, err
+	}
+
+	return t.write(ctx, "UpdateItem", q, opts.IsRetry)
+}
+
+// write sends a PutItem, UpdateItem or DeleteItem request.
+func (t *Table) write(ctx context.Context, operation string, q *Query, isRetry bool) (*WriteResult, error) {
+	md := &ResponseMetadata{}
+	jsonResponse, err := t.Server.queryServerContext(WithResponseMetadata(ctx, md), target(operation), q, isRetry)
+	if outer, ok := ctx.Value(responseMetadataKey{}).(*ResponseMetadata); ok {
+		*outer = *md
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseWriteResult(jsonResponse)
+	if err != nil {
+		return nil, err
+	}
+	result.RequestID = md.RequestID
+	return result, nil
+}
+
+// writeResponse is the body of a PutItem, UpdateItem or DeleteItem
+// response.
+type writeResponse struct {
+	Attributes            attributeMap
+	ConsumedCapacity      *ConsumedCapacityT
+	ItemCollectionMetrics *struct {
+		ItemCollectionKey   attributeMap
+		SizeEstimateRangeGB []float64
+	}
+}
+
+func parseWriteResult(jsonResponse []byte) (*WriteResult, error) {
+	var r writeResponse
+	if err := decodeResponse(jsonResponse, &r); err != nil {
+		return nil, err
+	}
+
+	result := &WriteResult{
+		ConsumedCapacity: r.ConsumedCapacity,
+	}
+	if r.Attributes != nil {
+		result.Attributes = r.Attributes.attributes()
+	}
+	if m := r.ItemCollectionMetrics; m != nil {
+		result.ItemCollectionMetrics = &ItemCollectionMetricsT{SizeEstimateRangeGB: m.SizeEstimateRangeGB}
+		if m.ItemCollectionKey != nil {
+			result.ItemCollectionMetrics.ItemCollectionKey = m.ItemCollectionKey.attributes()
+		}
+	}
+	return result, nil
+}